@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/py"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/flare"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
@@ -39,6 +41,7 @@ func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/hostname", getHostname).Methods("GET")
 	r.HandleFunc("/flare", makeFlare).Methods("POST")
 	r.HandleFunc("/stop", stopAgent).Methods("POST")
+	r.HandleFunc("/flush", flushAgent).Methods("POST")
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
@@ -48,6 +51,8 @@ func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
+	r.HandleFunc("/dogstatsd/capture", startDogstatsdCapture).Methods("POST")
+	r.HandleFunc("/dogstatsd/replay", startDogstatsdReplay).Methods("POST")
 }
 
 func stopAgent(w http.ResponseWriter, r *http.Request) {
@@ -57,6 +62,16 @@ func stopAgent(w http.ResponseWriter, r *http.Request) {
 	w.Write(j)
 }
 
+func flushAgent(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(config.Datadog.GetInt("dogstatsd_flush_on_exit_timeout")) * time.Second
+	if err := common.Flush(timeout); err != nil {
+		log.Errorf("Could not flush the agent: %s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Write([]byte(""))
+}
+
 func getVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	av, _ := version.New(version.AgentVersion, version.Commit)
@@ -209,7 +224,7 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	configs := common.AC.GetLoadedConfigs()
 	configSlice := make([]integration.Config, 0)
 	for _, config := range configs {
-		configSlice = append(configSlice, config)
+		configSlice = append(configSlice, maskSecrets(config))
 	}
 	sort.Slice(configSlice, func(i, j int) bool {
 		return configSlice[i].Name < configSlice[j].Name
@@ -218,6 +233,7 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
+	response.Services = common.AC.GetServiceConfigs()
 
 	jsonConfig, err := json.Marshal(response)
 	if err != nil {
@@ -230,6 +246,29 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonConfig)
 }
 
+// maskSecrets returns a copy of a loaded configuration with its decrypted
+// secrets masked, the data that can't be masked is dropped
+func maskSecrets(c integration.Config) integration.Config {
+	mask := func(data integration.Data) integration.Data {
+		masked, err := secrets.Mask(data)
+		if err != nil {
+			log.Errorf("Unable to mask the secrets of %s, not showing its configuration: %s", c.Name, err)
+			return nil
+		}
+		return masked
+	}
+
+	instances := make([]integration.Data, 0, len(c.Instances))
+	for _, instance := range c.Instances {
+		instances = append(instances, mask(instance))
+	}
+	c.Instances = instances
+	c.InitConfig = mask(c.InitConfig)
+	c.MetricConfig = mask(c.MetricConfig)
+	c.LogsConfig = mask(c.LogsConfig)
+	return c
+}
+
 func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	response := tagger.List(tagger.IsFullCardinality())
 
@@ -242,3 +281,37 @@ func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Write(jsonTags)
 }
+
+func startDogstatsdCapture(w http.ResponseWriter, r *http.Request) {
+	if common.DSD == nil {
+		http.Error(w, "dogstatsd is not running", 503)
+		return
+	}
+
+	duration, err := time.ParseDuration(r.FormValue("duration"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid capture duration: %s", err), 400)
+		return
+	}
+	path, err := common.DSD.StartCapture(duration)
+	if err != nil {
+		log.Errorf("Could not start the dogstatsd capture: %s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Write([]byte(path))
+}
+
+func startDogstatsdReplay(w http.ResponseWriter, r *http.Request) {
+	if common.DSD == nil {
+		http.Error(w, "dogstatsd is not running", 503)
+		return
+	}
+
+	if err := common.DSD.Replay(r.FormValue("file")); err != nil {
+		log.Errorf("Could not replay the dogstatsd capture: %s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Write([]byte(""))
+}
@@ -7,6 +7,7 @@ package response
 
 import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 )
 
 // ConfigCheckResponse holds the config check response
@@ -15,6 +16,7 @@ type ConfigCheckResponse struct {
 	ResolveWarnings map[string][]string           `json:"resolve_warnings"`
 	ConfigErrors    map[string]string             `json:"config_errors"`
 	Unresolved      map[string]integration.Config `json:"unresolved"`
+	Services        []integration.ServiceConfigs  `json:"services"`
 }
 
 // TaggerListResponse holds the tagger list response
@@ -27,3 +29,11 @@ type TaggerListEntity struct {
 	Sources []string `json:"sources"`
 	Tags    []string `json:"tags"`
 }
+
+// SchedulerConfigResult holds the runs of the check instances of a
+// configuration pushed by an external scheduler
+type SchedulerConfigResult struct {
+	Name   string                    `json:"check_name"`
+	Digest string                    `json:"digest"`
+	Stats  map[check.ID]*check.Stats `json:"stats"` // empty until the instances run
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package scheduler implements the api endpoints for the `/scheduler` prefix.
+// This group of endpoints is served to the external check schedulers, like
+// the cluster agent, on their own listener: they push the configurations
+// the agent should run and read back the results of the runs.
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// SetupHandlers adds the specific handlers for /scheduler endpoints
+func SetupHandlers(r *mux.Router) {
+	r.HandleFunc("/{scheduler}/configs", getConfigs).Methods("GET")
+	r.HandleFunc("/{scheduler}/configs", putConfigs).Methods("PUT")
+	r.HandleFunc("/{scheduler}/results", getResults).Methods("GET")
+}
+
+func writeError(w http.ResponseWriter, err error, code int) {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	http.Error(w, string(body), code)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		log.Errorf("Unable to marshal the external scheduler response: %s", err)
+		writeError(w, err, 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(j)
+}
+
+// validateConfig rejects the configurations the agent could not schedule
+func validateConfig(config integration.Config) error {
+	if config.Name == "" {
+		return fmt.Errorf("missing check_name")
+	}
+	if len(config.Instances) == 0 {
+		return fmt.Errorf("no instances for %s", config.Name)
+	}
+	return nil
+}
+
+// putConfigs replaces the configurations of a scheduler, the body is the
+// JSON list of configurations, as returned by the cluster agent
+func putConfigs(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["scheduler"]
+
+	var configs []integration.Config
+	if err := json.NewDecoder(r.Body).Decode(&configs); err != nil {
+		writeError(w, fmt.Errorf("invalid configurations: %s", err), 400)
+		return
+	}
+	for i, config := range configs {
+		if err := validateConfig(config); err != nil {
+			writeError(w, err, 400)
+			return
+		}
+		// The cluster checks flag is only meaningful to the cluster agent
+		configs[i].ClusterCheck = false
+	}
+
+	common.ExternalProvider.SetConfigs(name, configs)
+	log.Infof("External scheduler %s pushed %d configurations", name, len(configs))
+	writeJSON(w, map[string]int{"configs": len(configs)})
+}
+
+// getConfigs returns the configurations last pushed by a scheduler
+func getConfigs(w http.ResponseWriter, r *http.Request) {
+	configs := common.ExternalProvider.GetConfigs(mux.Vars(r)["scheduler"])
+	if configs == nil {
+		configs = []integration.Config{}
+	}
+	writeJSON(w, configs)
+}
+
+// getResults returns the stats of the runs of the instances pushed by a
+// scheduler. The instances of templates are not reported, their ID is only
+// known once they are resolved.
+func getResults(w http.ResponseWriter, r *http.Request) {
+	stats := runner.GetCheckStats()
+
+	results := []response.SchedulerConfigResult{}
+	for _, config := range common.ExternalProvider.GetConfigs(mux.Vars(r)["scheduler"]) {
+		result := response.SchedulerConfigResult{
+			Name:   config.Name,
+			Digest: config.Digest(),
+			Stats:  make(map[check.ID]*check.Stats),
+		}
+		if !config.IsTemplate() {
+			for _, instance := range config.Instances {
+				id := check.BuildID(config.Name, instance, config.InitConfig)
+				if s, found := stats[config.Name][id]; found {
+					result.Stats[id] = s
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, results)
+}
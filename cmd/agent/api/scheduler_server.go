@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	stdLog "log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/scheduler"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var (
+	schedulerListener net.Listener
+)
+
+// StartSchedulerServer starts the HTTPS server receiving the configurations
+// of the external schedulers. Unlike the IPC server, it listens on a
+// routable address, and trusts the requests bearing the cluster agent token.
+func StartSchedulerServer() error {
+	r := mux.NewRouter()
+	scheduler.SetupHandlers(r.PathPrefix("/scheduler").Subrouter())
+	r.Use(validateSchedulerToken)
+
+	if err := util.SetDCAAuthToken(); err != nil {
+		return err
+	}
+
+	var err error
+	address := net.JoinHostPort(config.Datadog.GetString("external_scheduler.bind_host"), config.Datadog.GetString("external_scheduler.port"))
+	schedulerListener, err = net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("Unable to create the external scheduler server: %v", err)
+	}
+
+	hosts := []string{"127.0.0.1", "::1", "localhost"}
+	_, rootCertPEM, rootKey, err := security.GenerateRootCert(hosts, 2048)
+	if err != nil {
+		return fmt.Errorf("unable to start TLS server")
+	}
+
+	// PEM encode the private key
+	rootKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rootKey),
+	})
+
+	// Create a TLS cert using the private key and certificate
+	rootTLSCert, err := tls.X509KeyPair(rootCertPEM, rootKeyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid key pair: %v", err)
+	}
+
+	tlsConfig := tls.Config{
+		Certificates: []tls.Certificate{rootTLSCert},
+	}
+
+	srv := &http.Server{
+		Handler:      r,
+		ErrorLog:     stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		TLSConfig:    &tlsConfig,
+		WriteTimeout: config.Datadog.GetDuration("server_timeout") * time.Second,
+	}
+	tlsListener := tls.NewListener(schedulerListener, &tlsConfig)
+
+	go srv.Serve(tlsListener)
+	return nil
+}
+
+// StopSchedulerServer closes the connection of the external scheduler server
+func StopSchedulerServer() {
+	if schedulerListener != nil {
+		schedulerListener.Close()
+	}
+}
+
+func validateSchedulerToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := util.ValidateDCARequest(w, r); err != nil {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/util"
@@ -29,6 +30,7 @@ var (
 	checkName  string
 	checkDelay int
 	logLevel   string
+	jsonCheck  bool
 )
 
 // Make the check cmd aggregator never flush by setting a very high interval
@@ -40,6 +42,7 @@ func init() {
 	checkCmd.Flags().BoolVarP(&checkRate, "check-rate", "r", false, "check rates by running the check twice")
 	checkCmd.Flags().StringVarP(&logLevel, "log-level", "l", "", "set the log level (default 'off')")
 	checkCmd.Flags().IntVarP(&checkDelay, "delay", "d", 100, "delay between running the check and grabbing the metrics in miliseconds")
+	checkCmd.Flags().BoolVarP(&jsonCheck, "json", "j", false, "print out the results of the check as json")
 	checkCmd.SetArgs([]string{"checkName"})
 }
 
@@ -55,7 +58,7 @@ var checkCmd = &cobra.Command{
 			return err
 		}
 
-		if flagNoColor {
+		if flagNoColor || jsonCheck {
 			color.NoColor = true
 		}
 
@@ -95,6 +98,10 @@ var checkCmd = &cobra.Command{
 		common.SetupAutoConfig(config.Datadog.GetString("confd_path"))
 		cs := collector.GetChecksByNameForConfigs(checkName, common.AC.GetAllConfigs())
 		if len(cs) == 0 {
+			if jsonCheck {
+				printJSON(newCheckErrorsJSON(checkName))
+				return fmt.Errorf("no valid check found")
+			}
 			for check, error := range autodiscovery.GetConfigErrors() {
 				if checkName == check {
 					fmt.Fprintln(color.Output, fmt.Sprintf("\n%s: invalid config for %s: %s", color.RedString("Error"), color.YellowString(check), error))
@@ -119,6 +126,16 @@ var checkCmd = &cobra.Command{
 			return fmt.Errorf("no valid check found")
 		}
 
+		if jsonCheck {
+			var runs []checkRunJSON
+			for _, c := range cs {
+				s := runCheck(c, agg)
+				time.Sleep(time.Duration(checkDelay) * time.Millisecond)
+				runs = append(runs, newCheckRunJSON(c, s, agg))
+			}
+			return printJSON(runs)
+		}
+
 		if len(cs) > 1 {
 			fmt.Println("Multiple check instances found, running each of them")
 		}
@@ -191,3 +208,59 @@ func printMetrics(agg *aggregator.BufferedAggregator) {
 		fmt.Println(string(j))
 	}
 }
+
+// checkRunJSON is the result of the run of an instance, as printed by
+// `agent check --json`
+type checkRunJSON struct {
+	Check         string                  `json:"check"`
+	ID            check.ID                `json:"id"`
+	Series        []*metrics.Serie        `json:"series"`
+	Sketches      []metrics.SketchSeries  `json:"sketches"`
+	ServiceChecks []*metrics.ServiceCheck `json:"service_checks"`
+	Events        []*metrics.Event        `json:"events"`
+	Stats         *check.Stats            `json:"stats"`
+}
+
+// newCheckRunJSON flushes the aggregator into the result of the run, the
+// slices are converted to their element type to skip their intake encoding
+func newCheckRunJSON(c check.Check, s *check.Stats, agg *aggregator.BufferedAggregator) checkRunJSON {
+	run := checkRunJSON{
+		Check:         c.String(),
+		ID:            c.ID(),
+		Series:        []*metrics.Serie(agg.GetSeries()),
+		Sketches:      []metrics.SketchSeries(agg.GetSketches()),
+		ServiceChecks: []*metrics.ServiceCheck(agg.GetServiceChecks()),
+		Events:        []*metrics.Event(agg.GetEvents()),
+		Stats:         s,
+	}
+	if run.Series == nil {
+		run.Series = []*metrics.Serie{}
+	}
+	if run.Sketches == nil {
+		run.Sketches = []metrics.SketchSeries{}
+	}
+	if run.ServiceChecks == nil {
+		run.ServiceChecks = []*metrics.ServiceCheck{}
+	}
+	if run.Events == nil {
+		run.Events = []*metrics.Event{}
+	}
+	return run
+}
+
+// checkErrorsJSON explains why no instance of a check could be run
+type checkErrorsJSON struct {
+	Error           string            `json:"error"`
+	ConfigError     string            `json:"config_error,omitempty"`
+	LoaderErrors    map[string]string `json:"loader_errors,omitempty"`
+	ResolveWarnings []string          `json:"resolve_warnings,omitempty"`
+}
+
+func newCheckErrorsJSON(checkName string) checkErrorsJSON {
+	return checkErrorsJSON{
+		Error:           "no valid check found",
+		ConfigError:     autodiscovery.GetConfigErrors()[checkName],
+		LoaderErrors:    collector.GetLoaderErrors()[checkName],
+		ResolveWarnings: autodiscovery.GetResolveWarnings()[checkName],
+	}
+}
@@ -7,6 +7,7 @@ package app
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/flare"
@@ -15,12 +16,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var withDebug bool
+var (
+	withDebug       bool
+	jsonConfigCheck bool
+)
 
 func init() {
 	AgentCmd.AddCommand(configCheckCommand)
 
 	configCheckCommand.Flags().BoolVarP(&withDebug, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().BoolVarP(&jsonConfigCheck, "json", "j", false, "print out the configurations as json")
 }
 
 var configCheckCommand = &cobra.Command{
@@ -32,6 +37,12 @@ var configCheckCommand = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("unable to set up global agent configuration: %v", err)
 		}
+		if jsonConfigCheck {
+			if err = flare.GetConfigCheckJSON(os.Stdout, withDebug); err != nil {
+				return printJSONError(err)
+			}
+			return nil
+		}
 		if flagNoColor {
 			color.NoColor = true
 		}
@@ -11,6 +11,8 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/flare"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/fatih/color"
@@ -19,6 +21,8 @@ import (
 
 func init() {
 	AgentCmd.AddCommand(diagnoseCommand)
+	diagnosis.Register("File permissions", diagnosePermissions)
+	diagnosis.Register("Configuration validation", diagnoseConfig)
 }
 
 var diagnoseCommand = &cobra.Command{
@@ -59,3 +63,31 @@ func doDiagnose(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 }
+
+// diagnosePermissions checks that the agent can use its configuration, log
+// file, secrets backend and sockets
+func diagnosePermissions() error {
+	logFile := config.Datadog.GetString("log_file")
+	if logFile == "" {
+		logFile = common.DefaultLogFile
+	}
+	return flare.CheckPermissions(logFile)
+}
+
+// diagnoseConfig checks the options of the configuration file, it fails if
+// the file has unknown, invalid or deprecated options
+func diagnoseConfig() error {
+	issues, err := config.Validate()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	for _, issue := range issues {
+		log.Warn(issue)
+	}
+	if len(issues) > 0 {
+		err = fmt.Errorf("%d issues found in %s", len(issues), config.Datadog.ConfigFileUsed())
+		log.Error(err)
+	}
+	return err
+}
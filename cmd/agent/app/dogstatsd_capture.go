@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var (
+	captureDuration time.Duration
+	replayFile      string
+)
+
+func init() {
+	AgentCmd.AddCommand(dogstatsdCaptureCmd)
+
+	dogstatsdCaptureCmd.Flags().DurationVarP(&captureDuration, "duration", "d", time.Minute, "Duration of the capture")
+	dogstatsdCaptureCmd.Flags().StringVarP(&replayFile, "replay", "r", "", "Replay the given capture file instead of capturing")
+}
+
+var dogstatsdCaptureCmd = &cobra.Command{
+	Use:   "dogstatsd-capture",
+	Short: "Record the dogstatsd traffic received by the running agent, or replay a capture",
+	Long: `Record the raw dogstatsd packets received by the running agent, along with their origin,
+to a capture file. With --replay, feed a capture file back through the dogstatsd
+pipeline of the running agent at the pace it was recorded.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		// Set session token
+		if err = util.SetAuthToken(); err != nil {
+			return err
+		}
+
+		if replayFile != "" {
+			return requestDogstatsdReplay(replayFile)
+		}
+		return requestDogstatsdCapture(captureDuration)
+	},
+}
+
+func requestDogstatsdCapture(duration time.Duration) error {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/dogstatsd/capture", config.Datadog.GetInt("cmd_port"))
+	body := url.Values{"duration": {duration.String()}}.Encode()
+
+	r, err := util.DoPost(c, urlstr, "application/x-www-form-urlencoded", strings.NewReader(body))
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while starting the capture: %s", strings.TrimSpace(string(r)))
+		}
+		return fmt.Errorf("could not reach agent: %v, make sure the agent is running", err)
+	}
+
+	fmt.Fprintln(color.Output, fmt.Sprintf("Capturing the dogstatsd traffic for %s to %s", duration, color.YellowString(string(r))))
+	return nil
+}
+
+func requestDogstatsdReplay(file string) error {
+	// the file is opened by the agent process, its working directory may differ
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	urlstr := fmt.Sprintf("https://localhost:%v/agent/dogstatsd/replay", config.Datadog.GetInt("cmd_port"))
+	body := url.Values{"file": {path}}.Encode()
+
+	r, err := util.DoPost(c, urlstr, "application/x-www-form-urlencoded", strings.NewReader(body))
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while replaying the capture: %s", strings.TrimSpace(string(r)))
+		}
+		return fmt.Errorf("could not reach agent: %v, make sure the agent is running", err)
+	}
+
+	fmt.Fprintln(color.Output, fmt.Sprintf("Replaying %s through the dogstatsd pipeline", color.YellowString(path)))
+	return nil
+}
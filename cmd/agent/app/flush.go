@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func init() {
+	AgentCmd.AddCommand(flushCmd)
+}
+
+var flushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Flush the metrics of the running agent and wait for them to be sent",
+	Long: `Flush the aggregator of the running agent, including the datapoints of the current
+interval, and wait for the forwarder to send them, for at most
+dogstatsd_flush_on_exit_timeout seconds. Meant to be run right before stopping the
+agent, from the pre-stop hook of a container or at the end of a batch job.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		// Set session token
+		if err = util.SetAuthToken(); err != nil {
+			return err
+		}
+
+		c := util.GetClient(false) // FIX: get certificates right then make this true
+		urlstr := fmt.Sprintf("https://localhost:%v/agent/flush", config.Datadog.GetInt("cmd_port"))
+
+		r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer([]byte{}))
+		if err != nil {
+			if r != nil && string(r) != "" {
+				return fmt.Errorf("the agent ran into an error while flushing: %s", strings.TrimSpace(string(r)))
+			}
+			return fmt.Errorf("could not reach agent: %v, make sure the agent is running", err)
+		}
+
+		fmt.Println("Agent successfully flushed")
+		return nil
+	},
+}
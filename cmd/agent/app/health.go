@@ -21,8 +21,19 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 )
 
+var jsonHealth bool
+
 func init() {
 	AgentCmd.AddCommand(healthCmd)
+
+	healthCmd.Flags().BoolVarP(&jsonHealth, "json", "j", false, "print out the health as json")
+}
+
+// healthJSON is the output of `agent health --json`
+type healthJSON struct {
+	Status    string   `json:"status"`
+	Healthy   []string `json:"healthy"`
+	Unhealthy []string `json:"unhealthy"`
 }
 
 var healthCmd = &cobra.Command{
@@ -62,6 +73,9 @@ func requestHealth() error {
 			err = fmt.Errorf(e)
 		}
 
+		if jsonHealth {
+			return printJSONError(err)
+		}
 		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before requesting the status and contact support if you continue having issues. \n", err)
 		return err
 	}
@@ -74,6 +88,10 @@ func requestHealth() error {
 	sort.Strings(s.Unhealthy)
 	sort.Strings(s.Healthy)
 
+	if jsonHealth {
+		return printHealthJSON(s)
+	}
+
 	statusString := color.GreenString("PASS")
 	if len(s.Unhealthy) > 0 {
 		statusString = color.RedString("FAIL")
@@ -92,3 +110,21 @@ func requestHealth() error {
 
 	return nil
 }
+
+func printHealthJSON(s *health.Status) error {
+	h := healthJSON{
+		Status:    "PASS",
+		Healthy:   append([]string{}, s.Healthy...),
+		Unhealthy: append([]string{}, s.Unhealthy...),
+	}
+	if len(h.Unhealthy) > 0 {
+		h.Status = "FAIL"
+	}
+	if err := printJSON(h); err != nil {
+		return err
+	}
+	if len(h.Unhealthy) > 0 {
+		return fmt.Errorf("found %d unhealthy components", len(h.Unhealthy))
+	}
+	return nil
+}
@@ -14,8 +14,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var jsonHostname bool
+
 func init() {
 	AgentCmd.AddCommand(getHostnameCommand)
+
+	getHostnameCommand.Flags().BoolVarP(&jsonHostname, "json", "j", false, "print out the hostname as json")
 }
 
 var getHostnameCommand = &cobra.Command{
@@ -34,9 +38,16 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 	}
 	hname, err := util.GetHostname()
 	if err != nil {
-		return fmt.Errorf("Error getting the hostname: %v", err)
+		err = fmt.Errorf("Error getting the hostname: %v", err)
+		if jsonHostname {
+			return printJSONError(err)
+		}
+		return err
 	}
 
+	if jsonHostname {
+		return printJSON(map[string]string{"hostname": hname})
+	}
 	fmt.Println(hname)
 	return nil
 }
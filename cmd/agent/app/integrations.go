@@ -10,10 +10,12 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -26,7 +28,15 @@ import (
 const (
 	constraintsFile = "agent_requirements.txt"
 	tufConfigFile   = "public-tuf-config.json"
-	tufPkgPattern   = "datadog-.*"
+)
+
+var (
+	// tufPkgPattern matches the official packages, optionally pinned to a
+	// version with ==
+	tufPkgPattern = regexp.MustCompile(`^(datadog-[a-z0-9][a-z0-9-]*)(==([0-9]+(\.[0-9]+)*[a-z0-9.]*))?$`)
+	// requirementName matches the name of the package of a requirement line
+	requirementName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+	sha256Pattern   = regexp.MustCompile(`^[0-9a-f]{64}$`)
 )
 
 var (
@@ -34,6 +44,7 @@ var (
 	withoutTuf   bool
 	useSysPython bool
 	tufConfig    string
+	wheelSHA256  string
 )
 
 func init() {
@@ -54,6 +65,8 @@ func init() {
 	tufCmd.PersistentFlags().MarkHidden("cmd-flags")
 	tufCmd.PersistentFlags().MarkHidden("idx-flags")
 	tufCmd.PersistentFlags().MarkHidden("use-sys-python")
+
+	installCmd.Flags().StringVar(&wheelSHA256, "sha256", "", "SHA256 checksum the downloaded package must match, requires a pinned version")
 }
 
 var tufCmd = &cobra.Command{
@@ -63,10 +76,13 @@ var tufCmd = &cobra.Command{
 }
 
 var installCmd = &cobra.Command{
-	Use:   "install [package]",
+	Use:   "install [package][==version]",
 	Short: "Install Datadog integration core/extra packages",
-	Long:  ``,
-	RunE:  installTuf,
+	Long: `Install an official integration package in the embedded environment.
+A specific version can be installed with package==version, it then replaces the
+version shipped with the agent. The packages are verified against the signed
+metadata of the TUF repository, and against the --sha256 checksum if given.`,
+	RunE: installTuf,
 }
 
 var removeCmd = &cobra.Command{
@@ -136,23 +152,72 @@ func getTUFConfigFilePath() (string, error) {
 	return tufConfig, nil
 }
 
-func validateTufArgs(args []string) error {
+// validateTufArgs checks a single official package is given, and returns
+// its name and version, if pinned
+func validateTufArgs(args []string) (string, string, error) {
 	if len(args) > 1 {
-		return fmt.Errorf("Too many arguments")
+		return "", "", fmt.Errorf("Too many arguments")
 	} else if len(args) == 0 {
-		return fmt.Errorf("Missing package argument")
+		return "", "", fmt.Errorf("Missing package argument")
 	}
 
-	exp, err := regexp.Compile(tufPkgPattern)
+	match := tufPkgPattern.FindStringSubmatch(args[0])
+	if match == nil {
+		return "", "", fmt.Errorf("invalid package name - this manager only handles datadog packages, optionally pinned with ==version")
+	}
+
+	return match[1], match[3], nil
+}
+
+// normalizePackageName normalizes a package name the way pip compares them
+func normalizePackageName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
+
+// writeConstraintsWithout copies the constraints file without the constraint
+// of a package, so that a version other than the one shipped with the agent
+// can be installed. The caller removes the returned temporary file.
+func writeConstraintsWithout(constraintsPath, pkg string) (string, error) {
+	content, err := ioutil.ReadFile(constraintsPath)
 	if err != nil {
-		return fmt.Errorf("internal error: %v", err)
+		return "", err
 	}
 
-	if !exp.MatchString(args[0]) {
-		return fmt.Errorf("invalid package name - this manager only handles datadog packages")
+	var kept []string
+	for _, line := range strings.Split(string(content), "\n") {
+		name := requirementName.FindString(strings.TrimSpace(line))
+		if name != "" && normalizePackageName(name) == normalizePackageName(pkg) {
+			continue
+		}
+		kept = append(kept, line)
 	}
 
-	return nil
+	f, err := ioutil.TempFile("", "agent_requirements")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strings.Join(kept, "\n")); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// writeHashedRequirement writes a requirements file pinning a package to a
+// version and a checksum, pip then refuses any other file. The caller
+// removes the returned temporary file.
+func writeHashedRequirement(pkg, version, sha256 string) (string, error) {
+	f, err := ioutil.TempFile("", "integration_requirement")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "%s==%s --hash=sha256:%s\n", pkg, version, sha256); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
 }
 
 func tuf(args []string) error {
@@ -247,14 +312,35 @@ func tuf(args []string) error {
 }
 
 func installTuf(cmd *cobra.Command, args []string) error {
-	if err := validateTufArgs(args); err != nil {
+	pkg, version, err := validateTufArgs(args)
+	if err != nil {
 		return err
 	}
+	if wheelSHA256 != "" {
+		if version == "" {
+			return errors.New("--sha256 requires a pinned version: package==version")
+		}
+		if !sha256Pattern.MatchString(wheelSHA256) {
+			return errors.New("--sha256 must be a hex encoded SHA256 checksum")
+		}
+	}
+
+	if withoutTuf && wheelSHA256 == "" {
+		fmt.Println(color.YellowString("TUF is disabled and no --sha256 is given: the package won't be verified"))
+	}
 
 	constraintsPath, err := getConstraintsFilePath()
 	if err != nil {
 		return err
 	}
+	if version != "" {
+		// the shipped version of the package is constrained, lift it
+		constraintsPath, err = writeConstraintsWithout(constraintsPath, pkg)
+		if err != nil {
+			return fmt.Errorf("unable to prepare the constraints file: %v", err)
+		}
+		defer os.Remove(constraintsPath)
+	}
 
 	cachePath, err := getTUFPipCachePath()
 	if err != nil {
@@ -267,15 +353,31 @@ func installTuf(cmd *cobra.Command, args []string) error {
 		"-c", constraintsPath,
 	}
 
-	tufArgs = append(tufArgs, args...)
+	if wheelSHA256 == "" {
+		tufArgs = append(tufArgs, args...)
+		return tuf(tufArgs)
+	}
+
+	// in hash-checking mode pip requires the hashes of all the packages it
+	// installs: the dependencies are already part of the agent
+	requirementPath, err := writeHashedRequirement(pkg, version, wheelSHA256)
+	if err != nil {
+		return fmt.Errorf("unable to prepare the requirements file: %v", err)
+	}
+	defer os.Remove(requirementPath)
+	tufArgs = append(tufArgs, "--require-hashes", "--no-deps", "-r", requirementPath)
 
 	return tuf(tufArgs)
 }
 
 func removeTuf(cmd *cobra.Command, args []string) error {
-	if err := validateTufArgs(args); err != nil {
+	_, version, err := validateTufArgs(args)
+	if err != nil {
 		return err
 	}
+	if version != "" {
+		return errors.New("remove doesn't take a version")
+	}
 
 	tufArgs := []string{
 		"uninstall",
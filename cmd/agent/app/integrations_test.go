@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build cpython
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTufArgs(t *testing.T) {
+	tests := []struct {
+		args            []string
+		expectedPkg     string
+		expectedVersion string
+		expectedErr     bool
+	}{
+		{[]string{"datadog-redisdb"}, "datadog-redisdb", "", false},
+		{[]string{"datadog-redisdb==1.2.3"}, "datadog-redisdb", "1.2.3", false},
+		{[]string{"datadog-ibm-mq==1.0.0rc1"}, "datadog-ibm-mq", "1.0.0rc1", false},
+		{[]string{}, "", "", true},
+		{[]string{"datadog-redisdb", "datadog-mysql"}, "", "", true},
+		{[]string{"requests"}, "", "", true},
+		{[]string{"datadog-redisdb>=1.2.3"}, "", "", true},
+		{[]string{"datadog-redisdb==1.2.3; rm -rf /"}, "", "", true},
+	}
+
+	for _, test := range tests {
+		pkg, version, err := validateTufArgs(test.args)
+		if test.expectedErr {
+			assert.Error(t, err, "%v", test.args)
+			continue
+		}
+		require.NoError(t, err, "%v", test.args)
+		assert.Equal(t, test.expectedPkg, pkg)
+		assert.Equal(t, test.expectedVersion, version)
+	}
+}
+
+func TestWriteConstraintsWithout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "constraints")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	constraintsPath := filepath.Join(dir, constraintsFile)
+	constraints := "datadog-checks-base==4.0.0\ndatadog_redisdb==1.2.0\nDatadog.MySQL==1.3.0\nredis==2.10.5\n"
+	require.NoError(t, ioutil.WriteFile(constraintsPath, []byte(constraints), 0644))
+
+	tests := []struct {
+		pkg      string
+		expected string
+	}{
+		{"datadog-redisdb", "datadog-checks-base==4.0.0\nDatadog.MySQL==1.3.0\nredis==2.10.5\n"},
+		{"datadog-mysql", "datadog-checks-base==4.0.0\ndatadog_redisdb==1.2.0\nredis==2.10.5\n"},
+		{"datadog-checks", constraints},
+		{"datadog-postgres", constraints},
+	}
+
+	for _, test := range tests {
+		path, err := writeConstraintsWithout(constraintsPath, test.pkg)
+		require.NoError(t, err, test.pkg)
+		content, err := ioutil.ReadFile(path)
+		os.Remove(path)
+		require.NoError(t, err, test.pkg)
+		assert.Equal(t, test.expected, string(content), test.pkg)
+	}
+
+	_, err = writeConstraintsWithout(filepath.Join(dir, "missing.txt"), "datadog-redisdb")
+	assert.Error(t, err)
+}
+
+func TestWriteHashedRequirement(t *testing.T) {
+	tests := []struct {
+		pkg, version, sha256 string
+		expected             string
+	}{
+		{"datadog-redisdb", "1.2.3", "abc123", "datadog-redisdb==1.2.3 --hash=sha256:abc123\n"},
+		{"datadog-ibm-mq", "1.0.0rc1", "def456", "datadog-ibm-mq==1.0.0rc1 --hash=sha256:def456\n"},
+	}
+
+	for _, test := range tests {
+		path, err := writeHashedRequirement(test.pkg, test.version, test.sha256)
+		require.NoError(t, err, test.pkg)
+		content, err := ioutil.ReadFile(path)
+		os.Remove(path)
+		require.NoError(t, err, test.pkg)
+		assert.Equal(t, test.expected, string(content), test.pkg)
+	}
+}
@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonError is printed instead of the error messages by the commands run
+// with `--json`, so that their output is always a JSON document
+type jsonError struct {
+	Error string `json:"error"`
+}
+
+// printJSON prints v as an indented JSON document on the standard output
+func printJSON(v interface{}) error {
+	j, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the output to JSON: %v", err)
+	}
+	fmt.Println(string(j))
+	return nil
+}
+
+// printJSONError prints err as a JSON document and returns it, the command
+// still exits with an error
+func printJSONError(err error) error {
+	printJSON(jsonError{Error: err.Error()})
+	return err
+}
@@ -44,6 +44,9 @@ const agentChecksMetadataCollectorInterval = 600
 // run the resources metadata collector every 300 seconds (5 minutes) by default, configurable
 const defaultResourcesMetadataCollectorInterval = 300
 
+// run the inventories metadata collector every 600 seconds (10 minutes) by default, configurable
+const defaultInventoriesMetadataCollectorInterval = 600
+
 func init() {
 
 	// attach the command to the root
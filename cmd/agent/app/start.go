@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/hints"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed/jmx"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
@@ -31,14 +32,17 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/relay"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
 
 	// register core checks
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/compliance"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/containers"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/network"
@@ -174,6 +178,11 @@ func StartAgent() error {
 		log.Errorf("Error while starting GUI: %v", err)
 	}
 
+	// correct the timestamps if the system clock is off
+	if config.Datadog.GetBool("clock_correction.enabled") {
+		clock.Start()
+	}
+
 	// setup the forwarder
 	keysPerDomain, err := config.GetMultipleEndpoints()
 	if err != nil {
@@ -187,8 +196,32 @@ func StartAgent() error {
 	// setup the aggregator
 	s := serializer.NewSerializer(common.Forwarder)
 	agg := aggregator.InitAggregator(s, hostname)
+	common.Aggregator = agg
 	agg.AddAgentStartupEvent(version.AgentVersion)
 
+	// send the service checks and events flushed on their own cadence apart
+	if config.Datadog.GetInt("aggregator_events_flush_interval") > 0 {
+		common.EventsForwarder = forwarder.NewDefaultForwarder(keysPerDomain)
+		common.EventsForwarder.Start()
+		agg.SetEventsSerializer(serializer.NewSerializer(common.EventsForwarder))
+	}
+
+	// relay the series to another agent
+	if relayURL := config.Datadog.GetString("metrics_relay.url"); relayURL != "" {
+		common.RelayForwarder = forwarder.NewDefaultForwarder(map[string][]string{relayURL: {config.Datadog.GetString("api_key")}})
+		common.RelayForwarder.Start()
+		if err = agg.SetSeriesRelay(serializer.NewSerializer(common.RelayForwarder)); err != nil {
+			log.Errorf("Could not relay the series to %s: %s", relayURL, err)
+		}
+	}
+
+	// receive the series relayed by other agents
+	if config.Datadog.GetBool("metrics_relay.listener.enabled") {
+		if err = relay.StartServer(common.Forwarder); err != nil {
+			log.Errorf("Error while starting the metrics relay server: %v", err)
+		}
+	}
+
 	// start dogstatsd
 	if config.Datadog.GetBool("use_dogstatsd") {
 		var err error
@@ -204,7 +237,8 @@ func StartAgent() error {
 		if config.Datadog.GetBool("log_enabled") {
 			log.Warn(`"log_enabled" is deprecated, use "logs_enabled" instead`)
 		}
-		err := logs.Start()
+		metricsOut, _, _ := agg.GetChannels()
+		err := logs.Start(metricsOut)
 		if err != nil {
 			log.Error("Could not start logs-agent: ", err)
 		}
@@ -217,6 +251,18 @@ func StartAgent() error {
 	// start the autoconfig, this will immediately run any configured check
 	common.StartAutoConfig()
 
+	// suggest checks for the services listening on the known ports
+	if config.Datadog.GetBool("service_hints.enabled") {
+		hints.Start()
+	}
+
+	// accept the configurations of the external schedulers once the checks can run
+	if config.Datadog.GetBool("external_scheduler.enabled") {
+		if err = api.StartSchedulerServer(); err != nil {
+			log.Errorf("Error while starting the external scheduler server: %v", err)
+		}
+	}
+
 	// setup the metadata collector, this needs a working Python env to function
 	if config.Datadog.GetBool("enable_metadata_collection") {
 		err = setupMetadataCollection(s, hostname)
@@ -235,6 +281,7 @@ func StartAgent() error {
 // setupMetadataCollection initializes the metadata scheduler and its collectors based on the config
 func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 	addDefaultResourcesCollector := true
+	addDefaultInventoriesCollector := true
 	common.MetadataScheduler = metadata.NewScheduler(s, hostname)
 	var C []config.MetadataProviders
 	err := config.Datadog.UnmarshalKey("metadata_providers", &C)
@@ -247,6 +294,9 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 			if c.Name == "resources" {
 				addDefaultResourcesCollector = false
 			}
+			if c.Name == "inventories" {
+				addDefaultInventoriesCollector = false
+			}
 			if c.Interval == 0 {
 				log.Infof("Interval of metadata provider '%v' set to 0, skipping provider", c.Name)
 				continue
@@ -277,6 +327,12 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 			log.Warn("Could not add resources metadata provider: ", err)
 		}
 	}
+	if addDefaultInventoriesCollector {
+		err = common.MetadataScheduler.AddCollector("inventories", defaultInventoriesMetadataCollectorInterval*time.Second)
+		if err != nil {
+			log.Warn("Could not add inventories metadata provider: ", err)
+		}
+	}
 
 	return nil
 }
@@ -299,14 +355,32 @@ func StopAgent() {
 	if common.AC != nil {
 		common.AC.Stop()
 	}
+	hints.Stop()
+	clock.Stop()
 	if common.MetadataScheduler != nil {
 		common.MetadataScheduler.Stop()
 	}
 	api.StopServer()
+	api.StopSchedulerServer()
+	relay.StopServer()
 	jmx.StopJmxfetch()
+	if config.Datadog.GetBool("dogstatsd_flush_on_exit") {
+		timeout := time.Duration(config.Datadog.GetInt("dogstatsd_flush_on_exit_timeout")) * time.Second
+		if err := common.Flush(timeout); err != nil {
+			log.Warnf("Unable to send the last datapoints before exiting: %s", err)
+		} else {
+			log.Info("Sent the last datapoints before exiting")
+		}
+	}
 	if common.Forwarder != nil {
 		common.Forwarder.Stop()
 	}
+	if common.EventsForwarder != nil {
+		common.EventsForwarder.Stop()
+	}
+	if common.RelayForwarder != nil {
+		common.RelayForwarder.Stop()
+	}
 	logs.Stop()
 	gui.StopGUIServer()
 	os.Remove(pidfilePath)
@@ -54,7 +54,10 @@ var statusCmd = &cobra.Command{
 }
 
 func requestStatus() error {
-	fmt.Printf("Getting the status from the agent.\n\n")
+	asJSON := jsonStatus || prettyPrintJSON
+	if !asJSON {
+		fmt.Printf("Getting the status from the agent.\n\n")
+	}
 	var e error
 	var s string
 	c := util.GetClient(false) // FIX: get certificates right then make this true
@@ -75,6 +78,9 @@ func requestStatus() error {
 			e = fmt.Errorf(err)
 		}
 
+		if asJSON {
+			return printJSONError(e)
+		}
 		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before requesting the status and contact support if you continue having issues. \n", e)
 		return e
 	}
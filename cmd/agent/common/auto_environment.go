@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package common
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// environmentAutodiscovery lists the listener and config provider enabled
+// for each detected feature, by order of precedence: only the first detected
+// one is enabled, as the kubelet and the ECS metadata describe the containers
+// docker runs.
+var environmentAutodiscovery = []struct {
+	feature config.Feature
+	name    string
+}{
+	{config.Kubernetes, "kubelet"},
+	{config.ECSFargate, "ecs"},
+	{config.Docker, "docker"},
+}
+
+// getEnvironmentAutodiscovery returns the listener and the config provider
+// of the features detected in the environment
+func getEnvironmentAutodiscovery() ([]config.Listeners, []config.ConfigurationProviders) {
+	for _, ad := range environmentAutodiscovery {
+		if config.IsFeaturePresent(ad.feature) {
+			return []config.Listeners{{Name: ad.name}}, []config.ConfigurationProviders{{Name: ad.name, Polling: true}}
+		}
+	}
+	return nil, nil
+}
+
+// addEnvironmentProviders returns the detected config providers if none is configured
+func addEnvironmentProviders(configProviders []config.ConfigurationProviders) []config.ConfigurationProviders {
+	if len(configProviders) > 0 {
+		return configProviders
+	}
+	_, detected := getEnvironmentAutodiscovery()
+	for _, cp := range detected {
+		log.Infof("Adding the %s config provider detected from the environment", cp.Name)
+	}
+	return detected
+}
+
+// addEnvironmentListeners returns the detected listeners if none is configured
+func addEnvironmentListeners(listeners []config.Listeners) []config.Listeners {
+	if len(listeners) > 0 {
+		return listeners
+	}
+	detected, _ := getEnvironmentAutodiscovery()
+	for _, l := range detected {
+		log.Infof("Adding the %s listener detected from the environment", l.Name)
+	}
+	return detected
+}
@@ -55,10 +55,18 @@ func SetupAutoConfig(confdPath string) {
 	}
 	AC.AddProvider(providers.NewFileConfigProvider(confSearchPaths), false)
 
+	// The configurations pushed by the external schedulers are polled like the
+	// other dynamic sources, and resolved against the local services
+	if config.Datadog.GetBool("external_scheduler.enabled") {
+		ExternalProvider = providers.NewExternalConfigProvider()
+		AC.AddProvider(ExternalProvider, true)
+	}
+
 	// Register additional configuration providers
 	var CP []config.ConfigurationProviders
 	err = config.Datadog.UnmarshalKey("config_providers", &CP)
 	if err == nil {
+		CP = addEnvironmentProviders(CP)
 		for _, cp := range CP {
 			factory, found := providers.ProviderCatalog[cp.Name]
 			if found {
@@ -83,7 +91,7 @@ func SetupAutoConfig(confdPath string) {
 	var listeners []config.Listeners
 	err = config.Datadog.UnmarshalKey("listeners", &listeners)
 	if err == nil {
-		listeners = AutoAddListeners(listeners)
+		listeners = AutoAddListeners(addEnvironmentListeners(listeners))
 		AC.AddListeners(listeners)
 	} else {
 		log.Errorf("Error while reading 'listeners' settings: %v", err)
@@ -10,7 +10,9 @@ package common
 import (
 	"path/filepath"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
@@ -26,15 +28,30 @@ var (
 	// Coll is the global collector instance
 	Coll *collector.Collector
 
+	// ExternalProvider holds the configurations pushed by the external schedulers,
+	// nil unless `external_scheduler.enabled` is set
+	ExternalProvider *providers.ExternalConfigProvider
+
 	// DSD is the global dogstastd instance
 	DSD *dogstatsd.Server
 
 	// MetadataScheduler is responsible to orchestrate metadata collection
 	MetadataScheduler *metadata.Scheduler
 
+	// Aggregator is the global aggregator instance
+	Aggregator *aggregator.BufferedAggregator
+
 	// Forwarder is the global forwarder instance
 	Forwarder forwarder.Forwarder
 
+	// EventsForwarder sends the service checks and events flushed on their own
+	// cadence, nil unless `aggregator_events_flush_interval` is set
+	EventsForwarder forwarder.Forwarder
+
+	// RelayForwarder sends the series to the agent set in `metrics_relay.url`,
+	// nil unless it's set
+	RelayForwarder forwarder.Forwarder
+
 	// utility variables
 	_here, _ = executable.Folder()
 )
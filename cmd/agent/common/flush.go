@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// Flush flushes the aggregator, including the datapoints of the current
+// interval, and waits for the forwarders to send the flushed payloads, for at
+// most the timeout. It's meant to be called right before the agent exits.
+func Flush(timeout time.Duration) error {
+	if Aggregator == nil || Forwarder == nil {
+		return fmt.Errorf("the aggregator is not running")
+	}
+
+	deadline := time.Now().Add(timeout)
+	if !Aggregator.FlushAndWait(timeout) {
+		return fmt.Errorf("the aggregator could not be flushed within %s", timeout)
+	}
+	if !Forwarder.Drain(time.Until(deadline)) {
+		return fmt.Errorf("the forwarder could not send the flushed payloads within %s", timeout)
+	}
+	if EventsForwarder != nil && !EventsForwarder.Drain(time.Until(deadline)) {
+		return fmt.Errorf("the events forwarder could not send the flushed events within %s", timeout)
+	}
+	if RelayForwarder != nil && !RelayForwarder.Drain(time.Until(deadline)) {
+		return fmt.Errorf("the relay forwarder could not send the flushed series within %s", timeout)
+	}
+	return nil
+}
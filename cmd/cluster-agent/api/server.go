@@ -59,7 +59,7 @@ func StartServer(sc clusteragent.ServerContext) error {
 	util.SetDCAAuthToken()
 
 	// create cert
-	hosts := []string{"127.0.0.1", "localhost"}
+	hosts := []string{"127.0.0.1", "::1", "localhost"}
 	_, rootCertPEM, rootKey, err := security.GenerateRootCert(hosts, 2048)
 	if err != nil {
 		return fmt.Errorf("unable to start TLS server")
@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/custommetrics"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
@@ -34,7 +35,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
-var stopCh chan struct{}
+var (
+	stopCh              chan struct{}
+	admissionController *admission.Controller
+)
 
 // FIXME: move SetupAutoConfig and StartAutoConfig in their own package so we don't import cmd/agent
 var (
@@ -167,6 +171,7 @@ func start(cmd *cobra.Command, args []string) error {
 		if err := apiserver.StartControllers(ctx); err != nil {
 			log.Errorf("Could not start controllers: %v", err)
 		}
+		admissionController = setupAdmissionController(apiCl)
 	}
 
 	// Setup a channel to catch OS signals
@@ -210,6 +215,9 @@ func start(cmd *cobra.Command, args []string) error {
 	if config.Datadog.GetBool("external_metrics_provider.enabled") {
 		custommetrics.StopServer()
 	}
+	if admissionController != nil {
+		admissionController.Stop()
+	}
 	if stopCh != nil {
 		close(stopCh)
 	}
@@ -238,3 +246,23 @@ func setupClusterCheck() *clusterchecks.Handler {
 	log.Info("Started cluster check Autodiscovery")
 	return clusterCheckHandler
 }
+
+func setupAdmissionController(apiCl *apiserver.APIClient) *admission.Controller {
+	if !config.Datadog.GetBool("admission_controller.enabled") {
+		log.Debug("Admission controller disabled")
+		return nil
+	}
+
+	controller, err := admission.NewController(apiCl.Cl)
+	if err != nil {
+		log.Errorf("Could not setup the admission controller: %s", err)
+		return nil
+	}
+	if err = controller.Start(); err != nil {
+		log.Errorf("Could not start the admission controller: %s", err)
+		return nil
+	}
+
+	log.Info("Started the admission controller")
+	return controller
+}
@@ -187,6 +187,13 @@ func start(cmd *cobra.Command, args []string) error {
 		metaScheduler.Stop()
 	}
 	statsd.Stop()
+	if config.Datadog.GetBool("dogstatsd_flush_on_exit") {
+		timeout := time.Duration(config.Datadog.GetInt("dogstatsd_flush_on_exit_timeout")) * time.Second
+		deadline := time.Now().Add(timeout)
+		if !aggregatorInstance.FlushAndWait(timeout) || !f.Drain(time.Until(deadline)) {
+			log.Warnf("Unable to send the last datapoints within %s", timeout)
+		}
+	}
 	log.Info("See ya!")
 	log.Flush()
 	return nil
@@ -74,6 +74,7 @@ build do
 
             # remove windows specific configs
             delete "/etc/datadog-agent/conf.d/winproc.d"
+            delete "/etc/datadog-agent/conf.d/pagefile.d"
 
             # cleanup clutter
             delete "#{install_dir}/etc"
@@ -83,6 +84,7 @@ build do
 
             # remove windows specific configs
             delete "#{install_dir}/etc/conf.d/winproc.d"
+            delete "#{install_dir}/etc/conf.d/pagefile.d"
 
             delete "#{install_dir}/etc/trace-agent.conf.example"
 
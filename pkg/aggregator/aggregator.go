@@ -8,10 +8,14 @@ package aggregator
 import (
 	"expvar"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
@@ -19,12 +23,25 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 )
 
 // DefaultFlushInterval aggregator default flush interval
 const DefaultFlushInterval = 15 * time.Second // flush interval
 const bucketSize = 10                         // fixed for now
 
+// flushWaitInterval is the interval at which FlushAndWait checks whether the
+// flushed payloads are serialized
+const flushWaitInterval = 100 * time.Millisecond
+
+// Policies applied when a flush overlaps with the serialization of the previous one
+const (
+	// flushOverlapMerge keeps the data in the samplers, it's sent with the next flush
+	flushOverlapMerge = "merge"
+	// flushOverlapSkip drops the data of the overlapping flush
+	flushOverlapSkip = "skip"
+)
+
 // Stats stores a statistic from several past flushes allowing computations like median or percentiles
 type Stats struct {
 	Flushes    [32]int64 // circular buffer of recent flushes stat
@@ -65,8 +82,14 @@ func expStatsMap(statsMap map[string]*Stats) func() interface{} {
 	}
 }
 
+// tagsForOrigin returns the tagger tags of the entity an event or a
+// service check originates from, overridden in tests
+var tagsForOrigin = func(originID string) ([]string, error) {
+	return tagger.Tag(originID, tagger.IsFullCardinality())
+}
+
 func timeNowNano() float64 {
-	return float64(time.Now().UnixNano()) / float64(time.Second) // Unix time with nanosecond precision
+	return float64(clock.Now().UnixNano()) / float64(time.Second) // Unix time with nanosecond precision
 }
 
 var (
@@ -91,6 +114,14 @@ var (
 	aggregatorServiceCheck            = expvar.Int{}
 	aggregatorEvent                   = expvar.Int{}
 	aggregatorHostnameUpdate          = expvar.Int{}
+	aggregatorFlushOverlaps           = expvar.Int{}
+	aggregatorFlushesSkipped          = expvar.Int{}
+	aggregatorFlushDeadlineExceeded   = expvar.Int{}
+	aggregatorChecksMetricFiltered    = expvar.Int{}
+	aggregatorSeriesRelayed           = expvar.Int{}
+	aggregatorSeriesRelayErrors       = expvar.Int{}
+	aggregatorNumberOfEventsFlush     = expvar.Int{}
+	aggregatorEventsFlushesDelayed    = expvar.Int{}
 )
 
 func init() {
@@ -121,11 +152,26 @@ func init() {
 	aggregatorExpvars.Set("ServiceCheck", &aggregatorServiceCheck)
 	aggregatorExpvars.Set("Event", &aggregatorEvent)
 	aggregatorExpvars.Set("HostnameUpdate", &aggregatorHostnameUpdate)
+	aggregatorExpvars.Set("FlushOverlaps", &aggregatorFlushOverlaps)
+	aggregatorExpvars.Set("FlushesSkipped", &aggregatorFlushesSkipped)
+	aggregatorExpvars.Set("FlushDeadlineExceeded", &aggregatorFlushDeadlineExceeded)
+	aggregatorExpvars.Set("ChecksMetricFiltered", &aggregatorChecksMetricFiltered)
+	aggregatorExpvars.Set("SeriesRelayed", &aggregatorSeriesRelayed)
+	aggregatorExpvars.Set("SeriesRelayErrors", &aggregatorSeriesRelayErrors)
+	aggregatorExpvars.Set("NumberOfEventsFlush", &aggregatorNumberOfEventsFlush)
+	aggregatorExpvars.Set("EventsFlushesDelayed", &aggregatorEventsFlushesDelayed)
+	aggregatorExpvars.Set("DroppedSeries", expvar.Func(func() interface{} {
+		return metrics.GetDroppedSeries()
+	}))
 }
 
 // InitAggregator returns the Singleton instance
 func InitAggregator(s *serializer.Serializer, hostname string) *BufferedAggregator {
-	return InitAggregatorWithFlushInterval(s, hostname, DefaultFlushInterval)
+	flushInterval := DefaultFlushInterval
+	if interval := config.Datadog.GetInt("aggregator_flush_interval"); interval > 0 {
+		flushInterval = time.Duration(interval) * time.Second
+	}
+	return InitAggregatorWithFlushInterval(s, hostname, flushInterval)
 }
 
 // InitAggregatorWithFlushInterval returns the Singleton instance with a configured flush interval
@@ -157,8 +203,17 @@ type BufferedAggregator struct {
 	serviceChecks      metrics.ServiceChecks
 	events             metrics.Events
 	flushInterval      time.Duration
-	mu                 sync.Mutex // to protect the checkSamplers field
+	eventsInterval     time.Duration // the service checks and events are flushed with the series if zero
+	flushDeadline      time.Duration // serializing a flush for longer is reported
+	flushOverlapPolicy string
+	flushRequest       chan chan struct{} // closes the received channel once the buckets are flushed
+	inFlightFlushes    int32              // number of payloads being serialized, accessed atomically
+	inFlightEvents     int32              // same, for the service checks and events flushed on their own cadence
+	metricFilter       *metricFilter      // global `metric_patterns`, applied to every check
+	mu                 sync.Mutex         // to protect the checkSamplers and relay fields
 	serializer         *serializer.Serializer
+	eventsSerializer   *serializer.Serializer // sends the service checks and events, the main serializer unless set
+	relay              *seriesRelay           // nil unless the series are relayed to another agent
 	hostname           string
 	hostnameUpdate     chan string
 	hostnameUpdateDone chan struct{}    // signals that the hostname update is finished
@@ -181,9 +236,37 @@ func NewBufferedAggregator(s *serializer.Serializer, hostname string, flushInter
 		hostname:           hostname,
 		hostnameUpdate:     make(chan string),
 		hostnameUpdateDone: make(chan struct{}),
+		flushRequest:       make(chan chan struct{}),
 		health:             health.Register("aggregator"),
 	}
 
+	if interval := config.Datadog.GetInt("aggregator_events_flush_interval"); interval > 0 {
+		aggregator.eventsInterval = time.Duration(interval) * time.Second
+	}
+
+	aggregator.flushDeadline = time.Duration(config.Datadog.GetInt("aggregator_flush_deadline")) * time.Second
+	if aggregator.flushDeadline <= 0 {
+		aggregator.flushDeadline = flushInterval
+	}
+
+	switch policy := config.Datadog.GetString("aggregator_flush_overlap_policy"); policy {
+	case flushOverlapMerge, flushOverlapSkip:
+		aggregator.flushOverlapPolicy = policy
+	default:
+		log.Warnf("Unknown aggregator_flush_overlap_policy %q, using %q", policy, flushOverlapMerge)
+		aggregator.flushOverlapPolicy = flushOverlapMerge
+	}
+
+	filter, err := newMetricFilter(MetricPatterns{
+		Include: config.Datadog.GetStringSlice("metric_patterns.include"),
+		Exclude: config.Datadog.GetStringSlice("metric_patterns.exclude"),
+	})
+	if err != nil {
+		log.Errorf("Ignoring metric_patterns: %s", err)
+	} else {
+		aggregator.metricFilter = filter
+	}
+
 	return aggregator
 }
 
@@ -201,6 +284,19 @@ func deduplicateTags(tags []string) []string {
 	return tags[:idx]
 }
 
+// appendOriginTags enriches the tags with the ones of the origin entity, if any
+func appendOriginTags(tags []string, originID string) []string {
+	if originID == "" {
+		return tags
+	}
+	originTags, err := tagsForOrigin(originID)
+	if err != nil {
+		log.Debugf("Cannot get tags for entity %s: %s", originID, err)
+		return tags
+	}
+	return append(tags, originTags...)
+}
+
 // IsInputQueueEmpty returns true if every input channel for the aggregator are
 // empty. This is mainly useful for tests and benchmark
 func (agg *BufferedAggregator) IsInputQueueEmpty() bool {
@@ -244,6 +340,9 @@ func (agg *BufferedAggregator) registerSender(id check.ID) error {
 
 func (agg *BufferedAggregator) deregisterSender(id check.ID) {
 	agg.mu.Lock()
+	if checkSampler, ok := agg.checkSamplers[id]; ok {
+		checkSampler.contextResolver.release()
+	}
 	delete(agg.checkSamplers, id)
 	agg.mu.Unlock()
 }
@@ -256,6 +355,12 @@ func (agg *BufferedAggregator) handleSenderSample(ss senderMetricSample) {
 		if ss.commit {
 			checkSampler.commit(timeNowNano())
 		} else {
+			// Filtered metrics are dropped before their context is created
+			name := ss.metricSample.Name
+			if !agg.metricFilter.allows(name) || !getCheckMetricFilter(ss.id).allows(name) {
+				aggregatorChecksMetricFiltered.Add(1)
+				return
+			}
 			ss.metricSample.Tags = deduplicateTags(ss.metricSample.Tags)
 			checkSampler.addSample(ss.metricSample)
 		}
@@ -270,9 +375,9 @@ func (agg *BufferedAggregator) addServiceCheck(sc metrics.ServiceCheck) {
 		sc.Host = agg.hostname
 	}
 	if sc.Ts == 0 {
-		sc.Ts = time.Now().Unix()
+		sc.Ts = clock.Now().Unix()
 	}
-	sc.Tags = deduplicateTags(sc.Tags)
+	sc.Tags = deduplicateTags(appendOriginTags(sc.Tags, sc.OriginID))
 
 	agg.serviceChecks = append(agg.serviceChecks, &sc)
 }
@@ -283,9 +388,9 @@ func (agg *BufferedAggregator) addEvent(e metrics.Event) {
 		e.Host = agg.hostname
 	}
 	if e.Ts == 0 {
-		e.Ts = time.Now().Unix()
+		e.Ts = clock.Now().Unix()
 	}
-	e.Tags = deduplicateTags(e.Tags)
+	e.Tags = deduplicateTags(appendOriginTags(e.Tags, e.OriginID))
 
 	agg.events = append(agg.events, &e)
 }
@@ -304,7 +409,12 @@ func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, tim
 
 // GetSeries grabs all the series from the queue and clears the queue
 func (agg *BufferedAggregator) GetSeries() metrics.Series {
-	series := agg.sampler.flush(timeNowNano())
+	return agg.getSeries(timeNowNano())
+}
+
+// getSeries flushes the buckets of the samplers ending before the timestamp
+func (agg *BufferedAggregator) getSeries(timestamp float64) metrics.Series {
+	series := agg.sampler.flush(timestamp)
 	agg.mu.Lock()
 	for _, checkSampler := range agg.checkSamplers {
 		series = append(series, checkSampler.flush()...)
@@ -313,15 +423,16 @@ func (agg *BufferedAggregator) GetSeries() metrics.Series {
 	return series
 }
 
-func (agg *BufferedAggregator) flushSeries() {
+func (agg *BufferedAggregator) flushSeries(timestamp float64) {
 	start := time.Now()
-	series := agg.GetSeries()
+	ts := float64(clock.Now().Unix())
+	series := agg.getSeries(timestamp)
 
 	// Send along a metric that showcases that this Agent is running (internally, in backend,
 	// a `datadog.`-prefixed metric allows identifying this host as an Agent host, used for dogbone icon)
 	series = append(series, &metrics.Serie{
 		Name:           "datadog.agent.running",
-		Points:         []metrics.Point{{Value: 1, Ts: float64(start.Unix())}},
+		Points:         []metrics.Point{{Value: 1, Ts: ts}},
 		Host:           agg.hostname,
 		MType:          metrics.APIGaugeType,
 		SourceTypeName: "System",
@@ -330,7 +441,7 @@ func (agg *BufferedAggregator) flushSeries() {
 	// Send along a metric that counts the number of times we dropped some payloads because we couldn't split them.
 	series = append(series, &metrics.Serie{
 		Name:           "n_o_i_n_d_e_x.datadog.agent.payload.dropped",
-		Points:         []metrics.Point{{Value: float64(split.GetPayloadDrops()), Ts: float64(start.Unix())}},
+		Points:         []metrics.Point{{Value: float64(split.GetPayloadDrops()), Ts: ts}},
 		Host:           agg.hostname,
 		MType:          metrics.APIGaugeType,
 		SourceTypeName: "System",
@@ -347,16 +458,54 @@ func (agg *BufferedAggregator) flushSeries() {
 	}
 
 	// Serialize and forward in a separate goroutine
-	go func() {
-		log.Debug("Flushing ", len(series), " series to the forwarder")
-		err := agg.serializer.SendSeries(series)
-		if err != nil {
-			log.Warnf("Error flushing series: %v", err)
-			aggregatorSeriesFlushErrors.Add(1)
+	relay := agg.getSeriesRelay()
+	agg.serializeAsync("series", start, &agg.inFlightFlushes, func() {
+		if relay != nil {
+			relay.send(series)
+		}
+		if relay == nil || !relay.only {
+			log.Debug("Flushing ", len(series), " series to the forwarder")
+			err := agg.serializer.SendSeries(series)
+			if err != nil {
+				log.Warnf("Error flushing series: %v", err)
+				aggregatorSeriesFlushErrors.Add(1)
+			}
 		}
 		addFlushTime("ChecksMetricSampleFlushTime", int64(time.Since(start)))
 		aggregatorSeriesFlushed.Add(int64(len(series)))
-	}()
+	})
+}
+
+// droppedSeriesServiceCheck reports the series dropped by the aggregator and
+// the serializer since the previous flush, with a few of their names, so that
+// data loss doesn't go unnoticed
+func droppedSeriesServiceCheck(dropped map[string]metrics.DroppedSeriesStats) metrics.ServiceCheck {
+	sc := metrics.ServiceCheck{
+		CheckName: "datadog.agent.dropped_series",
+		Status:    metrics.ServiceCheckOK,
+	}
+	if len(dropped) == 0 {
+		return sc
+	}
+
+	reasons := make([]string, 0, len(dropped))
+	for reason := range dropped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	details := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		stats := dropped[reason]
+		detail := fmt.Sprintf("%s: %d", reason, stats.Count)
+		if len(stats.Examples) > 0 {
+			detail += fmt.Sprintf(" (e.g. %s)", strings.Join(stats.Examples, ", "))
+		}
+		details = append(details, detail)
+	}
+	sc.Status = metrics.ServiceCheckWarning
+	sc.Message = fmt.Sprintf("Series dropped since the last flush, %s", strings.Join(details, "; "))
+	return sc
 }
 
 // GetServiceChecks grabs all the service checks from the queue and clears the queue
@@ -376,6 +525,7 @@ func (agg *BufferedAggregator) flushServiceChecks() {
 		CheckName: "datadog.agent.up",
 		Status:    metrics.ServiceCheckOK,
 	})
+	agg.addServiceCheck(droppedSeriesServiceCheck(metrics.FlushDroppedSeries()))
 
 	serviceChecks := agg.GetServiceChecks()
 	addFlushCount("ServiceChecks", int64(len(serviceChecks)))
@@ -389,36 +539,41 @@ func (agg *BufferedAggregator) flushServiceChecks() {
 	}
 
 	// Serialize and forward in a separate goroutine
-	go func() {
+	s := agg.getEventsSerializer()
+	agg.serializeAsync("service checks", start, agg.eventsInFlight(), func() {
 		log.Debug("Flushing ", len(serviceChecks), " service checks to the forwarder")
-		err := agg.serializer.SendServiceChecks(serviceChecks)
+		err := s.SendServiceChecks(serviceChecks)
 		if err != nil {
 			log.Warnf("Error flushing service checks: %v", err)
 			aggregatorServiceCheckFlushErrors.Add(1)
 		}
 		addFlushTime("ServiceCheckFlushTime", int64(time.Since(start)))
 		aggregatorServiceCheckFlushed.Add(int64(len(serviceChecks)))
-	}()
+	})
 }
 
 // GetSketches grabs all the sketches from the queue and clears the queue
 func (agg *BufferedAggregator) GetSketches() metrics.SketchSeriesList {
+	return agg.getSketches(timeNowNano())
+}
+
+func (agg *BufferedAggregator) getSketches(timestamp float64) metrics.SketchSeriesList {
 	agg.mu.Lock()
 	defer agg.mu.Unlock()
 
-	return agg.distSampler.flush(timeNowNano())
+	return agg.distSampler.flush(timestamp)
 }
 
-func (agg *BufferedAggregator) flushSketches() {
+func (agg *BufferedAggregator) flushSketches(timestamp float64) {
 	// Serialize and forward in a separate goroutine
 	start := time.Now()
-	sketchSeries := agg.GetSketches()
+	sketchSeries := agg.getSketches(timestamp)
 	addFlushCount("Sketches", int64(len(sketchSeries)))
 	if len(sketchSeries) == 0 {
 		return
 	}
 
-	go func() {
+	agg.serializeAsync("sketches", start, &agg.inFlightFlushes, func() {
 		log.Debug("Flushing ", len(sketchSeries), " sketches to the forwarder")
 		err := agg.serializer.SendSketch(sketchSeries)
 		if err != nil {
@@ -427,7 +582,7 @@ func (agg *BufferedAggregator) flushSketches() {
 		}
 		addFlushTime("MetricSketchFlushTime", int64(time.Since(start)))
 		aggregatorSketchesFlushed.Add(int64(len(sketchSeries)))
-	}()
+	})
 }
 
 // GetEvents grabs the events from the queue and clears it
@@ -457,41 +612,218 @@ func (agg *BufferedAggregator) flushEvents() {
 		}
 	}
 
-	go func() {
+	s := agg.getEventsSerializer()
+	agg.serializeAsync("events", start, agg.eventsInFlight(), func() {
 		log.Debug("Flushing ", len(events), " events to the forwarder")
-		err := agg.serializer.SendEvents(events)
+		err := s.SendEvents(events)
 		if err != nil {
 			log.Warnf("Error flushing events: %v", err)
 			aggregatorEventsFlushErrors.Add(1)
 		}
 		addFlushTime("EventFlushTime", int64(time.Since(start)))
 		aggregatorEventsFlushed.Add(int64(len(events)))
+	})
+}
+
+// serializeAsync runs the serialization of a flushed payload in a separate
+// goroutine, keeping track of the payloads still being serialized in inFlight
+// to detect the flushes overlapping with the previous one
+func (agg *BufferedAggregator) serializeAsync(name string, start time.Time, inFlight *int32, serialize func()) {
+	atomic.AddInt32(inFlight, 1)
+	go func() {
+		defer atomic.AddInt32(inFlight, -1)
+		serialize()
+		if elapsed := time.Since(start); elapsed > agg.flushDeadline {
+			log.Warnf("Flushing %s took %s, longer than the flush deadline of %s", name, elapsed, agg.flushDeadline)
+			aggregatorFlushDeadlineExceeded.Add(1)
+		}
 	}()
 }
 
-func (agg *BufferedAggregator) flush() {
-	agg.flushSeries()
-	agg.flushSketches()
+// flush flushes the buckets ending before the timestamp, the service checks
+// and the events
+func (agg *BufferedAggregator) flush(timestamp float64) {
+	agg.flushMetrics(timestamp)
+	agg.flushServiceChecksAndEvents()
+}
+
+func (agg *BufferedAggregator) flushMetrics(timestamp float64) {
+	agg.flushSeries(timestamp)
+	agg.flushSketches(timestamp)
+}
+
+func (agg *BufferedAggregator) flushServiceChecksAndEvents() {
 	agg.flushServiceChecks()
 	agg.flushEvents()
 }
 
+// dropFlush empties the samplers and the queues without sending their content,
+// the service checks and events flushed on their own cadence are kept
+func (agg *BufferedAggregator) dropFlush() {
+	agg.GetSeries()
+	agg.GetSketches()
+	if agg.eventsInterval == 0 {
+		agg.GetServiceChecks()
+		agg.GetEvents()
+	}
+}
+
+// eventsInFlight returns the counter of the service checks and events
+// payloads being serialized, they're counted with the series unless they're
+// flushed on their own cadence
+func (agg *BufferedAggregator) eventsInFlight() *int32 {
+	if agg.eventsInterval > 0 {
+		return &agg.inFlightEvents
+	}
+	return &agg.inFlightFlushes
+}
+
+// SetEventsSerializer sends the service checks and the events with their own
+// serializer, with a forwarder dedicated to them so that they aren't queued
+// behind the series payloads
+func (agg *BufferedAggregator) SetEventsSerializer(s *serializer.Serializer) {
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+	agg.eventsSerializer = s
+}
+
+func (agg *BufferedAggregator) getEventsSerializer() *serializer.Serializer {
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+	if agg.eventsSerializer != nil {
+		return agg.eventsSerializer
+	}
+	return agg.serializer
+}
+
+// tick flushes the aggregator, unless the payloads of the previous flush are
+// still being serialized. In that case, the flush is either merged into the
+// next one or dropped, depending on the overlap policy, so that flushes don't
+// pile up when the serialization can't keep up.
+func (agg *BufferedAggregator) tick() {
+	if inFlight := atomic.LoadInt32(&agg.inFlightFlushes); inFlight > 0 {
+		aggregatorFlushOverlaps.Add(1)
+		if agg.flushOverlapPolicy == flushOverlapSkip {
+			log.Warnf("%d payloads of the previous flush are still being serialized, dropping this flush", inFlight)
+			agg.dropFlush()
+			aggregatorFlushesSkipped.Add(1)
+		} else {
+			log.Warnf("%d payloads of the previous flush are still being serialized, merging this flush into the next one", inFlight)
+		}
+		return
+	}
+
+	start := time.Now()
+	if agg.eventsInterval > 0 {
+		agg.flushMetrics(timeNowNano())
+	} else {
+		agg.flush(timeNowNano())
+	}
+	addFlushTime("MainFlushTime", int64(time.Since(start)))
+	aggregatorNumberOfFlush.Add(1)
+}
+
+// tickEvents flushes the service checks and events on their own cadence,
+// set with `aggregator_events_flush_interval`. The flush is delayed to the
+// next tick while the previous one is still being serialized.
+func (agg *BufferedAggregator) tickEvents() {
+	if inFlight := atomic.LoadInt32(&agg.inFlightEvents); inFlight > 0 {
+		log.Debugf("%d service checks and events payloads are still being serialized, delaying their flush", inFlight)
+		aggregatorEventsFlushesDelayed.Add(1)
+		return
+	}
+	agg.flushServiceChecksAndEvents()
+	aggregatorNumberOfEventsFlush.Add(1)
+}
+
+// FlushAndWait flushes the aggregator, including the buckets still open, once
+// the samples already queued are aggregated. It blocks until the flushed
+// payloads are serialized and submitted to the forwarder, or until the
+// timeout, and returns whether they were. It's used to send the last
+// datapoints before the agent exits.
+func (agg *BufferedAggregator) FlushAndWait(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	flushed := make(chan struct{})
+	select {
+	case agg.flushRequest <- flushed:
+	case <-deadline:
+		return false
+	}
+	select {
+	case <-flushed:
+	case <-deadline:
+		return false
+	}
+	return agg.waitForFlushes(deadline)
+}
+
+// waitForFlushes blocks until no payload is being serialized anymore, or
+// until the deadline
+func (agg *BufferedAggregator) waitForFlushes(deadline <-chan time.Time) bool {
+	ticker := time.NewTicker(flushWaitInterval)
+	defer ticker.Stop()
+
+	for atomic.LoadInt32(&agg.inFlightFlushes)+atomic.LoadInt32(&agg.inFlightEvents) > 0 {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
+
+// drainInputs aggregates the samples, service checks and events already
+// queued on the input channels
+func (agg *BufferedAggregator) drainInputs() {
+	for {
+		select {
+		case sample := <-agg.dogstatsdIn:
+			aggregatorDogstatsdMetricSample.Add(1)
+			agg.addSample(sample, timeNowNano())
+			metrics.PutMetricSample(sample)
+		case ss := <-agg.checkMetricIn:
+			aggregatorChecksMetricSample.Add(1)
+			agg.handleSenderSample(ss)
+		case sc := <-agg.serviceCheckIn:
+			aggregatorServiceCheck.Add(1)
+			agg.addServiceCheck(sc)
+		case e := <-agg.eventIn:
+			aggregatorEvent.Add(1)
+			agg.addEvent(e)
+		default:
+			return
+		}
+	}
+}
+
 func (agg *BufferedAggregator) run() {
 	if agg.TickerChan == nil {
 		flushPeriod := agg.flushInterval
 		agg.TickerChan = time.NewTicker(flushPeriod).C
 	}
+	var eventsTicker <-chan time.Time // never ticks unless a cadence is set
+	if agg.eventsInterval > 0 {
+		eventsTicker = time.NewTicker(agg.eventsInterval).C
+	}
 	for {
 		select {
 		case <-agg.health.C:
 		case <-agg.TickerChan:
-			start := time.Now()
-			agg.flush()
-			addFlushTime("MainFlushTime", int64(time.Since(start)))
+			agg.tick()
+		case <-eventsTicker:
+			agg.tickEvents()
+		case flushed := <-agg.flushRequest:
+			agg.drainInputs()
+			// flush the buckets still open as well, no sample is expected anymore
+			agg.flush(timeNowNano() + bucketSize)
 			aggregatorNumberOfFlush.Add(1)
+			close(flushed)
 		case sample := <-agg.dogstatsdIn:
 			aggregatorDogstatsdMetricSample.Add(1)
 			agg.addSample(sample, timeNowNano())
+			// the contexts hold copies of the tags, the sample can be reused
+			metrics.PutMetricSample(sample)
 		case ss := <-agg.checkMetricIn:
 			aggregatorChecksMetricSample.Add(1)
 			agg.handleSenderSample(ss)
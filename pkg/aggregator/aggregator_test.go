@@ -7,7 +7,10 @@ package aggregator
 
 import (
 	// stdlib
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	// 3p
 	"github.com/stretchr/testify/assert"
@@ -128,6 +131,45 @@ func TestAddEventDefaultValues(t *testing.T) {
 	assert.Equal(t, "custom_source_type", event2.SourceTypeName)
 }
 
+func TestAddOriginTags(t *testing.T) {
+	resetAggregator()
+	agg := InitAggregator(nil, "resolved-hostname")
+
+	defer func(f func(string) ([]string, error)) { tagsForOrigin = f }(tagsForOrigin)
+	tagsForOrigin = func(originID string) ([]string, error) {
+		if originID == "docker://abcdef" {
+			return []string{"image_name:redis", "foo"}, nil
+		}
+		return nil, fmt.Errorf("unknown entity %s", originID)
+	}
+
+	agg.addServiceCheck(metrics.ServiceCheck{
+		CheckName: "my_service.can_connect",
+		Tags:      []string{"foo"},
+		OriginID:  "docker://abcdef",
+	})
+	agg.addServiceCheck(metrics.ServiceCheck{
+		CheckName: "my_service.can_connect",
+		Tags:      []string{"foo"},
+		OriginID:  "docker://unknown",
+	})
+	agg.addEvent(metrics.Event{
+		Title:    "An event occurred",
+		OriginID: "docker://abcdef",
+	})
+	agg.addEvent(metrics.Event{
+		Title: "Another event occurred",
+		Tags:  []string{"bar"},
+	})
+
+	require.Len(t, agg.serviceChecks, 2)
+	assert.Equal(t, []string{"foo", "image_name:redis"}, agg.serviceChecks[0].Tags)
+	assert.Equal(t, []string{"foo"}, agg.serviceChecks[1].Tags)
+	require.Len(t, agg.events, 2)
+	assert.Equal(t, []string{"image_name:redis", "foo"}, agg.events[0].Tags)
+	assert.Equal(t, []string{"bar"}, agg.events[1].Tags)
+}
+
 func TestSetHostname(t *testing.T) {
 	resetAggregator()
 	agg := InitAggregator(nil, "hostname")
@@ -135,3 +177,101 @@ func TestSetHostname(t *testing.T) {
 	agg.SetHostname("different-hostname")
 	assert.Equal(t, "different-hostname", agg.hostname)
 }
+
+func TestTickFlushOverlapMerge(t *testing.T) {
+	resetAggregator()
+	agg := InitAggregator(nil, "hostname")
+	agg.flushOverlapPolicy = flushOverlapMerge
+
+	agg.addServiceCheck(metrics.ServiceCheck{CheckName: "my_service.can_connect"})
+	agg.inFlightFlushes = 1
+	overlaps := aggregatorFlushOverlaps.Value()
+
+	agg.tick()
+	assert.Equal(t, overlaps+1, aggregatorFlushOverlaps.Value())
+	// kept for the next flush
+	assert.Len(t, agg.serviceChecks, 1)
+}
+
+func TestTickFlushOverlapSkip(t *testing.T) {
+	resetAggregator()
+	agg := InitAggregator(nil, "hostname")
+	agg.flushOverlapPolicy = flushOverlapSkip
+
+	agg.addServiceCheck(metrics.ServiceCheck{CheckName: "my_service.can_connect"})
+	agg.addEvent(metrics.Event{Title: "my event"})
+	agg.inFlightFlushes = 1
+	skipped := aggregatorFlushesSkipped.Value()
+
+	agg.tick()
+	assert.Equal(t, skipped+1, aggregatorFlushesSkipped.Value())
+	assert.Len(t, agg.serviceChecks, 0)
+	assert.Len(t, agg.events, 0)
+}
+
+func TestTickFlushOverlapSkipEventsCadence(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+	agg.flushOverlapPolicy = flushOverlapSkip
+	agg.eventsInterval = 2 * time.Second
+
+	agg.addServiceCheck(metrics.ServiceCheck{CheckName: "my_service.can_connect"})
+	agg.addEvent(metrics.Event{Title: "my event"})
+	agg.inFlightFlushes = 1
+
+	agg.tick()
+	// flushed on their own cadence
+	assert.Len(t, agg.serviceChecks, 1)
+	assert.Len(t, agg.events, 1)
+}
+
+func TestTickEventsDelayed(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+	agg.eventsInterval = 2 * time.Second
+
+	agg.addServiceCheck(metrics.ServiceCheck{CheckName: "my_service.can_connect"})
+	agg.inFlightEvents = 1
+	delayed := aggregatorEventsFlushesDelayed.Value()
+
+	agg.tickEvents()
+	assert.Equal(t, delayed+1, aggregatorEventsFlushesDelayed.Value())
+	// kept for the next flush
+	assert.Len(t, agg.serviceChecks, 1)
+}
+
+func TestEventsInFlight(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+	assert.True(t, agg.eventsInFlight() == &agg.inFlightFlushes)
+
+	agg.eventsInterval = 2 * time.Second
+	assert.True(t, agg.eventsInFlight() == &agg.inFlightEvents)
+}
+
+func TestFlushAndWaitNotRunning(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+	// nothing handles the flush request
+	assert.False(t, agg.FlushAndWait(10*time.Millisecond))
+}
+
+func TestWaitForFlushes(t *testing.T) {
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+	assert.True(t, agg.waitForFlushes(time.After(time.Second)))
+
+	agg.inFlightFlushes = 1
+	assert.False(t, agg.waitForFlushes(time.After(10*time.Millisecond)))
+
+	go atomic.AddInt32(&agg.inFlightFlushes, -1)
+	assert.True(t, agg.waitForFlushes(time.After(time.Second)))
+}
+
+func TestDroppedSeriesServiceCheck(t *testing.T) {
+	sc := droppedSeriesServiceCheck(nil)
+	assert.Equal(t, "datadog.agent.dropped_series", sc.CheckName)
+	assert.Equal(t, metrics.ServiceCheckOK, sc.Status)
+
+	sc = droppedSeriesServiceCheck(map[string]metrics.DroppedSeriesStats{
+		metrics.DropReasonTooBig:        {Count: 3, Examples: []string{"foo", "bar"}},
+		metrics.DropReasonInvalidSample: {Count: 1},
+	})
+	assert.Equal(t, metrics.ServiceCheckWarning, sc.Status)
+	assert.Equal(t, "Series dropped since the last flush, invalid_sample: 1; too_big: 3 (e.g. foo, bar)", sc.Message)
+}
@@ -8,17 +8,24 @@ package aggregator
 import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
 const checksSourceTypeName = "System"
 
+// metricExpiryRuns is the number of runs of a check after which its metrics
+// which were not sampled are forgotten
+const metricExpiryRuns = 10
+
 // CheckSampler aggregates metrics from one Check instance
 type CheckSampler struct {
 	series          []*metrics.Serie
 	contextResolver *ContextResolver
 	metrics         metrics.ContextMetrics
 	defaultHostname string
+	runs            uint64
+	lastRunByKey    map[ckey.ContextKey]uint64
 }
 
 // newCheckSampler returns a newly initialized CheckSampler
@@ -28,6 +35,7 @@ func newCheckSampler(hostname string) *CheckSampler {
 		contextResolver: newContextResolver(),
 		metrics:         metrics.MakeContextMetrics(),
 		defaultHostname: hostname,
+		lastRunByKey:    make(map[ckey.ContextKey]uint64),
 	}
 }
 
@@ -36,7 +44,10 @@ func (cs *CheckSampler) addSample(metricSample *metrics.MetricSample) {
 
 	if err := cs.metrics.AddSample(contextKey, metricSample, metricSample.Timestamp, 1); err != nil {
 		log.Debug("Ignoring sample '%s' on host '%s' and tags '%s': %s", metricSample.Name, metricSample.Host, metricSample.Tags, err)
+		metrics.RecordDroppedSeries(metrics.DropReasonInvalidSample, metricSample.Name)
+		return
 	}
+	cs.lastRunByKey[contextKey] = cs.runs
 }
 
 func (cs *CheckSampler) commit(timestamp float64) {
@@ -69,6 +80,22 @@ func (cs *CheckSampler) commit(timestamp float64) {
 	}
 
 	cs.contextResolver.expireContexts(timestamp - defaultExpiry)
+	cs.expireMetrics()
+}
+
+// expireMetrics forgets the metrics which were not sampled during the last
+// metricExpiryRuns runs of the check. The rates and monotonic counts keep
+// their last sample across the runs to compute their deltas, the expiry is
+// counted in runs to support the checks running less often than the
+// contexts expire.
+func (cs *CheckSampler) expireMetrics() {
+	for contextKey, lastRun := range cs.lastRunByKey {
+		if cs.runs-lastRun >= metricExpiryRuns {
+			delete(cs.metrics, contextKey)
+			delete(cs.lastRunByKey, contextKey)
+		}
+	}
+	cs.runs++
 }
 
 func (cs *CheckSampler) flush() metrics.Series {
@@ -222,3 +222,55 @@ func TestCheckSamplerHostname(t *testing.T) {
 	assert.Contains(t, actualHostnames, "my.test.hostname")
 	assert.Contains(t, actualHostnames, "metric-hostname")
 }
+
+func TestCheckMonotonicCountSampling(t *testing.T) {
+	checkSampler := newCheckSampler("")
+
+	sample := func(value, timestamp float64) *metrics.MetricSample {
+		return &metrics.MetricSample{
+			Name:       "my.metric.name",
+			Value:      value,
+			Mtype:      metrics.MonotonicCountType,
+			Tags:       []string{"foo", "bar"},
+			SampleRate: 1,
+			Timestamp:  timestamp,
+		}
+	}
+
+	// the first run only sets the initial value of the counter
+	checkSampler.addSample(sample(10, 12345.0))
+	checkSampler.commit(12346.0)
+	assert.Len(t, checkSampler.flush(), 0)
+
+	// the next runs submit the increase since the previous run
+	checkSampler.addSample(sample(25, 12360.0))
+	checkSampler.commit(12361.0)
+	series := checkSampler.flush()
+	require.Len(t, series, 1)
+	assert.Equal(t, metrics.APICountType, series[0].MType)
+	assert.Equal(t, []metrics.Point{{Ts: 12361.0, Value: 15}}, series[0].Points)
+
+	// a reset of the counter is not reported as a decrease
+	checkSampler.addSample(sample(3, 12375.0))
+	checkSampler.commit(12376.0)
+	series = checkSampler.flush()
+	require.Len(t, series, 1)
+	assert.Equal(t, []metrics.Point{{Ts: 12376.0, Value: 0}}, series[0].Points)
+
+	checkSampler.addSample(sample(8, 12390.0))
+	checkSampler.commit(12391.0)
+	series = checkSampler.flush()
+	require.Len(t, series, 1)
+	assert.Equal(t, []metrics.Point{{Ts: 12391.0, Value: 5}}, series[0].Points)
+
+	// the counter is forgotten after metricExpiryRuns runs without sample
+	for i := 0; i < metricExpiryRuns-1; i++ {
+		checkSampler.commit(12391.0)
+	}
+	assert.Len(t, checkSampler.metrics, 1)
+	checkSampler.commit(12391.0)
+	assert.Len(t, checkSampler.metrics, 0)
+	checkSampler.addSample(sample(50, 12405.0))
+	checkSampler.commit(12406.0)
+	assert.Len(t, checkSampler.flush(), 0)
+}
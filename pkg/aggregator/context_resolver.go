@@ -11,13 +11,16 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
 )
 
-// Context holds the elements that form a context, and can be serialized into a context key
+// Context holds the elements that form a context, and can be serialized into a context key.
+// Its tags are interned and shared with the other contexts, they must not be modified.
 type Context struct {
-	Name string
-	Tags []string
-	Host string
+	Name    string
+	Tags    []string
+	Host    string
+	tagsKey tagset.Key
 }
 
 // ContextResolver allows tracking and expiring contexts
@@ -42,10 +45,13 @@ func newContextResolver() *ContextResolver {
 func (cr *ContextResolver) trackContext(metricSample *metrics.MetricSample, currentTimestamp float64) ckey.ContextKey {
 	contextKey := generateContextKey(metricSample)
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
+		// the tags were sorted while generating the context key
+		tags, tagsKey := tagset.Intern(metricSample.Tags)
 		cr.contextsByKey[contextKey] = &Context{
-			Name: metricSample.Name,
-			Tags: metricSample.Tags,
-			Host: metricSample.Host,
+			Name:    metricSample.Name,
+			Tags:    tags,
+			Host:    metricSample.Host,
+			tagsKey: tagsKey,
 		}
 	}
 	cr.lastSeenByKey[contextKey] = currentTimestamp
@@ -78,9 +84,22 @@ func (cr *ContextResolver) expireContexts(expireTimestamp float64) []ckey.Contex
 
 	// Delete expired context keys
 	for _, expiredContextKey := range expiredContextKeys {
+		if context, found := cr.contextsByKey[expiredContextKey]; found {
+			tagset.Release(context.tagsKey)
+		}
 		delete(cr.contextsByKey, expiredContextKey)
 		delete(cr.lastSeenByKey, expiredContextKey)
 	}
 
 	return expiredContextKeys
 }
+
+// release drops the references of all the contexts on their interned tags,
+// the resolver must not be used afterwards
+func (cr *ContextResolver) release() {
+	for _, context := range cr.contextsByKey {
+		tagset.Release(context.tagsKey)
+	}
+	cr.contextsByKey = make(map[ckey.ContextKey]*Context)
+	cr.lastSeenByKey = make(map[ckey.ContextKey]float64)
+}
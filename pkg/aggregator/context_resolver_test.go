@@ -7,6 +7,7 @@ package aggregator
 
 import (
 	// stdlib
+	"fmt"
 	"testing"
 
 	// 3p
@@ -14,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
 )
 
 func TestGenerateContextKey(t *testing.T) {
@@ -73,6 +75,11 @@ func TestTrackContext(t *testing.T) {
 	contextKey2 := contextResolver.trackContext(&mSample2, 1)
 	contextKey3 := contextResolver.trackContext(&mSample3, 1)
 
+	// The tags were sorted while tracking, the contexts 2 and 3 share them
+	expectedContext1.tagsKey = tagset.NewKey(mSample1.Tags)
+	expectedContext2.tagsKey = tagset.NewKey(mSample2.Tags)
+	expectedContext3.tagsKey = expectedContext2.tagsKey
+
 	// When we look up the 2 keys, they return the correct contexts
 	context1 := contextResolver.contextsByKey[contextKey1]
 	assert.Equal(t, expectedContext1, *context1)
@@ -82,6 +89,7 @@ func TestTrackContext(t *testing.T) {
 
 	context3 := contextResolver.contextsByKey[contextKey3]
 	assert.Equal(t, expectedContext3, *context3)
+	assert.True(t, &context2.Tags[0] == &context3.Tags[0])
 
 	// Looking for a missing context key returns an error
 	unknownContextKey, _ := ckey.Parse("ffffffffffffffffffffffffffffffff")
@@ -129,3 +137,52 @@ func TestExpireContexts(t *testing.T) {
 	_, ok = contextResolver.contextsByKey[contextKey2]
 	assert.True(t, ok)
 }
+
+func TestContextsTagsInterning(t *testing.T) {
+	contextResolver := newContextResolver()
+	var contexts []*Context
+	for _, name := range []string{"metric.a", "metric.b"} {
+		contextKey := contextResolver.trackContext(&metrics.MetricSample{
+			Name:  name,
+			Mtype: metrics.GaugeType,
+			Tags:  []string{"foo", "bar"},
+		}, 1)
+		contexts = append(contexts, contextResolver.contextsByKey[contextKey])
+	}
+
+	assert.Equal(t, []string{"bar", "foo"}, contexts[0].Tags)
+	assert.Equal(t, tagset.NewKey(contexts[0].Tags), contexts[0].tagsKey)
+	assert.True(t, &contexts[0].Tags[0] == &contexts[1].Tags[0])
+	// the interned tags can't be modified by appending to them
+	assert.Equal(t, len(contexts[0].Tags), cap(contexts[0].Tags))
+
+	contextResolver.release()
+	assert.Len(t, contextResolver.contextsByKey, 0)
+	assert.Len(t, contextResolver.lastSeenByKey, 0)
+}
+
+// BenchmarkTrackContextsSharedTags tracks contexts of many metrics sharing a
+// few tag sets, like the metrics of the replicas of a container
+func BenchmarkTrackContextsSharedTags(b *testing.B) {
+	tagSets := make([][]string, 100)
+	for i := range tagSets {
+		for j := 0; j < 10; j++ {
+			tagSets[i] = append(tagSets[i], fmt.Sprintf("tag%d:value%d", j, i))
+		}
+	}
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		contextResolver := newContextResolver()
+		for i := 0; i < 50000; i++ {
+			tags := make([]string, len(tagSets[i%len(tagSets)]))
+			copy(tags, tagSets[i%len(tagSets)])
+			contextResolver.trackContext(&metrics.MetricSample{
+				Name:  fmt.Sprintf("metric.%d", i),
+				Mtype: metrics.GaugeType,
+				Tags:  tags,
+			}, 1)
+		}
+		contextResolver.release()
+	}
+}
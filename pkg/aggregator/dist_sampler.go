@@ -15,6 +15,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/quantile"
 )
 
+// maxSampleWeight is the weight of a sample with the lowest sample rate, it
+// fills a single bin of the sketch
+const maxSampleWeight = math.MaxUint16
+
 type distSampler struct {
 	interval        int64
 	defaultHostname string
@@ -42,7 +46,7 @@ func (d *distSampler) calculateBucketStart(ts float64) int64 {
 
 func (d *distSampler) addSample(ms *metrics.MetricSample, ts float64) {
 	ck := d.ctxResolver.trackContext(ms, ts)
-	d.m.insert(d.calculateBucketStart(ts), ck, ms.Value)
+	d.m.insert(d.calculateBucketStart(ts), ck, ms.Value, ms.SampleRate)
 }
 
 func (d *distSampler) flush(flushTs float64) metrics.SketchSeriesList {
@@ -95,12 +99,19 @@ func (m sketchMap) Len() int {
 
 // insert v into a sketch for the given (ts, contextKey)
 // NOTE: ts is truncated to bucketSize
-func (m sketchMap) insert(ts int64, ck ckey.ContextKey, v float64) bool {
+// insert adds a value to the sketch of a context. A sampled value is inserted
+// with the weight it stands for, the sketches only hold whole counts. The
+// weight is capped to maxSampleWeight, to bound the effect of a single packet.
+func (m sketchMap) insert(ts int64, ck ckey.ContextKey, v float64, sampleRate float64) bool {
 	if math.IsInf(v, 0) || math.IsNaN(v) {
 		return false
 	}
 
-	m.getOrCreate(ts, ck).Insert(v)
+	n := uint(1)
+	if sampleRate > 0 && sampleRate < 1 {
+		n = uint(math.Min(math.Round(1/sampleRate), maxSampleWeight))
+	}
+	m.getOrCreate(ts, ck).InsertN(v, n)
 	return true
 }
 
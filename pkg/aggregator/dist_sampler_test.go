@@ -176,3 +176,34 @@ func TestDistSamplerContextSampling(t *testing.T) {
 		ContextKey: generateContextKey(&mSample2),
 	}, flushed[1])
 }
+
+func TestDistSamplerSampleRate(t *testing.T) {
+	distSampler := newDistSampler(10, "")
+
+	distSampler.addSample(&metrics.MetricSample{
+		Name:       "test.metric.name",
+		Value:      2,
+		Mtype:      metrics.DistributionType,
+		SampleRate: 0.25,
+	}, 10001)
+
+	flushed := distSampler.flush(10020)
+	require.Len(t, flushed, 1)
+	require.Len(t, flushed[0].Points, 1)
+	// the sampled value stands for 4 values
+	assert.EqualValues(t, 4, flushed[0].Points[0].Sketch.Basic.Cnt)
+	assert.EqualValues(t, 8, flushed[0].Points[0].Sketch.Basic.Sum)
+
+	// the weight of a tiny sample rate is capped
+	distSampler.addSample(&metrics.MetricSample{
+		Name:       "test.metric.name",
+		Value:      2,
+		Mtype:      metrics.DistributionType,
+		SampleRate: 0.000000001,
+	}, 10031)
+
+	flushed = distSampler.flush(10050)
+	require.Len(t, flushed, 1)
+	require.Len(t, flushed[0].Points, 1)
+	assert.EqualValues(t, maxSampleWeight, flushed[0].Points[0].Sketch.Basic.Cnt)
+}
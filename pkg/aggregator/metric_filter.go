@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+// MetricPatterns holds the regular expressions a metric name is matched
+// against before its context is created in the aggregator. When include is
+// set, only the matching metrics are kept; the ones matching exclude are
+// always dropped.
+type MetricPatterns struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// metricFilter is the compiled form of MetricPatterns, a nil filter
+// allows every metric
+type metricFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// checkMetricFilters keeps the filter of every check instance configured
+// with `metric_patterns`, it's filled by the loaders alongside the config
+// hashes so that all kind of checks get one.
+var (
+	checkMetricFilters      = make(map[check.ID]*metricFilter)
+	checkMetricFiltersMutex sync.RWMutex
+)
+
+func newMetricFilter(patterns MetricPatterns) (*metricFilter, error) {
+	if len(patterns.Include) == 0 && len(patterns.Exclude) == 0 {
+		return nil, nil
+	}
+
+	include, err := compilePatterns(patterns.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns(patterns.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &metricFilter{include: include, exclude: exclude}, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric pattern %q: %s", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// allows returns whether a metric with this name should be aggregated
+func (f *metricFilter) allows(name string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchesAny(f.include, name) {
+		return false
+	}
+	return !matchesAny(f.exclude, name)
+}
+
+func matchesAny(patterns []*regexp.Regexp, name string) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCheckMetricPatterns parses the `metric_patterns` section of a check
+// instance config and applies it to the metrics submitted by this instance.
+func SetCheckMetricPatterns(id check.ID, instance integration.Data) error {
+	var conf struct {
+		MetricPatterns MetricPatterns `yaml:"metric_patterns"`
+	}
+	if err := yaml.Unmarshal(instance, &conf); err != nil {
+		return err
+	}
+	filter, err := newMetricFilter(conf.MetricPatterns)
+	if err != nil {
+		return err
+	}
+
+	checkMetricFiltersMutex.Lock()
+	defer checkMetricFiltersMutex.Unlock()
+
+	if filter == nil {
+		delete(checkMetricFilters, id)
+	} else {
+		checkMetricFilters[id] = filter
+	}
+	return nil
+}
+
+// RemoveCheckMetricPatterns forgets the metric patterns of a check instance
+func RemoveCheckMetricPatterns(id check.ID) {
+	checkMetricFiltersMutex.Lock()
+	defer checkMetricFiltersMutex.Unlock()
+
+	delete(checkMetricFilters, id)
+}
+
+func getCheckMetricFilter(id check.ID) *metricFilter {
+	checkMetricFiltersMutex.RLock()
+	defer checkMetricFiltersMutex.RUnlock()
+
+	return checkMetricFilters[id]
+}
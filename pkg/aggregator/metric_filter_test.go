@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	// stdlib
+	"testing"
+
+	// 3p
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestMetricFilterAllows(t *testing.T) {
+	var noFilter *metricFilter
+	assert.True(t, noFilter.allows("anything"))
+
+	filter, err := newMetricFilter(MetricPatterns{})
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+
+	filter, err = newMetricFilter(MetricPatterns{
+		Include: []string{`^my_check\.`},
+		Exclude: []string{`\.by_user$`},
+	})
+	require.NoError(t, err)
+	assert.True(t, filter.allows("my_check.requests"))
+	assert.False(t, filter.allows("my_check.requests.by_user"))
+	assert.False(t, filter.allows("other_check.requests"))
+
+	filter, err = newMetricFilter(MetricPatterns{Exclude: []string{`^system\.`}})
+	require.NoError(t, err)
+	assert.True(t, filter.allows("my_check.requests"))
+	assert.False(t, filter.allows("system.load.1"))
+
+	_, err = newMetricFilter(MetricPatterns{Include: []string{`(`}})
+	assert.Error(t, err)
+}
+
+func TestSetCheckMetricPatterns(t *testing.T) {
+	defer RemoveCheckMetricPatterns(checkID1)
+
+	err := SetCheckMetricPatterns(checkID1, []byte("metric_patterns:\n  exclude:\n    - ^my_check\\.debug\\."))
+	require.NoError(t, err)
+	filter := getCheckMetricFilter(checkID1)
+	require.NotNil(t, filter)
+	assert.False(t, filter.allows("my_check.debug.queue"))
+	assert.True(t, filter.allows("my_check.queue"))
+
+	// an invalid pattern keeps the previous filter
+	err = SetCheckMetricPatterns(checkID1, []byte("metric_patterns:\n  exclude:\n    - \"(\""))
+	assert.Error(t, err)
+	assert.Equal(t, filter, getCheckMetricFilter(checkID1))
+
+	// removing the section removes the filter
+	err = SetCheckMetricPatterns(checkID1, []byte("tags: [foo]"))
+	require.NoError(t, err)
+	assert.Nil(t, getCheckMetricFilter(checkID1))
+}
+
+func TestHandleSenderSampleFiltered(t *testing.T) {
+	defer RemoveCheckMetricPatterns(checkID1)
+
+	agg := NewBufferedAggregator(nil, "", DefaultFlushInterval)
+	filter, err := newMetricFilter(MetricPatterns{Exclude: []string{`^global\.dropped$`}})
+	require.NoError(t, err)
+	agg.metricFilter = filter
+	require.NoError(t, SetCheckMetricPatterns(checkID1, []byte("metric_patterns:\n  exclude: [instance.dropped]")))
+	require.NoError(t, agg.registerSender(checkID1))
+	require.NoError(t, agg.registerSender(checkID2))
+
+	for _, name := range []string{"global.dropped", "instance.dropped", "kept"} {
+		for _, id := range []check.ID{checkID1, checkID2} {
+			agg.handleSenderSample(senderMetricSample{
+				id:           id,
+				metricSample: &metrics.MetricSample{Name: name, Value: 1, Mtype: metrics.GaugeType, Timestamp: 12345},
+			})
+		}
+	}
+
+	assert.Len(t, agg.checkSamplers[checkID1].contextResolver.contextsByKey, 1)
+	assert.Len(t, agg.checkSamplers[checkID2].contextResolver.contextsByKey, 2)
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// seriesRelay sends the aggregated series to another agent, a gateway running
+// the relay listener, instead of or in addition to the intake
+type seriesRelay struct {
+	serializer *serializer.Serializer
+	filter     *metricFilter
+	only       bool
+}
+
+// SetSeriesRelay relays the flushed series with a serializer sending to
+// another agent. The relayed series are filtered by
+// `metrics_relay.metric_patterns`, and the series are not sent to the intake
+// anymore when `metrics_relay.only` is set.
+func (agg *BufferedAggregator) SetSeriesRelay(s *serializer.Serializer) error {
+	filter, err := newMetricFilter(MetricPatterns{
+		Include: config.Datadog.GetStringSlice("metrics_relay.metric_patterns.include"),
+		Exclude: config.Datadog.GetStringSlice("metrics_relay.metric_patterns.exclude"),
+	})
+	if err != nil {
+		return err
+	}
+
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+	agg.relay = &seriesRelay{
+		serializer: s,
+		filter:     filter,
+		only:       config.Datadog.GetBool("metrics_relay.only"),
+	}
+	return nil
+}
+
+func (agg *BufferedAggregator) getSeriesRelay() *seriesRelay {
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+	return agg.relay
+}
+
+// send relays the series allowed by the filter of the relay
+func (r *seriesRelay) send(series metrics.Series) {
+	relayed := r.filterSeries(series)
+	if len(relayed) == 0 {
+		return
+	}
+	log.Debug("Relaying ", len(relayed), " series")
+	if err := r.serializer.SendSeries(relayed); err != nil {
+		log.Warnf("Error relaying series: %v", err)
+		aggregatorSeriesRelayErrors.Add(1)
+		return
+	}
+	aggregatorSeriesRelayed.Add(int64(len(relayed)))
+}
+
+// filterSeries returns the series to relay, the given slice is left untouched
+// as it's sent to the intake as well
+func (r *seriesRelay) filterSeries(series metrics.Series) metrics.Series {
+	if r.filter == nil {
+		return series
+	}
+	relayed := make(metrics.Series, 0, len(series))
+	for _, serie := range series {
+		if r.filter.allows(serie.Name) {
+			relayed = append(relayed, serie)
+		}
+	}
+	return relayed
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package aggregator
+
+import (
+	// stdlib
+	"testing"
+
+	// 3p
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestSeriesRelayFilter(t *testing.T) {
+	series := metrics.Series{
+		{Name: "app.requests"},
+		{Name: "app.requests.by_user"},
+		{Name: "system.load.1"},
+	}
+
+	relay := &seriesRelay{}
+	assert.Equal(t, series, relay.filterSeries(series))
+
+	filter, err := newMetricFilter(MetricPatterns{
+		Include: []string{`^app\.`},
+		Exclude: []string{`\.by_user$`},
+	})
+	require.NoError(t, err)
+	relay = &seriesRelay{filter: filter}
+	assert.Equal(t, metrics.Series{{Name: "app.requests"}}, relay.filterSeries(series))
+	// the series sent to the intake are left untouched
+	assert.Len(t, series, 3)
+}
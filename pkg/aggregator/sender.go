@@ -9,8 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"sync"
-	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
@@ -243,7 +243,7 @@ func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheck
 		CheckName: checkName,
 		Status:    status,
 		Host:      hostname,
-		Ts:        time.Now().Unix(),
+		Ts:        clock.Now().Unix(),
 		Tags:      tags,
 		Message:   message,
 	}
@@ -8,10 +8,12 @@ package autodiscovery
 import (
 	"expvar"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/configresolver"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/hints"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
@@ -40,6 +42,9 @@ func init() {
 	acErrors.Set("ResolveWarnings", expvar.Func(func() interface{} {
 		return errorStats.getResolveWarnings()
 	}))
+	acErrors.Set("ServiceHints", expvar.Func(func() interface{} {
+		return hints.GetHints()
+	}))
 }
 
 // providerDescriptor keeps track of the configurations loaded by a certain
@@ -76,6 +81,7 @@ type AutoConfig struct {
 	newService         chan listeners.Service
 	delService         chan listeners.Service
 	store              *store
+	warmup             *warmupQueue
 	m                  sync.RWMutex
 }
 
@@ -92,6 +98,7 @@ func NewAutoConfig(scheduler *scheduler.MetaScheduler) *AutoConfig {
 		newService:         make(chan listeners.Service),
 		delService:         make(chan listeners.Service),
 		store:              newStore(),
+		warmup:             newWarmupQueue(time.Duration(config.Datadog.GetInt("ad_config_warmup_grace_period")) * time.Second),
 		scheduler:          scheduler,
 	}
 	// We need to listen to the service channels before anything is sent to them
@@ -116,6 +123,14 @@ func (ac *AutoConfig) startServiceListening() {
 	defer ac.m.Unlock()
 
 	go func() {
+		// the tick channel stays nil, and blocks, when no grace period is set
+		var warmupTick <-chan time.Time
+		if ac.warmup.enabled() {
+			warmupTicker := time.NewTicker(warmupCheckIntl)
+			defer warmupTicker.Stop()
+			warmupTick = warmupTicker.C
+		}
+
 		for {
 			select {
 			case <-ac.listenerStop:
@@ -126,6 +141,8 @@ func (ac *AutoConfig) startServiceListening() {
 				ac.processNewService(svc)
 			case svc := <-ac.delService:
 				ac.processDelService(svc)
+			case <-warmupTick:
+				ac.processWarmedUpServices()
 			}
 		}
 	}()
@@ -580,8 +597,10 @@ func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc list
 	if err != nil {
 		newErr := fmt.Errorf("error resolving template %s for service %s: %v", tpl.Name, svc.GetEntity(), err)
 		errorStats.setResolveWarning(tpl.Name, newErr.Error())
+		ac.store.setResolveErrorForService(svc.GetEntity(), tpl.Name, err.Error())
 		return tpl, log.Warn(newErr)
 	}
+	ac.store.removeResolveErrorForService(svc.GetEntity(), tpl.Name)
 	ac.store.setLoadedConfig(resolvedConfig)
 	ac.store.addConfigForService(svc.GetEntity(), resolvedConfig)
 	ac.store.setTagsHashForService(
@@ -635,6 +654,37 @@ func (ac *AutoConfig) GetUnresolvedTemplates() map[string]integration.Config {
 	return ac.store.templateCache.GetUnresolvedTemplates()
 }
 
+// GetServiceConfigs returns the configurations resolved for the known
+// services, sorted by entity
+func (ac *AutoConfig) GetServiceConfigs() []integration.ServiceConfigs {
+	services := ac.store.getServices()
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].GetEntity() < services[j].GetEntity()
+	})
+
+	result := make([]integration.ServiceConfigs, 0, len(services))
+	for _, svc := range services {
+		entity := svc.GetEntity()
+		sc := integration.ServiceConfigs{
+			Entity:        entity,
+			ADIdentifiers: []string{},
+			WarmingUp:     ac.warmup.isPending(entity),
+			Configs:       []string{},
+			ResolveErrors: ac.store.getResolveErrorsForService(entity),
+		}
+		if ids, err := svc.GetADIdentifiers(); err != nil {
+			sc.ADIDError = err.Error()
+		} else if ids != nil {
+			sc.ADIdentifiers = ids
+		}
+		for _, c := range ac.store.getConfigsForService(entity) {
+			sc.Configs = append(sc.Configs, c.Name)
+		}
+		result = append(result, sc)
+	}
+	return result
+}
+
 // check if the descriptor contains the Config passed
 func (pd *providerDescriptor) contains(c *integration.Config) bool {
 	for _, config := range pd.configs {
@@ -661,6 +711,37 @@ func (ac *AutoConfig) processNewService(svc listeners.Service) {
 	// in any case, register the service and store its tag hash
 	ac.store.setServiceForEntity(svc, svc.GetEntity())
 
+	if ac.warmup.shouldWait(svc) {
+		log.Debugf("Service %s just started, scheduling its checks once it is ready or in %s", svc.GetEntity(), ac.warmup.gracePeriod)
+		ac.warmup.add(svc, time.Now())
+	} else {
+		ac.scheduleServiceChecks(svc)
+	}
+
+	// FIXME: schedule new services as well
+	ac.schedule([]integration.Config{
+		{
+			LogsConfig:   integration.Data{},
+			Entity:       svc.GetEntity(),
+			CreationTime: svc.GetCreationTime(),
+		},
+	})
+
+}
+
+// processWarmedUpServices schedules the checks of the services that are
+// done warming up
+func (ac *AutoConfig) processWarmedUpServices() {
+	for _, svc := range ac.warmup.popReady(time.Now()) {
+		log.Debugf("Service %s is warmed up, scheduling its checks", svc.GetEntity())
+		ac.scheduleServiceChecks(svc)
+	}
+}
+
+// scheduleServiceChecks matches a service against the templates and
+// schedules the resulting checks. Until then, the service is not mapped to
+// its AD identifiers, so new templates are not resolved against it either.
+func (ac *AutoConfig) scheduleServiceChecks(svc listeners.Service) {
 	// get all the templates matching service identifiers
 	var templates []integration.Config
 	ADIdentifiers, err := svc.GetADIdentifiers()
@@ -688,24 +769,17 @@ func (ac *AutoConfig) processNewService(svc listeners.Service) {
 		// ask the Collector to schedule the checks
 		ac.schedule([]integration.Config{resolvedConfig})
 	}
-	// FIXME: schedule new services as well
-	ac.schedule([]integration.Config{
-		{
-			LogsConfig:   integration.Data{},
-			Entity:       svc.GetEntity(),
-			CreationTime: svc.GetCreationTime(),
-		},
-	})
-
 }
 
 // processDelService takes a service, stops its associated checks, and updates the cache
 func (ac *AutoConfig) processDelService(svc listeners.Service) {
+	ac.warmup.remove(svc.GetEntity())
 	ac.store.removeServiceForEntity(svc.GetEntity())
 	configs := ac.store.getConfigsForService(svc.GetEntity())
 	ac.store.removeConfigsForService(svc.GetEntity())
 	ac.processRemovedConfigs(configs)
 	ac.store.removeTagsHashForService(svc.GetEntity())
+	ac.store.removeResolveErrorsForService(svc.GetEntity())
 	// FIXME: unschedule remove services as well
 	ac.unschedule([]integration.Config{
 		{
@@ -314,3 +314,31 @@ func TestResolveTemplate(t *testing.T) {
 	res = ac.resolveTemplate(tpl)
 	assert.Len(t, res, 1)
 }
+
+func TestGetServiceConfigs(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	ac.store.templateCache.Set(integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"redis"},
+	})
+	ac.store.templateCache.Set(integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%")},
+	})
+
+	ac.processNewService(&dummyService{ID: "docker://b", ADIdentifiers: []string{"redis"}})
+	ac.processNewService(&dummyService{ID: "docker://a", ADIdentifiers: []string{"nginx"}})
+
+	services := ac.GetServiceConfigs()
+	require.Len(t, services, 2)
+
+	assert.Equal(t, "docker://a", services[0].Entity)
+	assert.Equal(t, []string{"nginx"}, services[0].ADIdentifiers)
+	assert.Len(t, services[0].Configs, 0)
+	assert.Len(t, services[0].ResolveErrors, 0)
+
+	assert.Equal(t, "docker://b", services[1].Entity)
+	assert.Equal(t, []string{"cpu"}, services[1].Configs)
+	assert.Contains(t, services[1].ResolveErrors["redisdb"], "no network found")
+}
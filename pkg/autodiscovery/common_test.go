@@ -18,6 +18,7 @@ type dummyService struct {
 	Pid           int
 	Hostname      string
 	CreationTime  integration.CreationTime
+	Ready         bool
 }
 
 // GetEntity returns the service entity name
@@ -59,3 +60,8 @@ func (s *dummyService) GetHostname() (string, error) {
 func (s *dummyService) GetCreationTime() integration.CreationTime {
 	return s.CreationTime
 }
+
+// IsReady returns whether the dummy service is ready
+func (s *dummyService) IsReady() bool {
+	return s.Ready
+}
@@ -11,8 +11,12 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"unicode"
 
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -29,6 +33,12 @@ var templateVariables = map[string]variableGetter{
 	"hostname": getHostname,
 }
 
+// unifiedServiceTagKeys are the keys of the unified service tags
+var unifiedServiceTagKeys = []string{"env", "service", "version"}
+
+// for testing purpose
+var tagEntity = tagger.Tag
+
 // Resolve takes a template and a service and generates a config with
 // valid connection info and relevant tags.
 func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
@@ -51,6 +61,7 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 	if err != nil {
 		return resolvedConfig, err
 	}
+	tags = addUnifiedServiceTags(tags, svc.GetEntity())
 	for i := 0; i < len(tpl.Instances); i++ {
 		// Copy original content from template
 		vars := tpl.GetTemplateVariablesForInstance(i)
@@ -66,7 +77,11 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 				resolvedConfig.Instances[i] = bytes.Replace(resolvedConfig.Instances[i], v, resolvedVar, -1)
 			}
 		}
-		err = resolvedConfig.Instances[i].MergeAdditionalTags(tags)
+		instanceTags, err := withoutTemplateServiceTags(tags, resolvedConfig.Instances[i])
+		if err != nil {
+			return resolvedConfig, err
+		}
+		err = resolvedConfig.Instances[i].MergeAdditionalTags(instanceTags)
 		if err != nil {
 			return resolvedConfig, err
 		}
@@ -75,6 +90,69 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 	return resolvedConfig, nil
 }
 
+// unifiedServiceTagKey returns the key of a unified service tag, empty if
+// it's another tag
+func unifiedServiceTagKey(tag string) string {
+	for _, key := range unifiedServiceTagKeys {
+		if strings.HasPrefix(tag, key+":") {
+			return key
+		}
+	}
+	return ""
+}
+
+// addUnifiedServiceTags adds the env, service and version tags of the entity
+// from the tagger to the tags of the service, which a listener may have
+// computed before the tagger collected them
+func addUnifiedServiceTags(tags []string, entity string) []string {
+	entityTags, err := tagEntity(entity, false)
+	if err != nil {
+		log.Debugf("Cannot get the unified service tags of %s: %s", entity, err)
+		return tags
+	}
+
+	present := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		present[tag] = true
+	}
+	// the tags of the service can be shared, they must not be appended to
+	merged := append([]string{}, tags...)
+	for _, tag := range entityTags {
+		if unifiedServiceTagKey(tag) != "" && !present[tag] {
+			merged = append(merged, tag)
+			present[tag] = true
+		}
+	}
+	return merged
+}
+
+// withoutTemplateServiceTags removes the unified service tags which keys are
+// already set in the tags of the template instance, they take precedence
+func withoutTemplateServiceTags(tags []string, instance integration.Data) ([]string, error) {
+	rawConfig := integration.RawMap{}
+	if err := yaml.Unmarshal(instance, &rawConfig); err != nil {
+		return nil, err
+	}
+	rawTags, _ := rawConfig["tags"].([]interface{})
+	templateKeys := map[string]bool{}
+	for _, rawTag := range rawTags {
+		if key := unifiedServiceTagKey(fmt.Sprint(rawTag)); key != "" {
+			templateKeys[key] = true
+		}
+	}
+	if len(templateKeys) == 0 {
+		return tags, nil
+	}
+
+	var kept []string
+	for _, tag := range tags {
+		if !templateKeys[unifiedServiceTagKey(tag)] {
+			kept = append(kept, tag)
+		}
+	}
+	return kept, nil
+}
+
 func getHost(tplVar []byte, svc listeners.Service) ([]byte, error) {
 	hosts, err := svc.GetHosts()
 	if err != nil {
@@ -12,9 +12,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
 
 	// we need some valid check in the catalog to run tests
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
@@ -28,6 +30,7 @@ type dummyService struct {
 	Pid           int
 	Hostname      string
 	CreationTime  integration.CreationTime
+	Ready         bool
 }
 
 // GetEntity returns the service entity name
@@ -70,6 +73,11 @@ func (s *dummyService) GetCreationTime() integration.CreationTime {
 	return s.CreationTime
 }
 
+// IsReady returns whether the dummy service is ready
+func (s *dummyService) IsReady() bool {
+	return s.Ready
+}
+
 func TestParseTemplateVar(t *testing.T) {
 	name, key := parseTemplateVar([]byte("%%host%%"))
 	assert.Equal(t, "host", string(name))
@@ -464,3 +472,41 @@ func newFakeContainerPorts() []listeners.ContainerPort {
 		{Port: 3, Name: "baz"},
 	}
 }
+
+func TestResolveUnifiedServiceTags(t *testing.T) {
+	defer func() { tagEntity = tagger.Tag }()
+	tagEntity = func(entity string, highCard bool) ([]string, error) {
+		assert.Equal(t, "a5901276aed1", entity)
+		assert.False(t, highCard)
+		return []string{"env:prod", "service:billing", "version:1.2.3", "image_name:redis"}, nil
+	}
+	svc := &dummyService{ID: "a5901276aed1", ADIdentifiers: []string{"redis"}}
+
+	cfg, err := Resolve(integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("tags: [\"foo\"]")},
+	}, svc)
+	require.NoError(t, err)
+	assertInstanceTags(t, cfg.Instances[0], "env:prod", "foo", "service:billing", "version:1.2.3")
+
+	// the service of the template takes precedence
+	cfg, err = Resolve(integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("tags: [\"service:cache\"]")},
+	}, svc)
+	require.NoError(t, err)
+	assertInstanceTags(t, cfg.Instances[0], "env:prod", "service:cache", "version:1.2.3")
+}
+
+func assertInstanceTags(t *testing.T, instance integration.Data, expected ...string) {
+	t.Helper()
+	rawConfig := integration.RawMap{}
+	require.NoError(t, yaml.Unmarshal(instance, &rawConfig))
+	var tags []string
+	for _, tag := range rawConfig["tags"].([]interface{}) {
+		tags = append(tags, fmt.Sprint(tag))
+	}
+	assert.ElementsMatch(t, expected, tags)
+}
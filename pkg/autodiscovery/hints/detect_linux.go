@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package hints
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+// tcpListenState is the state of the listening sockets in /proc/net/tcp
+const tcpListenState = "0A"
+
+var (
+	// for testing purpose
+	entityForPID = containers.EntityForPID
+)
+
+// detectHints lists the processes listening on the known ports. The sockets
+// of a process are listed in the network namespace of the process, so the
+// services of the containers are detected with their container port.
+func detectHints(procRoot string) ([]Hint, error) {
+	procDir, err := os.Open(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	dirNames, err := procDir.Readdirnames(-1)
+	procDir.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, dirName := range dirNames {
+		if pid, err := strconv.Atoi(dirName); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	// the parent processes are usually listed first
+	sort.Ints(pids)
+
+	// namespace -> socket inode -> port
+	listeningByNamespace := make(map[string]map[uint64]int)
+	seen := make(map[string]bool)
+	var detected []Hint
+	for _, pid := range pids {
+		dirName := strconv.Itoa(pid)
+		inodes := socketInodes(filepath.Join(procRoot, dirName, "fd"))
+		if len(inodes) == 0 {
+			continue
+		}
+
+		namespace, err := os.Readlink(filepath.Join(procRoot, dirName, "ns", "net"))
+		if err != nil {
+			namespace = dirName
+		}
+		listening, found := listeningByNamespace[namespace]
+		if !found {
+			listening = make(map[uint64]int)
+			for _, file := range []string{"tcp", "tcp6"} {
+				readListeningSockets(filepath.Join(procRoot, dirName, "net", file), listening)
+			}
+			listeningByNamespace[namespace] = listening
+		}
+
+		for _, inode := range inodes {
+			port, found := listening[inode]
+			if !found {
+				continue
+			}
+			check, known := knownPorts[port]
+			if !known {
+				continue
+			}
+			// the workers of a service share its listening socket
+			key := fmt.Sprintf("%s/%s/%d", namespace, check, port)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			entity, _ := entityForPID(int32(pid))
+			detected = append(detected, Hint{
+				Check:   check,
+				Port:    port,
+				Entity:  entity,
+				PID:     pid,
+				Process: readComm(filepath.Join(procRoot, dirName, "comm")),
+			})
+		}
+	}
+	return detected, nil
+}
+
+// socketInodes returns the inodes of the sockets opened by a process, from
+// its file descriptors linking to socket:[inode]
+func socketInodes(fdDir string) []uint64 {
+	fds, err := ioutil.ReadDir(fdDir)
+	if err != nil {
+		return nil
+	}
+	var inodes []uint64
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+		if err == nil {
+			inodes = append(inodes, inode)
+		}
+	}
+	return inodes
+}
+
+// readListeningSockets adds the inodes and ports of the listening sockets of
+// a /proc/net/tcp file to listening
+func readListeningSockets(path string, listening map[uint64]int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+		address := fields[1]
+		port, err := strconv.ParseUint(address[strings.LastIndex(address, ":")+1:], 16, 16)
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		listening[inode] = int(port)
+	}
+}
+
+func readComm(path string) string {
+	comm, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package hints
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// postgres listening on 0.0.0.0:5432, and an established connection
+	postgresNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 28421 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1538 0100007F:D2F4 01 00000000:00000000 00:00000000 00000000   999        0 28500 1 0000000000000000 20 4 30 10 -1
+`
+	// redis listening on [::]:6379, and sshd on [::]:22
+	hostNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:18EB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 31337 1 0000000000000000 100 0 0 10 0
+   1: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12000 1 0000000000000000 100 0 0 10 0
+`
+)
+
+type fakeProcess struct {
+	pid       string
+	comm      string
+	namespace string
+	sockets   []string
+	tcp       string
+	tcp6      string
+}
+
+func writeProcess(t *testing.T, root string, p fakeProcess) {
+	dir := filepath.Join(root, p.pid)
+	for _, sub := range []string{"fd", "ns", "net"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "comm"), []byte(p.comm+"\n"), 0644))
+	require.NoError(t, os.Symlink(p.namespace, filepath.Join(dir, "ns", "net")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "net", "tcp"), []byte(p.tcp), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "net", "tcp6"), []byte(p.tcp6), 0644))
+	require.NoError(t, os.Symlink("/dev/null", filepath.Join(dir, "fd", "0")))
+	for i, socket := range p.sockets {
+		require.NoError(t, os.Symlink(socket, filepath.Join(dir, "fd", strconv.Itoa(3+i))))
+	}
+}
+
+func TestDetectHints(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	defer func(original func(int32) (string, error)) { entityForPID = original }(entityForPID)
+	entityForPID = func(pid int32) (string, error) {
+		if pid == 200 || pid == 201 {
+			return "docker://3d5d2e1c", nil
+		}
+		return "", nil
+	}
+
+	for _, p := range []fakeProcess{
+		// a postgres container, the worker shares the listening socket
+		{pid: "200", comm: "postgres", namespace: "net:[4026532200]", sockets: []string{"socket:[28421]"}, tcp: postgresNetTCP},
+		{pid: "201", comm: "postgres", namespace: "net:[4026532200]", sockets: []string{"socket:[28421]", "socket:[28500]"}, tcp: postgresNetTCP},
+		// redis and sshd on the host
+		{pid: "300", comm: "redis-server", namespace: "net:[4026531993]", sockets: []string{"socket:[31337]"}, tcp6: hostNetTCP6},
+		{pid: "301", comm: "sshd", namespace: "net:[4026531993]", sockets: []string{"socket:[12000]"}, tcp6: hostNetTCP6},
+	} {
+		writeProcess(t, root, p)
+	}
+
+	detected, err := detectHints(root)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Hint{
+		{Check: "postgres", Port: 5432, Entity: "docker://3d5d2e1c", PID: 200, Process: "postgres"},
+		{Check: "redisdb", Port: 6379, PID: 300, Process: "redis-server"},
+	}, detected)
+}
+
+func TestReadListeningSockets(t *testing.T) {
+	f, err := ioutil.TempFile("", "tcp")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(postgresNetTCP)
+	require.NoError(t, err)
+	f.Close()
+
+	listening := make(map[uint64]int)
+	readListeningSockets(f.Name(), listening)
+	assert.Equal(t, map[uint64]int{28421: 5432}, listening)
+}
@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !linux
+
+package hints
+
+import "errors"
+
+// detectHints is only implemented on linux
+func detectHints(procRoot string) ([]Hint, error) {
+	return nil, errors.New("the listening services are only detected on linux")
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package hints
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Hint is a service detected from its listening port, a check of the
+// integration can likely monitor it
+type Hint struct {
+	Check   string `json:"check"`
+	Port    int    `json:"port"`
+	Entity  string `json:"entity"` // container of the process, empty on the host
+	PID     int    `json:"pid"`
+	Process string `json:"process"`
+}
+
+// knownPorts are the default ports of the services with an integration
+var knownPorts = map[int]string{
+	2181:  "zk",
+	2379:  "etcd",
+	3306:  "mysql",
+	5432:  "postgres",
+	5672:  "rabbitmq",
+	5984:  "couch",
+	6379:  "redisdb",
+	8091:  "couchbase",
+	8500:  "consul",
+	9042:  "cassandra",
+	9092:  "kafka",
+	9200:  "elastic",
+	11211: "mcache",
+	27017: "mongo",
+}
+
+var (
+	hints   []Hint
+	hintsMu sync.RWMutex
+	stop    chan struct{}
+)
+
+// defaultInterval is used when `service_hints.interval` is not positive
+const defaultInterval = 60
+
+// Start periodically detects the services listening on the known ports of
+// the integrations, every `service_hints.interval` seconds
+func Start() {
+	if stop != nil {
+		return
+	}
+	seconds := config.Datadog.GetInt("service_hints.interval")
+	if seconds <= 0 {
+		log.Warnf("Configured service_hints.interval (%v) is not positive; %v will be used", seconds, defaultInterval)
+		seconds = defaultInterval
+	}
+	interval := time.Duration(seconds) * time.Second
+	stop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			refresh()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(stop)
+}
+
+// Stop stops the detection of the services
+func Stop() {
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// GetHints returns the services detected at the last refresh
+func GetHints() []Hint {
+	hintsMu.RLock()
+	defer hintsMu.RUnlock()
+
+	return append([]Hint{}, hints...)
+}
+
+func refresh() {
+	detected, err := detectHints(config.Datadog.GetString("container_proc_root"))
+	if err != nil {
+		log.Debugf("Could not detect the listening services: %s", err)
+		return
+	}
+	sort.Slice(detected, func(i, j int) bool {
+		if detected[i].Check != detected[j].Check {
+			return detected[i].Check < detected[j].Check
+		}
+		if detected[i].Port != detected[j].Port {
+			return detected[i].Port < detected[j].Port
+		}
+		return detected[i].Entity < detected[j].Entity
+	})
+
+	hintsMu.Lock()
+	hints = detected
+	hintsMu.Unlock()
+}
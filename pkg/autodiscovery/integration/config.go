@@ -200,3 +200,14 @@ func (c *Config) Digest() string {
 
 	return strconv.FormatUint(h.Sum64(), 16)
 }
+
+// ServiceConfigs explains which check configurations were resolved for a
+// service, and why the other templates matching it were not
+type ServiceConfigs struct {
+	Entity        string            `json:"entity"`
+	ADIdentifiers []string          `json:"ad_identifiers"`
+	WarmingUp     bool              `json:"warming_up"`            // the templates are resolved once the service is ready
+	Configs       []string          `json:"configs"`               // names of the resolved configurations
+	ResolveErrors map[string]string `json:"resolve_errors"`        // template name -> error
+	ADIDError     string            `json:"ad_id_error,omitempty"` // the service is not monitored
+}
@@ -535,6 +535,20 @@ func (s *DockerService) GetCreationTime() integration.CreationTime {
 	return s.creationTime
 }
 
+// IsReady returns whether the container passed its Docker health check.
+// Containers without a health check are never reported ready.
+func (s *DockerService) IsReady() bool {
+	du, err := docker.GetDockerUtil()
+	if err != nil {
+		return false
+	}
+	cInspect, err := du.Inspect(s.cID, false)
+	if err != nil || cInspect.State == nil || cInspect.State.Health == nil {
+		return false
+	}
+	return cInspect.State.Health.Status == types.Healthy
+}
+
 // findKubernetesInLabels traverses a map of container labels and
 // returns true if a kubernetes label is detected
 func findKubernetesInLabels(labels map[string]string) bool {
@@ -240,3 +240,9 @@ func (s *ECSService) GetHostname() (string, error) {
 func (s *ECSService) GetCreationTime() integration.CreationTime {
 	return s.creationTime
 }
+
+// IsReady returns false: the health of the containers is not exposed by the
+// task metadata endpoint, their checks wait for the whole grace period.
+func (s *ECSService) IsReady() bool {
+	return false
+}
@@ -0,0 +1,165 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/hints"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+)
+
+const hintsRefreshInterval = 5 * time.Second
+
+var (
+	// for testing purpose
+	getHints = hints.GetHints
+)
+
+// HintsListener implements the ServiceListener interface for the services
+// detected by the hints package. Only the processes of the host are reported,
+// the services of the containers are already reported by the listener of
+// their runtime.
+type HintsListener struct {
+	services   map[string]Service // maps the entities to the services
+	newService chan<- Service
+	delService chan<- Service
+	stop       chan bool
+	t          *time.Ticker
+	health     *health.Handle
+}
+
+// HintService implements the Service interface for a process listening on
+// the default port of an integration
+type HintService struct {
+	hint hints.Hint
+}
+
+func init() {
+	Register("hints", NewHintsListener)
+}
+
+// NewHintsListener creates a HintsListener, the detection of the services must
+// be enabled with `service_hints.enabled`
+func NewHintsListener() (ServiceListener, error) {
+	if !config.Datadog.GetBool("service_hints.enabled") {
+		return nil, fmt.Errorf("service_hints.enabled must be set to use the hints listener")
+	}
+	return &HintsListener{
+		services: make(map[string]Service),
+		stop:     make(chan bool),
+		t:        time.NewTicker(hintsRefreshInterval),
+		health:   health.Register("ad-hintslistener"),
+	}, nil
+}
+
+// Listen regularly reports the detected services as Services
+func (l *HintsListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+
+	go func() {
+		l.refreshServices()
+		for {
+			select {
+			case <-l.stop:
+				l.t.Stop()
+				l.health.Deregister()
+				return
+			case <-l.health.C:
+			case <-l.t.C:
+				l.refreshServices()
+			}
+		}
+	}()
+}
+
+// Stop queues a shutdown of HintsListener
+func (l *HintsListener) Stop() {
+	l.stop <- true
+}
+
+// refreshServices compares the detected services to the reported ones and
+// sends the new and dead services over newService and delService
+func (l *HintsListener) refreshServices() {
+	notSeen := make(map[string]bool, len(l.services))
+	for entity := range l.services {
+		notSeen[entity] = true
+	}
+
+	for _, hint := range getHints() {
+		if hint.Entity != "" {
+			continue
+		}
+		svc := &HintService{hint: hint}
+		entity := svc.GetEntity()
+		delete(notSeen, entity)
+		if _, found := l.services[entity]; found {
+			continue
+		}
+		l.services[entity] = svc
+		l.newService <- svc
+	}
+
+	for entity := range notSeen {
+		l.delService <- l.services[entity]
+		delete(l.services, entity)
+	}
+}
+
+// GetEntity returns the unique entity name of the process and its port
+func (s *HintService) GetEntity() string {
+	return fmt.Sprintf("process://%d:%d", s.hint.PID, s.hint.Port)
+}
+
+// GetADIdentifiers returns the name of the check of the service, followed by
+// the name of the process
+func (s *HintService) GetADIdentifiers() ([]string, error) {
+	if s.hint.Process == "" || s.hint.Process == s.hint.Check {
+		return []string{s.hint.Check}, nil
+	}
+	return []string{s.hint.Check, s.hint.Process}, nil
+}
+
+// GetHosts returns the loopback address, the process runs on the host
+func (s *HintService) GetHosts() (map[string]string, error) {
+	return map[string]string{"host": "127.0.0.1"}, nil
+}
+
+// GetPorts returns the listening port of the service
+func (s *HintService) GetPorts() ([]ContainerPort, error) {
+	return []ContainerPort{{Port: s.hint.Port, Name: s.hint.Check}}, nil
+}
+
+// GetTags returns nil, the processes of the host have no tags of their own
+func (s *HintService) GetTags() ([]string, error) {
+	return nil, nil
+}
+
+// GetPid returns the pid of the listening process
+func (s *HintService) GetPid() (int, error) {
+	return s.hint.PID, nil
+}
+
+// GetHostname returns nil and an error because the hostname of a process is
+// the one of the host
+func (s *HintService) GetHostname() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetCreationTime returns integration.Before: the service is already accepting
+// connections when it is detected
+func (s *HintService) GetCreationTime() integration.CreationTime {
+	return integration.Before
+}
+
+// IsReady returns true, the service is listening
+func (s *HintService) IsReady() bool {
+	return true
+}
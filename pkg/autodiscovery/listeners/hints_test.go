@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/hints"
+)
+
+func TestHintsListenerRefreshServices(t *testing.T) {
+	defer func() { getHints = hints.GetHints }()
+	detected := []hints.Hint{
+		{Check: "redisdb", Port: 6379, PID: 12, Process: "redis-server"},
+		{Check: "postgres", Port: 5432, PID: 34, Process: "postgres"},
+		{Check: "mysql", Port: 3306, PID: 56, Entity: "docker://abcdef", Process: "mysqld"},
+	}
+	getHints = func() []hints.Hint { return detected }
+
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l := &HintsListener{services: make(map[string]Service), newService: newSvc, delService: delSvc}
+
+	l.refreshServices()
+	require.Len(t, newSvc, 2)
+	redis := <-newSvc
+	assert.Equal(t, "process://12:6379", redis.GetEntity())
+	ids, err := redis.GetADIdentifiers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"redisdb", "redis-server"}, ids)
+	ports, err := redis.GetPorts()
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{Port: 6379, Name: "redisdb"}}, ports)
+	hosts, err := redis.GetHosts()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "127.0.0.1"}, hosts)
+	postgres := <-newSvc
+	ids, err = postgres.GetADIdentifiers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"postgres"}, ids)
+
+	// the known services are not reported again, the stopped ones are deleted
+	detected = detected[1:]
+	l.refreshServices()
+	assert.Len(t, newSvc, 0)
+	require.Len(t, delSvc, 1)
+	assert.Equal(t, "process://12:6379", (<-delSvc).GetEntity())
+	assert.Len(t, l.services, 1)
+}
@@ -278,3 +278,22 @@ func (s *KubeContainerService) GetHostname() (string, error) {
 func (s *KubeContainerService) GetCreationTime() integration.CreationTime {
 	return s.creationTime
 }
+
+// IsReady returns whether the kubelet reports the container as ready, that
+// is running and passing its readiness probe if it has one.
+func (s *KubeContainerService) IsReady() bool {
+	ku, err := kubelet.GetKubeUtil()
+	if err != nil {
+		return false
+	}
+	pod, err := ku.GetPodForContainerID(s.entity)
+	if err != nil {
+		return false
+	}
+	for _, container := range pod.Status.Containers {
+		if container.ID == s.entity {
+			return container.Ready
+		}
+	}
+	return false
+}
@@ -65,10 +65,12 @@ func (p *ECSConfigProvider) getTaskMetadata() (ecs.TaskMetadata, error) {
 	var meta ecs.TaskMetadata
 	resp, err := p.client.Get(metadataURL)
 	if err != nil {
-		log.Errorf("unable to get task metadata - %s", err)
+		// the metadata API is polled, log once in a while while it's unreachable
+		log.ErrorfThrottled(metadataURL, "unable to get task metadata - %s", err)
 		return meta, err
 	}
 	defer resp.Body.Close()
+	log.ResetThrottled(metadataURL)
 
 	decoder := json.NewDecoder(resp.Body)
 	err = decoder.Decode(&meta)
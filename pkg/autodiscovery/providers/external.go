@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// ExternalConfigProvider implements the ConfigProvider interface.
+// It holds the configurations pushed to the agent by external schedulers,
+// like the cluster agent, through the agent API. Every scheduler owns its
+// set of configurations and replaces it as a whole on each push.
+type ExternalConfigProvider struct {
+	sync.RWMutex
+	configs  map[string][]integration.Config // by scheduler name
+	upToDate bool
+}
+
+// NewExternalConfigProvider returns a new ExternalConfigProvider
+func NewExternalConfigProvider() *ExternalConfigProvider {
+	return &ExternalConfigProvider{
+		configs: make(map[string][]integration.Config),
+	}
+}
+
+// String returns a string representation of the ExternalConfigProvider
+func (p *ExternalConfigProvider) String() string {
+	return External
+}
+
+// IsUpToDate returns whether no configuration was pushed since the last Collect
+func (p *ExternalConfigProvider) IsUpToDate() (bool, error) {
+	p.RLock()
+	defer p.RUnlock()
+	return p.upToDate, nil
+}
+
+// Collect returns the configurations of all the schedulers
+func (p *ExternalConfigProvider) Collect() ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	// keep a stable order across collects
+	schedulers := make([]string, 0, len(p.configs))
+	for name := range p.configs {
+		schedulers = append(schedulers, name)
+	}
+	sort.Strings(schedulers)
+
+	var configs []integration.Config
+	for _, name := range schedulers {
+		configs = append(configs, p.configs[name]...)
+	}
+	p.upToDate = true
+	return configs, nil
+}
+
+// SetConfigs replaces the configurations of a scheduler, an empty slice
+// unschedules all of them
+func (p *ExternalConfigProvider) SetConfigs(scheduler string, configs []integration.Config) {
+	p.Lock()
+	defer p.Unlock()
+
+	if len(configs) == 0 {
+		delete(p.configs, scheduler)
+	} else {
+		p.configs[scheduler] = configs
+	}
+	p.upToDate = false
+}
+
+// GetConfigs returns the configurations last pushed by a scheduler
+func (p *ExternalConfigProvider) GetConfigs(scheduler string) []integration.Config {
+	p.RLock()
+	defer p.RUnlock()
+	return p.configs[scheduler]
+}
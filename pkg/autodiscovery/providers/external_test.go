@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestExternalConfigProvider(t *testing.T) {
+	p := NewExternalConfigProvider()
+	upToDate, err := p.IsUpToDate()
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	configs, err := p.Collect()
+	require.NoError(t, err)
+	assert.Len(t, configs, 0)
+	upToDate, _ = p.IsUpToDate()
+	assert.True(t, upToDate)
+
+	redis := integration.Config{Name: "redisdb", Instances: []integration.Data{integration.Data("host: foo")}}
+	http := integration.Config{Name: "http_check", Instances: []integration.Data{integration.Data("url: http://foo")}}
+	p.SetConfigs("scheduler-b", []integration.Config{redis})
+	p.SetConfigs("scheduler-a", []integration.Config{http})
+	upToDate, _ = p.IsUpToDate()
+	assert.False(t, upToDate)
+
+	// configs are sorted by scheduler name
+	configs, err = p.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, []integration.Config{http, redis}, configs)
+	assert.Equal(t, []integration.Config{redis}, p.GetConfigs("scheduler-b"))
+
+	// an empty push removes the scheduler configs
+	p.SetConfigs("scheduler-b", nil)
+	configs, err = p.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, []integration.Config{http}, configs)
+	assert.Len(t, p.GetConfigs("scheduler-b"), 0)
+}
@@ -19,6 +19,8 @@ const (
 	ECS = "ECS"
 	// Etcd represents the name of the etcd config provider
 	Etcd = "etcd"
+	// External represents the name of the external scheduler config provider
+	External = "external"
 	// File represents the name of the file config provider
 	File = "File"
 	// Kubernetes represents the name of the kubernetes config provider
@@ -20,8 +20,10 @@ type store struct {
 	nameToJMXMetrics  map[string]integration.Data
 	adIDToServices    map[string]map[string]bool
 	entityToService   map[string]listeners.Service
-	templateCache     *TemplateCache
-	m                 sync.RWMutex
+	// service entity -> template name -> error resolving the template
+	serviceToErrors map[string]map[string]string
+	templateCache   *TemplateCache
+	m               sync.RWMutex
 }
 
 // newStore creates a store
@@ -33,6 +35,7 @@ func newStore() *store {
 		nameToJMXMetrics:  make(map[string]integration.Data),
 		adIDToServices:    make(map[string]map[string]bool),
 		entityToService:   make(map[string]listeners.Service),
+		serviceToErrors:   make(map[string]map[string]string),
 		templateCache:     NewTemplateCache(),
 	}
 
@@ -164,3 +167,41 @@ func (s *store) getServiceEntitiesForADID(adID string) (map[string]bool, bool) {
 	services, found := s.adIDToServices[adID]
 	return services, found
 }
+
+// setResolveErrorForService stores the error resolving a template for a service
+func (s *store) setResolveErrorForService(serviceEntity, templateName, err string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.serviceToErrors[serviceEntity] == nil {
+		s.serviceToErrors[serviceEntity] = make(map[string]string)
+	}
+	s.serviceToErrors[serviceEntity][templateName] = err
+}
+
+// removeResolveErrorForService removes the error resolving a template for a service
+func (s *store) removeResolveErrorForService(serviceEntity, templateName string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.serviceToErrors[serviceEntity], templateName)
+	if len(s.serviceToErrors[serviceEntity]) == 0 {
+		delete(s.serviceToErrors, serviceEntity)
+	}
+}
+
+// removeResolveErrorsForService removes all the errors resolving templates for a service
+func (s *store) removeResolveErrorsForService(serviceEntity string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.serviceToErrors, serviceEntity)
+}
+
+// getResolveErrorsForService returns a copy of the errors resolving templates for a service
+func (s *store) getResolveErrorsForService(serviceEntity string) map[string]string {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	errors := make(map[string]string, len(s.serviceToErrors[serviceEntity]))
+	for name, err := range s.serviceToErrors[serviceEntity] {
+		errors[name] = err
+	}
+	return errors
+}
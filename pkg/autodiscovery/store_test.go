@@ -27,3 +27,17 @@ func TestServiceToConfig(t *testing.T) {
 	s.addConfigForService(service.GetEntity(), integration.Config{Name: "foo"})
 	assert.Equal(t, len(s.getConfigsForService(service.GetEntity())), 1)
 }
+
+func TestServiceResolveErrors(t *testing.T) {
+	s := newStore()
+	s.setResolveErrorForService("docker://foo", "redis", "no port")
+	s.setResolveErrorForService("docker://foo", "nginx", "no host")
+	assert.Equal(t, map[string]string{"redis": "no port", "nginx": "no host"}, s.getResolveErrorsForService("docker://foo"))
+
+	s.removeResolveErrorForService("docker://foo", "redis")
+	assert.Equal(t, map[string]string{"nginx": "no host"}, s.getResolveErrorsForService("docker://foo"))
+
+	s.removeResolveErrorsForService("docker://foo")
+	assert.Len(t, s.getResolveErrorsForService("docker://foo"), 0)
+	assert.Len(t, s.serviceToErrors, 0)
+}
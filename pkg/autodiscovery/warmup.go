@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+)
+
+// warmupCheckIntl is the interval at which the warming up services are
+// checked for readiness
+var warmupCheckIntl = 1 * time.Second
+
+// pendingService is a service whose checks are not scheduled yet
+type pendingService struct {
+	svc      listeners.Service
+	deadline time.Time
+}
+
+// warmupQueue holds the services started after the agent until they pass
+// their health check, or until their grace period expires. Scheduling their
+// checks right away would make the first runs fail while the service is
+// still starting.
+type warmupQueue struct {
+	gracePeriod time.Duration
+	pending     map[string]pendingService
+	m           sync.Mutex
+}
+
+func newWarmupQueue(gracePeriod time.Duration) *warmupQueue {
+	return &warmupQueue{
+		gracePeriod: gracePeriod,
+		pending:     make(map[string]pendingService),
+	}
+}
+
+// enabled returns whether a grace period is configured
+func (q *warmupQueue) enabled() bool {
+	return q.gracePeriod > 0
+}
+
+// shouldWait returns whether the checks of a service should wait: the
+// services already running when the agent starts are considered warmed up
+func (q *warmupQueue) shouldWait(svc listeners.Service) bool {
+	return q.enabled() && svc.GetCreationTime() == integration.After
+}
+
+// add queues a service, a service already queued keeps its deadline
+func (q *warmupQueue) add(svc listeners.Service, now time.Time) {
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	if _, found := q.pending[svc.GetEntity()]; found {
+		return
+	}
+	q.pending[svc.GetEntity()] = pendingService{
+		svc:      svc,
+		deadline: now.Add(q.gracePeriod),
+	}
+}
+
+// remove drops a service from the queue, it returns whether it was queued
+func (q *warmupQueue) remove(entity string) bool {
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	_, found := q.pending[entity]
+	delete(q.pending, entity)
+	return found
+}
+
+// isPending returns whether a service is queued
+func (q *warmupQueue) isPending(entity string) bool {
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	_, found := q.pending[entity]
+	return found
+}
+
+// popReady removes and returns the services that are ready or whose grace
+// period expired. The readiness of the services is queried without holding
+// the lock, it can call the container runtime.
+func (q *warmupQueue) popReady(now time.Time) []listeners.Service {
+	q.m.Lock()
+	candidates := make(map[string]pendingService, len(q.pending))
+	for entity, p := range q.pending {
+		candidates[entity] = p
+	}
+	q.m.Unlock()
+
+	readyEntities := make(map[string]pendingService)
+	for entity, p := range candidates {
+		if !now.Before(p.deadline) || p.svc.IsReady() {
+			readyEntities[entity] = p
+		}
+	}
+
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	var ready []listeners.Service
+	for entity, p := range readyEntities {
+		// skip the services removed, or removed and queued again, meanwhile
+		if current, found := q.pending[entity]; !found || current.deadline != p.deadline {
+			continue
+		}
+		ready = append(ready, p.svc)
+		delete(q.pending, entity)
+	}
+	return ready
+}
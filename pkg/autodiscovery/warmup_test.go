@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+)
+
+func TestWarmupShouldWait(t *testing.T) {
+	before := &dummyService{ID: "before", CreationTime: integration.Before}
+	after := &dummyService{ID: "after", CreationTime: integration.After}
+
+	q := newWarmupQueue(0)
+	assert.False(t, q.shouldWait(before))
+	assert.False(t, q.shouldWait(after))
+
+	q = newWarmupQueue(30 * time.Second)
+	assert.False(t, q.shouldWait(before))
+	assert.True(t, q.shouldWait(after))
+}
+
+func TestWarmupPopReady(t *testing.T) {
+	now := time.Now()
+	q := newWarmupQueue(30 * time.Second)
+
+	starting := &dummyService{ID: "starting", CreationTime: integration.After}
+	healthy := &dummyService{ID: "healthy", CreationTime: integration.After, Ready: true}
+	q.add(starting, now)
+	q.add(healthy, now)
+
+	// healthy services don't wait for the grace period
+	assert.Equal(t, []listeners.Service{healthy}, q.popReady(now.Add(time.Second)))
+	assert.Len(t, q.popReady(now.Add(time.Second)), 0)
+
+	// adding a queued service again keeps its deadline
+	q.add(starting, now.Add(20*time.Second))
+	assert.Len(t, q.popReady(now.Add(29*time.Second)), 0)
+	assert.Equal(t, []listeners.Service{starting}, q.popReady(now.Add(30*time.Second)))
+	assert.Len(t, q.pending, 0)
+}
+
+func TestWarmupRemove(t *testing.T) {
+	now := time.Now()
+	q := newWarmupQueue(30 * time.Second)
+
+	svc := &dummyService{ID: "foo", CreationTime: integration.After}
+	q.add(svc, now)
+	assert.True(t, q.remove("foo"))
+	assert.False(t, q.remove("foo"))
+	assert.Len(t, q.popReady(now.Add(time.Minute)), 0)
+}
+
+// reentrantService queries the queue from IsReady, which deadlocks if the
+// queue is locked meanwhile
+type reentrantService struct {
+	*dummyService
+	q *warmupQueue
+}
+
+func (s *reentrantService) IsReady() bool {
+	return s.q.isPending(s.GetEntity())
+}
+
+func TestWarmupPopReadyUnlocked(t *testing.T) {
+	now := time.Now()
+	q := newWarmupQueue(30 * time.Second)
+
+	svc := &reentrantService{dummyService: &dummyService{ID: "foo", CreationTime: integration.After}, q: q}
+	q.add(svc, now)
+	assert.Equal(t, []listeners.Service{svc}, q.popReady(now.Add(time.Second)))
+}
@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	certificateKey = "cert.pem"
+	privateKeyKey  = "key.pem"
+	keyBits        = 2048
+)
+
+// certificate is the self-signed certificate of the webhook server, the
+// API server trusts it through the CA bundle of the webhook configuration
+type certificate struct {
+	certPEM []byte
+	keyPEM  []byte
+	cert    *x509.Certificate
+}
+
+// keyPair returns the TLS certificate served by the webhook server
+func (c *certificate) keyPair() (tls.Certificate, error) {
+	return tls.X509KeyPair(c.certPEM, c.keyPEM)
+}
+
+// expiresWithin returns whether the certificate expires before now + threshold
+func (c *certificate) expiresWithin(now time.Time, threshold time.Duration) bool {
+	return now.Add(threshold).After(c.cert.NotAfter)
+}
+
+// generateCertificate returns a new self-signed certificate for the DNS
+// names of the webhook service
+func generateCertificate(hosts []string, validity time.Duration) (*certificate, error) {
+	template, err := security.CertTemplate()
+	if err != nil {
+		return nil, err
+	}
+	template.NotAfter = template.NotBefore.Add(validity)
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	template.DNSNames = hosts
+
+	key, err := security.GenerateKeyPair(keyBits)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the certificate: %s", err)
+	}
+
+	return parseCertificate(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	)
+}
+
+func parseCertificate(certPEM, keyPEM []byte) (*certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &certificate{certPEM: certPEM, keyPEM: keyPEM, cert: cert}, nil
+}
+
+// getOrCreateCertificate returns the certificate stored in the secret, it is
+// regenerated if it is missing or expires within the threshold. The secret
+// shares the certificate between the replicas of the cluster agent.
+func getOrCreateCertificate(client kubernetes.Interface, namespace, name string, hosts []string, validity, threshold time.Duration) (*certificate, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to get the secret %s/%s: %s", namespace, name, err)
+	}
+
+	if found {
+		cert, err := parseCertificate(secret.Data[certificateKey], secret.Data[privateKeyKey])
+		if err == nil && !cert.expiresWithin(time.Now(), threshold) {
+			return cert, nil
+		}
+		log.Infof("Renewing the certificate of the admission controller stored in %s/%s", namespace, name)
+	}
+
+	cert, err := generateCertificate(hosts, validity)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]byte{
+		certificateKey: cert.certPEM,
+		privateKeyKey:  cert.keyPEM,
+	}
+
+	if !found {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       data,
+		}
+		_, err = client.CoreV1().Secrets(namespace).Create(secret)
+	} else {
+		secret.Data = data
+		_, err = client.CoreV1().Secrets(namespace).Update(secret)
+	}
+	if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+		// another replica stored its certificate first
+		return getOrCreateCertificate(client, namespace, name, hosts, validity, threshold)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to store the certificate in %s/%s: %s", namespace, name, err)
+	}
+	return cert, nil
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetOrCreateCertificate(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	hosts := []string{"svc.ns.svc"}
+
+	cert, err := getOrCreateCertificate(client, "ns", "secret", hosts, 24*time.Hour, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, hosts, cert.cert.DNSNames)
+	_, err = cert.keyPair()
+	assert.NoError(t, err)
+
+	secret, err := client.CoreV1().Secrets("ns").Get("secret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, cert.certPEM, secret.Data[certificateKey])
+
+	// the stored certificate is reused
+	same, err := getOrCreateCertificate(client, "ns", "secret", hosts, 24*time.Hour, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, cert.certPEM, same.certPEM)
+
+	// and renewed when it is about to expire
+	renewed, err := getOrCreateCertificate(client, "ns", "secret", hosts, 24*time.Hour, 48*time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.certPEM, renewed.certPEM)
+}
+
+func TestParseFailurePolicy(t *testing.T) {
+	policy, err := parseFailurePolicy("Fail")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Fail", policy)
+
+	policy, err = parseFailurePolicy("")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Ignore", policy)
+
+	_, err = parseFailurePolicy("retry")
+	assert.Error(t, err)
+}
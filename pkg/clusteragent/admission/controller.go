@@ -0,0 +1,239 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+// Package admission implements the mutating admission webhook of the cluster
+// agent: it injects the address of the node agent, the entity ID and the
+// standard tags in the env of the annotated pods, for the tracing libraries
+// and dogstatsd clients to be configured without editing the manifests.
+package admission
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	stdLog "log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admiv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// certificateCheckInterval is the interval at which the certificate is
+// renewed if it is about to expire
+var certificateCheckInterval = 1 * time.Hour
+
+// Controller serves the webhook, and manages its certificate and its
+// registration in the API server
+type Controller struct {
+	client      kubernetes.Interface
+	namespace   string
+	secretName  string
+	webhookName string
+	serviceName string
+	port        int
+	validity    time.Duration
+	threshold   time.Duration
+	policy      admiv1beta1.FailurePolicyType
+	mutation    mutationConfig
+
+	m      sync.RWMutex
+	cert   *certificate
+	server *http.Server
+	stopCh chan struct{}
+}
+
+// NewController returns a new admission controller configured from the
+// `admission_controller` section
+func NewController(client kubernetes.Interface) (*Controller, error) {
+	policy, err := parseFailurePolicy(config.Datadog.GetString("admission_controller.failure_policy"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Controller{
+		client:      client,
+		namespace:   common.GetResourcesNamespace(),
+		secretName:  config.Datadog.GetString("admission_controller.certificate.secret_name"),
+		webhookName: config.Datadog.GetString("admission_controller.webhook_name"),
+		serviceName: config.Datadog.GetString("admission_controller.service_name"),
+		port:        config.Datadog.GetInt("admission_controller.port"),
+		validity:    config.Datadog.GetDuration("admission_controller.certificate.validity_bound") * time.Hour,
+		threshold:   config.Datadog.GetDuration("admission_controller.certificate.expiration_threshold") * time.Hour,
+		policy:      policy,
+		mutation: mutationConfig{
+			mutateUnlabelled: config.Datadog.GetBool("admission_controller.mutate_unlabelled"),
+			injectConfig:     config.Datadog.GetBool("admission_controller.inject_config.enabled"),
+			injectTags:       config.Datadog.GetBool("admission_controller.inject_tags.enabled"),
+		},
+		stopCh: make(chan struct{}),
+	}, nil
+}
+
+// Start registers the webhook and starts the HTTPS server it calls
+func (c *Controller) Start() error {
+	if err := c.refreshCertificate(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", c.port))
+	if err != nil {
+		return fmt.Errorf("unable to listen on the admission controller port %d: %s", c.port, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(mutatePath, c.handleMutate)
+	tlsConfig := &tls.Config{GetCertificate: c.getCertificate}
+	c.server = &http.Server{
+		Handler:   mux,
+		ErrorLog:  stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		TLSConfig: tlsConfig,
+	}
+	go c.server.Serve(tls.NewListener(listener, tlsConfig))
+	go c.renewCertificate()
+
+	log.Infof("Admission controller listening on port %d", c.port)
+	return nil
+}
+
+// Stop stops the HTTPS server, the webhook is kept registered to keep the
+// failure policy while the cluster agent restarts
+func (c *Controller) Stop() {
+	close(c.stopCh)
+	if c.server != nil {
+		c.server.Close()
+	}
+}
+
+// hosts returns the DNS names of the webhook service
+func (c *Controller) hosts() []string {
+	return []string{
+		c.serviceName,
+		fmt.Sprintf("%s.%s", c.serviceName, c.namespace),
+		fmt.Sprintf("%s.%s.svc", c.serviceName, c.namespace),
+	}
+}
+
+// refreshCertificate loads or renews the certificate, and registers the
+// webhook with its CA bundle
+func (c *Controller) refreshCertificate() error {
+	cert, err := getOrCreateCertificate(c.client, c.namespace, c.secretName, c.hosts(), c.validity, c.threshold)
+	if err != nil {
+		return err
+	}
+
+	c.m.Lock()
+	previous := c.cert
+	c.cert = cert
+	c.m.Unlock()
+
+	if previous != nil && string(previous.certPEM) == string(cert.certPEM) {
+		return nil
+	}
+	return registerWebhook(c.client, newWebhookConfiguration(c.webhookName, c.namespace, c.serviceName, cert.certPEM, c.policy))
+}
+
+func (c *Controller) renewCertificate() {
+	ticker := time.NewTicker(certificateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			if err := c.refreshCertificate(); err != nil {
+				log.Errorf("Could not renew the admission controller certificate: %s", err)
+			}
+		}
+	}
+}
+
+func (c *Controller) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	keyPair, err := c.cert.keyPair()
+	if err != nil {
+		return nil, err
+	}
+	return &keyPair, nil
+}
+
+// handleMutate answers the admission reviews of the API server
+func (c *Controller) handleMutate(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		log.Debugf("Invalid admission review: %v", err)
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = c.review(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// review returns the response to an admission request, the pods failing
+// to be mutated are admitted unless the failure policy is Fail
+func (c *Controller) review(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	patch, err := c.patchPod(req.Object.Raw)
+	if err != nil {
+		log.Warnf("Could not mutate the pod %s/%s: %s", req.Namespace, req.Name, err)
+		if c.policy == admiv1beta1.Fail {
+			return &admissionv1beta1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Message: err.Error()},
+			}
+		}
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	resp := &admissionv1beta1.AdmissionResponse{Allowed: true}
+	if patch != nil {
+		patchType := admissionv1beta1.PatchTypeJSONPatch
+		resp.Patch = patch
+		resp.PatchType = &patchType
+	}
+	return resp
+}
+
+// patchPod returns the JSON patch of the pod, nil if it is not mutated
+func (c *Controller) patchPod(raw []byte) ([]byte, error) {
+	pod := v1.Pod{}
+	if err := json.Unmarshal(raw, &pod); err != nil {
+		return nil, fmt.Errorf("unable to decode the pod: %s", err)
+	}
+
+	patch := c.mutation.mutatePod(&pod)
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(patch)
+}
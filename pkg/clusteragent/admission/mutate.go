@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/core/v1"
+)
+
+const (
+	// enabledAnnotation selects the pods mutated by the webhook, the pods
+	// without it are mutated if mutate_unlabelled is enabled
+	enabledAnnotation = "admission.datadoghq.com/enabled"
+
+	agentHostEnvVar = "DD_AGENT_HOST"
+	entityIDEnvVar  = "DD_ENTITY_ID"
+)
+
+// standardTagsLabels maps the labels of the standard tags to the env vars
+// read by the tracing libraries and dogstatsd clients
+var standardTagsLabels = []struct {
+	label  string
+	envVar string
+}{
+	{"tags.datadoghq.com/env", "DD_ENV"},
+	{"tags.datadoghq.com/service", "DD_SERVICE"},
+	{"tags.datadoghq.com/version", "DD_VERSION"},
+}
+
+// mutationConfig describes what the webhook injects in the pods
+type mutationConfig struct {
+	mutateUnlabelled bool
+	injectConfig     bool
+	injectTags       bool
+}
+
+// patchOperation is a JSON patch operation, see RFC 6902
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// shouldMutate returns whether the pod is selected by its annotation
+func (c mutationConfig) shouldMutate(pod *v1.Pod) bool {
+	value, found := pod.Annotations[enabledAnnotation]
+	if !found {
+		return c.mutateUnlabelled
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// envVars returns the env vars injected in the containers of the pod
+func (c mutationConfig) envVars(pod *v1.Pod) []v1.EnvVar {
+	var envs []v1.EnvVar
+	if c.injectConfig {
+		envs = append(envs,
+			v1.EnvVar{
+				Name:      agentHostEnvVar,
+				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.hostIP"}},
+			},
+			v1.EnvVar{
+				Name:      entityIDEnvVar,
+				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.uid"}},
+			},
+		)
+	}
+	if c.injectTags {
+		for _, tag := range standardTagsLabels {
+			if value := pod.Labels[tag.label]; value != "" {
+				envs = append(envs, v1.EnvVar{Name: tag.envVar, Value: value})
+			}
+		}
+	}
+	return envs
+}
+
+// mutatePod returns the JSON patch injecting the env vars in the containers
+// of the pod. The env vars already defined by a container are kept.
+func (c mutationConfig) mutatePod(pod *v1.Pod) []patchOperation {
+	if !c.shouldMutate(pod) {
+		return nil
+	}
+	envs := c.envVars(pod)
+	if len(envs) == 0 {
+		return nil
+	}
+
+	var patch []patchOperation
+	patch = append(patch, patchContainers("/spec/initContainers", pod.Spec.InitContainers, envs)...)
+	patch = append(patch, patchContainers("/spec/containers", pod.Spec.Containers, envs)...)
+	return patch
+}
+
+// patchContainers sets the env of the containers missing some of the env vars
+func patchContainers(path string, containers []v1.Container, envs []v1.EnvVar) []patchOperation {
+	var patch []patchOperation
+	for i, container := range containers {
+		defined := make(map[string]bool, len(container.Env))
+		for _, env := range container.Env {
+			defined[env.Name] = true
+		}
+
+		containerEnv := append([]v1.EnvVar{}, container.Env...)
+		for _, env := range envs {
+			if !defined[env.Name] {
+				containerEnv = append(containerEnv, env)
+			}
+		}
+		if len(containerEnv) == len(container.Env) {
+			continue
+		}
+		// adding an existing member replaces it
+		patch = append(patch, patchOperation{
+			Op:    "add",
+			Path:  fmt.Sprintf("%s/%d/env", path, i),
+			Value: containerEnv,
+		})
+	}
+	return patch
+}
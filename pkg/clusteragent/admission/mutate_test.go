@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(annotations, labels map[string]string, containers ...v1.Container) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod",
+			Annotations: annotations,
+			Labels:      labels,
+		},
+		Spec: v1.PodSpec{Containers: containers},
+	}
+}
+
+func TestShouldMutate(t *testing.T) {
+	for _, tc := range []struct {
+		annotations      map[string]string
+		mutateUnlabelled bool
+		expected         bool
+	}{
+		{nil, false, false},
+		{nil, true, true},
+		{map[string]string{enabledAnnotation: "true"}, false, true},
+		{map[string]string{enabledAnnotation: "false"}, true, false},
+		{map[string]string{enabledAnnotation: "invalid"}, true, false},
+	} {
+		c := mutationConfig{mutateUnlabelled: tc.mutateUnlabelled}
+		assert.Equal(t, tc.expected, c.shouldMutate(newPod(tc.annotations, nil)), "%v", tc)
+	}
+}
+
+func TestMutatePod(t *testing.T) {
+	c := mutationConfig{injectConfig: true, injectTags: true}
+	pod := newPod(
+		map[string]string{enabledAnnotation: "true"},
+		map[string]string{"tags.datadoghq.com/env": "prod", "tags.datadoghq.com/service": "web"},
+		v1.Container{Name: "web", Env: []v1.EnvVar{{Name: "DD_ENV", Value: "staging"}}},
+		v1.Container{Name: "sidecar"},
+	)
+
+	patch := c.mutatePod(pod)
+	require.Len(t, patch, 2)
+
+	assert.Equal(t, "/spec/containers/0/env", patch[0].Path)
+	env := patch[0].Value.([]v1.EnvVar)
+	require.Len(t, env, 4)
+	// the env vars defined by the container are kept
+	assert.Equal(t, v1.EnvVar{Name: "DD_ENV", Value: "staging"}, env[0])
+	assert.Equal(t, agentHostEnvVar, env[1].Name)
+	assert.Equal(t, "status.hostIP", env[1].ValueFrom.FieldRef.FieldPath)
+	assert.Equal(t, entityIDEnvVar, env[2].Name)
+	assert.Equal(t, "metadata.uid", env[2].ValueFrom.FieldRef.FieldPath)
+	assert.Equal(t, v1.EnvVar{Name: "DD_SERVICE", Value: "web"}, env[3])
+
+	assert.Equal(t, "/spec/containers/1/env", patch[1].Path)
+	assert.Len(t, patch[1].Value.([]v1.EnvVar), 4)
+}
+
+func TestMutatePodNotAnnotated(t *testing.T) {
+	c := mutationConfig{injectConfig: true, injectTags: true}
+	assert.Nil(t, c.mutatePod(newPod(nil, nil, v1.Container{Name: "web"})))
+}
+
+func TestMutatePodAlreadyConfigured(t *testing.T) {
+	c := mutationConfig{injectConfig: true, mutateUnlabelled: true}
+	pod := newPod(nil, nil, v1.Container{
+		Name: "web",
+		Env:  []v1.EnvVar{{Name: agentHostEnvVar, Value: "10.0.0.1"}, {Name: entityIDEnvVar, Value: "uid"}},
+	})
+	assert.Empty(t, c.mutatePod(pod))
+}
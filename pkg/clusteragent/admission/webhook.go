@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"fmt"
+	"strings"
+
+	admiv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// mutatePath is the path of the webhook mutating the pods
+	mutatePath = "/injectconfig"
+	// webhookPrefix is prepended to the webhook name, the API server
+	// requires a fully qualified name
+	webhookPrefix = "datadog.webhook."
+)
+
+// parseFailurePolicy returns the failure policy of the webhook: the pods
+// are created unmodified (Ignore) or rejected (Fail) when the cluster
+// agent doesn't answer
+func parseFailurePolicy(policy string) (admiv1beta1.FailurePolicyType, error) {
+	switch strings.ToLower(policy) {
+	case "ignore", "":
+		return admiv1beta1.Ignore, nil
+	case "fail":
+		return admiv1beta1.Fail, nil
+	default:
+		return "", fmt.Errorf("invalid failure policy %q, must be Ignore or Fail", policy)
+	}
+}
+
+// newWebhookConfiguration returns the configuration registering the
+// webhook service for the creation of the pods
+func newWebhookConfiguration(name, namespace, service string, caBundle []byte, policy admiv1beta1.FailurePolicyType) *admiv1beta1.MutatingWebhookConfiguration {
+	path := mutatePath
+	return &admiv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Webhooks: []admiv1beta1.Webhook{
+			{
+				Name: webhookPrefix + name,
+				ClientConfig: admiv1beta1.WebhookClientConfig{
+					Service: &admiv1beta1.ServiceReference{
+						Namespace: namespace,
+						Name:      service,
+						Path:      &path,
+					},
+					CABundle: caBundle,
+				},
+				Rules: []admiv1beta1.RuleWithOperations{
+					{
+						Operations: []admiv1beta1.OperationType{admiv1beta1.Create},
+						Rule: admiv1beta1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"pods"},
+						},
+					},
+				},
+				FailurePolicy: &policy,
+			},
+		},
+	}
+}
+
+// registerWebhook creates or updates the webhook configuration
+func registerWebhook(client kubernetes.Interface, webhook *admiv1beta1.MutatingWebhookConfiguration) error {
+	webhooks := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+
+	current, err := webhooks.Get(webhook.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = webhooks.Create(webhook)
+	} else if err == nil {
+		webhook.ResourceVersion = current.ResourceVersion
+		_, err = webhooks.Update(webhook)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to register the webhook %s: %s", webhook.Name, err)
+	}
+	return nil
+}
@@ -23,12 +23,17 @@ func Identify(check Check, instance integration.Data, initConfig integration.Dat
 
 // BuildID returns an unique ID for a check name and its configuration
 func BuildID(checkName string, instance, initConfig integration.Data) ID {
+	id := fmt.Sprintf("%s:%s", checkName, ConfigHash(instance, initConfig))
+	return ID(id)
+}
+
+// ConfigHash returns a hash of the resolved instance and init config of a check
+func ConfigHash(instance, initConfig integration.Data) string {
 	h := fnv.New64()
 	h.Write([]byte(instance))
 	h.Write([]byte(initConfig))
 
-	id := fmt.Sprintf("%s:%x", checkName, h.Sum64())
-	return ID(id)
+	return fmt.Sprintf("%x", h.Sum64())
 }
 
 // IDToCheckName returns the check name from a check ID
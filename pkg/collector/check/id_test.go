@@ -74,3 +74,18 @@ func TestIDToCheckName(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigHash(t *testing.T) {
+	instance := integration.Data("key1:value1\nkey2:value2")
+	initConfig := integration.Data("key:value")
+
+	hash := ConfigHash(instance, initConfig)
+	assert.Equal(t, hash, ConfigHash(instance, initConfig))
+	assert.NotEqual(t, hash, ConfigHash(integration.Data("key1:value1"), initConfig))
+	assert.Equal(t, ID("TestCheck:"+hash), BuildID("TestCheck", instance, initConfig))
+
+	SetConfigHash("TestCheck", instance, initConfig)
+	assert.Equal(t, hash, GetConfigHash("TestCheck"))
+	RemoveConfigHash("TestCheck")
+	assert.Equal(t, "", GetConfigHash("TestCheck"))
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package check
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// configHashes keeps the hash of the resolved config of every check instance,
+// it's filled by the loaders so that all kind of checks get one, and reported
+// in the status page and the inventory metadata to detect config drifts.
+var (
+	configHashes      = make(map[ID]string)
+	configHashesMutex sync.RWMutex
+)
+
+// SetConfigHash stores the hash of the config a check instance was configured with
+func SetConfigHash(id ID, instance, initConfig integration.Data) {
+	configHashesMutex.Lock()
+	defer configHashesMutex.Unlock()
+
+	configHashes[id] = ConfigHash(instance, initConfig)
+}
+
+// GetConfigHash returns the hash of the config of a check instance, or an
+// empty string if unknown
+func GetConfigHash(id ID) string {
+	configHashesMutex.RLock()
+	defer configHashesMutex.RUnlock()
+
+	return configHashes[id]
+}
+
+// RemoveConfigHash forgets the config hash of a check instance
+func RemoveConfigHash(id ID) {
+	configHashesMutex.Lock()
+	defer configHashesMutex.Unlock()
+
+	delete(configHashes, id)
+}
@@ -10,10 +10,20 @@ import (
 	"time"
 )
 
+// historySize is the number of errors and warnings kept per check instance
+const historySize = 10
+
+// StatsEntry is an error or a warning reported by a check run
+type StatsEntry struct {
+	Message   string
+	Timestamp int64 // time of the run, unix timestamp in seconds
+}
+
 // Stats holds basic runtime statistics about check instances
 type Stats struct {
 	CheckName            string
 	CheckVersion         string
+	CheckConfigHash      string
 	CheckID              ID
 	TotalRuns            uint64
 	TotalErrors          uint64
@@ -24,21 +34,27 @@ type Stats struct {
 	TotalMetricSamples   int64
 	TotalEvents          int64
 	TotalServiceChecks   int64
-	ExecutionTimes       [32]int64 // circular buffer of recent run durations, most recent at [(TotalRuns+31) % 32]
-	AverageExecutionTime int64     // average run duration
-	LastExecutionTime    int64     // most recent run duration, provided for convenience
-	LastError            string    // error that occurred in the last run, if any
-	LastWarnings         []string  // warnings that occurred in the last run, if any
-	UpdateTimestamp      int64     // latest update to this instance, unix timestamp in seconds
+	ExecutionTimes       [32]int64    // circular buffer of recent run durations, most recent at [(TotalRuns+31) % 32]
+	AverageExecutionTime int64        // average run duration
+	LastExecutionTime    int64        // most recent run duration, provided for convenience
+	LastCPUTime          int64        // CPU time of the most recent run, in milliseconds
+	TotalCPUTime         int64        // CPU time of all the runs, in milliseconds
+	LastAllocatedBytes   uint64       // memory allocated by the agent during the most recent run, approximately
+	LastError            string       // error that occurred in the last run, if any
+	LastWarnings         []string     // warnings that occurred in the last run, if any
+	ErrorHistory         []StatsEntry // most recent errors, oldest first
+	WarningHistory       []StatsEntry // most recent warnings, oldest first
+	UpdateTimestamp      int64        // latest update to this instance, unix timestamp in seconds
 	m                    sync.Mutex
 }
 
 // NewStats returns a new check stats instance
 func NewStats(c Check) *Stats {
 	return &Stats{
-		CheckID:      c.ID(),
-		CheckName:    c.String(),
-		CheckVersion: c.Version(),
+		CheckID:         c.ID(),
+		CheckName:       c.String(),
+		CheckVersion:    c.Version(),
+		CheckConfigHash: GetConfigHash(c.ID()),
 	}
 }
 
@@ -61,9 +77,11 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 		totalExecutionTime += cs.ExecutionTimes[i]
 	}
 	cs.AverageExecutionTime = totalExecutionTime / int64(ringSize)
+	cs.UpdateTimestamp = time.Now().Unix()
 	if err != nil {
 		cs.TotalErrors++
 		cs.LastError = err.Error()
+		cs.ErrorHistory = appendHistory(cs.ErrorHistory, cs.LastError, cs.UpdateTimestamp)
 	} else {
 		cs.LastError = ""
 	}
@@ -72,9 +90,9 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 		for _, w := range warnings {
 			cs.TotalWarnings++
 			cs.LastWarnings = append(cs.LastWarnings, w.Error())
+			cs.WarningHistory = appendHistory(cs.WarningHistory, w.Error(), cs.UpdateTimestamp)
 		}
 	}
-	cs.UpdateTimestamp = time.Now().Unix()
 
 	if m, ok := metricStats["MetricSamples"]; ok {
 		cs.MetricSamples = m
@@ -95,3 +113,23 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 		}
 	}
 }
+
+// AddResourceUsage tracks the resources used by the last run, following Add
+func (cs *Stats) AddResourceUsage(cpuTime time.Duration, allocatedBytes uint64) {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	cs.LastCPUTime = cpuTime.Nanoseconds() / 1e6
+	cs.TotalCPUTime += cs.LastCPUTime
+	cs.LastAllocatedBytes = allocatedBytes
+}
+
+// appendHistory appends an entry to a history, the oldest entries are dropped
+// to keep at most historySize entries
+func appendHistory(history []StatsEntry, message string, timestamp int64) []StatsEntry {
+	if len(history) >= historySize {
+		copy(history, history[len(history)-historySize+1:])
+		history = history[:historySize-1]
+	}
+	return append(history, StatsEntry{Message: message, Timestamp: timestamp})
+}
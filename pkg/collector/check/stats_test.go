@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package check
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsHistory(t *testing.T) {
+	s := &Stats{}
+
+	s.Add(time.Millisecond, errors.New("error 0"), []error{errors.New("warning 0")}, nil)
+	s.Add(time.Millisecond, nil, nil, nil)
+	assert.Equal(t, "", s.LastError)
+	assert.Empty(t, s.LastWarnings)
+	// the history is kept after a successful run
+	require.Len(t, s.ErrorHistory, 1)
+	assert.Equal(t, "error 0", s.ErrorHistory[0].Message)
+	assert.NotZero(t, s.ErrorHistory[0].Timestamp)
+	require.Len(t, s.WarningHistory, 1)
+	assert.Equal(t, "warning 0", s.WarningHistory[0].Message)
+
+	for i := 1; i <= historySize; i++ {
+		s.Add(time.Millisecond, fmt.Errorf("error %d", i), nil, nil)
+	}
+	assert.Equal(t, uint64(historySize+1), s.TotalErrors)
+	// only the most recent errors are kept, oldest first
+	require.Len(t, s.ErrorHistory, historySize)
+	assert.Equal(t, "error 1", s.ErrorHistory[0].Message)
+	assert.Equal(t, fmt.Sprintf("error %d", historySize), s.ErrorHistory[historySize-1].Message)
+	assert.Len(t, s.WarningHistory, 1)
+}
+
+func TestStatsResourceUsage(t *testing.T) {
+	s := &Stats{}
+
+	s.AddResourceUsage(15*time.Millisecond, 2048)
+	s.AddResourceUsage(5*time.Millisecond, 1024)
+	assert.Equal(t, int64(5), s.LastCPUTime)
+	assert.Equal(t, int64(20), s.TotalCPUTime)
+	assert.Equal(t, uint64(1024), s.LastAllocatedBytes)
+}
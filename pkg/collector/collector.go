@@ -10,6 +10,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner"
@@ -150,14 +151,18 @@ func (c *Collector) ReloadCheck(id check.ID, config, initConfig integration.Data
 	}
 
 	// re-configure
-	check := c.checks[id]
-	err = check.Configure(config, initConfig)
+	ch := c.checks[id]
+	err = ch.Configure(config, initConfig)
 	if err != nil {
 		return fmt.Errorf("error configuring the check with ID %s", id)
 	}
+	if err = aggregator.SetCheckMetricPatterns(id, config); err != nil {
+		return fmt.Errorf("error configuring the check with ID %s: %s", id, err)
+	}
+	check.SetConfigHash(id, config, initConfig)
 
 	// re-schedule
-	c.scheduler.Enter(check)
+	c.scheduler.Enter(ch)
 
 	return nil
 }
@@ -185,6 +190,8 @@ func (c *Collector) StopCheck(id check.ID) error {
 
 	// remove the check from the stats map
 	runner.RemoveCheckStats(id)
+	check.RemoveConfigHash(id)
+	aggregator.RemoveCheckMetricPatterns(id)
 
 	// vaporize the check
 	c.delete(id)
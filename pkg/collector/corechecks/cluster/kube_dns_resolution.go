@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeDNSCheckName     = "kube_dns_resolution"
+	kubeDNSMetricsPrefix = "kube_dns_resolution."
+)
+
+var (
+	// for testing purpose
+	getDNSServiceIP = apiserverDNSServiceIP
+)
+
+// KubeDNSConfig is the config of the kube_dns_resolution check.
+type KubeDNSConfig struct {
+	Services       []string `yaml:"services"`
+	ClusterDomain  string   `yaml:"cluster_domain"`
+	DNSService     string   `yaml:"dns_service"`
+	Nameserver     string   `yaml:"nameserver"`
+	NameserverPort int      `yaml:"nameserver_port"`
+	Timeout        float64  `yaml:"timeout"` // in seconds
+	Tags           []string `yaml:"tags"`
+}
+
+// KubeDNSCheck resolves in-cluster service names against the DNS service of
+// the cluster, kube-dns or CoreDNS, and reports the resolution latency and
+// failures. It is meant to be scheduled as a cluster check, as it reports
+// on the whole cluster.
+type KubeDNSCheck struct {
+	core.CheckBase
+	instance   *KubeDNSConfig
+	nameserver string
+	lookup     func(ctx context.Context, nameserver, host string) ([]net.IPAddr, error)
+}
+
+func (c *KubeDNSConfig) parse(data []byte) error {
+	// default values
+	c.Services = []string{"kubernetes.default"}
+	c.ClusterDomain = "cluster.local"
+	c.DNSService = "kube-system/kube-dns"
+	c.NameserverPort = 53
+	c.Timeout = 5
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if len(c.Services) == 0 {
+		return errors.New("the services option must list at least one service")
+	}
+	if c.Nameserver == "" && len(strings.Split(c.DNSService, "/")) != 2 {
+		return fmt.Errorf("invalid dns_service %s, must be <namespace>/<name>", c.DNSService)
+	}
+	c.ClusterDomain = strings.Trim(c.ClusterDomain, ".")
+	return nil
+}
+
+// Configure parses the check configuration and init the check.
+func (k *KubeDNSCheck) Configure(config, initConfig integration.Data) error {
+	err := k.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the kube_dns_resolution check")
+		return err
+	}
+	k.nameserver = k.instance.Nameserver
+
+	k.BuildID(config, initConfig)
+	log.Debugf("Running config %s", config)
+	return nil
+}
+
+// Run executes the check.
+func (k *KubeDNSCheck) Run() error {
+	sender, err := aggregator.GetSender(k.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	if k.nameserver == "" {
+		parts := strings.Split(k.instance.DNSService, "/")
+		k.nameserver, err = getDNSServiceIP(parts[0], parts[1])
+		if err != nil {
+			k.Warnf("Could not get the IP of the DNS service %s: %s", k.instance.DNSService, err)
+			return err
+		}
+	}
+
+	for _, service := range k.instance.Services {
+		k.resolveService(sender, service)
+	}
+	return nil
+}
+
+// resolveService resolves a service name and submits the
+// `kube_dns_resolution.can_resolve` service check and the resolution metrics
+func (k *KubeDNSCheck) resolveService(sender aggregator.Sender, service string) {
+	tags := append([]string{
+		fmt.Sprintf("resolved_service:%s", service),
+		fmt.Sprintf("nameserver:%s", k.nameserver),
+	}, k.instance.Tags...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(k.instance.Timeout*float64(time.Second)))
+	defer cancel()
+
+	nameserver := net.JoinHostPort(k.nameserver, strconv.Itoa(k.instance.NameserverPort))
+	host := serviceFQDN(service, k.instance.ClusterDomain)
+	start := time.Now()
+	addrs, err := k.lookup(ctx, nameserver, host)
+	elapsed := time.Since(start)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("no record for %s", host)
+	}
+	if err != nil {
+		sender.ServiceCheck(kubeDNSMetricsPrefix+"can_resolve", metrics.ServiceCheckCritical, "", tags, err.Error())
+		sender.Count(kubeDNSMetricsPrefix+"errors", 1, "", tags)
+		return
+	}
+
+	sender.ServiceCheck(kubeDNSMetricsPrefix+"can_resolve", metrics.ServiceCheckOK, "", tags, "")
+	sender.Count(kubeDNSMetricsPrefix+"errors", 0, "", tags)
+	sender.Gauge(kubeDNSMetricsPrefix+"response_time", elapsed.Seconds(), "", tags)
+}
+
+// serviceFQDN returns the fully qualified name of a service, the names are
+// <service>.<namespace>, the namespace defaults to default. The names ending
+// with a dot are already fully qualified.
+func serviceFQDN(service, clusterDomain string) string {
+	if strings.HasSuffix(service, ".") {
+		return service
+	}
+	if strings.HasSuffix(service, "."+clusterDomain) {
+		return service + "."
+	}
+	parts := strings.Split(service, ".")
+	if len(parts) == 1 {
+		parts = append(parts, "default")
+	}
+	if parts[len(parts)-1] != "svc" {
+		parts = append(parts, "svc")
+	}
+	return strings.Join(append(parts, clusterDomain), ".") + "."
+}
+
+// lookupWithNameserver resolves a host against the given nameserver,
+// the resolv.conf of the agent is ignored
+func lookupWithNameserver(ctx context.Context, nameserver, host string) ([]net.IPAddr, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, nameserver)
+		},
+	}
+	return resolver.LookupIPAddr(ctx, host)
+}
+
+// apiserverDNSServiceIP returns the cluster IP of the DNS service
+func apiserverDNSServiceIP(namespace, name string) (string, error) {
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return "", err
+	}
+	service, err := ac.Cl.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == "None" {
+		return "", fmt.Errorf("service %s/%s has no cluster IP", namespace, name)
+	}
+	return service.Spec.ClusterIP, nil
+}
+
+// KubeDNSFactory is exported for integration testing.
+func KubeDNSFactory() check.Check {
+	return &KubeDNSCheck{
+		CheckBase: core.NewCheckBase(kubeDNSCheckName),
+		instance:  &KubeDNSConfig{},
+		lookup:    lookupWithNameserver,
+	}
+}
+
+func init() {
+	core.RegisterCheck(kubeDNSCheckName, KubeDNSFactory)
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestServiceFQDN(t *testing.T) {
+	assert.Equal(t, "kubernetes.default.svc.cluster.local.", serviceFQDN("kubernetes", "cluster.local"))
+	assert.Equal(t, "kubernetes.default.svc.cluster.local.", serviceFQDN("kubernetes.default", "cluster.local"))
+	assert.Equal(t, "redis.cache.svc.cluster.local.", serviceFQDN("redis.cache.svc", "cluster.local"))
+	assert.Equal(t, "redis-0.redis.cache.svc.k8s.example.com.", serviceFQDN("redis-0.redis.cache", "k8s.example.com"))
+	assert.Equal(t, "redis.cache.svc.cluster.local.", serviceFQDN("redis.cache.svc.cluster.local", "cluster.local"))
+	assert.Equal(t, "example.com.", serviceFQDN("example.com.", "cluster.local"))
+}
+
+func TestKubeDNSCheck(t *testing.T) {
+	defer func(original func(string, string) (string, error)) { getDNSServiceIP = original }(getDNSServiceIP)
+	getDNSServiceIP = func(namespace, name string) (string, error) {
+		assert.Equal(t, "kube-system", namespace)
+		assert.Equal(t, "kube-dns", name)
+		return "10.96.0.10", nil
+	}
+
+	check := KubeDNSFactory().(*KubeDNSCheck)
+	require.NoError(t, check.Configure([]byte("services: [kubernetes.default, redis.cache]\ntags: [customtag]"), []byte("")))
+	check.lookup = func(ctx context.Context, nameserver, host string) ([]net.IPAddr, error) {
+		assert.Equal(t, "10.96.0.10:53", nameserver)
+		if host == "kubernetes.default.svc.cluster.local." {
+			return []net.IPAddr{{IP: net.ParseIP("10.96.0.1")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	okTags := []string{"resolved_service:kubernetes.default", "nameserver:10.96.0.10", "customtag"}
+	mocked.AssertServiceCheck(t, "kube_dns_resolution.can_resolve", metrics.ServiceCheckOK, "", okTags, "")
+	mocked.AssertMetric(t, "Count", "kube_dns_resolution.errors", 0, "", okTags)
+	mocked.AssertCalled(t, "Gauge", "kube_dns_resolution.response_time", mock.AnythingOfType("float64"), "", mocksender.MatchTagsContains(okTags))
+
+	failedTags := []string{"resolved_service:redis.cache", "nameserver:10.96.0.10", "customtag"}
+	mocked.AssertServiceCheck(t, "kube_dns_resolution.can_resolve", metrics.ServiceCheckCritical, "", failedTags, "no such host")
+	mocked.AssertMetric(t, "Count", "kube_dns_resolution.errors", 1, "", failedTags)
+	mocked.AssertNumberOfCalls(t, "Gauge", 1)
+}
+
+func TestKubeDNSCheckConfig(t *testing.T) {
+	check := KubeDNSFactory()
+	require.Error(t, check.Configure([]byte("services: []"), []byte("")))
+	require.Error(t, check.Configure([]byte("dns_service: kube-dns"), []byte("")))
+	require.NoError(t, check.Configure([]byte("dns_service: kube-dns\nnameserver: 10.96.0.10"), []byte("")))
+}
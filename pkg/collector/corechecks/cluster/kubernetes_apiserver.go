@@ -179,7 +179,13 @@ func KubernetesASFactory() check.Check {
 }
 
 func (k *KubeASCheck) runLeaderElection() error {
+	return runLeaderElection(&k.CheckBase)
+}
 
+// runLeaderElection makes sure the leader election engine is running and
+// returns apiserver.ErrNotLeader if the current agent is not the leader,
+// warnings are reported on the given check.
+func runLeaderElection(k *core.CheckBase) error {
 	leaderEngine, err := leaderelection.GetLeaderEngine()
 	if err != nil {
 		k.Warn("Failed to instantiate the Leader Elector. Not running the Kubernetes API Server check or collecting Kubernetes Events.")
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeAPIServerHealthCheckName = "kube_apiserver_health"
+	kubeAPIServerMetricsPrefix   = "kube_apiserver."
+	kubeAPIServerMetricsEndpoint = "/metrics"
+)
+
+var defaultHealthEndpoints = []string{"/healthz", "/livez", "/readyz"}
+
+// KubeAPIServerHealthConfig is the config of the APIserver health check.
+type KubeAPIServerHealthConfig struct {
+	Tags            []string `yaml:"tags"`
+	HealthEndpoints []string `yaml:"health_endpoints"`
+	CollectMetrics  bool     `yaml:"collect_metrics"`
+}
+
+// KubeAPIServerHealthCheck queries the health endpoints of the APIserver
+// and reports service checks and the key latency/error metrics of the
+// control plane.
+type KubeAPIServerHealthCheck struct {
+	core.CheckBase
+	instance *KubeAPIServerHealthConfig
+	ac       *apiserver.APIClient
+}
+
+func (c *KubeAPIServerHealthConfig) parse(data []byte) error {
+	// default values
+	c.HealthEndpoints = defaultHealthEndpoints
+	c.CollectMetrics = true
+
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check.
+func (k *KubeAPIServerHealthCheck) Configure(config, initConfig integration.Data) error {
+	err := k.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the API server health check")
+		return err
+	}
+
+	log.Debugf("Running config %s", config)
+	return nil
+}
+
+// Run executes the check.
+func (k *KubeAPIServerHealthCheck) Run() error {
+	sender, err := aggregator.GetSender(k.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	// Only run if Leader Election is enabled, the control plane
+	// is shared by every replica of the Cluster Agent.
+	if !config.Datadog.GetBool("leader_election") {
+		k.Warn("Leader Election not enabled. Not running Kubernetes API Server health check.")
+		return nil
+	}
+	errLeader := runLeaderElection(&k.CheckBase)
+	if errLeader != nil {
+		if errLeader == apiserver.ErrNotLeader {
+			return nil
+		}
+		return errLeader
+	}
+
+	if k.ac == nil {
+		k.ac, err = apiserver.GetAPIClient()
+		if err != nil {
+			k.Warnf("Could not connect to apiserver: %s", err)
+			return err
+		}
+	}
+
+	for _, endpoint := range k.instance.HealthEndpoints {
+		// The verbose output lists the individual checks run by the APIserver
+		raw, err := k.ac.GetRawEndpoint(endpoint, true)
+		k.reportHealth(sender, endpoint, raw, err)
+	}
+
+	if !k.instance.CollectMetrics {
+		return nil
+	}
+	raw, err := k.ac.GetRawEndpoint(kubeAPIServerMetricsEndpoint, false)
+	if err != nil {
+		k.Warnf("Could not collect the API server metrics: %s", err.Error())
+		return nil
+	}
+	if err = k.reportMetrics(sender, raw); err != nil {
+		k.Warnf("Could not parse the API server metrics: %s", err.Error())
+	}
+	return nil
+}
+
+// reportHealth submits a `kube_apiserver.<endpoint>` service check from the
+// response of a health endpoint. Failed individual checks are listed in the
+// service check message.
+func (k *KubeAPIServerHealthCheck) reportHealth(sender aggregator.Sender, endpoint string, raw []byte, err error) {
+	name := fmt.Sprintf("%s%s", kubeAPIServerMetricsPrefix, strings.Trim(endpoint, "/"))
+	tags := append([]string{fmt.Sprintf("endpoint:%s", endpoint)}, k.instance.Tags...)
+
+	if err != nil && len(raw) == 0 {
+		sender.ServiceCheck(name, metrics.ServiceCheckCritical, "", tags, err.Error())
+		return
+	}
+
+	failed := parseFailedHealthChecks(raw)
+	switch {
+	case len(failed) > 0:
+		sender.ServiceCheck(name, metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("failed checks: %s", strings.Join(failed, ", ")))
+	case err != nil:
+		sender.ServiceCheck(name, metrics.ServiceCheckCritical, "", tags, err.Error())
+	default:
+		sender.ServiceCheck(name, metrics.ServiceCheckOK, "", tags, "")
+	}
+}
+
+// parseFailedHealthChecks returns the name of the checks reported as failed
+// in the verbose output of a health endpoint, formatted as:
+//
+//	[+]ping ok
+//	[-]etcd failed: reason withheld
+func parseFailedHealthChecks(raw []byte) []string {
+	var failed []string
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "[-]") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "[-]"))
+		if len(fields) > 0 {
+			failed = append(failed, fields[0])
+		}
+	}
+	return failed
+}
+
+// reportMetrics parses the prometheus text output of the APIserver and
+// submits the request counts, errors, latencies and inflight requests.
+func (k *KubeAPIServerHealthCheck) reportMetrics(sender aggregator.Sender, raw []byte) error {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	for familyName, family := range families {
+		for _, metric := range family.GetMetric() {
+			tags := append(labelsToTags(metric.GetLabel()), k.instance.Tags...)
+
+			switch familyName {
+			// apiserver_request_count was renamed in Kubernetes 1.14
+			case "apiserver_request_total", "apiserver_request_count":
+				sender.MonotonicCount(kubeAPIServerMetricsPrefix+"request.count", metric.GetCounter().GetValue(), "", tags)
+				if strings.HasPrefix(labelValue(metric.GetLabel(), "code"), "5") {
+					sender.MonotonicCount(kubeAPIServerMetricsPrefix+"request.errors", metric.GetCounter().GetValue(), "", tags)
+				}
+			case "apiserver_request_duration_seconds":
+				sender.MonotonicCount(kubeAPIServerMetricsPrefix+"request.duration.sum", metric.GetHistogram().GetSampleSum(), "", tags)
+				sender.MonotonicCount(kubeAPIServerMetricsPrefix+"request.duration.count", float64(metric.GetHistogram().GetSampleCount()), "", tags)
+			case "apiserver_request_latencies_summary":
+				// Legacy latency summary, reported in microseconds
+				sender.MonotonicCount(kubeAPIServerMetricsPrefix+"request.duration.sum", metric.GetSummary().GetSampleSum()/1e6, "", tags)
+				sender.MonotonicCount(kubeAPIServerMetricsPrefix+"request.duration.count", float64(metric.GetSummary().GetSampleCount()), "", tags)
+			case "apiserver_current_inflight_requests":
+				sender.Gauge(kubeAPIServerMetricsPrefix+"inflight_requests", metric.GetGauge().GetValue(), "", tags)
+			case "etcd_object_counts":
+				sender.Gauge(kubeAPIServerMetricsPrefix+"etcd_object_counts", metric.GetGauge().GetValue(), "", tags)
+			}
+		}
+	}
+	return nil
+}
+
+// labelsToTags converts the labels of a prometheus metric to datadog tags,
+// skipping the ones with an empty value.
+func labelsToTags(labels []*dto.LabelPair) []string {
+	tags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label.GetValue() == "" {
+			continue
+		}
+		tags = append(tags, fmt.Sprintf("%s:%s", label.GetName(), label.GetValue()))
+	}
+	return tags
+}
+
+func labelValue(labels []*dto.LabelPair, name string) string {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+// KubeAPIServerHealthFactory is exported for integration testing.
+func KubeAPIServerHealthFactory() check.Check {
+	return &KubeAPIServerHealthCheck{
+		CheckBase: core.NewCheckBase(kubeAPIServerHealthCheckName),
+		instance:  &KubeAPIServerHealthConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(kubeAPIServerHealthCheckName, KubeAPIServerHealthFactory)
+}
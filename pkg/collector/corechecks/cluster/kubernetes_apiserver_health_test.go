@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestParseFailedHealthChecks(t *testing.T) {
+	raw := []byte("[+]ping ok\n[+]log ok\n[-]etcd failed: reason withheld\n[+]poststarthook/generic-apiserver-start-informers ok\n[-]poststarthook/rbac/bootstrap-roles failed: reason withheld\nhealthz check failed\n")
+	assert.Equal(t, []string{"etcd", "poststarthook/rbac/bootstrap-roles"}, parseFailedHealthChecks(raw))
+	assert.Len(t, parseFailedHealthChecks([]byte("ok")), 0)
+}
+
+func TestReportHealth(t *testing.T) {
+	check := KubeAPIServerHealthFactory().(*KubeAPIServerHealthCheck)
+	require.NoError(t, check.Configure([]byte("tags: [customtag]"), []byte("")))
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+
+	check.reportHealth(mocked, "/healthz", []byte("[+]ping ok\n[+]etcd ok\nhealthz check passed\n"), nil)
+	mocked.AssertServiceCheck(t, "kube_apiserver.healthz", metrics.ServiceCheckOK, "", []string{"customtag", "endpoint:/healthz"}, "")
+
+	check.reportHealth(mocked, "/readyz", []byte("[+]ping ok\n[-]etcd failed: reason withheld\n"), errors.New("the server is currently unable to handle the request"))
+	mocked.AssertServiceCheck(t, "kube_apiserver.readyz", metrics.ServiceCheckCritical, "", []string{"customtag", "endpoint:/readyz"}, "failed checks: etcd")
+
+	check.reportHealth(mocked, "/livez", nil, errors.New("connection refused"))
+	mocked.AssertServiceCheck(t, "kube_apiserver.livez", metrics.ServiceCheckCritical, "", []string{"customtag", "endpoint:/livez"}, "connection refused")
+}
+
+func TestReportAPIServerMetrics(t *testing.T) {
+	raw, err := ioutil.ReadFile("./testdata/apiserver_metrics.txt")
+	require.NoError(t, err)
+
+	check := KubeAPIServerHealthFactory().(*KubeAPIServerHealthCheck)
+	require.NoError(t, check.Configure([]byte("tags: [customtag]"), []byte("")))
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+	require.NoError(t, check.reportMetrics(mocked, raw))
+
+	mocked.AssertMetric(t, "Gauge", "kube_apiserver.inflight_requests", 2, "", []string{"customtag", "requestKind:mutating"})
+	mocked.AssertMetric(t, "Gauge", "kube_apiserver.inflight_requests", 5, "", []string{"customtag", "requestKind:readOnly"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.count", 120, "", []string{"customtag", "code:200", "resource:pods", "verb:LIST"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.count", 3, "", []string{"customtag", "code:503", "resource:pods", "verb:LIST"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.errors", 3, "", []string{"customtag", "code:503"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.duration.sum", 18.5, "", []string{"customtag", "resource:pods", "verb:LIST"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_apiserver.request.duration.count", 123, "", []string{"customtag", "resource:pods", "verb:LIST"})
+	mocked.AssertNumberOfCalls(t, "Gauge", 2)
+	mocked.AssertNumberOfCalls(t, "MonotonicCount", 5)
+}
@@ -0,0 +1,264 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeStateCheckName     = "kubernetes_state_core"
+	kubeStateMetricsPrefix = "kubernetes_state."
+)
+
+// KubeStateConfig is the config of the kubernetes_state_core check.
+type KubeStateConfig struct {
+	Tags             []string `yaml:"tags"`
+	CacheSyncTimeout int      `yaml:"cache_sync_timeout_seconds"`
+}
+
+// KubeStateCheck reports object counts and states from the informer caches
+// of the Cluster Agent, without querying the APIserver at every run.
+type KubeStateCheck struct {
+	core.CheckBase
+	instance         *KubeStateConfig
+	deploymentLister appslisters.DeploymentLister
+	podLister        corelisters.PodLister
+	nodeLister       corelisters.NodeLister
+	stopCh           chan struct{} // closed to stop the informers of the check
+	m                sync.Mutex    // protects stopCh
+}
+
+func (c *KubeStateConfig) parse(data []byte) error {
+	// default values
+	c.CacheSyncTimeout = 30
+
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check.
+func (k *KubeStateCheck) Configure(config, initConfig integration.Data) error {
+	err := k.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the kubernetes_state_core check")
+		return err
+	}
+
+	log.Debugf("Running config %s", config)
+	return nil
+}
+
+// Run executes the check.
+func (k *KubeStateCheck) Run() error {
+	sender, err := aggregator.GetSender(k.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	// Only the leader reports cluster level metrics, to avoid duplicates
+	// between replicas of the Cluster Agent.
+	if !config.Datadog.GetBool("leader_election") {
+		k.Warn("Leader Election not enabled. Not running the kubernetes_state_core check.")
+		return nil
+	}
+	errLeader := runLeaderElection(&k.CheckBase)
+	if errLeader != nil {
+		if errLeader == apiserver.ErrNotLeader {
+			return nil
+		}
+		return errLeader
+	}
+
+	// Informers initialisation on first run
+	k.m.Lock()
+	if k.stopCh == nil {
+		err = k.initInformers()
+	}
+	k.m.Unlock()
+	if err != nil {
+		k.Warnf("Could not initialize the informers: %s", err)
+		return err
+	}
+
+	deployments, err := k.deploymentLister.List(labels.Everything())
+	if err != nil {
+		k.Warnf("Could not list deployments: %s", err)
+	} else {
+		k.reportDeployments(sender, deployments)
+	}
+
+	pods, err := k.podLister.List(labels.Everything())
+	if err != nil {
+		k.Warnf("Could not list pods: %s", err)
+	} else {
+		k.reportPods(sender, pods)
+	}
+
+	nodes, err := k.nodeLister.List(labels.Everything())
+	if err != nil {
+		k.Warnf("Could not list nodes: %s", err)
+	} else {
+		k.reportNodes(sender, nodes)
+	}
+	return nil
+}
+
+// initInformers registers the informers on the shared informer factory of
+// the APIClient and waits for their caches to be synced.
+func (k *KubeStateCheck) initInformers() error {
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return err
+	}
+
+	// The informers are not shared with the controllers of the Cluster
+	// Agent, so that they can be stopped with the check.
+	resyncPeriod := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period")) * time.Second
+	factory := informers.NewSharedInformerFactory(ac.Cl, resyncPeriod)
+	deploymentInformer := factory.Apps().V1().Deployments()
+	podInformer := factory.Core().V1().Pods()
+	nodeInformer := factory.Core().V1().Nodes()
+
+	k.deploymentLister = deploymentInformer.Lister()
+	k.podLister = podInformer.Lister()
+	k.nodeLister = nodeInformer.Lister()
+
+	stopCh := make(chan struct{})
+	factory.Start(stopCh)
+
+	timeout := time.After(time.Duration(k.instance.CacheSyncTimeout) * time.Second)
+	syncCh := make(chan struct{})
+	go func() {
+		<-timeout
+		close(syncCh)
+	}()
+	if !cache.WaitForCacheSync(syncCh, deploymentInformer.Informer().HasSynced, podInformer.Informer().HasSynced, nodeInformer.Informer().HasSynced) {
+		close(stopCh)
+		return errors.New("timeout while waiting for the informer caches to sync")
+	}
+
+	k.stopCh = stopCh
+	return nil
+}
+
+// Stop stops the informers of the check, they are started again on the
+// next run
+func (k *KubeStateCheck) Stop() {
+	k.m.Lock()
+	defer k.m.Unlock()
+
+	if k.stopCh != nil {
+		close(k.stopCh)
+		k.stopCh = nil
+	}
+}
+
+func (k *KubeStateCheck) reportDeployments(sender aggregator.Sender, deployments []*appsv1.Deployment) {
+	for _, deployment := range deployments {
+		tags := append([]string{
+			fmt.Sprintf("kube_deployment:%s", deployment.Name),
+			fmt.Sprintf("kube_namespace:%s", deployment.Namespace),
+		}, k.instance.Tags...)
+
+		desired := int32(1) // defaulted by the APIserver when unset
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		sender.Gauge(kubeStateMetricsPrefix+"deployment.replicas_desired", float64(desired), "", tags)
+		sender.Gauge(kubeStateMetricsPrefix+"deployment.replicas", float64(deployment.Status.Replicas), "", tags)
+		sender.Gauge(kubeStateMetricsPrefix+"deployment.replicas_available", float64(deployment.Status.AvailableReplicas), "", tags)
+		sender.Gauge(kubeStateMetricsPrefix+"deployment.replicas_unavailable", float64(deployment.Status.UnavailableReplicas), "", tags)
+		sender.Gauge(kubeStateMetricsPrefix+"deployment.replicas_updated", float64(deployment.Status.UpdatedReplicas), "", tags)
+	}
+}
+
+// reportPods counts pods by namespace and phase
+func (k *KubeStateCheck) reportPods(sender aggregator.Sender, pods []*v1.Pod) {
+	type podKey struct {
+		namespace string
+		phase     string
+	}
+	counts := make(map[podKey]int)
+	for _, pod := range pods {
+		phase := string(pod.Status.Phase)
+		if phase == "" {
+			phase = string(v1.PodUnknown)
+		}
+		counts[podKey{pod.Namespace, phase}]++
+	}
+
+	for key, count := range counts {
+		tags := append([]string{
+			fmt.Sprintf("kube_namespace:%s", key.namespace),
+			fmt.Sprintf("phase:%s", strings.ToLower(key.phase)),
+		}, k.instance.Tags...)
+		sender.Gauge(kubeStateMetricsPrefix+"pod.count", float64(count), "", tags)
+	}
+}
+
+// reportNodes reports the node count and the number of nodes by condition
+func (k *KubeStateCheck) reportNodes(sender aggregator.Sender, nodes []*v1.Node) {
+	type conditionKey struct {
+		condition string
+		status    string
+	}
+	counts := make(map[conditionKey]int)
+	unschedulable := 0
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			unschedulable++
+		}
+		for _, condition := range node.Status.Conditions {
+			counts[conditionKey{string(condition.Type), string(condition.Status)}]++
+		}
+	}
+
+	sender.Gauge(kubeStateMetricsPrefix+"node.count", float64(len(nodes)), "", k.instance.Tags)
+	sender.Gauge(kubeStateMetricsPrefix+"node.unschedulable", float64(unschedulable), "", k.instance.Tags)
+	for key, count := range counts {
+		tags := append([]string{
+			fmt.Sprintf("condition:%s", strings.ToLower(key.condition)),
+			fmt.Sprintf("status:%s", strings.ToLower(key.status)),
+		}, k.instance.Tags...)
+		sender.Gauge(kubeStateMetricsPrefix+"node.by_condition", float64(count), "", tags)
+	}
+}
+
+// KubeStateFactory is exported for integration testing.
+func KubeStateFactory() check.Check {
+	return &KubeStateCheck{
+		CheckBase: core.NewCheckBase(kubeStateCheckName),
+		instance:  &KubeStateConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(kubeStateCheckName, KubeStateFactory)
+}
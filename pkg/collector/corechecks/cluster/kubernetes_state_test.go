@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	obj "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+func newKubeStateCheck(t *testing.T) (*KubeStateCheck, *mocksender.MockSender) {
+	kubeStateCheck := KubeStateFactory().(*KubeStateCheck)
+	require.NoError(t, kubeStateCheck.Configure([]byte("tags: [customtag]"), []byte("")))
+
+	mocked := mocksender.NewMockSender(kubeStateCheck.ID())
+	mocked.SetupAcceptAll()
+	return kubeStateCheck, mocked
+}
+
+func TestReportDeployments(t *testing.T) {
+	kubeStateCheck, mocked := newKubeStateCheck(t)
+	replicas := int32(3)
+	deployments := []*appsv1.Deployment{
+		{
+			ObjectMeta: obj.ObjectMeta{Name: "nginx", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{Replicas: 3, AvailableReplicas: 2, UnavailableReplicas: 1, UpdatedReplicas: 3},
+		},
+		{
+			ObjectMeta: obj.ObjectMeta{Name: "redis", Namespace: "cache"},
+		},
+	}
+	kubeStateCheck.reportDeployments(mocked, deployments)
+
+	nginxTags := []string{"customtag", "kube_deployment:nginx", "kube_namespace:default"}
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas_desired", 3, "", nginxTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas", 3, "", nginxTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas_available", 2, "", nginxTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas_unavailable", 1, "", nginxTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas_updated", 3, "", nginxTags)
+
+	redisTags := []string{"customtag", "kube_deployment:redis", "kube_namespace:cache"}
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas_desired", 1, "", redisTags)
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.deployment.replicas_available", 0, "", redisTags)
+	mocked.AssertNumberOfCalls(t, "Gauge", 10)
+}
+
+func TestReportPods(t *testing.T) {
+	kubeStateCheck, mocked := newKubeStateCheck(t)
+	pods := []*v1.Pod{
+		{ObjectMeta: obj.ObjectMeta{Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		{ObjectMeta: obj.ObjectMeta{Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		{ObjectMeta: obj.ObjectMeta{Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodPending}},
+		{ObjectMeta: obj.ObjectMeta{Namespace: "kube-system"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		{ObjectMeta: obj.ObjectMeta{Namespace: "kube-system"}},
+	}
+	kubeStateCheck.reportPods(mocked, pods)
+
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.pod.count", 2, "", []string{"customtag", "kube_namespace:default", "phase:running"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.pod.count", 1, "", []string{"customtag", "kube_namespace:default", "phase:pending"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.pod.count", 1, "", []string{"customtag", "kube_namespace:kube-system", "phase:running"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.pod.count", 1, "", []string{"customtag", "kube_namespace:kube-system", "phase:unknown"})
+	mocked.AssertNumberOfCalls(t, "Gauge", 4)
+}
+
+func TestReportNodes(t *testing.T) {
+	kubeStateCheck, mocked := newKubeStateCheck(t)
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue},
+				{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+			}},
+		},
+		{
+			Spec: v1.NodeSpec{Unschedulable: true},
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionFalse},
+				{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+			}},
+		},
+	}
+	kubeStateCheck.reportNodes(mocked, nodes)
+
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.node.count", 2, "", []string{"customtag"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.node.unschedulable", 1, "", []string{"customtag"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.node.by_condition", 1, "", []string{"customtag", "condition:ready", "status:true"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.node.by_condition", 1, "", []string{"customtag", "condition:ready", "status:false"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.node.by_condition", 2, "", []string{"customtag", "condition:diskpressure", "status:false"})
+	mocked.AssertNumberOfCalls(t, "Gauge", 5)
+}
+
+func TestKubeStateCheckStop(t *testing.T) {
+	kubeStateCheck, _ := newKubeStateCheck(t)
+	stopCh := make(chan struct{})
+	kubeStateCheck.stopCh = stopCh
+
+	kubeStateCheck.Stop()
+	_, open := <-stopCh
+	require.False(t, open)
+	require.Nil(t, kubeStateCheck.stopCh)
+
+	// stopping a stopped check is a no-op
+	kubeStateCheck.Stop()
+}
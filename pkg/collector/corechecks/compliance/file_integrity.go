@@ -0,0 +1,336 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package compliance provides core checks for the compliance of the host
+package compliance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	fileIntegrityCheckName = "file_integrity"
+	defaultMaxFileSize     = 10 * 1024 * 1024
+)
+
+// fileChange is the kind of change reported for a file
+type fileChange string
+
+const (
+	fileCreated     fileChange = "created"
+	fileModified    fileChange = "modified"
+	fileDeleted     fileChange = "deleted"
+	fileModeChanged fileChange = "permissions_changed"
+)
+
+// FileIntegrityConfig is the config of the file_integrity check.
+type FileIntegrityConfig struct {
+	// Paths are the monitored files, the regular files of a directory are
+	// monitored, not its subdirectories
+	Paths       []string `yaml:"paths"`
+	MaxFileSize int64    `yaml:"max_file_size"` // in bytes, larger files are not hashed
+	Tags        []string `yaml:"tags"`
+}
+
+// fileState is the state of a monitored file at the last run
+type fileState struct {
+	hash string
+	size int64
+	mode os.FileMode
+}
+
+// FileIntegrityCheck reports the changes of the monitored files as events,
+// with their hash before and after the change. The files are hashed at every
+// run, and watched between the runs to report the processes modifying them
+// where available.
+type FileIntegrityCheck struct {
+	core.CheckBase
+	instance *FileIntegrityConfig
+	states   map[string]fileState
+	watcher  *fsnotify.Watcher
+
+	m sync.Mutex
+	// modifiers are the processes which modified the files since the last run
+	modifiers map[string]*processInfo
+}
+
+func (c *FileIntegrityConfig) parse(data []byte) error {
+	// default values
+	c.MaxFileSize = defaultMaxFileSize
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if len(c.Paths) == 0 {
+		return errors.New("the paths option is required")
+	}
+	for i, path := range c.Paths {
+		c.Paths[i] = filepath.Clean(path)
+	}
+	return nil
+}
+
+// Configure parses the check configuration and starts watching the files.
+func (c *FileIntegrityCheck) Configure(config, initConfig integration.Data) error {
+	err := c.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the file_integrity check")
+		return err
+	}
+	c.BuildID(config, initConfig)
+
+	c.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		// the files are still hashed at every run
+		log.Warnf("Could not watch the files of the file_integrity check, the modifying processes won't be reported: %s", err)
+		return nil
+	}
+	for _, path := range c.instance.Paths {
+		// watching the parent directory catches the files replaced by a rename
+		if err := c.watcher.Add(watchedDir(path)); err != nil {
+			log.Debugf("Could not watch %s: %s", path, err)
+		}
+	}
+	go c.watch(c.watcher)
+	return nil
+}
+
+// watchedDir returns the directory watched for a monitored path
+func watchedDir(path string) string {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// watch records the processes modifying the monitored files
+func (c *FileIntegrityCheck) watch(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !c.isMonitored(event.Name) || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Chmod) == 0 {
+				continue
+			}
+			if process := findModifyingProcess(event.Name); process != nil {
+				c.m.Lock()
+				c.modifiers[event.Name] = process
+				c.m.Unlock()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Debugf("Error watching the file_integrity files: %s", err)
+		}
+	}
+}
+
+// isMonitored returns whether the path is a monitored file, or a file of a
+// monitored directory
+func (c *FileIntegrityCheck) isMonitored(path string) bool {
+	for _, monitored := range c.instance.Paths {
+		if path == monitored || filepath.Dir(path) == monitored {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop stops watching the files.
+func (c *FileIntegrityCheck) Stop() {
+	if c.watcher != nil {
+		c.watcher.Close()
+	}
+}
+
+// Run executes the check.
+func (c *FileIntegrityCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	states := c.collectStates()
+	sender.Gauge("file_integrity.files_monitored", float64(len(states)), "", c.instance.Tags)
+
+	c.m.Lock()
+	modifiers := c.modifiers
+	c.modifiers = make(map[string]*processInfo)
+	c.m.Unlock()
+
+	// the first run records the initial state of the files
+	if c.states != nil {
+		for _, event := range c.diffStates(states, modifiers) {
+			sender.Event(event)
+		}
+	}
+	c.states = states
+	return nil
+}
+
+// collectStates returns the current state of the monitored files
+func (c *FileIntegrityCheck) collectStates() map[string]fileState {
+	states := make(map[string]fileState)
+	for _, path := range c.instance.Paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				c.Warnf("Could not stat %s: %s", path, err)
+			}
+			continue
+		}
+		if !fi.IsDir() {
+			c.collectState(states, path, fi)
+			continue
+		}
+
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			c.Warnf("Could not list %s: %s", path, err)
+			continue
+		}
+		for _, f := range files {
+			if f.Mode().IsRegular() {
+				c.collectState(states, filepath.Join(path, f.Name()), f)
+			}
+		}
+	}
+	return states
+}
+
+func (c *FileIntegrityCheck) collectState(states map[string]fileState, path string, fi os.FileInfo) {
+	state := fileState{size: fi.Size(), mode: fi.Mode()}
+	if fi.Size() <= c.instance.MaxFileSize {
+		hash, err := hashFile(path)
+		if err != nil {
+			log.Debugf("Could not hash %s: %s", path, err)
+		}
+		state.hash = hash
+	}
+	states[path] = state
+}
+
+// hashFile returns the hex encoded SHA-256 hash of a file
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// diffStates returns the events of the files changed since the last run,
+// sorted by path
+func (c *FileIntegrityCheck) diffStates(states map[string]fileState, modifiers map[string]*processInfo) []metrics.Event {
+	paths := make([]string, 0, len(states)+len(c.states))
+	for path := range states {
+		paths = append(paths, path)
+	}
+	for path := range c.states {
+		if _, found := states[path]; !found {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var events []metrics.Event
+	for _, path := range paths {
+		before, existed := c.states[path]
+		after, exists := states[path]
+
+		var change fileChange
+		switch {
+		case !existed:
+			change = fileCreated
+		case !exists:
+			change = fileDeleted
+		case before.hash != after.hash || before.size != after.size:
+			change = fileModified
+		case before.mode != after.mode:
+			change = fileModeChanged
+		default:
+			continue
+		}
+		events = append(events, c.newEvent(path, change, before, after, modifiers[path]))
+	}
+	return events
+}
+
+func (c *FileIntegrityCheck) newEvent(path string, change fileChange, before, after fileState, process *processInfo) metrics.Event {
+	text := fmt.Sprintf("%%%%%% \nFile `%s` was %s.\n\n", path, change)
+	if change != fileCreated {
+		text += fmt.Sprintf("Before: sha256 `%s`, mode `%s`\n\n", orNone(before.hash), before.mode)
+	}
+	if change != fileDeleted {
+		text += fmt.Sprintf("After: sha256 `%s`, mode `%s`\n\n", orNone(after.hash), after.mode)
+	}
+	if process != nil {
+		text += fmt.Sprintf("Modified by %s\n", process)
+	}
+	text += " \n%%%%%%"
+
+	tags := append([]string{"path:" + path, "change:" + string(change)}, c.instance.Tags...)
+	return metrics.Event{
+		Title:          fmt.Sprintf("File %s %s", path, change),
+		Text:           text,
+		Ts:             time.Now().Unix(),
+		Priority:       metrics.EventPriorityNormal,
+		Tags:           tags,
+		AlertType:      metrics.EventAlertTypeWarning,
+		AggregationKey: fileIntegrityCheckName + ":" + path,
+		SourceTypeName: fileIntegrityCheckName,
+		EventType:      fileIntegrityCheckName,
+	}
+}
+
+func orNone(hash string) string {
+	if hash == "" {
+		return "none"
+	}
+	return hash
+}
+
+// FileIntegrityFactory is exported for integration testing.
+func FileIntegrityFactory() check.Check {
+	return &FileIntegrityCheck{
+		CheckBase: core.NewCheckBase(fileIntegrityCheckName),
+		instance:  &FileIntegrityConfig{},
+		modifiers: make(map[string]*processInfo),
+	}
+}
+
+func init() {
+	core.RegisterCheck(fileIntegrityCheckName, FileIntegrityFactory)
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package compliance
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestFileIntegrityCheckConfig(t *testing.T) {
+	fimCheck := FileIntegrityFactory()
+	require.Error(t, fimCheck.Configure([]byte("tags: [foo:bar]"), nil))
+}
+
+func TestFileIntegrityCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_integrity")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	modified := filepath.Join(dir, "modified")
+	deleted := filepath.Join(dir, "deleted")
+	require.NoError(t, ioutil.WriteFile(modified, []byte("before"), 0644))
+	require.NoError(t, ioutil.WriteFile(deleted, []byte("deleted"), 0644))
+
+	fimCheck := FileIntegrityFactory().(*FileIntegrityCheck)
+	require.NoError(t, fimCheck.Configure([]byte(fmt.Sprintf("paths: [%s]", dir)), nil))
+	defer fimCheck.Stop()
+	sender := mocksender.NewMockSender(fimCheck.ID())
+	sender.SetupAcceptAll()
+
+	// the first run records the initial state
+	require.NoError(t, fimCheck.Run())
+	sender.AssertMetric(t, "Gauge", "file_integrity.files_monitored", 2, "", nil)
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+
+	require.NoError(t, ioutil.WriteFile(modified, []byte("after"), 0644))
+	require.NoError(t, os.Remove(deleted))
+	created := filepath.Join(dir, "created")
+	require.NoError(t, ioutil.WriteFile(created, []byte("created"), 0644))
+
+	sender.ResetCalls()
+	require.NoError(t, fimCheck.Run())
+	sender.AssertNumberOfCalls(t, "Event", 3)
+	assertChange(t, sender, created, fileCreated)
+	assertChange(t, sender, deleted, fileDeleted)
+	assertChange(t, sender, modified, fileModified)
+
+	// nothing changed
+	sender.ResetCalls()
+	require.NoError(t, fimCheck.Run())
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+}
+
+func assertChange(t *testing.T, sender *mocksender.MockSender, path string, change fileChange) {
+	sender.AssertCalled(t, "Event", mock.MatchedBy(func(e metrics.Event) bool {
+		return e.Title == fmt.Sprintf("File %s %s", path, change) &&
+			assert.ObjectsAreEqual([]string{"path:" + path, "change:" + string(change)}, e.Tags)
+	}))
+}
+
+func TestDiffStates(t *testing.T) {
+	fimCheck := FileIntegrityFactory().(*FileIntegrityCheck)
+	fimCheck.states = map[string]fileState{
+		"/etc/passwd": {hash: "a", size: 1, mode: 0644},
+		"/etc/shadow": {hash: "b", size: 1, mode: 0600},
+	}
+
+	events := fimCheck.diffStates(map[string]fileState{
+		"/etc/passwd": {hash: "a", size: 1, mode: 0644},
+		"/etc/shadow": {hash: "b", size: 1, mode: 0666},
+	}, nil)
+	require.Len(t, events, 1)
+	assert.Equal(t, "File /etc/shadow permissions_changed", events[0].Title)
+	assert.Contains(t, events[0].Text, "Before: sha256 `b`, mode `-rw-------`")
+	assert.Contains(t, events[0].Text, "After: sha256 `b`, mode `-rw-rw-rw-`")
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+// +build linux
+
+package compliance
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// processInfo describes the process modifying a file
+type processInfo struct {
+	pid     int
+	command string
+	uid     uint32
+}
+
+func (p *processInfo) String() string {
+	return fmt.Sprintf("process %d (%s), uid %d", p.pid, p.command, p.uid)
+}
+
+// findModifyingProcess returns the process having the file open, if it's
+// still open when the change is notified
+func findModifyingProcess(path string) *processInfo {
+	procRoot := config.Datadog.GetString("container_proc_root")
+	if procRoot == "" {
+		procRoot = "/proc"
+	}
+
+	fds, err := filepath.Glob(filepath.Join(procRoot, "[0-9]*", "fd", "*"))
+	if err != nil {
+		return nil
+	}
+	for _, fd := range fds {
+		target, err := os.Readlink(fd)
+		if err != nil || target != path {
+			continue
+		}
+
+		pidDir := filepath.Dir(filepath.Dir(fd))
+		pid, err := strconv.Atoi(filepath.Base(pidDir))
+		if err != nil {
+			continue
+		}
+		process := &processInfo{pid: pid}
+		if comm, err := ioutil.ReadFile(filepath.Join(pidDir, "comm")); err == nil {
+			process.command = strings.TrimSpace(string(comm))
+		}
+		if fi, err := os.Stat(pidDir); err == nil {
+			if stat, ok := fi.Sys().(*syscall.Stat_t); ok {
+				process.uid = stat.Uid
+			}
+		}
+		return process
+	}
+	return nil
+}
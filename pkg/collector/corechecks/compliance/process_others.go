@@ -0,0 +1,19 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+// +build !linux
+
+package compliance
+
+// processInfo describes the process modifying a file
+type processInfo struct{}
+
+func (p *processInfo) String() string {
+	return "unknown process"
+}
+
+// findModifyingProcess is not implemented, the process is only known on linux
+func findModifyingProcess(path string) *processInfo {
+	return nil
+}
@@ -46,6 +46,9 @@ type DockerConfig struct {
 	CollectEvent             bool               `yaml:"collect_events"`
 	FilteredEventType        []string           `yaml:"filtered_event_types"`
 	CappedMetrics            map[string]float64 `yaml:"capped_metrics"`
+	CollectLifecycleEvents   bool               `yaml:"collect_lifecycle_events"`
+	RestartLoopThreshold     int                `yaml:"restart_loop_threshold"`
+	RestartLoopWindow        int                `yaml:"restart_loop_window_seconds"`
 }
 
 type containerPerImage struct {
@@ -58,6 +61,9 @@ func (c *DockerConfig) Parse(data []byte) error {
 	// default values
 	c.CollectEvent = true
 	c.CollectContainerSizeFreq = 5
+	c.CollectLifecycleEvents = true
+	c.RestartLoopThreshold = 3
+	c.RestartLoopWindow = 600
 
 	if err := yaml.Unmarshal(data, c); err != nil {
 		return err
@@ -73,6 +79,7 @@ type DockerCheck struct {
 	dockerHostname              string
 	cappedSender                *cappedSender
 	collectContainerSizeCounter uint64
+	restarts                    *restartTracker
 }
 
 func updateContainerRunningCount(images map[string]*containerPerImage, c *containers.Container) {
@@ -174,7 +181,10 @@ func (d *DockerCheck) Run() error {
 
 	images := map[string]*containerPerImage{}
 	for _, c := range cList {
-		updateContainerRunningCount(images, c)
+		// the infra containers of the pods are not counted
+		if !containers.IsPauseContainer(c.Image) {
+			updateContainerRunningCount(images, c)
+		}
 		if c.State != containers.ContainerRunningState || c.Excluded {
 			continue
 		}
@@ -280,7 +290,7 @@ func (d *DockerCheck) Run() error {
 	}
 	sender.ServiceCheck(DockerServiceUp, metrics.ServiceCheckOK, "", d.instance.Tags, "")
 
-	if d.instance.CollectEvent || d.instance.CollectExitCodes {
+	if d.instance.CollectEvent || d.instance.CollectExitCodes || d.instance.CollectLifecycleEvents {
 		events, err := d.retrieveEvents(du)
 		if err != nil {
 			d.Warnf("Error collecting events: %s", err)
@@ -297,6 +307,9 @@ func (d *DockerCheck) Run() error {
 					log.Warn(err.Error())
 				}
 			}
+			if d.instance.CollectLifecycleEvents {
+				d.reportLifecycleEvents(events, sender, time.Now())
+			}
 		}
 	}
 
@@ -348,6 +361,7 @@ func (d *DockerCheck) Configure(config, initConfig integration.Data) error {
 	if len(d.instance.FilteredEventType) == 0 {
 		d.instance.FilteredEventType = []string{"top", "exec_create", "exec_start", "exec_die"}
 	}
+	d.restarts = newRestartTracker(time.Duration(d.instance.RestartLoopWindow)*time.Second, d.instance.RestartLoopThreshold)
 
 	var err error
 	// Use the same hostname as the agent so that host tags (like `availability-zone:us-east-1b`)
@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package containers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// Labels set by the kubelet on the containers it runs, used to identify a
+// container across its restarts as each one creates a new docker container
+const (
+	kubernetesPodNamespaceLabel = "io.kubernetes.pod.namespace"
+	kubernetesPodNameLabel      = "io.kubernetes.pod.name"
+	kubernetesContainerLabel    = "io.kubernetes.container.name"
+)
+
+const (
+	actionDie       = "die"
+	actionOOM       = "oom"
+	actionUnhealthy = "health_status: unhealthy"
+)
+
+// lifecycleKey identifies a container across its restarts
+func lifecycleKey(ev *docker.ContainerEvent) string {
+	if pod, found := ev.Attributes[kubernetesPodNameLabel]; found {
+		return fmt.Sprintf("%s/%s/%s", ev.Attributes[kubernetesPodNamespaceLabel], pod, ev.Attributes[kubernetesContainerLabel])
+	}
+	return ev.ContainerName
+}
+
+// restartTracker keeps the recent restarts of the containers to detect the
+// restart loops, reporting each loop at most once per window
+type restartTracker struct {
+	window       time.Duration
+	threshold    int
+	restarts     map[string][]time.Time
+	lastEntity   map[string]string
+	lastReported map[string]time.Time
+}
+
+func newRestartTracker(window time.Duration, threshold int) *restartTracker {
+	return &restartTracker{
+		window:       window,
+		threshold:    threshold,
+		restarts:     make(map[string][]time.Time),
+		lastEntity:   make(map[string]string),
+		lastReported: make(map[string]time.Time),
+	}
+}
+
+func (t *restartTracker) addRestart(key, entity string, ts time.Time) {
+	t.restarts[key] = append(t.restarts[key], ts)
+	t.lastEntity[key] = entity
+}
+
+// restartLoops returns the containers restarted at least threshold times in
+// the window that were not reported yet, with their number of restarts
+func (t *restartTracker) restartLoops(now time.Time) map[string]int {
+	loops := make(map[string]int)
+	cutoff := now.Add(-t.window)
+
+	for key, restarts := range t.restarts {
+		recent := restarts[:0]
+		for _, ts := range restarts {
+			if ts.After(cutoff) {
+				recent = append(recent, ts)
+			}
+		}
+		if len(recent) == 0 {
+			delete(t.restarts, key)
+			delete(t.lastEntity, key)
+			continue
+		}
+		t.restarts[key] = recent
+
+		if len(recent) >= t.threshold && t.lastReported[key].Before(cutoff) {
+			loops[key] = len(recent)
+			t.lastReported[key] = now
+		}
+	}
+
+	for key, reported := range t.lastReported {
+		if reported.Before(cutoff) {
+			delete(t.lastReported, key)
+		}
+	}
+	return loops
+}
+
+// lifecycleSummary counts the lifecycle events of a container during a run
+type lifecycleSummary struct {
+	name      string
+	entity    string
+	ooms      int
+	unhealthy int
+	lastTs    time.Time
+}
+
+// reportLifecycleEvents sends the OOM kills, failed health checks and restart
+// loops of the containers as Datadog events. The events of a container are
+// rolled up by run and share an aggregation key across its restarts, so that
+// a crashlooping container produces a single event.
+func (d *DockerCheck) reportLifecycleEvents(events []*docker.ContainerEvent, sender aggregator.Sender, now time.Time) {
+	summaries := make(map[string]*lifecycleSummary)
+
+	for _, ev := range events {
+		if ev.Action != actionDie && ev.Action != actionOOM && ev.Action != actionUnhealthy {
+			continue
+		}
+		key := lifecycleKey(ev)
+		summary, found := summaries[key]
+		if !found {
+			summary = &lifecycleSummary{name: key}
+			summaries[key] = summary
+		}
+		summary.entity = ev.ContainerEntityName()
+		if ev.Timestamp.After(summary.lastTs) {
+			summary.lastTs = ev.Timestamp
+		}
+
+		switch ev.Action {
+		case actionDie:
+			d.restarts.addRestart(key, summary.entity, ev.Timestamp)
+		case actionOOM:
+			summary.ooms++
+		case actionUnhealthy:
+			summary.unhealthy++
+		}
+	}
+
+	// sorted to send the events in a stable order
+	keys := make([]string, 0, len(summaries))
+	for key := range summaries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		summary := summaries[key]
+		if summary.ooms > 0 {
+			d.sendLifecycleEvent(sender, metrics.Event{
+				Title:          fmt.Sprintf("Container %s was OOM killed on %s", summary.name, d.dockerHostname),
+				Text:           fmt.Sprintf("Container %s was killed %d time(s) for running out of memory", summary.name, summary.ooms),
+				AlertType:      metrics.EventAlertTypeError,
+				AggregationKey: fmt.Sprintf("docker:oom:%s", key),
+				Ts:             summary.lastTs.Unix(),
+			}, summary.entity)
+		}
+		if summary.unhealthy > 0 {
+			d.sendLifecycleEvent(sender, metrics.Event{
+				Title:          fmt.Sprintf("Container %s is unhealthy on %s", summary.name, d.dockerHostname),
+				Text:           fmt.Sprintf("The health check of container %s failed %d time(s)", summary.name, summary.unhealthy),
+				AlertType:      metrics.EventAlertTypeWarning,
+				AggregationKey: fmt.Sprintf("docker:unhealthy:%s", key),
+				Ts:             summary.lastTs.Unix(),
+			}, summary.entity)
+		}
+	}
+
+	loops := d.restarts.restartLoops(now)
+	keys = keys[:0]
+	for key := range loops {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		d.sendLifecycleEvent(sender, metrics.Event{
+			Title:          fmt.Sprintf("Container %s is restarting in a loop on %s", key, d.dockerHostname),
+			Text:           fmt.Sprintf("Container %s restarted %d times in the last %s", key, loops[key], d.restarts.window),
+			AlertType:      metrics.EventAlertTypeError,
+			AggregationKey: fmt.Sprintf("docker:restart_loop:%s", key),
+			Ts:             now.Unix(),
+		}, d.restarts.lastEntity[key])
+	}
+}
+
+func (d *DockerCheck) sendLifecycleEvent(sender aggregator.Sender, ev metrics.Event, entity string) {
+	ev.Priority = metrics.EventPriorityNormal
+	ev.Host = d.dockerHostname
+	ev.SourceTypeName = dockerCheckName
+	ev.EventType = dockerCheckName
+
+	tags, err := tagger.Tag(entity, true)
+	if err != nil {
+		log.Debugf("no tags for %s: %s", entity, err)
+	}
+	ev.Tags = append(tags, d.instance.Tags...)
+	sender.Event(ev)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package containers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+func TestLifecycleKey(t *testing.T) {
+	assert.Equal(t, "web", lifecycleKey(&docker.ContainerEvent{ContainerName: "web"}))
+	assert.Equal(t, "default/web-1234/nginx", lifecycleKey(&docker.ContainerEvent{
+		ContainerName: "k8s_nginx_web-1234_default_0",
+		Attributes: map[string]string{
+			kubernetesPodNamespaceLabel: "default",
+			kubernetesPodNameLabel:      "web-1234",
+			kubernetesContainerLabel:    "nginx",
+		},
+	}))
+}
+
+func TestRestartTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newRestartTracker(10*time.Minute, 3)
+
+	tracker.addRestart("web", "docker://1", now.Add(-20*time.Minute))
+	tracker.addRestart("web", "docker://2", now.Add(-5*time.Minute))
+	tracker.addRestart("web", "docker://3", now.Add(-2*time.Minute))
+	assert.Empty(t, tracker.restartLoops(now))
+
+	tracker.addRestart("web", "docker://4", now)
+	assert.Equal(t, map[string]int{"web": 3}, tracker.restartLoops(now))
+
+	// reported once per window
+	tracker.addRestart("web", "docker://5", now)
+	assert.Empty(t, tracker.restartLoops(now))
+
+	// reported again once the window is over
+	later := now.Add(11 * time.Minute)
+	tracker.addRestart("web", "docker://6", later.Add(-time.Minute))
+	tracker.addRestart("web", "docker://7", later.Add(-30*time.Second))
+	tracker.addRestart("web", "docker://8", later)
+	assert.Equal(t, map[string]int{"web": 3}, tracker.restartLoops(later))
+	assert.Equal(t, "docker://8", tracker.lastEntity["web"])
+}
+
+func TestReportLifecycleEvents(t *testing.T) {
+	now := time.Now()
+	dockerCheck := &DockerCheck{
+		instance:       &DockerConfig{},
+		dockerHostname: "host",
+		restarts:       newRestartTracker(10*time.Minute, 2),
+	}
+	mockSender := mocksender.NewMockSender(dockerCheck.ID())
+	mockSender.On("Event", mock.AnythingOfType("metrics.Event")).Return()
+
+	labels := map[string]string{
+		kubernetesPodNamespaceLabel: "default",
+		kubernetesPodNameLabel:      "web-1234",
+		kubernetesContainerLabel:    "nginx",
+	}
+	events := []*docker.ContainerEvent{
+		{ContainerID: "1", Action: "start", Timestamp: now, Attributes: labels},
+		{ContainerID: "1", Action: "oom", Timestamp: now, Attributes: labels},
+		{ContainerID: "1", Action: "die", Timestamp: now, Attributes: labels},
+		{ContainerID: "2", Action: "oom", Timestamp: now, Attributes: labels},
+		{ContainerID: "2", Action: "die", Timestamp: now, Attributes: labels},
+		{ContainerID: "3", ContainerName: "db", Action: "health_status: unhealthy", Timestamp: now},
+	}
+	dockerCheck.reportLifecycleEvents(events, mockSender, now)
+
+	mockSender.AssertNumberOfCalls(t, "Event", 3)
+	var keys []string
+	for _, call := range mockSender.Calls {
+		if call.Method == "Event" {
+			ev := call.Arguments.Get(0).(metrics.Event)
+			keys = append(keys, ev.AggregationKey)
+			assert.Equal(t, "host", ev.Host)
+		}
+	}
+	assert.Equal(t, []string{
+		"docker:unhealthy:db",
+		"docker:oom:default/web-1234/nginx",
+		"docker:restart_loop:default/web-1234/nginx",
+	}, keys)
+}
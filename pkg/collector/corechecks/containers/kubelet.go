@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package containers
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	kubeletCheckName     = "kubelet_core"
+	kubeletMetricsPrefix = "kubernetes."
+
+	// KubeletCheckServiceCheck reports whether the kubelet API is reachable
+	KubeletCheckServiceCheck = "kubernetes.kubelet.check"
+	// KubeletPodReadyServiceCheck reports the readiness of each pod
+	KubeletPodReadyServiceCheck = "kubernetes.pod.ready"
+)
+
+// KubeletConfig is the config of the kubelet_core check.
+type KubeletConfig struct {
+	Tags           []string `yaml:"tags"`
+	CollectMetrics bool     `yaml:"collect_kubelet_metrics"`
+}
+
+// KubeletCheck reports the state of the pods and containers of the node,
+// and the health metrics of the kubelet itself.
+type KubeletCheck struct {
+	core.CheckBase
+	instance *KubeletConfig
+}
+
+func (c *KubeletConfig) parse(data []byte) error {
+	// default values
+	c.CollectMetrics = true
+
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check.
+func (k *KubeletCheck) Configure(config, initConfig integration.Data) error {
+	err := k.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the kubelet_core check")
+		return err
+	}
+
+	log.Debugf("Running config %s", config)
+	return nil
+}
+
+// Run executes the check.
+func (k *KubeletCheck) Run() error {
+	sender, err := aggregator.GetSender(k.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	ku, err := kubelet.GetKubeUtil()
+	if err != nil {
+		sender.ServiceCheck(KubeletCheckServiceCheck, metrics.ServiceCheckCritical, "", k.instance.Tags, err.Error())
+		k.Warnf("Could not connect to the kubelet: %s", err)
+		return err
+	}
+
+	pods, err := ku.GetLocalPodList()
+	if err != nil {
+		sender.ServiceCheck(KubeletCheckServiceCheck, metrics.ServiceCheckCritical, "", k.instance.Tags, err.Error())
+		k.Warnf("Could not list the pods: %s", err)
+		return err
+	}
+	sender.ServiceCheck(KubeletCheckServiceCheck, metrics.ServiceCheckOK, "", k.instance.Tags, "")
+	k.reportPods(sender, pods)
+
+	if !k.instance.CollectMetrics {
+		return nil
+	}
+	raw, err := ku.GetRawMetrics()
+	if err != nil {
+		k.Warnf("Could not collect the kubelet metrics: %s", err)
+		return nil
+	}
+	if err = k.reportKubeletMetrics(sender, raw); err != nil {
+		k.Warnf("Could not parse the kubelet metrics: %s", err)
+	}
+	return nil
+}
+
+// reportPods submits the running pods and containers counts, the container
+// restarts and the readiness of every pod.
+func (k *KubeletCheck) reportPods(sender aggregator.Sender, pods []*kubelet.Pod) {
+	type podCount struct {
+		tags  []string
+		count int
+	}
+	runningPods := make(map[string]*podCount)
+
+	for _, pod := range pods {
+		podTags, err := tagger.Tag(kubelet.PodUIDToEntityName(pod.Metadata.UID), false)
+		if err != nil {
+			log.Debugf("no tags for pod %s: %s", pod.Metadata.Name, err)
+		}
+		podTags = append(podTags, k.instance.Tags...)
+
+		if pod.Status.Phase == "Running" {
+			sort.Strings(podTags)
+			key := strings.Join(podTags, ",")
+			if _, found := runningPods[key]; !found {
+				runningPods[key] = &podCount{tags: podTags}
+			}
+			runningPods[key].count++
+		}
+
+		// Completed pods are not expected to be ready
+		if pod.Status.Phase != "Succeeded" {
+			status := metrics.ServiceCheckOK
+			message := ""
+			if !kubelet.IsPodReady(pod) {
+				status = metrics.ServiceCheckCritical
+				message = fmt.Sprintf("pod %s/%s is not ready, phase: %s", pod.Metadata.Namespace, pod.Metadata.Name, pod.Status.Phase)
+			}
+			tags := append([]string{
+				fmt.Sprintf("pod_name:%s", pod.Metadata.Name),
+				fmt.Sprintf("kube_namespace:%s", pod.Metadata.Namespace),
+			}, k.instance.Tags...)
+			sender.ServiceCheck(KubeletPodReadyServiceCheck, status, "", tags, message)
+		}
+
+		for _, container := range pod.Status.Containers {
+			if container.ID == "" {
+				// not created yet
+				continue
+			}
+			tags, err := tagger.Tag(container.ID, false)
+			if err != nil {
+				log.Debugf("no tags for container %s: %s", container.Name, err)
+			}
+			tags = append(tags, k.instance.Tags...)
+			sender.Gauge(kubeletMetricsPrefix+"containers.restarts", float64(container.RestartCount), "", tags)
+			if container.State.Running != nil {
+				sender.Gauge(kubeletMetricsPrefix+"containers.running", 1, "", tags)
+			}
+		}
+	}
+
+	for _, running := range runningPods {
+		sender.Gauge(kubeletMetricsPrefix+"pods.running", float64(running.count), "", running.tags)
+	}
+}
+
+// reportKubeletMetrics parses the prometheus text output of the kubelet and
+// submits the PLEG latency and the container runtime operations and errors.
+func (k *KubeletCheck) reportKubeletMetrics(sender aggregator.Sender, raw []byte) error {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	for familyName, family := range families {
+		for _, metric := range family.GetMetric() {
+			tags := append(kubeletLabelsToTags(metric.GetLabel()), k.instance.Tags...)
+
+			switch familyName {
+			case "kubelet_pleg_relist_duration_seconds":
+				sender.MonotonicCount(kubeletMetricsPrefix+"kubelet.pleg.relist_duration.sum", metric.GetHistogram().GetSampleSum(), "", tags)
+				sender.MonotonicCount(kubeletMetricsPrefix+"kubelet.pleg.relist_duration.count", float64(metric.GetHistogram().GetSampleCount()), "", tags)
+			case "kubelet_pleg_relist_latency_microseconds":
+				// Legacy summary, reported in microseconds
+				sender.MonotonicCount(kubeletMetricsPrefix+"kubelet.pleg.relist_duration.sum", metric.GetSummary().GetSampleSum()/1e6, "", tags)
+				sender.MonotonicCount(kubeletMetricsPrefix+"kubelet.pleg.relist_duration.count", float64(metric.GetSummary().GetSampleCount()), "", tags)
+			// the _total suffix was added in Kubernetes 1.14
+			case "kubelet_runtime_operations_total", "kubelet_runtime_operations":
+				sender.MonotonicCount(kubeletMetricsPrefix+"kubelet.runtime.operations", metric.GetCounter().GetValue(), "", tags)
+			case "kubelet_runtime_operations_errors_total", "kubelet_runtime_operations_errors":
+				sender.MonotonicCount(kubeletMetricsPrefix+"kubelet.runtime.errors", metric.GetCounter().GetValue(), "", tags)
+			}
+		}
+	}
+	return nil
+}
+
+// kubeletLabelsToTags converts the labels of a prometheus metric to datadog
+// tags, skipping the ones with an empty value.
+func kubeletLabelsToTags(labels []*dto.LabelPair) []string {
+	tags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label.GetValue() == "" {
+			continue
+		}
+		tags = append(tags, fmt.Sprintf("%s:%s", label.GetName(), label.GetValue()))
+	}
+	return tags
+}
+
+// KubeletFactory is exported for integration testing.
+func KubeletFactory() check.Check {
+	return &KubeletCheck{
+		CheckBase: core.NewCheckBase(kubeletCheckName),
+		instance:  &KubeletConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(kubeletCheckName, KubeletFactory)
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package containers
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+func TestReportPods(t *testing.T) {
+	check := KubeletFactory().(*KubeletCheck)
+	require.NoError(t, check.Configure([]byte("tags: [customtag]"), []byte("")))
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+
+	pods := []*kubelet.Pod{
+		{
+			Metadata: kubelet.PodMetadata{Name: "ready", Namespace: "default", UID: "1"},
+			Status: kubelet.Status{
+				Phase:      "Running",
+				Conditions: []kubelet.Conditions{{Type: "Ready", Status: "True"}},
+				Containers: []kubelet.ContainerStatus{{
+					Name:         "nginx",
+					ID:           "docker://1234",
+					RestartCount: 3,
+					State:        kubelet.ContainerState{Running: &kubelet.ContainerStateRunning{}},
+				}},
+			},
+		},
+		{
+			Metadata: kubelet.PodMetadata{Name: "not-ready", Namespace: "default", UID: "2"},
+			Status: kubelet.Status{
+				Phase:      "Running",
+				Conditions: []kubelet.Conditions{{Type: "Ready", Status: "False"}},
+			},
+		},
+		{
+			Metadata: kubelet.PodMetadata{Name: "completed", Namespace: "default", UID: "3"},
+			Status:   kubelet.Status{Phase: "Succeeded"},
+		},
+	}
+	check.reportPods(mocked, pods)
+
+	mocked.AssertMetric(t, "Gauge", "kubernetes.pods.running", 2, "", []string{"customtag"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes.containers.restarts", 3, "", []string{"customtag"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes.containers.running", 1, "", []string{"customtag"})
+	mocked.AssertServiceCheck(t, "kubernetes.pod.ready", metrics.ServiceCheckOK, "", []string{"customtag", "pod_name:ready"}, "")
+	mocked.AssertServiceCheck(t, "kubernetes.pod.ready", metrics.ServiceCheckCritical, "", []string{"customtag", "pod_name:not-ready"}, "pod default/not-ready is not ready, phase: Running")
+	mocked.AssertNotCalled(t, "ServiceCheck", "kubernetes.pod.ready", mock.Anything, "", mocksender.MatchTagsContains([]string{"pod_name:completed"}), mock.Anything)
+}
+
+func TestReportKubeletMetrics(t *testing.T) {
+	raw, err := ioutil.ReadFile("./testdata/kubelet_metrics.txt")
+	require.NoError(t, err)
+
+	check := KubeletFactory().(*KubeletCheck)
+	require.NoError(t, check.Configure([]byte("tags: [customtag]"), []byte("")))
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+	require.NoError(t, check.reportKubeletMetrics(mocked, raw))
+
+	mocked.AssertMetric(t, "MonotonicCount", "kubernetes.kubelet.pleg.relist_duration.sum", 0.4, "", []string{"customtag"})
+	mocked.AssertMetric(t, "MonotonicCount", "kubernetes.kubelet.pleg.relist_duration.count", 50, "", []string{"customtag"})
+	mocked.AssertMetric(t, "MonotonicCount", "kubernetes.kubelet.runtime.operations", 300, "", []string{"customtag", "operation_type:list_containers"})
+	mocked.AssertMetric(t, "MonotonicCount", "kubernetes.kubelet.runtime.errors", 4, "", []string{"customtag", "operation_type:container_status"})
+}
@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// DefaultQueryTimeout is applied to the custom queries not setting a timeout
+const DefaultQueryTimeout = 10 * time.Second
+
+// Column types of a custom query, the other ones are metric types
+const (
+	columnTypeTag  = "tag"
+	columnTypeSkip = ""
+)
+
+var validColumnTypes = map[string]bool{
+	columnTypeTag:     true,
+	columnTypeSkip:    true,
+	"gauge":           true,
+	"rate":            true,
+	"count":           true,
+	"monotonic_count": true,
+	"histogram":       true,
+}
+
+// CustomQuery is a user defined query, every returned row is submitted as
+// metrics tagged with the tag columns of the row. It follows the format of
+// the `custom_queries` option of the Python integrations:
+//
+//	custom_queries:
+//	  - metric_prefix: app
+//	    query: SELECT status, count(*) FROM orders GROUP BY status
+//	    columns:
+//	      - name: order_status
+//	        type: tag
+//	      - name: orders.count
+//	        type: gauge
+//	    tags:
+//	      - team:billing
+type CustomQuery struct {
+	MetricPrefix string              `yaml:"metric_prefix"`
+	Query        string              `yaml:"query"`
+	Columns      []CustomQueryColumn `yaml:"columns"`
+	Tags         []string            `yaml:"tags"`
+	Timeout      int                 `yaml:"timeout"` // in seconds
+}
+
+// CustomQueryColumn maps a column of the result of a custom query to a
+// metric or a tag. Columns without name and type are ignored.
+type CustomQueryColumn struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+func (q *CustomQuery) validate() error {
+	if q.Query == "" {
+		return errors.New("field `query` is required")
+	}
+	if len(q.Columns) == 0 {
+		return fmt.Errorf("field `columns` is required for query %q", q.Query)
+	}
+	for _, column := range q.Columns {
+		if !validColumnTypes[column.Type] {
+			return fmt.Errorf("unknown type %q for column %q of query %q", column.Type, column.Name, q.Query)
+		}
+		if column.Name == "" && column.Type != columnTypeSkip {
+			return fmt.Errorf("a column of type %q has no name in query %q", column.Type, q.Query)
+		}
+	}
+	return nil
+}
+
+func (q *CustomQuery) metricName(column CustomQueryColumn) string {
+	if q.MetricPrefix == "" {
+		return column.Name
+	}
+	return fmt.Sprintf("%s.%s", strings.TrimSuffix(q.MetricPrefix, "."), column.Name)
+}
+
+// QueryExecutor runs the custom queries of a database check instance. The
+// connection is opened on the first run and reset when it breaks, so that
+// checks embedding it only have to call Run from theirs.
+type QueryExecutor struct {
+	driverName string
+	dataSource string
+	queries    []CustomQuery
+	db         *sql.DB
+}
+
+// NewQueryExecutor validates the custom queries and returns an executor
+// running them against the database described by the driver and the
+// data source name, as accepted by `sql.Open`.
+func NewQueryExecutor(driverName, dataSource string, queries []CustomQuery) (*QueryExecutor, error) {
+	for i := range queries {
+		if err := queries[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &QueryExecutor{
+		driverName: driverName,
+		dataSource: dataSource,
+		queries:    queries,
+	}, nil
+}
+
+// Run executes every custom query and submits their results with the given
+// tags. A failing query doesn't prevent the other ones from running, all the
+// errors are returned together.
+func (e *QueryExecutor) Run(sender aggregator.Sender, tags []string) error {
+	if err := e.connect(); err != nil {
+		return err
+	}
+
+	var errs []string
+	for i := range e.queries {
+		if err := e.runQuery(sender, &e.queries[i], tags); err != nil {
+			log.Debugf("custom query %q failed: %s", e.queries[i].Query, err)
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		// The connection may be broken, it is opened again on the next run
+		if pingErr := e.db.Ping(); pingErr != nil {
+			e.Close()
+		}
+		return fmt.Errorf("%d custom queries failed: %s", len(errs), strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Close releases the connections to the database
+func (e *QueryExecutor) Close() error {
+	if e.db == nil {
+		return nil
+	}
+	err := e.db.Close()
+	e.db = nil
+	return err
+}
+
+func (e *QueryExecutor) connect() error {
+	if e.db != nil {
+		return nil
+	}
+	db, err := sql.Open(e.driverName, e.dataSource)
+	if err != nil {
+		return fmt.Errorf("could not open the %s connection: %s", e.driverName, err)
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return fmt.Errorf("could not connect to the database: %s", err)
+	}
+	e.db = db
+	return nil
+}
+
+func (e *QueryExecutor) runQuery(sender aggregator.Sender, query *CustomQuery, tags []string) error {
+	timeout := DefaultQueryTimeout
+	if query.Timeout > 0 {
+		timeout = time.Duration(query.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := e.db.QueryContext(ctx, query.Query)
+	if err != nil {
+		return fmt.Errorf("query %q: %s", query.Query, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("query %q: %s", query.Query, err)
+	}
+	if len(columns) != len(query.Columns) {
+		return fmt.Errorf("query %q returned %d columns, %d are configured", query.Query, len(columns), len(query.Columns))
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return fmt.Errorf("query %q: %s", query.Query, err)
+		}
+		submitRow(sender, query, values, tags)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("query %q: %s", query.Query, err)
+	}
+	return nil
+}
+
+// submitRow submits the metric columns of a row, tagged with its tag columns
+func submitRow(sender aggregator.Sender, query *CustomQuery, values []sql.NullString, tags []string) {
+	rowTags := make([]string, 0, len(tags)+len(query.Tags)+len(values))
+	rowTags = append(rowTags, tags...)
+	rowTags = append(rowTags, query.Tags...)
+	for i, column := range query.Columns {
+		if column.Type == columnTypeTag && values[i].Valid {
+			rowTags = append(rowTags, fmt.Sprintf("%s:%s", column.Name, values[i].String))
+		}
+	}
+
+	for i, column := range query.Columns {
+		if column.Type == columnTypeTag || column.Type == columnTypeSkip || !values[i].Valid {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(values[i].String), 64)
+		if err != nil {
+			log.Debugf("custom query %q: non numeric value %q for column %q", query.Query, values[i].String, column.Name)
+			continue
+		}
+
+		name := query.metricName(column)
+		switch column.Type {
+		case "gauge":
+			sender.Gauge(name, value, "", rowTags)
+		case "rate":
+			sender.Rate(name, value, "", rowTags)
+		case "count":
+			sender.Count(name, value, "", rowTags)
+		case "monotonic_count":
+			sender.MonotonicCount(name, value, "", rowTags)
+		case "histogram":
+			sender.Histogram(name, value, "", rowTags)
+		}
+	}
+}
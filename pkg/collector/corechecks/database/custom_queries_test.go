@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+// fakeResult is the result of a query in the fake driver
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDriver answers the queries registered in its results, keyed by query
+type fakeDriver struct {
+	results map[string]fakeResult
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ driver *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	result, found := c.driver.results[query]
+	if !found {
+		return nil, fmt.Errorf("unknown query %q", query)
+	}
+	return &fakeStmt{result}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("not supported") }
+
+type fakeStmt struct{ result fakeResult }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return 0 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{result: s.result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	index  int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.index])
+	r.index++
+	return nil
+}
+
+func init() {
+	sql.Register("fake", &fakeDriver{results: map[string]fakeResult{
+		"SELECT status, count(*), ignored FROM orders GROUP BY status": {
+			columns: []string{"status", "count", "ignored"},
+			rows: [][]driver.Value{
+				{"paid", int64(12), "foo"},
+				{"pending", "3.5", "bar"},
+				{nil, nil, nil},
+			},
+		},
+		"SELECT 1": {
+			columns: []string{"one"},
+			rows:    [][]driver.Value{{int64(1)}},
+		},
+	}})
+}
+
+func TestCustomQueryValidation(t *testing.T) {
+	for _, query := range []CustomQuery{
+		{Columns: []CustomQueryColumn{{Name: "foo", Type: "gauge"}}},
+		{Query: "SELECT 1"},
+		{Query: "SELECT 1", Columns: []CustomQueryColumn{{Name: "foo", Type: "distribution"}}},
+		{Query: "SELECT 1", Columns: []CustomQueryColumn{{Type: "gauge"}}},
+	} {
+		_, err := NewQueryExecutor("fake", "", []CustomQuery{query})
+		assert.Error(t, err, "query %+v should be invalid", query)
+	}
+}
+
+func TestQueryExecutorRun(t *testing.T) {
+	executor, err := NewQueryExecutor("fake", "", []CustomQuery{
+		{
+			MetricPrefix: "app",
+			Query:        "SELECT status, count(*), ignored FROM orders GROUP BY status",
+			Columns: []CustomQueryColumn{
+				{Name: "order_status", Type: "tag"},
+				{Name: "orders.count", Type: "gauge"},
+				{},
+			},
+			Tags: []string{"team:billing"},
+		},
+		{
+			Query:   "SELECT 1",
+			Columns: []CustomQueryColumn{{Name: "app.up", Type: "monotonic_count"}},
+		},
+	})
+	require.NoError(t, err)
+	defer executor.Close()
+
+	sender := mocksender.NewMockSender("")
+	sender.SetupAcceptAll()
+
+	require.NoError(t, executor.Run(sender, []string{"db:orders"}))
+	sender.AssertMetric(t, "Gauge", "app.orders.count", 12, "", []string{"db:orders", "team:billing", "order_status:paid"})
+	sender.AssertMetric(t, "Gauge", "app.orders.count", 3.5, "", []string{"db:orders", "team:billing", "order_status:pending"})
+	sender.AssertMetric(t, "MonotonicCount", "app.up", 1, "", []string{"db:orders"})
+	sender.AssertNumberOfCalls(t, "Gauge", 2)
+}
+
+func TestQueryExecutorErrors(t *testing.T) {
+	executor, err := NewQueryExecutor("fake", "", []CustomQuery{
+		{Query: "SELECT nothing", Columns: []CustomQueryColumn{{Name: "foo", Type: "gauge"}}},
+		{Query: "SELECT 1", Columns: []CustomQueryColumn{{Name: "foo", Type: "gauge"}, {Name: "bar", Type: "gauge"}}},
+		{Query: "SELECT 1", Columns: []CustomQueryColumn{{Name: "one", Type: "gauge"}}},
+	})
+	require.NoError(t, err)
+	defer executor.Close()
+
+	sender := mocksender.NewMockSender("")
+	sender.SetupAcceptAll()
+
+	err = executor.Run(sender, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 custom queries failed")
+	// the valid query still ran
+	sender.AssertMetric(t, "Gauge", "one", 1, "", []string{})
+}
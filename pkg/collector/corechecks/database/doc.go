@@ -0,0 +1,11 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+/*
+Package database provides the building blocks shared by the database core
+checks, like the executor of the user defined `custom_queries`
+
+*/
+package database
@@ -71,6 +71,7 @@ func (jl *JMXCheckLoader) Load(config integration.Config) ([]check.Check, error)
 
 	for _, cf := range splitConfig(config) {
 		c := newJMXCheck(cf)
+		check.SetConfigHash(c.ID(), cf.Instances[0], cf.InitConfig)
 		checks = append(checks, c)
 	}
 
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
@@ -76,6 +77,12 @@ func (gl *GoCheckLoader) Load(config integration.Config) ([]check.Check, error)
 			log.Errorf("core.loader: could not configure check %s: %s", newCheck, err)
 			continue
 		}
+		if err := aggregator.SetCheckMetricPatterns(newCheck.ID(), instance); err != nil {
+			errors = append(errors, fmt.Sprintf("Could not configure check %s: %s", newCheck, err))
+			log.Errorf("core.loader: could not configure check %s: %s", newCheck, err)
+			continue
+		}
+		check.SetConfigHash(newCheck.ID(), instance, config.InitConfig)
 		checks = append(checks, newCheck)
 	}
 
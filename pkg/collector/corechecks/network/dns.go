@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const dnsCheckName = "dns_core"
+
+// DNSConfig is the config of the dns_core check.
+type DNSConfig struct {
+	Name           string   `yaml:"name"`
+	Hostname       string   `yaml:"hostname"`
+	Nameserver     string   `yaml:"nameserver"`
+	NameserverPort int      `yaml:"nameserver_port"`
+	RecordType     string   `yaml:"record_type"`
+	ResolvesAs     []string `yaml:"resolves_as"`
+	Timeout        float64  `yaml:"timeout"` // in seconds
+	Tags           []string `yaml:"tags"`
+}
+
+// DNSCheck reports whether a name resolves, against the system resolver or
+// a given nameserver, and how long the resolution takes.
+type DNSCheck struct {
+	core.CheckBase
+	instance *DNSConfig
+	resolver *net.Resolver
+}
+
+func (c *DNSConfig) parse(data []byte) error {
+	// default values
+	c.NameserverPort = 53
+	c.RecordType = "A"
+	c.Timeout = 5
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if c.Hostname == "" {
+		return errors.New("the hostname option is required")
+	}
+	c.RecordType = strings.ToUpper(c.RecordType)
+	switch c.RecordType {
+	case "A", "AAAA", "CNAME", "MX", "TXT":
+	default:
+		return fmt.Errorf("unsupported record_type %s, must be A, AAAA, CNAME, MX or TXT", c.RecordType)
+	}
+	return nil
+}
+
+// Configure parses the check configuration and init the check.
+func (c *DNSCheck) Configure(config, initConfig integration.Data) error {
+	err := c.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the dns_core check")
+		return err
+	}
+
+	c.resolver = net.DefaultResolver
+	if c.instance.Nameserver != "" {
+		nameserver := net.JoinHostPort(c.instance.Nameserver, strconv.Itoa(c.instance.NameserverPort))
+		c.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, nameserver)
+			},
+		}
+	}
+
+	c.BuildID(config, initConfig)
+	return nil
+}
+
+// resolve returns the records of the hostname
+func (c *DNSCheck) resolve(ctx context.Context) ([]string, error) {
+	hostname := c.instance.Hostname
+	switch c.instance.RecordType {
+	case "CNAME":
+		cname, err := c.resolver.LookupCNAME(ctx, hostname)
+		return []string{strings.TrimSuffix(cname, ".")}, err
+	case "MX":
+		mxs, err := c.resolver.LookupMX(ctx, hostname)
+		var records []string
+		for _, mx := range mxs {
+			records = append(records, strings.TrimSuffix(mx.Host, "."))
+		}
+		return records, err
+	case "TXT":
+		return c.resolver.LookupTXT(ctx, hostname)
+	default:
+		addrs, err := c.resolver.LookupIPAddr(ctx, hostname)
+		var records []string
+		for _, addr := range addrs {
+			// A records are IPv4 addresses, AAAA records IPv6 ones
+			if (addr.IP.To4() != nil) == (c.instance.RecordType == "A") {
+				records = append(records, addr.IP.String())
+			}
+		}
+		if err == nil && len(records) == 0 {
+			err = fmt.Errorf("no %s record for %s", c.instance.RecordType, hostname)
+		}
+		return records, err
+	}
+}
+
+// checkResolvesAs returns an error if the records don't match the expected ones
+func checkResolvesAs(records, expected []string) error {
+	if len(expected) == 0 {
+		return nil
+	}
+	got := append([]string{}, records...)
+	want := append([]string{}, expected...)
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		return fmt.Errorf("resolved as %s, expected %s", strings.Join(got, ","), strings.Join(want, ","))
+	}
+	return nil
+}
+
+// Run executes the check.
+func (c *DNSCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	nameserver := c.instance.Nameserver
+	if nameserver == "" {
+		nameserver = "system"
+	}
+	tags := append([]string{
+		fmt.Sprintf("resolved_hostname:%s", c.instance.Hostname),
+		fmt.Sprintf("nameserver:%s", nameserver),
+		fmt.Sprintf("record_type:%s", c.instance.RecordType),
+	}, c.instance.Tags...)
+	if c.instance.Name != "" {
+		tags = append(tags, fmt.Sprintf("instance:%s", c.instance.Name))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.instance.Timeout*float64(time.Second)))
+	defer cancel()
+
+	start := time.Now()
+	records, err := c.resolve(ctx)
+	elapsed := time.Since(start)
+	if err == nil {
+		err = checkResolvesAs(records, c.instance.ResolvesAs)
+	}
+	if err != nil {
+		sender.ServiceCheck("dns.can_resolve", metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+
+	sender.ServiceCheck("dns.can_resolve", metrics.ServiceCheckOK, "", tags, "")
+	sender.Gauge("dns.response_time", elapsed.Seconds(), "", tags)
+	return nil
+}
+
+// DNSFactory is exported for integration testing.
+func DNSFactory() check.Check {
+	return &DNSCheck{
+		CheckBase: core.NewCheckBase(dnsCheckName),
+		instance:  &DNSConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(dnsCheckName, DNSFactory)
+}
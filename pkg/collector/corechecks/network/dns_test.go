@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestDNSCheck(t *testing.T) {
+	dnsCheck := DNSFactory().(*DNSCheck)
+	require.NoError(t, dnsCheck.Configure([]byte("hostname: localhost\nresolves_as: [127.0.0.1]\nname: local"), nil))
+	sender := mocksender.NewMockSender(dnsCheck.ID())
+	sender.SetupAcceptAll()
+
+	require.NoError(t, dnsCheck.Run())
+	tags := []string{"resolved_hostname:localhost", "nameserver:system", "record_type:A", "instance:local"}
+	sender.AssertServiceCheck(t, "dns.can_resolve", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertCalled(t, "Gauge", "dns.response_time", mock.AnythingOfType("float64"), "", mocksender.MatchTagsContains(tags))
+}
+
+func TestDNSCheckUnexpectedRecords(t *testing.T) {
+	dnsCheck := DNSFactory().(*DNSCheck)
+	require.NoError(t, dnsCheck.Configure([]byte("hostname: localhost\nresolves_as: [10.0.0.1]"), nil))
+	sender := mocksender.NewMockSender(dnsCheck.ID())
+	sender.SetupAcceptAll()
+
+	require.NoError(t, dnsCheck.Run())
+	sender.AssertServiceCheck(t, "dns.can_resolve", metrics.ServiceCheckCritical, "", []string{"resolved_hostname:localhost", "nameserver:system", "record_type:A"}, "resolved as 127.0.0.1, expected 10.0.0.1")
+	sender.AssertNotCalled(t, "Gauge", "dns.response_time", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDNSCheckConfig(t *testing.T) {
+	dnsCheck := DNSFactory()
+	require.Error(t, dnsCheck.Configure([]byte("nameserver: 8.8.8.8"), nil))
+	require.Error(t, dnsCheck.Configure([]byte("hostname: example.org\nrecord_type: SRV"), nil))
+}
+
+func TestCheckResolvesAs(t *testing.T) {
+	assert.NoError(t, checkResolvesAs([]string{"10.0.0.2", "10.0.0.1"}, nil))
+	assert.NoError(t, checkResolvesAs([]string{"10.0.0.2", "10.0.0.1"}, []string{"10.0.0.1", "10.0.0.2"}))
+	assert.Error(t, checkResolvesAs([]string{"10.0.0.1"}, []string{"10.0.0.1", "10.0.0.2"}))
+}
@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	httpCheckName = "http_core"
+
+	// maxContentSize is the size of the body read for the content match
+	maxContentSize = 1 << 20
+)
+
+// HTTPConfig is the config of the http_core check, the options are named
+// after the ones of the Python http_check.
+type HTTPConfig struct {
+	Name                       string            `yaml:"name"`
+	URL                        string            `yaml:"url"`
+	Method                     string            `yaml:"method"`
+	Headers                    map[string]string `yaml:"headers"`
+	Data                       string            `yaml:"data"`
+	Timeout                    float64           `yaml:"timeout"` // in seconds
+	HTTPResponseStatusCode     string            `yaml:"http_response_status_code"`
+	ContentMatch               string            `yaml:"content_match"`
+	ReverseContentMatch        bool              `yaml:"reverse_content_match"`
+	DisableSSLValidation       bool              `yaml:"disable_ssl_validation"`
+	CheckCertificateExpiration bool              `yaml:"check_certificate_expiration"`
+	DaysWarning                int               `yaml:"days_warning"`
+	DaysCritical               int               `yaml:"days_critical"`
+	CollectResponseTime        bool              `yaml:"collect_response_time"`
+	Tags                       []string          `yaml:"tags"`
+}
+
+// HTTPCheck monitors an HTTP endpoint: it reports whether it answers with
+// the expected status and content, its response time and, over HTTPS, the
+// expiration of its certificate.
+type HTTPCheck struct {
+	core.CheckBase
+	instance     *HTTPConfig
+	statusRegex  *regexp.Regexp
+	contentRegex *regexp.Regexp
+	client       *http.Client
+}
+
+func (c *HTTPConfig) parse(data []byte) error {
+	// default values
+	c.Method = "GET"
+	c.Timeout = 10
+	c.HTTPResponseStatusCode = `(1|2|3)\d\d`
+	c.CheckCertificateExpiration = true
+	c.DaysWarning = defaultDaysWarning
+	c.DaysCritical = defaultDaysCritical
+	c.CollectResponseTime = true
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if c.URL == "" {
+		return errors.New("the url option is required")
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		return fmt.Errorf("invalid url %q: %s", c.URL, err)
+	}
+	return nil
+}
+
+// Configure parses the check configuration and init the check.
+func (c *HTTPCheck) Configure(config, initConfig integration.Data) error {
+	err := c.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the http_core check")
+		return err
+	}
+
+	// The status code is matched as a whole
+	c.statusRegex, err = regexp.Compile(fmt.Sprintf("^(%s)$", c.instance.HTTPResponseStatusCode))
+	if err != nil {
+		return fmt.Errorf("invalid http_response_status_code: %s", err)
+	}
+	if c.instance.ContentMatch != "" {
+		c.contentRegex, err = regexp.Compile(c.instance.ContentMatch)
+		if err != nil {
+			return fmt.Errorf("invalid content_match: %s", err)
+		}
+	}
+
+	c.client = &http.Client{
+		Timeout: time.Duration(c.instance.Timeout * float64(time.Second)),
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.instance.DisableSSLValidation},
+		},
+	}
+
+	c.BuildID(config, initConfig)
+	return nil
+}
+
+// Run executes the check.
+func (c *HTTPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	tags := append([]string{fmt.Sprintf("url:%s", c.instance.URL)}, c.instance.Tags...)
+	if c.instance.Name != "" {
+		tags = append(tags, fmt.Sprintf("instance:%s", c.instance.Name))
+	}
+
+	var body io.Reader
+	if c.instance.Data != "" {
+		body = strings.NewReader(c.instance.Data)
+	}
+	req, err := http.NewRequest(c.instance.Method, c.instance.URL, body)
+	if err != nil {
+		sender.ServiceCheck("http.can_connect", metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+	for name, value := range c.instance.Headers {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		sender.ServiceCheck("http.can_connect", metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxContentSize))
+	elapsed := time.Since(start)
+	if err != nil {
+		sender.ServiceCheck("http.can_connect", metrics.ServiceCheckCritical, "", tags, fmt.Sprintf("could not read the response: %s", err))
+		return nil
+	}
+
+	if c.instance.CollectResponseTime {
+		sender.Gauge("network.http.response_time", elapsed.Seconds(), "", tags)
+	}
+	status, message := c.responseStatus(resp.StatusCode, content)
+	sender.ServiceCheck("http.can_connect", status, "", tags, message)
+
+	if c.instance.CheckCertificateExpiration && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		days, status, message := certExpiration(resp.TLS.PeerCertificates[0], time.Now(), c.instance.DaysWarning, c.instance.DaysCritical)
+		sender.Gauge("http.ssl.days_left", days, "", tags)
+		sender.ServiceCheck("http.ssl_cert", status, "", tags, message)
+	}
+	return nil
+}
+
+// responseStatus checks the status code and the content of a response
+func (c *HTTPCheck) responseStatus(code int, content []byte) (metrics.ServiceCheckStatus, string) {
+	if !c.statusRegex.MatchString(fmt.Sprintf("%d", code)) {
+		return metrics.ServiceCheckCritical, fmt.Sprintf("Incorrect HTTP return code for url %s. Expected %s, got %d.", c.instance.URL, c.instance.HTTPResponseStatusCode, code)
+	}
+	if c.contentRegex == nil {
+		return metrics.ServiceCheckOK, ""
+	}
+
+	matched := c.contentRegex.Match(content)
+	switch {
+	case matched && c.instance.ReverseContentMatch:
+		return metrics.ServiceCheckCritical, fmt.Sprintf("Content %q found in the response", c.instance.ContentMatch)
+	case !matched && !c.instance.ReverseContentMatch:
+		return metrics.ServiceCheckCritical, fmt.Sprintf("Content %q not found in the response", c.instance.ContentMatch)
+	default:
+		return metrics.ServiceCheckOK, ""
+	}
+}
+
+// HTTPFactory is exported for integration testing.
+func HTTPFactory() check.Check {
+	return &HTTPCheck{
+		CheckBase: core.NewCheckBase(httpCheckName),
+		instance:  &HTTPConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(httpCheckName, HTTPFactory)
+}
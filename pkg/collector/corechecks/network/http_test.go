@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "status: healthy")
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	return httptest.NewServer(mux)
+}
+
+func runHTTPCheck(t *testing.T, config string) *mocksender.MockSender {
+	httpCheck := HTTPFactory().(*HTTPCheck)
+	require.NoError(t, httpCheck.Configure([]byte(config), nil))
+
+	sender := mocksender.NewMockSender(httpCheck.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, httpCheck.Run())
+	return sender
+}
+
+func TestHTTPCheckOK(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	sender := runHTTPCheck(t, fmt.Sprintf("url: %s/ok\ncontent_match: healthy\ntags: [foo:bar]", server.URL))
+	tags := []string{fmt.Sprintf("url:%s/ok", server.URL), "foo:bar"}
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertCalled(t, "Gauge", "network.http.response_time", mock.AnythingOfType("float64"), "", mocksender.MatchTagsContains(tags))
+	sender.AssertNotCalled(t, "ServiceCheck", "http.ssl_cert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHTTPCheckCritical(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	for _, tc := range []struct {
+		config  string
+		message string
+	}{
+		{
+			config:  fmt.Sprintf("url: %s/error", server.URL),
+			message: fmt.Sprintf("Incorrect HTTP return code for url %s/error. Expected (1|2|3)\\d\\d, got 503.", server.URL),
+		},
+		{
+			config:  fmt.Sprintf("url: %s/ok\ncontent_match: unhealthy", server.URL),
+			message: `Content "unhealthy" not found in the response`,
+		},
+		{
+			config:  fmt.Sprintf("url: %s/ok\ncontent_match: healthy\nreverse_content_match: true", server.URL),
+			message: `Content "healthy" found in the response`,
+		},
+	} {
+		t.Run(tc.config, func(t *testing.T) {
+			sender := runHTTPCheck(t, tc.config)
+			sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckCritical, "", nil, tc.message)
+		})
+	}
+
+	// The expected status codes can be overridden
+	sender := runHTTPCheck(t, fmt.Sprintf("url: %s/error\nhttp_response_status_code: 503", server.URL))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", nil, "")
+}
+
+func TestHTTPCheckCertificateExpiration(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The test certificate is valid until 2084
+	sender := runHTTPCheck(t, fmt.Sprintf("url: %s\ndisable_ssl_validation: true", server.URL))
+	sender.AssertServiceCheck(t, "http.can_connect", metrics.ServiceCheckOK, "", nil, "")
+	sender.AssertServiceCheck(t, "http.ssl_cert", metrics.ServiceCheckOK, "", nil, "")
+	sender.AssertMetricInRange(t, "Gauge", "http.ssl.days_left", 365, 365*100, "", nil)
+
+	// The certificate is self-signed
+	sender = runHTTPCheck(t, fmt.Sprintf("url: %s", server.URL))
+	sender.AssertCalled(t, "ServiceCheck", "http.can_connect", metrics.ServiceCheckCritical, "", mock.Anything, mock.Anything)
+}
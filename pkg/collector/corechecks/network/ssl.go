@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	sslCheckName = "ssl_core"
+
+	defaultDaysWarning  = 14
+	defaultDaysCritical = 7
+)
+
+// SSLConfig is the config of the ssl_core check.
+type SSLConfig struct {
+	Name         string   `yaml:"name"`
+	Host         string   `yaml:"host"`
+	Port         int      `yaml:"port"`
+	ServerName   string   `yaml:"server_name"`
+	Timeout      float64  `yaml:"timeout"` // in seconds
+	DaysWarning  int      `yaml:"days_warning"`
+	DaysCritical int      `yaml:"days_critical"`
+	Tags         []string `yaml:"tags"`
+}
+
+// SSLCheck reports the expiration of the certificate served on a TLS port,
+// independently of the protocol spoken over the connection.
+type SSLCheck struct {
+	core.CheckBase
+	instance *SSLConfig
+}
+
+func (c *SSLConfig) parse(data []byte) error {
+	// default values
+	c.Port = 443
+	c.Timeout = 10
+	c.DaysWarning = defaultDaysWarning
+	c.DaysCritical = defaultDaysCritical
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if c.Host == "" {
+		return errors.New("the host option is required")
+	}
+	if c.ServerName == "" {
+		c.ServerName = c.Host
+	}
+	return nil
+}
+
+// Configure parses the check configuration and init the check.
+func (c *SSLCheck) Configure(config, initConfig integration.Data) error {
+	err := c.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the ssl_core check")
+		return err
+	}
+
+	c.BuildID(config, initConfig)
+	return nil
+}
+
+// Run executes the check.
+func (c *SSLCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	address := net.JoinHostPort(c.instance.Host, strconv.Itoa(c.instance.Port))
+	tags := append([]string{fmt.Sprintf("server:%s", c.instance.ServerName), fmt.Sprintf("port:%d", c.instance.Port)}, c.instance.Tags...)
+	if c.instance.Name != "" {
+		tags = append(tags, fmt.Sprintf("instance:%s", c.instance.Name))
+	}
+
+	dialer := &net.Dialer{Timeout: time.Duration(c.instance.Timeout * float64(time.Second))}
+	// The certificate is verified below, an invalid one still has an expiry to report
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         c.instance.ServerName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		sender.ServiceCheck("ssl.can_connect", metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+	defer conn.Close()
+	sender.ServiceCheck("ssl.can_connect", metrics.ServiceCheckOK, "", tags, "")
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		sender.ServiceCheck("ssl.cert_expiration", metrics.ServiceCheckUnknown, "", tags, "no certificate was presented")
+		return nil
+	}
+
+	if err := verifyCertificates(certs, c.instance.ServerName); err != nil {
+		sender.ServiceCheck("ssl.cert_valid", metrics.ServiceCheckCritical, "", tags, err.Error())
+	} else {
+		sender.ServiceCheck("ssl.cert_valid", metrics.ServiceCheckOK, "", tags, "")
+	}
+
+	days, status, message := certExpiration(certs[0], time.Now(), c.instance.DaysWarning, c.instance.DaysCritical)
+	sender.Gauge("ssl.days_left", days, "", tags)
+	sender.ServiceCheck("ssl.cert_expiration", status, "", tags, message)
+	return nil
+}
+
+// verifyCertificates verifies the chain presented by the server against the
+// system roots, and the leaf against the server name
+func verifyCertificates(certs []*x509.Certificate, serverName string) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// certExpiration returns the number of days before the certificate expires,
+// and the status of the expiration service check for these thresholds
+func certExpiration(cert *x509.Certificate, now time.Time, daysWarning, daysCritical int) (float64, metrics.ServiceCheckStatus, string) {
+	days := cert.NotAfter.Sub(now).Hours() / 24
+	switch {
+	case days < 0:
+		return days, metrics.ServiceCheckCritical, fmt.Sprintf("certificate expired on %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	case days < float64(daysCritical):
+		return days, metrics.ServiceCheckCritical, fmt.Sprintf("certificate expires in %.1f days", days)
+	case days < float64(daysWarning):
+		return days, metrics.ServiceCheckWarning, fmt.Sprintf("certificate expires in %.1f days", days)
+	default:
+		return days, metrics.ServiceCheckOK, ""
+	}
+}
+
+// SSLFactory is exported for integration testing.
+func SSLFactory() check.Check {
+	return &SSLCheck{
+		CheckBase: core.NewCheckBase(sslCheckName),
+		instance:  &SSLConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(sslCheckName, SSLFactory)
+}
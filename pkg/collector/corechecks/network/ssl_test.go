@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestCertExpiration(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		notAfter time.Time
+		status   metrics.ServiceCheckStatus
+	}{
+		{now.Add(-time.Hour), metrics.ServiceCheckCritical},
+		{now.Add(3 * 24 * time.Hour), metrics.ServiceCheckCritical},
+		{now.Add(10 * 24 * time.Hour), metrics.ServiceCheckWarning},
+		{now.Add(30 * 24 * time.Hour), metrics.ServiceCheckOK},
+	} {
+		days, status, _ := certExpiration(&x509.Certificate{NotAfter: tc.notAfter}, now, 14, 7)
+		assert.Equal(t, tc.status, status, "expiring on %s", tc.notAfter)
+		assert.InDelta(t, tc.notAfter.Sub(now).Hours()/24, days, 0.01)
+	}
+}
+
+func TestSSLCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	sslCheck := SSLFactory().(*SSLCheck)
+	require.NoError(t, sslCheck.Configure([]byte(fmt.Sprintf("host: %s\nport: %s\nserver_name: example.com", host, port)), nil))
+	sender := mocksender.NewMockSender(sslCheck.ID())
+	sender.SetupAcceptAll()
+
+	require.NoError(t, sslCheck.Run())
+	tags := []string{"server:example.com", fmt.Sprintf("port:%s", port)}
+	sender.AssertServiceCheck(t, "ssl.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertServiceCheck(t, "ssl.cert_expiration", metrics.ServiceCheckOK, "", tags, "")
+	// The test certificate is self-signed
+	sender.AssertCalled(t, "ServiceCheck", "ssl.cert_valid", metrics.ServiceCheckCritical, "", mocksender.MatchTagsContains(tags), mock.Anything)
+	sender.AssertMetricInRange(t, "Gauge", "ssl.days_left", 365, 365*100, "", tags)
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const tcpCheckName = "tcp_core"
+
+// TCPConfig is the config of the tcp_core check.
+type TCPConfig struct {
+	Name                string   `yaml:"name"`
+	Host                string   `yaml:"host"`
+	Port                int      `yaml:"port"`
+	Timeout             float64  `yaml:"timeout"` // in seconds
+	CollectResponseTime bool     `yaml:"collect_response_time"`
+	Tags                []string `yaml:"tags"`
+}
+
+// TCPCheck reports whether a TCP port accepts connections, and how long
+// establishing the connection takes.
+type TCPCheck struct {
+	core.CheckBase
+	instance *TCPConfig
+}
+
+func (c *TCPConfig) parse(data []byte) error {
+	// default values
+	c.Timeout = 10
+	c.CollectResponseTime = true
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if c.Host == "" || c.Port == 0 {
+		return errors.New("the host and port options are required")
+	}
+	return nil
+}
+
+// Configure parses the check configuration and init the check.
+func (c *TCPCheck) Configure(config, initConfig integration.Data) error {
+	err := c.instance.parse(config)
+	if err != nil {
+		log.Error("could not parse the config for the tcp_core check")
+		return err
+	}
+
+	c.BuildID(config, initConfig)
+	return nil
+}
+
+// Run executes the check.
+func (c *TCPCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	address := net.JoinHostPort(c.instance.Host, strconv.Itoa(c.instance.Port))
+	tags := append([]string{fmt.Sprintf("target_host:%s", c.instance.Host), fmt.Sprintf("port:%d", c.instance.Port)}, c.instance.Tags...)
+	if c.instance.Name != "" {
+		tags = append(tags, fmt.Sprintf("instance:%s", c.instance.Name))
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, time.Duration(c.instance.Timeout*float64(time.Second)))
+	if err != nil {
+		sender.ServiceCheck("tcp.can_connect", metrics.ServiceCheckCritical, "", tags, err.Error())
+		return nil
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+
+	sender.ServiceCheck("tcp.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	if c.instance.CollectResponseTime {
+		sender.Gauge("network.tcp.response_time", elapsed.Seconds(), "", tags)
+	}
+	return nil
+}
+
+// TCPFactory is exported for integration testing.
+func TCPFactory() check.Check {
+	return &TCPCheck{
+		CheckBase: core.NewCheckBase(tcpCheckName),
+		instance:  &TCPConfig{},
+	}
+}
+
+func init() {
+	core.RegisterCheck(tcpCheckName, TCPFactory)
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tcpCheck := TCPFactory().(*TCPCheck)
+	require.NoError(t, tcpCheck.Configure([]byte(fmt.Sprintf("host: 127.0.0.1\nport: %d\nname: local", port)), nil))
+	sender := mocksender.NewMockSender(tcpCheck.ID())
+	sender.SetupAcceptAll()
+
+	require.NoError(t, tcpCheck.Run())
+	tags := []string{"target_host:127.0.0.1", fmt.Sprintf("port:%d", port), "instance:local"}
+	sender.AssertServiceCheck(t, "tcp.can_connect", metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertCalled(t, "Gauge", "network.tcp.response_time", mock.AnythingOfType("float64"), "", mocksender.MatchTagsContains(tags))
+
+	// The port is closed
+	listener.Close()
+	require.NoError(t, tcpCheck.Run())
+	sender.AssertCalled(t, "ServiceCheck", "tcp.can_connect", metrics.ServiceCheckCritical, "", mocksender.MatchTagsContains(tags), mock.Anything)
+}
+
+func TestTCPCheckConfig(t *testing.T) {
+	tcpCheck := TCPFactory()
+	require.Error(t, tcpCheck.Configure([]byte("host: localhost"), nil))
+}
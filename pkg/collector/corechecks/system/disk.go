@@ -0,0 +1,322 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shirou/gopsutil/disk"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const diskCheckName = "disk_core"
+
+// For testing purpose
+var (
+	diskPartitions = disk.Partitions
+	diskUsage      = disk.Usage
+	mountInfoPath  = "/proc/self/mountinfo"
+)
+
+// Filesystems and mount points of the container runtimes: every layer of
+// every container is reported as a filesystem otherwise, with the size of
+// the underlying disk.
+var (
+	containerLayersFileSystems = []string{"^overlay$", "^aufs$", "^nsfs$", "^shm$"}
+	containerLayersMountPoints = []string{"^/var/lib/docker/", "^/var/lib/containerd/", "^/run/containerd/", "^/var/lib/kubelet/pods/", "^/run/docker/"}
+)
+
+// diskInstanceConfig is the config of the disk_core check, the options are
+// named after the ones of the Python disk check.
+type diskInstanceConfig struct {
+	UseMount               bool              `yaml:"use_mount"`
+	AllPartitions          bool              `yaml:"all_partitions"`
+	TagByFilesystem        bool              `yaml:"tag_by_filesystem"`
+	CollectInodes          bool              `yaml:"collect_inodes"`
+	ExcludeBindMounts      bool              `yaml:"exclude_bind_mounts"`
+	ExcludeContainerLayers bool              `yaml:"exclude_container_layers"`
+	DeviceInclude          []string          `yaml:"device_include"`
+	DeviceExclude          []string          `yaml:"device_exclude"`
+	MountPointInclude      []string          `yaml:"mount_point_include"`
+	MountPointExclude      []string          `yaml:"mount_point_exclude"`
+	FileSystemInclude      []string          `yaml:"file_system_include"`
+	FileSystemExclude      []string          `yaml:"file_system_exclude"`
+	DeviceTagRe            map[string]string `yaml:"device_tag_re"`
+	Tags                   []string          `yaml:"tags"`
+}
+
+// diskFilter includes and excludes the partitions matching regexes on one
+// of their fields
+type diskFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// deviceTags are added to the metrics of the devices matching the regex
+type deviceTags struct {
+	re   *regexp.Regexp
+	tags []string
+}
+
+// DiskCheck reports the usage of the mounted filesystems
+type DiskCheck struct {
+	core.CheckBase
+	cfg        diskInstanceConfig
+	devices    diskFilter
+	mounts     diskFilter
+	fileSystem diskFilter
+	deviceTags []deviceTags
+}
+
+func (c *diskInstanceConfig) parse(data []byte) error {
+	// default values
+	c.TagByFilesystem = true
+	c.CollectInodes = true
+	c.ExcludeBindMounts = true
+	c.ExcludeContainerLayers = true
+
+	return yaml.Unmarshal(data, c)
+}
+
+func compileDiskRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %s", pattern, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func newDiskFilter(include, exclude []string) (diskFilter, error) {
+	var f diskFilter
+	var err error
+	if f.include, err = compileDiskRegexes(include); err != nil {
+		return f, err
+	}
+	f.exclude, err = compileDiskRegexes(exclude)
+	return f, err
+}
+
+func (f diskFilter) excludes(value string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	if len(f.include) == 0 {
+		return false
+	}
+	for _, re := range f.include {
+		if re.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Configure parses the check configuration and init the check.
+func (c *DiskCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	if err := c.cfg.parse(data); err != nil {
+		log.Error("could not parse the config for the disk_core check")
+		return err
+	}
+
+	fileSystemExclude := c.cfg.FileSystemExclude
+	mountPointExclude := c.cfg.MountPointExclude
+	if c.cfg.ExcludeContainerLayers {
+		fileSystemExclude = append(fileSystemExclude, containerLayersFileSystems...)
+		mountPointExclude = append(mountPointExclude, containerLayersMountPoints...)
+	}
+
+	var err error
+	if c.devices, err = newDiskFilter(c.cfg.DeviceInclude, c.cfg.DeviceExclude); err != nil {
+		return err
+	}
+	if c.mounts, err = newDiskFilter(c.cfg.MountPointInclude, mountPointExclude); err != nil {
+		return err
+	}
+	if c.fileSystem, err = newDiskFilter(c.cfg.FileSystemInclude, fileSystemExclude); err != nil {
+		return err
+	}
+
+	c.deviceTags = nil
+	for pattern, tags := range c.cfg.DeviceTagRe {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid device_tag_re %q: %s", pattern, err)
+		}
+		dt := deviceTags{re: re}
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				dt.tags = append(dt.tags, tag)
+			}
+		}
+		c.deviceTags = append(c.deviceTags, dt)
+	}
+
+	c.BuildID(data, initConfig)
+	return nil
+}
+
+// Run executes the check
+func (c *DiskCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	partitions, err := diskPartitions(c.cfg.AllPartitions)
+	if err != nil {
+		log.Errorf("system.DiskCheck: could not list the partitions: %s", err)
+		return err
+	}
+
+	var bindMounts map[string]bool
+	if c.cfg.ExcludeBindMounts {
+		bindMounts = readBindMounts(mountInfoPath)
+	}
+
+	for _, partition := range partitions {
+		if c.excludes(partition, bindMounts) {
+			continue
+		}
+
+		usage, err := diskUsage(partition.Mountpoint)
+		if err != nil {
+			log.Debugf("system.DiskCheck: could not get the usage of %s: %s", partition.Mountpoint, err)
+			continue
+		}
+		// pseudo filesystems like /proc or /sys have no size
+		if usage.Total == 0 {
+			continue
+		}
+
+		tags := c.partitionTags(partition)
+		sender.Gauge("system.disk.total", float64(usage.Total)/kB, "", tags)
+		sender.Gauge("system.disk.used", float64(usage.Used)/kB, "", tags)
+		sender.Gauge("system.disk.free", float64(usage.Free)/kB, "", tags)
+		sender.Gauge("system.disk.in_use", usage.UsedPercent/100, "", tags)
+
+		// inodes are not reported on every platform
+		if c.cfg.CollectInodes && usage.InodesTotal > 0 {
+			sender.Gauge("system.fs.inodes.total", float64(usage.InodesTotal), "", tags)
+			sender.Gauge("system.fs.inodes.used", float64(usage.InodesUsed), "", tags)
+			sender.Gauge("system.fs.inodes.free", float64(usage.InodesFree), "", tags)
+			sender.Gauge("system.fs.inodes.in_use", usage.InodesUsedPercent/100, "", tags)
+		}
+	}
+	return nil
+}
+
+func (c *DiskCheck) excludes(partition disk.PartitionStat, bindMounts map[string]bool) bool {
+	return c.devices.excludes(partition.Device) ||
+		c.mounts.excludes(partition.Mountpoint) ||
+		c.fileSystem.excludes(partition.Fstype) ||
+		bindMounts[partition.Mountpoint]
+}
+
+func (c *DiskCheck) partitionTags(partition disk.PartitionStat) []string {
+	device := partition.Device
+	if c.cfg.UseMount {
+		device = partition.Mountpoint
+	}
+	tags := append([]string{
+		fmt.Sprintf("device:%s", device),
+		fmt.Sprintf("device_name:%s", filepath.Base(partition.Device)),
+	}, c.cfg.Tags...)
+	if c.cfg.TagByFilesystem && partition.Fstype != "" {
+		tags = append(tags, fmt.Sprintf("filesystem:%s", partition.Fstype))
+	}
+	for _, dt := range c.deviceTags {
+		if dt.re.MatchString(partition.Device) {
+			tags = append(tags, dt.tags...)
+		}
+	}
+	return tags
+}
+
+// readBindMounts returns the mount points of the bind mounts, empty on the
+// platforms without mountinfo
+func readBindMounts(path string) map[string]bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	return parseBindMounts(f)
+}
+
+// parseBindMounts parses a mountinfo file, formatted as:
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+//
+// A mount is a bind mount when another mount of the same major:minor device
+// has a root containing its root, like `/` for `/srv`. A directory mounted
+// several times is reported once, at its first mount point. The disjoint
+// roots of a device, like the btrfs subvolumes, are all reported.
+func parseBindMounts(r io.Reader) map[string]bool {
+	type mount struct {
+		device, root, mountPoint string
+	}
+	var mounts []mount
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mounts = append(mounts, mount{device: fields[2], root: fields[3], mountPoint: fields[4]})
+	}
+
+	bindMounts := make(map[string]bool)
+	for i, m := range mounts {
+		for j, other := range mounts {
+			if i == j || other.device != m.device {
+				continue
+			}
+			if (other.root == m.root && j < i) || isParentRoot(other.root, m.root) {
+				bindMounts[m.mountPoint] = true
+				break
+			}
+		}
+	}
+	return bindMounts
+}
+
+// isParentRoot returns whether the root of a mount strictly contains another
+func isParentRoot(parent, root string) bool {
+	if parent == root {
+		return false
+	}
+	return parent == "/" || strings.HasPrefix(root, strings.TrimSuffix(parent, "/")+"/")
+}
+
+func diskFactory() check.Check {
+	return &DiskCheck{
+		CheckBase: core.NewCheckBase(diskCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(diskCheckName, diskFactory)
+}
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package system
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+var (
+	testPartitions = []disk.PartitionStat{
+		{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+		{Device: "/dev/sda1", Mountpoint: "/srv/data", Fstype: "ext4"}, // bind mount
+		{Device: "/dev/sdb1", Mountpoint: "/mnt/backup", Fstype: "xfs"},
+		{Device: "overlay", Mountpoint: "/var/lib/docker/overlay2/abc/merged", Fstype: "overlay"},
+		{Device: "proc", Mountpoint: "/proc", Fstype: "proc"},
+	}
+	testMountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+23 22 0:4 / /proc rw,nosuid shared:12 - proc proc rw
+24 22 8:1 /srv /srv/data rw,relatime shared:1 - ext4 /dev/sda1 rw
+25 22 8:17 / /mnt/backup rw,relatime shared:2 - xfs /dev/sdb1 rw
+26 22 0:40 / /var/lib/docker/overlay2/abc/merged rw,relatime - overlay overlay rw
+`
+)
+
+func testDiskUsage(path string) (*disk.UsageStat, error) {
+	if path == "/proc" {
+		return &disk.UsageStat{Path: path}, nil
+	}
+	return &disk.UsageStat{
+		Path:              path,
+		Total:             100 * kB,
+		Used:              25 * kB,
+		Free:              75 * kB,
+		UsedPercent:       25,
+		InodesTotal:       1000,
+		InodesUsed:        100,
+		InodesFree:        900,
+		InodesUsedPercent: 10,
+	}, nil
+}
+
+func runDiskCheck(t *testing.T, config string) *mocksender.MockSender {
+	f, err := ioutil.TempFile("", "mountinfo")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(testMountInfo)
+	require.NoError(t, err)
+	f.Close()
+
+	diskPartitions = func(all bool) ([]disk.PartitionStat, error) { return testPartitions, nil }
+	diskUsage = testDiskUsage
+	mountInfoPath = f.Name()
+	defer func() {
+		diskPartitions = disk.Partitions
+		diskUsage = disk.Usage
+		mountInfoPath = "/proc/self/mountinfo"
+	}()
+
+	diskCheck := diskFactory()
+	require.NoError(t, diskCheck.Configure([]byte(config), nil))
+	sender := mocksender.NewMockSender(diskCheck.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, diskCheck.Run())
+	return sender
+}
+
+func TestDiskCheck(t *testing.T) {
+	sender := runDiskCheck(t, "tags: [foo:bar]")
+
+	tags := []string{"device:/dev/sda1", "device_name:sda1", "filesystem:ext4", "foo:bar"}
+	sender.AssertMetric(t, "Gauge", "system.disk.total", 100, "", tags)
+	sender.AssertMetric(t, "Gauge", "system.disk.used", 25, "", tags)
+	sender.AssertMetric(t, "Gauge", "system.disk.free", 75, "", tags)
+	sender.AssertMetric(t, "Gauge", "system.disk.in_use", 0.25, "", tags)
+	sender.AssertMetric(t, "Gauge", "system.fs.inodes.total", 1000, "", tags)
+	sender.AssertMetric(t, "Gauge", "system.fs.inodes.in_use", 0.1, "", tags)
+	sender.AssertMetric(t, "Gauge", "system.disk.total", 100, "", []string{"device:/dev/sdb1", "filesystem:xfs"})
+
+	// the bind mount, the container layer and the pseudo filesystem are excluded
+	sender.AssertNumberOfCalls(t, "Gauge", 2*8)
+}
+
+func TestDiskCheckFilters(t *testing.T) {
+	config := strings.Join([]string{
+		"use_mount: true",
+		"exclude_bind_mounts: false",
+		"collect_inodes: false",
+		"mount_point_exclude: ['^/mnt/']",
+		"device_tag_re:",
+		"  /dev/sda.*: role:system, disk:primary",
+	}, "\n")
+	sender := runDiskCheck(t, config)
+
+	sender.AssertMetric(t, "Gauge", "system.disk.total", 100, "", []string{"device:/", "role:system", "disk:primary"})
+	sender.AssertMetric(t, "Gauge", "system.disk.total", 100, "", []string{"device:/srv/data", "device_name:sda1"})
+	sender.AssertNotCalled(t, "Gauge", "system.disk.total", mock.Anything, "", mocksender.MatchTagsContains([]string{"device:/mnt/backup"}))
+	sender.AssertNotCalled(t, "Gauge", "system.fs.inodes.total", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNumberOfCalls(t, "Gauge", 2*4)
+}
+
+func TestParseBindMounts(t *testing.T) {
+	bindMounts := parseBindMounts(strings.NewReader(testMountInfo))
+	assert.Equal(t, map[string]bool{"/srv/data": true}, bindMounts)
+
+	// the subvolumes of a filesystem are mounted from different roots
+	subvolumes := `22 1 0:30 /@ / rw,relatime shared:1 - btrfs /dev/sda2 rw
+23 22 0:30 /@home /home rw,relatime shared:2 - btrfs /dev/sda2 rw
+24 22 0:30 /@home /srv/home rw,relatime shared:2 - btrfs /dev/sda2 rw
+`
+	bindMounts = parseBindMounts(strings.NewReader(subvolumes))
+	assert.Equal(t, map[string]bool{"/srv/home": true}, bindMounts)
+
+	// a directory of a subvolume is bound from a root inside the subvolume
+	subdirectory := `22 1 0:30 /@ / rw,relatime shared:1 - btrfs /dev/sda2 rw
+23 22 0:30 /@home /home rw,relatime shared:2 - btrfs /dev/sda2 rw
+24 22 0:30 /@home/alice /srv/alice rw,relatime shared:2 - btrfs /dev/sda2 rw
+`
+	bindMounts = parseBindMounts(strings.NewReader(subdirectory))
+	assert.Equal(t, map[string]bool{"/srv/alice": true}, bindMounts)
+	assert.Nil(t, readBindMounts(fmt.Sprintf("/does/not/exist/%s", t.Name())))
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
-	"github.com/DataDog/datadog-agent/pkg/util/winutil/pdhutil"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 )
@@ -20,7 +19,6 @@ const fileHandlesCheckName = "file_handle"
 
 type fhCheck struct {
 	core.CheckBase
-	counter *pdhutil.PdhCounterSet
 }
 
 // Run executes the check
@@ -30,22 +28,21 @@ func (c *fhCheck) Run() error {
 	if err != nil {
 		return err
 	}
-	val, err := c.counter.GetSingleValue()
+	perfInfo, err := performanceInfo()
 	if err != nil {
 		log.Warnf("Error getting handle value %v", err)
 		return err
 	}
-	log.Debugf("Submitting system.fs.file_handles_in_use %v", val)
-	sender.Gauge("system.fs.file_handles.in_use", float64(val), "", nil)
+	log.Debugf("Submitting system.fs.file_handles_in_use %v", perfInfo.HandleCount)
+	sender.Gauge("system.fs.file_handles.in_use", float64(perfInfo.HandleCount), "", nil)
 	sender.Commit()
 
 	return nil
 }
 
 // The check doesn't need configuration
-func (c *fhCheck) Configure(data integration.Data, initConfig integration.Data) (err error) {
-	c.counter, err = pdhutil.GetCounterSet("Process", "Handle Count", "_Total", nil)
-	return err
+func (c *fhCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	return nil
 }
 
 func fhFactory() check.Check {
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/winutil"
+)
+
+const pagefileCheckName = "pagefile"
+
+// For testing purpose
+var pageFiles = winutil.PageFiles
+
+// pagefileCheck reports the usage of the paging files
+type pagefileCheck struct {
+	core.CheckBase
+}
+
+// Run executes the check
+func (c *pagefileCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	files, err := pageFiles()
+	if err != nil {
+		log.Errorf("system.pagefileCheck: could not retrieve the paging files usage: %s", err)
+		return err
+	}
+
+	for _, file := range files {
+		tags := []string{fmt.Sprintf("pagefile:%s", file.Name)}
+		sender.Gauge("system.pagefile.total", float64(file.Total)/mbSize, "", tags)
+		sender.Gauge("system.pagefile.used", float64(file.Used)/mbSize, "", tags)
+		sender.Gauge("system.pagefile.free", float64(file.Total-file.Used)/mbSize, "", tags)
+		sender.Gauge("system.pagefile.peak", float64(file.Peak)/mbSize, "", tags)
+		if file.Total > 0 {
+			sender.Gauge("system.pagefile.pct_used", float64(file.Used)/float64(file.Total), "", tags)
+		}
+	}
+	sender.Commit()
+
+	return nil
+}
+
+// The check doesn't need configuration
+func (c *pagefileCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	return nil
+}
+
+func pagefileFactory() check.Check {
+	return &pagefileCheck{
+		CheckBase: core.NewCheckBase(pagefileCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(pagefileCheckName, pagefileFactory)
+}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package system
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/winutil"
+	"github.com/stretchr/testify/require"
+)
+
+func PageFiles() ([]winutil.PageFileStat, error) {
+	return []winutil.PageFileStat{
+		{
+			Name:  `C:\pagefile.sys`,
+			Total: 4096 << 20,
+			Used:  1024 << 20,
+			Peak:  2048 << 20,
+		},
+	}, nil
+}
+
+func TestPagefileCheck(t *testing.T) {
+	pageFiles = PageFiles
+	defer func() { pageFiles = winutil.PageFiles }()
+	pfCheck := pagefileFactory()
+
+	mock := mocksender.NewMockSender(pfCheck.ID())
+
+	tags := []string{`pagefile:C:\pagefile.sys`}
+	mock.On("Gauge", "system.pagefile.total", 4096.0, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.pagefile.used", 1024.0, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.pagefile.free", 3072.0, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.pagefile.peak", 2048.0, "", tags).Return().Times(1)
+	mock.On("Gauge", "system.pagefile.pct_used", 0.25, "", tags).Return().Times(1)
+	mock.On("Commit").Return().Times(1)
+
+	require.NoError(t, pfCheck.Run())
+	mock.AssertExpectations(t)
+}
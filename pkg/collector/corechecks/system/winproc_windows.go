@@ -11,15 +11,20 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
-	"github.com/DataDog/datadog-agent/pkg/util/winutil/pdhutil"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/winutil"
 )
 
 const winprocCheckName = "winproc"
 
+// For testing purpose
+var (
+	performanceInfo      = winutil.PerformanceInfo
+	processorQueueLength = winutil.ProcessorQueueLength
+)
+
 type processChk struct {
 	core.CheckBase
-	numprocs *pdhutil.PdhCounterSet
-	pql      *pdhutil.PdhCounterSet
 }
 
 // Run executes the check
@@ -30,24 +35,28 @@ func (c *processChk) Run() error {
 		return err
 	}
 
-	procQueueLength, _ := c.pql.GetSingleValue()
-	procCount, _ := c.numprocs.GetSingleValue()
+	perfInfo, err := performanceInfo()
+	if err != nil {
+		log.Errorf("system.processChk: could not retrieve the performance information: %s", err)
+		return err
+	}
+	sender.Gauge("system.proc.count", float64(perfInfo.ProcessCount), "", nil)
+	sender.Gauge("system.proc.threads", float64(perfInfo.ThreadCount), "", nil)
 
-	sender.Gauge("system.proc.queue_length", procQueueLength, "", nil)
-	sender.Gauge("system.proc.count", procCount, "", nil)
+	procQueueLength, err := processorQueueLength()
+	if err != nil {
+		log.Warnf("system.processChk: could not compute the processor queue length: %s", err)
+	} else {
+		sender.Gauge("system.proc.queue_length", float64(procQueueLength), "", nil)
+	}
 	sender.Commit()
 
 	return nil
 }
 
-func (c *processChk) Configure(data integration.Data, initConfig integration.Data) (err error) {
-	c.numprocs, err = pdhutil.GetCounterSet("System", "Processes", "", nil)
-	if err != nil {
-		return err
-	}
-	c.pql, err = pdhutil.GetCounterSet("System", "Processor Queue Length", "", nil)
-
-	return err
+// The check doesn't need configuration
+func (c *processChk) Configure(data integration.Data, initConfig integration.Data) error {
+	return nil
 }
 
 func processCheckFactory() check.Check {
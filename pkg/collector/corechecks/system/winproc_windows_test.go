@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package system
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/winutil"
+	"github.com/stretchr/testify/require"
+)
+
+func PerformanceInfo() (*winutil.PerformanceInfoStat, error) {
+	return &winutil.PerformanceInfoStat{
+		PageSize:     4096,
+		HandleCount:  31000,
+		ProcessCount: 120,
+		ThreadCount:  1500,
+	}, nil
+}
+
+func ProcessorQueueLength() (uint64, error) {
+	return 3, nil
+}
+
+func TestWinprocCheck(t *testing.T) {
+	performanceInfo = PerformanceInfo
+	processorQueueLength = ProcessorQueueLength
+	defer func() {
+		performanceInfo = winutil.PerformanceInfo
+		processorQueueLength = winutil.ProcessorQueueLength
+	}()
+	procCheck := processCheckFactory()
+
+	mock := mocksender.NewMockSender(procCheck.ID())
+
+	mock.On("Gauge", "system.proc.count", 120.0, "", []string(nil)).Return().Times(1)
+	mock.On("Gauge", "system.proc.threads", 1500.0, "", []string(nil)).Return().Times(1)
+	mock.On("Gauge", "system.proc.queue_length", 3.0, "", []string(nil)).Return().Times(1)
+	mock.On("Commit").Return().Times(1)
+
+	require.NoError(t, procCheck.Run())
+	mock.AssertExpectations(t)
+}
+
+func TestFileHandlesCheckWindows(t *testing.T) {
+	performanceInfo = PerformanceInfo
+	defer func() { performanceInfo = winutil.PerformanceInfo }()
+	fhCheck := fhFactory()
+
+	mock := mocksender.NewMockSender(fhCheck.ID())
+
+	mock.On("Gauge", "system.fs.file_handles.in_use", 31000.0, "", []string(nil)).Return().Times(1)
+	mock.On("Commit").Return().Times(1)
+
+	require.NoError(t, fhCheck.Run())
+	mock.AssertExpectations(t)
+}
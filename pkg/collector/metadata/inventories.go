@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/metadata/inventories"
+	md "github.com/DataDog/datadog-agent/pkg/metadata"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// InventoriesCollector fills and sends the inventories metadata payload,
+// listing the checks running on the agent along with their version and
+// config hash
+type InventoriesCollector struct{}
+
+// Send collects the data needed and submits the payload
+func (ic *InventoriesCollector) Send(s *serializer.Serializer) error {
+	hostname, _ := util.GetHostname()
+
+	payload := inventories.GetPayload(hostname)
+	if err := s.SendMetadata(payload); err != nil {
+		return fmt.Errorf("unable to submit inventories metadata payload, %s", err)
+	}
+	return nil
+}
+
+func init() {
+	md.RegisterCollector("inventories", new(InventoriesCollector))
+}
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// GetPayload fills and returns the inventories metadata payload, listing the
+// version and the config hash of every running check instance so config and
+// version drifts can be detected across agents.
+func GetPayload(hostname string) *Payload {
+	return &Payload{
+		Hostname:      hostname,
+		Timestamp:     time.Now().UnixNano(),
+		AgentVersion:  version.AgentVersion,
+		CheckMetadata: getCheckMetadata(runner.GetCheckStats()),
+	}
+}
+
+func getCheckMetadata(checkStats map[string]map[check.ID]*check.Stats) CheckMetadata {
+	checkMetadata := make(CheckMetadata)
+	for checkName, stats := range checkStats {
+		for _, s := range stats {
+			checkMetadata[checkName] = append(checkMetadata[checkName], &CheckInstanceMetadata{
+				ID:         string(s.CheckID),
+				Version:    s.CheckVersion,
+				ConfigHash: s.CheckConfigHash,
+			})
+		}
+	}
+	return checkMetadata
+}
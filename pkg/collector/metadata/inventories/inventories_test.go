@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+func TestGetCheckMetadata(t *testing.T) {
+	checkStats := map[string]map[check.ID]*check.Stats{
+		"redisdb": {
+			"redisdb:abc": {CheckName: "redisdb", CheckID: "redisdb:abc", CheckVersion: "1.5.0", CheckConfigHash: "abc"},
+			"redisdb:def": {CheckName: "redisdb", CheckID: "redisdb:def", CheckVersion: "1.5.0", CheckConfigHash: "def"},
+		},
+		"cpu": {
+			"cpu": {CheckName: "cpu", CheckID: "cpu", CheckConfigHash: "123"},
+		},
+	}
+
+	metadata := getCheckMetadata(checkStats)
+	require.Len(t, metadata, 2)
+	assert.Len(t, metadata["redisdb"], 2)
+	assert.ElementsMatch(t, []*CheckInstanceMetadata{
+		{ID: "redisdb:abc", Version: "1.5.0", ConfigHash: "abc"},
+		{ID: "redisdb:def", Version: "1.5.0", ConfigHash: "def"},
+	}, metadata["redisdb"])
+	assert.Equal(t, []*CheckInstanceMetadata{{ID: "cpu", ConfigHash: "123"}}, metadata["cpu"])
+}
+
+func TestPayloadMarshalJSON(t *testing.T) {
+	p := &Payload{
+		Hostname:     "myhost",
+		Timestamp:    1,
+		AgentVersion: "6.5.0",
+		CheckMetadata: CheckMetadata{
+			"cpu": {{ID: "cpu", ConfigHash: "123"}},
+		},
+	}
+
+	out, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"hostname": "myhost",
+		"timestamp": 1,
+		"agent_version": "6.5.0",
+		"check_metadata": {"cpu": [{"id": "cpu", "version": "", "config.hash": "123"}]}
+	}`, string(out))
+}
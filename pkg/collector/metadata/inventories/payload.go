@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+)
+
+// CheckInstanceMetadata describes a running check instance
+type CheckInstanceMetadata struct {
+	ID         string `json:"id"`
+	Version    string `json:"version"`
+	ConfigHash string `json:"config.hash"`
+}
+
+// CheckMetadata maps a check name to the metadata of its running instances
+type CheckMetadata map[string][]*CheckInstanceMetadata
+
+// Payload handles the JSON unmarshalling of the inventories metadata payload
+type Payload struct {
+	Hostname      string        `json:"hostname"`
+	Timestamp     int64         `json:"timestamp"`
+	AgentVersion  string        `json:"agent_version"`
+	CheckMetadata CheckMetadata `json:"check_metadata"`
+}
+
+// MarshalJSON serialization a Payload to JSON
+func (p *Payload) MarshalJSON() ([]byte, error) {
+	// use an alias to avoid infinite recursion while serializing
+	type PayloadAlias Payload
+
+	return json.Marshal((*PayloadAlias)(p))
+}
+
+// Marshal not implemented
+func (p *Payload) Marshal() ([]byte, error) {
+	return nil, fmt.Errorf("Inventories payload serialization is not implemented")
+}
+
+// SplitPayload implements marshaler.AbstractMarshaler#SplitPayload.
+func (p *Payload) SplitPayload(times int) ([]marshaler.Marshaler, error) {
+	// Metadata payloads are analyzed as a whole, so they cannot be split
+	return nil, fmt.Errorf("Inventories payload splitting is not implemented")
+}
@@ -203,6 +203,11 @@ func (c *PythonCheck) getInstance(args, kwargs *python.PyObject) (*python.PyObje
 func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Data) error {
 	// Generate check ID
 	c.id = check.Identify(c, data, initConfig)
+	check.SetConfigHash(c.id, data, initConfig)
+	if err := aggregator.SetCheckMetricPatterns(c.id, data); err != nil {
+		log.Errorf("invalid metric_patterns for check %s: %s", c.id, err)
+		return err
+	}
 
 	// Unmarshal instances config to a RawConfigMap
 	rawInstances := integration.RawMap{}
@@ -55,6 +55,9 @@ func Initialize(paths ...string) *python.PyThreadState {
 
 	// store the final value of Python Home in the cache
 	PythonHome = C.GoString(C.Py_GetPythonHome())
+	if PythonHome != "" {
+		cache.Cache.Set(cache.BuildAgentKey("pythonHome"), PythonHome, cache.NoExpiration)
+	}
 
 	// Start the interpreter
 	if C.Py_IsInitialized() == 0 {
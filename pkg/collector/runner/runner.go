@@ -8,6 +8,7 @@ package runner
 import (
 	"expvar"
 	"fmt"
+	"runtime"
 	"strings"
 
 	"strconv"
@@ -262,9 +263,16 @@ func (r *Runner) work() {
 
 		// run the check
 		var err error
+		resourceMetrics := config.Datadog.GetBool("check_resource_metrics")
 		t0 := time.Now()
 
+		// the check runs on this thread to measure its CPU time
+		runtime.LockOSThread()
+		stopUsage := startUsage(resourceMetrics)
 		err = check.Run()
+		usage := stopUsage()
+		runtime.UnlockOSThread()
+		execTime := time.Since(t0)
 		longRunning := check.Interval() == 0
 
 		warnings := check.GetWarnings()
@@ -293,6 +301,11 @@ func (r *Runner) work() {
 
 		if sender != nil && !longRunning {
 			sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, "")
+			if resourceMetrics {
+				sender.Gauge("datadog.agent.check.execution_time", execTime.Seconds(), hostname, serviceCheckTags)
+				sender.Gauge("datadog.agent.check.cpu_time", usage.cpuTime.Seconds(), hostname, serviceCheckTags)
+				sender.Gauge("datadog.agent.check.allocated_bytes", float64(usage.allocatedBytes), hostname, serviceCheckTags)
+			}
 			sender.Commit()
 		}
 
@@ -311,7 +324,7 @@ func (r *Runner) work() {
 			// otherwise only do so if the check is in the scheduler
 			if r.scheduler == nil || r.scheduler.IsCheckScheduled(check.ID()) {
 				mStats, _ := check.GetMetricStats()
-				addWorkStats(check, time.Since(t0), err, warnings, mStats)
+				addWorkStats(check, execTime, err, warnings, mStats, usage)
 			}
 		}
 		r.m.Unlock()
@@ -363,7 +376,7 @@ func shouldLog(id check.ID) (doLog bool, lastLog bool) {
 	return
 }
 
-func addWorkStats(c check.Check, execTime time.Duration, err error, warnings []error, mStats map[string]int64) {
+func addWorkStats(c check.Check, execTime time.Duration, err error, warnings []error, mStats map[string]int64, usage resourceUsage) {
 	var s *check.Stats
 	var found bool
 
@@ -382,6 +395,7 @@ func addWorkStats(c check.Check, execTime time.Duration, err error, warnings []e
 	checkStats.M.Unlock()
 
 	s.Add(execTime, err, warnings, mStats)
+	s.AddResourceUsage(usage.cpuTime, usage.allocatedBytes)
 }
 
 func expCheckStats() interface{} {
@@ -173,3 +173,21 @@ func TestStopCheck(t *testing.T) {
 	err = r.StopCheck(c2.ID())
 	assert.Equal(t, "timeout during stop operation on check id TestCheck:2", err.Error())
 }
+
+var allocated []byte
+
+func TestStartUsage(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	stopUsage := startUsage(true)
+	allocated = make([]byte, 1024*1024)
+	usage := stopUsage()
+	assert.True(t, usage.allocatedBytes >= 1024*1024)
+	assert.True(t, usage.cpuTime >= 0)
+
+	stopUsage = startUsage(false)
+	allocated = make([]byte, 1024*1024)
+	usage = stopUsage()
+	assert.Zero(t, usage.allocatedBytes)
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package runner
+
+import (
+	"runtime"
+	"time"
+)
+
+// resourceUsage are the resources used by a check run
+type resourceUsage struct {
+	cpuTime        time.Duration // CPU time of the thread running the check
+	allocatedBytes uint64        // memory allocated by the whole agent meanwhile
+}
+
+// startUsage starts measuring the resources used by a check run, until the
+// returned func is called. The goroutine must stay locked to its thread
+// meanwhile: the CPU time of the thread is measured, which includes the
+// python checks but not the goroutines started by the check. The allocated
+// memory is the one of the agent process, the concurrent runs included. It is
+// only measured withAllocations, reading it stops the world.
+func startUsage(withAllocations bool) func() resourceUsage {
+	cpuStart := threadCPUTime()
+	var allocatedStart uint64
+	if withAllocations {
+		allocatedStart = totalAllocatedBytes()
+	}
+
+	return func() resourceUsage {
+		usage := resourceUsage{cpuTime: threadCPUTime() - cpuStart}
+		if withAllocations {
+			usage.allocatedBytes = totalAllocatedBytes() - allocatedStart
+		}
+		return usage
+	}
+}
+
+func totalAllocatedBytes() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.TotalAlloc
+}
@@ -80,10 +80,24 @@ func init() {
 
 	// Configuration defaults
 	// Agent
-	BindEnvAndSetDefault("dd_url", "https://app.datadoghq.com")
+	BindEnvAndSetDefault("site", "")
+	BindEnvAndSetDefault("dd_url", defaultDDURL)
+	BindEnvAndSetDefault("failover_domains", []string{})
 	BindEnvAndSetDefault("app_key", "")
 	Datadog.SetDefault("proxy", nil)
 	BindEnvAndSetDefault("skip_ssl_validation", false)
+	BindEnvAndSetDefault("tls_ca_file", "")          // PEM bundle trusted on top of the system roots
+	BindEnvAndSetDefault("tls_client_cert_file", "") // used for mutual TLS with the intake or a proxy
+	BindEnvAndSetDefault("tls_client_key_file", "")
+	// FIPS: send the traffic through the local FIPS proxy
+	BindEnvAndSetDefault("fips.enabled", false)
+	BindEnvAndSetDefault("fips.local_address", "localhost")
+	BindEnvAndSetDefault("fips.port_range_start", 9803)
+	BindEnvAndSetDefault("fips.https", true)
+	// Detect the container runtimes and orchestrators to enable their listeners and config providers
+	BindEnvAndSetDefault("autoconfig_from_environment", true)
+	BindEnvAndSetDefault("autoconfig_exclude_features", []string{})
+	BindEnvAndSetDefault("container_runtime", "auto")
 	BindEnvAndSetDefault("hostname", "")
 	BindEnvAndSetDefault("tags", []string{})
 	BindEnvAndSetDefault("tag_value_split_separator", map[string]string{})
@@ -109,6 +123,7 @@ func init() {
 	BindEnvAndSetDefault("enable_metadata_collection", true)
 	BindEnvAndSetDefault("enable_gohai", true)
 	BindEnvAndSetDefault("check_runners", int64(4))
+	BindEnvAndSetDefault("check_resource_metrics", false)
 	BindEnvAndSetDefault("auth_token_file_path", "")
 	BindEnvAndSetDefault("bind_host", "localhost")
 
@@ -123,6 +138,10 @@ func init() {
 	Datadog.BindEnv("secret_backend_arguments")
 	BindEnvAndSetDefault("secret_backend_output_max_size", 1024)
 	BindEnvAndSetDefault("secret_backend_timeout", 5)
+	BindEnvAndSetDefault("secret_backend_user", "")
+	BindEnvAndSetDefault("secret_backend_group", "")
+	BindEnvAndSetDefault("secret_backend_command_allow_group_exec_perm", false)
+	BindEnvAndSetDefault("secret_backend_no_new_privs", false)
 
 	// Retry settings
 	BindEnvAndSetDefault("forwarder_backoff_factor", 2)
@@ -164,6 +183,13 @@ func init() {
 	BindEnvAndSetDefault("proc_root", "/proc")
 	BindEnvAndSetDefault("histogram_aggregates", []string{"max", "median", "avg", "count"})
 	BindEnvAndSetDefault("histogram_percentiles", []string{"0.95"})
+	// Aggregator
+	BindEnvAndSetDefault("aggregator_flush_interval", 15)            // in seconds
+	BindEnvAndSetDefault("aggregator_flush_deadline", 0)             // in seconds, 0 means the flush interval
+	BindEnvAndSetDefault("aggregator_flush_overlap_policy", "merge") // "merge" or "skip"
+	BindEnvAndSetDefault("aggregator_events_flush_interval", 0)      // in seconds, 0 means with the series
+	BindEnvAndSetDefault("metric_patterns.include", []string{})
+	BindEnvAndSetDefault("metric_patterns.exclude", []string{})
 	// Serializer
 	BindEnvAndSetDefault("use_v2_api.series", false)
 	BindEnvAndSetDefault("use_v2_api.events", false)
@@ -174,10 +200,28 @@ func init() {
 	BindEnvAndSetDefault("enable_payloads.service_checks", true)
 	BindEnvAndSetDefault("enable_payloads.sketches", true)
 	BindEnvAndSetDefault("enable_payloads.json_to_v1_intake", true)
+	// Metrics relay: send the series to another agent, and receive the series of other agents
+	BindEnvAndSetDefault("metrics_relay.url", "")
+	BindEnvAndSetDefault("metrics_relay.only", false)
+	BindEnvAndSetDefault("metrics_relay.metric_patterns.include", []string{})
+	BindEnvAndSetDefault("metrics_relay.metric_patterns.exclude", []string{})
+	BindEnvAndSetDefault("metrics_relay.listener.enabled", false)
+	BindEnvAndSetDefault("metrics_relay.listener.bind_host", "localhost")
+	BindEnvAndSetDefault("metrics_relay.listener.port", 5033)
+	BindEnvAndSetDefault("metrics_relay.listener.tls_cert_file", "")
+	BindEnvAndSetDefault("metrics_relay.listener.tls_key_file", "")
+
+	// Clock correction: apply the offset measured with NTP to the timestamps
+	BindEnvAndSetDefault("clock_correction.enabled", false)
+	BindEnvAndSetDefault("clock_correction.ntp_hosts", []string{"0.datadog.pool.ntp.org", "1.datadog.pool.ntp.org", "2.datadog.pool.ntp.org", "3.datadog.pool.ntp.org"})
+	BindEnvAndSetDefault("clock_correction.threshold", 60) // in seconds
+	BindEnvAndSetDefault("clock_correction.interval", 900) // in seconds
 
 	// Forwarder
 	BindEnvAndSetDefault("forwarder_timeout", 20)
 	BindEnvAndSetDefault("forwarder_retry_queue_max_size", 30)
+	BindEnvAndSetDefault("forwarder_apikey_quarantine_period", 1800) // in seconds, 0 disables the quarantine
+	BindEnvAndSetDefault("forwarder_apikey_quarantine_max_size", 30)
 	BindEnvAndSetDefault("forwarder_num_workers", 1)
 	// Dogstatsd
 	BindEnvAndSetDefault("use_dogstatsd", true)
@@ -191,14 +235,38 @@ func init() {
 	BindEnvAndSetDefault("dogstatsd_expiry_seconds", 300)
 	BindEnvAndSetDefault("dogstatsd_origin_detection", false) // Only supported for socket traffic
 	BindEnvAndSetDefault("dogstatsd_so_rcvbuf", 0)
+	BindEnvAndSetDefault("dogstatsd_capture_path", "") // Notice: empty means the temporary directory
+	BindEnvAndSetDefault("dogstatsd_rollup.prefixes", []string{})
+	BindEnvAndSetDefault("dogstatsd_rollup.interval", 10) // in seconds
+	BindEnvAndSetDefault("dogstatsd_flush_on_exit", false)
+	BindEnvAndSetDefault("dogstatsd_flush_on_exit_timeout", 10) // in seconds
 	BindEnvAndSetDefault("statsd_forward_host", "")
 	BindEnvAndSetDefault("statsd_forward_port", 0)
 	BindEnvAndSetDefault("statsd_metric_namespace", "")
+	// the metrics of the agent, tracers and JMX integrations are not namespaced
+	BindEnvAndSetDefault("statsd_metric_namespace_exclude", []string{
+		"datadog.agent",
+		"datadog.dogstatsd",
+		"datadog.process",
+		"datadog.trace_agent",
+		"datadog.tracer",
+		"activemq",
+		"cassandra",
+		"jmx",
+		"jvm",
+		"kafka",
+		"tomcat",
+		"runtime",
+	})
 	// Autoconfig
 	BindEnvAndSetDefault("autoconf_template_dir", "/datadog/check_configs")
 	BindEnvAndSetDefault("exclude_pause_container", true)
+	BindEnvAndSetDefault("pause_container_images", []string{})
 	BindEnvAndSetDefault("ac_include", []string{})
 	BindEnvAndSetDefault("ac_exclude", []string{})
+	BindEnvAndSetDefault("ad_config_warmup_grace_period", 0) // in seconds, 0 means disabled
+	BindEnvAndSetDefault("service_hints.enabled", false)
+	BindEnvAndSetDefault("service_hints.interval", 60) // in seconds
 
 	// Docker
 	BindEnvAndSetDefault("docker_query_timeout", int64(5))
@@ -208,6 +276,9 @@ func init() {
 	BindEnvAndSetDefault("kubernetes_pod_annotations_as_tags", map[string]string{})
 	BindEnvAndSetDefault("kubernetes_node_labels_as_tags", map[string]string{})
 
+	// Podman
+	BindEnvAndSetDefault("podman_socket", "/run/podman/podman.sock")
+
 	// Kubernetes
 	BindEnvAndSetDefault("kubernetes_kubelet_host", "")
 	BindEnvAndSetDefault("kubernetes_http_kubelet_port", 10255)
@@ -225,6 +296,9 @@ func init() {
 	BindEnvAndSetDefault("kubernetes_metadata_tag_update_freq", 60) // Polling frequency of the Agent to the DCA in seconds (gets the local cache if the DCA is disabled)
 	BindEnvAndSetDefault("kubernetes_apiserver_client_timeout", 10)
 	BindEnvAndSetDefault("kubernetes_map_services_on_ip", false) // temporary opt-out of the new mapping logic
+	// imported from the kubernetes check of agent 5 by `import`
+	BindEnvAndSetDefault("kubernetes_collect_service_tags", true)
+	BindEnvAndSetDefault("kubernetes_service_tag_update_freq", 60)
 
 	// Kube ApiServer
 	BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
@@ -238,6 +312,11 @@ func init() {
 	BindEnvAndSetDefault("cluster_agent.url", "")
 	BindEnvAndSetDefault("cluster_agent.kubernetes_service_name", "datadog-cluster-agent")
 
+	// External check schedulers, authenticated with the cluster_agent.auth_token
+	BindEnvAndSetDefault("external_scheduler.enabled", false)
+	BindEnvAndSetDefault("external_scheduler.bind_host", "0.0.0.0")
+	BindEnvAndSetDefault("external_scheduler.port", 5006)
+
 	// ECS
 	BindEnvAndSetDefault("ecs_agent_url", "") // Will be autodetected
 	BindEnvAndSetDefault("collect_ec2_tags", false)
@@ -248,6 +327,12 @@ func init() {
 	// Cloud Foundry
 	BindEnvAndSetDefault("cloud_foundry", false)
 	BindEnvAndSetDefault("bosh_id", "")
+	BindEnvAndSetDefault("cloud_foundry_garden.listen_network", "unix")
+	BindEnvAndSetDefault("cloud_foundry_garden.listen_address", "/var/vcap/data/garden/garden.sock")
+	BindEnvAndSetDefault("cloud_foundry_bbs.url", "https://bbs.service.cf.internal:8889")
+	BindEnvAndSetDefault("cloud_foundry_bbs.ca_file", "")
+	BindEnvAndSetDefault("cloud_foundry_bbs.cert_file", "")
+	BindEnvAndSetDefault("cloud_foundry_bbs.key_file", "")
 
 	// JMXFetch
 	BindEnvAndSetDefault("jmx_custom_jars", []string{})
@@ -284,10 +369,10 @@ func init() {
 	// Internal Use Only: avoid modifying those configuration parameters, this could lead to unexpected results.
 	BindEnvAndSetDefault("logset", "")
 	BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
-	BindEnvAndSetDefault("logs_config.dd_url", "agent-intake.logs.datadoghq.com")
+	BindEnvAndSetDefault("logs_config.dd_url", defaultLogsDDURL)
 	BindEnvAndSetDefault("logs_config.dd_port", 10516)
 	BindEnvAndSetDefault("logs_config.dev_mode_use_proto", true)
-	BindEnvAndSetDefault("logs_config.dd_url_443", "agent-443-intake.logs.datadoghq.com")
+	BindEnvAndSetDefault("logs_config.dd_url_443", defaultLogsDDURL443)
 
 	// Tagger full cardinality mode
 	// Undocumented opt-in feature for now
@@ -312,11 +397,25 @@ func init() {
 	// Cluster check Autodiscovery
 	BindEnvAndSetDefault("cluster_checks.enabled", false)
 
+	// Admission controller
+	BindEnvAndSetDefault("admission_controller.enabled", false)
+	BindEnvAndSetDefault("admission_controller.port", 8000)
+	BindEnvAndSetDefault("admission_controller.service_name", "datadog-admission-controller")
+	BindEnvAndSetDefault("admission_controller.webhook_name", "datadog-webhook")
+	BindEnvAndSetDefault("admission_controller.failure_policy", "Ignore")
+	BindEnvAndSetDefault("admission_controller.mutate_unlabelled", false)
+	BindEnvAndSetDefault("admission_controller.inject_config.enabled", true)
+	BindEnvAndSetDefault("admission_controller.inject_tags.enabled", true)
+	BindEnvAndSetDefault("admission_controller.certificate.secret_name", "webhook-certificate")
+	BindEnvAndSetDefault("admission_controller.certificate.validity_bound", 365*24)      // hours
+	BindEnvAndSetDefault("admission_controller.certificate.expiration_threshold", 30*24) // hours
+
 	setAssetFs()
 }
 
 // BindEnvAndSetDefault sets the default value for a config parameter, and adds an env binding
 func BindEnvAndSetDefault(key string, val interface{}) {
+	registerOption(key, val)
 	Datadog.SetDefault(key, val)
 	Datadog.BindEnv(key)
 	if !strings.Contains(key, "_key") {
@@ -420,6 +519,14 @@ func Load() error {
 	}
 	log.Infof("config.load succeeded")
 
+	issues, err := Validate()
+	if err != nil {
+		log.Warnf("Could not validate the configuration: %v", err)
+	}
+	for _, issue := range issues {
+		log.Warnf("Invalid configuration: %s", issue)
+	}
+
 	// We have to init the secrets package before we can use it to decrypt
 	// anything.
 	secrets.Init(
@@ -428,8 +535,19 @@ func Load() error {
 		Datadog.GetInt("secret_backend_timeout"),
 		Datadog.GetInt("secret_backend_output_max_size"),
 	)
+	secrets.InitSandbox(secrets.SandboxConfig{
+		User:               Datadog.GetString("secret_backend_user"),
+		Group:              Datadog.GetString("secret_backend_group"),
+		AllowGroupExecPerm: Datadog.GetBool("secret_backend_command_allow_group_exec_perm"),
+		NoNewPrivs:         Datadog.GetBool("secret_backend_no_new_privs"),
+	})
+	backends := map[string]secrets.BackendConfig{}
+	if err := Datadog.UnmarshalKey("secret_backends", &backends); err != nil {
+		return fmt.Errorf("could not parse secret_backends: %v", err)
+	}
+	secrets.InitBackends(backends)
 
-	if Datadog.IsSet("secret_backend_command") {
+	if Datadog.IsSet("secret_backend_command") || len(backends) != 0 {
 		// Viper doesn't expose the final location of the file it
 		// loads. Since we are searching for 'datadog.yaml' in multiple
 		// localtions we let viper determine the one to use before
@@ -451,7 +569,21 @@ func Load() error {
 
 	loadProxyFromEnv()
 	sanitizeAPIKey()
-	return nil
+	resolveHostTags()
+	DetectFeatures()
+	if err := setupSiteEndpoints(Datadog); err != nil {
+		return err
+	}
+	return setupFIPSEndpoints(Datadog)
+}
+
+// Resolve the environment variable and file templates of the host tags once,
+// so every consumer of the `tags` option gets the final values
+func resolveHostTags() {
+	if !Datadog.IsSet("tags") {
+		return
+	}
+	Datadog.Set("tags", resolveTagTemplates(Datadog.GetStringSlice("tags")))
 }
 
 // Avoid log ingestion breaking because of a newline in the API key
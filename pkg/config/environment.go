@@ -0,0 +1,364 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Feature is a feature of the environment the agent runs in, detected at startup
+type Feature string
+
+// Features of the environment
+const (
+	Docker     Feature = "docker"
+	Cri        Feature = "cri"
+	Kubernetes Feature = "kubernetes"
+	ECSEC2     Feature = "ecsec2"
+	ECSFargate Feature = "ecsfargate"
+	Cgroups    Feature = "cgroups"
+)
+
+const (
+	defaultDockerSocketPath = "/var/run/docker.sock"
+	socketProbeTimeout      = time.Second
+)
+
+// Container runtimes, as set in `container_runtime`
+const (
+	RuntimeAuto       = "auto"
+	RuntimeDocker     = "docker"
+	RuntimeContainerd = "containerd"
+	RuntimeCRIO       = "cri-o"
+)
+
+// runtimeSockets are the well-known sockets of the container runtimes, in
+// detection order: docker runs on top of containerd, it's detected first
+var runtimeSockets = []struct {
+	runtime string
+	paths   []string
+}{
+	{RuntimeDocker, []string{defaultDockerSocketPath}},
+	{RuntimeContainerd, []string{"/var/run/containerd/containerd.sock", "/run/containerd/containerd.sock"}},
+	{RuntimeCRIO, []string{"/var/run/crio/crio.sock"}},
+}
+
+// RuntimeDetection reports whether a container runtime is used and why
+type RuntimeDetection struct {
+	Name     string `json:"name"`
+	Detected bool   `json:"detected"`
+	Reason   string `json:"reason"`
+}
+
+// FeatureMap is the set of the detected features
+type FeatureMap map[Feature]struct{}
+
+var (
+	detectedFeatures  FeatureMap
+	runtimeDetections []RuntimeDetection
+	featuresLock      sync.RWMutex
+
+	// for testing purpose
+	isSocketReachable  = probeSocket
+	isAddressReachable = probeAddress
+)
+
+// DetectFeatures probes the environment for the container runtimes,
+// orchestrators and resources the agent can monitor. It replaces the
+// previously detected features. Nothing is detected if
+// `autoconfig_from_environment` is disabled, and the features listed in
+// `autoconfig_exclude_features` are ignored. The runtime set in
+// `container_runtime` is used without being detected.
+func DetectFeatures() {
+	features := make(FeatureMap)
+	runtimes := detectRuntimes(Datadog.GetString("container_runtime"))
+	for _, runtime := range runtimes {
+		if !runtime.Detected {
+			log.Debugf("Container runtime %s skipped: %s", runtime.Name, runtime.Reason)
+			continue
+		}
+		log.Infof("Container runtime %s detected: %s", runtime.Name, runtime.Reason)
+		if runtime.Name == RuntimeDocker {
+			features[Docker] = struct{}{}
+		} else {
+			features[Cri] = struct{}{}
+		}
+	}
+
+	if Datadog.GetBool("autoconfig_from_environment") {
+		excluded := make(map[string]bool)
+		for _, name := range Datadog.GetStringSlice("autoconfig_exclude_features") {
+			excluded[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+
+		for feature, detect := range featureDetectors {
+			if detect() {
+				features[feature] = struct{}{}
+			}
+		}
+		for feature := range features {
+			if excluded[string(feature)] {
+				delete(features, feature)
+			}
+		}
+		log.Infof("%d features detected from environment: %s", len(features), features)
+	}
+
+	featuresLock.Lock()
+	detectedFeatures = features
+	runtimeDetections = runtimes
+	featuresLock.Unlock()
+}
+
+// detectRuntimes returns the detection of the container runtimes, in
+// detection order. A forced runtime is used even if its socket is not
+// reachable, the others are skipped. Containerd is skipped when docker is
+// detected, as docker runs its containers through its own containerd.
+func detectRuntimes(forced string) []RuntimeDetection {
+	forced = strings.ToLower(strings.TrimSpace(forced))
+	if forced == "" {
+		forced = RuntimeAuto
+	}
+	valid := forced == RuntimeAuto
+	for _, rs := range runtimeSockets {
+		valid = valid || rs.runtime == forced
+	}
+	if !valid {
+		log.Errorf("Unknown container_runtime %q, must be one of auto, docker, containerd or cri-o: detecting the runtimes", forced)
+		forced = RuntimeAuto
+	}
+
+	var detections []RuntimeDetection
+	dockerDetected := false
+	for _, rs := range runtimeSockets {
+		detection := RuntimeDetection{Name: rs.runtime}
+		switch {
+		case forced == rs.runtime:
+			detection.Detected, detection.Reason = true, "container_runtime is set to "+forced
+		case forced != RuntimeAuto:
+			detection.Reason = "container_runtime is set to " + forced
+		case !Datadog.GetBool("autoconfig_from_environment"):
+			detection.Reason = "autoconfig_from_environment is disabled"
+		case rs.runtime == RuntimeContainerd && dockerDetected:
+			detection.Reason = "docker is detected and owns the containerd socket"
+		case rs.runtime == RuntimeDocker && os.Getenv("DOCKER_HOST") != "":
+			// DOCKER_HOST overrides the default socket location
+			dockerHost := os.Getenv("DOCKER_HOST")
+			detection.Detected = isDockerHostReachable(dockerHost)
+			if detection.Detected {
+				detection.Reason = "DOCKER_HOST " + dockerHost + " is reachable"
+			} else {
+				detection.Reason = "DOCKER_HOST " + dockerHost + " is not reachable"
+			}
+		default:
+			detection.Reason = "no socket found at " + strings.Join(rs.paths, ", ")
+			for _, path := range rs.paths {
+				if hostPath := getHostPath(path); isSocketReachable(hostPath) {
+					detection.Detected, detection.Reason = true, "socket found at "+hostPath
+					break
+				}
+			}
+		}
+		if rs.runtime == RuntimeDocker {
+			dockerDetected = detection.Detected
+		}
+		detections = append(detections, detection)
+	}
+	return detections
+}
+
+// IsFeaturePresent returns whether a feature was detected by DetectFeatures
+func IsFeaturePresent(feature Feature) bool {
+	featuresLock.RLock()
+	defer featuresLock.RUnlock()
+
+	_, found := detectedFeatures[feature]
+	return found
+}
+
+// GetRuntimeDetections returns the detection of the container runtimes by
+// DetectFeatures, in detection order
+func GetRuntimeDetections() []RuntimeDetection {
+	featuresLock.RLock()
+	defer featuresLock.RUnlock()
+
+	return append([]RuntimeDetection{}, runtimeDetections...)
+}
+
+// GetDetectedFeatures returns the features detected by DetectFeatures
+func GetDetectedFeatures() FeatureMap {
+	featuresLock.RLock()
+	defer featuresLock.RUnlock()
+
+	features := make(FeatureMap, len(detectedFeatures))
+	for feature := range detectedFeatures {
+		features[feature] = struct{}{}
+	}
+	return features
+}
+
+// String returns the sorted list of the features
+func (fm FeatureMap) String() string {
+	names := make([]string, 0, len(fm))
+	for feature := range fm {
+		names = append(names, string(feature))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// featureDetectors detect the features other than the container runtimes
+var featureDetectors = map[Feature]func() bool{
+	Kubernetes: detectKubernetes,
+	ECSEC2:     detectECSEC2,
+	ECSFargate: detectECSFargate,
+	Cgroups:    detectCgroups,
+}
+
+// detectKubernetes detects the agent runs in a pod: every pod gets the
+// address of the kubernetes service, which must accept connections. The
+// KUBERNETES variable set in the agent manifests is trusted as is.
+func detectKubernetes() bool {
+	if os.Getenv("KUBERNETES") != "" {
+		return true
+	}
+	host := os.Getenv("KUBERNETES_SERVICE_HOST")
+	if host == "" {
+		return false
+	}
+	port := os.Getenv("KUBERNETES_SERVICE_PORT")
+	if port == "" {
+		port = "443"
+	}
+	return isAddressReachable(net.JoinHostPort(host, port))
+}
+
+// detectECSEC2 detects the agent runs in an ECS task on EC2, whose metadata
+// endpoint must accept connections
+func detectECSEC2() bool {
+	if detectECSFargate() {
+		return false
+	}
+	if os.Getenv("AWS_EXECUTION_ENV") != "AWS_ECS_EC2" && os.Getenv("ECS_CONTAINER_METADATA_URI") == "" {
+		return false
+	}
+	return isECSMetadataReachable()
+}
+
+// detectECSFargate detects the agent runs in a Fargate task, whose metadata
+// endpoint must accept connections. The ECS_FARGATE variable set in the agent
+// task definitions is trusted as is.
+func detectECSFargate() bool {
+	if os.Getenv("ECS_FARGATE") != "" {
+		return true
+	}
+	return os.Getenv("AWS_EXECUTION_ENV") == "AWS_ECS_FARGATE" && isECSMetadataReachable()
+}
+
+// isECSMetadataReachable returns whether the task metadata endpoint set in
+// ECS_CONTAINER_METADATA_URI accepts connections
+func isECSMetadataReachable() bool {
+	address := urlAddress(os.Getenv("ECS_CONTAINER_METADATA_URI"))
+	return address != "" && isAddressReachable(address)
+}
+
+// isDockerHostReachable returns whether the docker daemon set in DOCKER_HOST,
+// a unix socket or a tcp address, accepts connections
+func isDockerHostReachable(dockerHost string) bool {
+	if strings.HasPrefix(dockerHost, "unix://") {
+		return isSocketReachable(strings.TrimPrefix(dockerHost, "unix://"))
+	}
+	address := urlAddress(dockerHost)
+	return address != "" && isAddressReachable(address)
+}
+
+// urlAddress returns the host:port of a URL, empty if it's invalid
+func urlAddress(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// detectCgroups checks the memory cgroup controller of the host is readable
+func detectCgroups() bool {
+	f, err := os.Open(filepath.Join(Datadog.GetString("container_cgroup_root"), "memory"))
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// getHostPath returns the path of a host file, the host filesystem is
+// mounted under /host in the agent container
+func getHostPath(path string) string {
+	if IsContainerized() {
+		hostPath := filepath.Join("/host", path)
+		if _, err := os.Stat(hostPath); err == nil {
+			return hostPath
+		}
+	}
+	return path
+}
+
+// RuntimeSocket returns the first well-known socket of a container runtime
+// accepting connections, empty if none does
+func RuntimeSocket(runtime string) string {
+	for _, rs := range runtimeSockets {
+		if rs.runtime != runtime {
+			continue
+		}
+		for _, path := range rs.paths {
+			if hostPath := getHostPath(path); isSocketReachable(hostPath) {
+				return hostPath
+			}
+		}
+	}
+	return ""
+}
+
+// probeAddress returns whether a tcp address accepts connections
+func probeAddress(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, socketProbeTimeout)
+	if err != nil {
+		log.Debugf("Address %s is not reachable: %s", address, err)
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// probeSocket returns whether a unix socket accepts connections
+func probeSocket(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", path, socketProbeTimeout)
+	if err != nil {
+		log.Debugf("Socket %s is not reachable: %s", path, err)
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeSockets(paths ...string) func() {
+	reachable := make(map[string]bool)
+	for _, path := range paths {
+		reachable[path] = true
+	}
+	isSocketReachable = func(path string) bool { return reachable[path] }
+	return func() { isSocketReachable = probeSocket }
+}
+
+func fakeAddresses(addresses ...string) func() {
+	reachable := make(map[string]bool)
+	for _, address := range addresses {
+		reachable[address] = true
+	}
+	isAddressReachable = func(address string) bool { return reachable[address] }
+	return func() { isAddressReachable = probeAddress }
+}
+
+func TestDetectFeatures(t *testing.T) {
+	defer fakeSockets(defaultDockerSocketPath)()
+	defer fakeAddresses("10.0.0.1:443")()
+	os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	defer os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	defer Datadog.Set("container_cgroup_root", Datadog.GetString("container_cgroup_root"))
+	Datadog.Set("container_cgroup_root", "/does/not/exist")
+
+	DetectFeatures()
+	assert.True(t, IsFeaturePresent(Docker))
+	assert.True(t, IsFeaturePresent(Kubernetes))
+	assert.False(t, IsFeaturePresent(Cri))
+	assert.False(t, IsFeaturePresent(ECSFargate))
+	assert.False(t, IsFeaturePresent(Cgroups))
+	assert.Equal(t, "docker,kubernetes", GetDetectedFeatures().String())
+}
+
+func TestDetectFeaturesExcluded(t *testing.T) {
+	defer fakeSockets(defaultDockerSocketPath, "/var/run/crio/crio.sock")()
+	Datadog.Set("autoconfig_exclude_features", []string{"Docker "})
+	defer Datadog.Set("autoconfig_exclude_features", []string{})
+
+	DetectFeatures()
+	assert.False(t, IsFeaturePresent(Docker))
+	assert.True(t, IsFeaturePresent(Cri))
+}
+
+func TestDetectFeaturesDisabled(t *testing.T) {
+	defer fakeSockets(defaultDockerSocketPath)()
+	Datadog.Set("autoconfig_from_environment", false)
+	defer Datadog.Set("autoconfig_from_environment", true)
+
+	DetectFeatures()
+	assert.False(t, IsFeaturePresent(Docker))
+	assert.Empty(t, GetDetectedFeatures())
+}
+
+func TestDetectKubernetes(t *testing.T) {
+	defer fakeAddresses("10.0.0.1:6443")()
+	os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	defer os.Unsetenv("KUBERNETES_SERVICE_HOST")
+
+	// the service must accept connections
+	assert.False(t, detectKubernetes())
+	os.Setenv("KUBERNETES_SERVICE_PORT", "6443")
+	defer os.Unsetenv("KUBERNETES_SERVICE_PORT")
+	assert.True(t, detectKubernetes())
+}
+
+func TestDetectECS(t *testing.T) {
+	defer fakeAddresses("169.254.170.2:80")()
+	os.Setenv("AWS_EXECUTION_ENV", "AWS_ECS_FARGATE")
+	defer os.Unsetenv("AWS_EXECUTION_ENV")
+
+	// the metadata endpoint must accept connections
+	assert.False(t, detectECSFargate())
+	os.Setenv("ECS_CONTAINER_METADATA_URI", "http://169.254.170.2/v3/abc")
+	defer os.Unsetenv("ECS_CONTAINER_METADATA_URI")
+	assert.True(t, detectECSFargate())
+	assert.False(t, detectECSEC2())
+
+	os.Setenv("AWS_EXECUTION_ENV", "AWS_ECS_EC2")
+	assert.False(t, detectECSFargate())
+	assert.True(t, detectECSEC2())
+
+	os.Setenv("ECS_CONTAINER_METADATA_URI", "http://169.254.170.3/v3/abc")
+	assert.False(t, detectECSEC2())
+}
+
+func TestDetectDockerHost(t *testing.T) {
+	defer fakeSockets("/tmp/docker.sock")()
+	defer fakeAddresses("10.0.0.2:2375")()
+	defer os.Unsetenv("DOCKER_HOST")
+
+	for dockerHost, detected := range map[string]bool{
+		"unix:///tmp/docker.sock":   true,
+		"unix:///var/run/dind.sock": false,
+		"tcp://10.0.0.2:2375":       true,
+		"tcp://10.0.0.3:2375":       false,
+	} {
+		os.Setenv("DOCKER_HOST", dockerHost)
+		assert.Equal(t, detected, detectRuntimes("auto")[0].Detected, dockerHost)
+	}
+}
+
+func TestDetectRuntimes(t *testing.T) {
+	defer fakeSockets("/run/containerd/containerd.sock")()
+
+	assert.Equal(t, []RuntimeDetection{
+		{Name: RuntimeDocker, Reason: "no socket found at /var/run/docker.sock"},
+		{Name: RuntimeContainerd, Detected: true, Reason: "socket found at /run/containerd/containerd.sock"},
+		{Name: RuntimeCRIO, Reason: "no socket found at /var/run/crio/crio.sock"},
+	}, detectRuntimes("auto"))
+
+	// an unknown runtime falls back to the detection
+	assert.Equal(t, detectRuntimes("auto"), detectRuntimes("rkt"))
+}
+
+func TestDetectRuntimesDockerContainerd(t *testing.T) {
+	defer fakeSockets(defaultDockerSocketPath, "/run/containerd/containerd.sock")()
+
+	assert.Equal(t, []RuntimeDetection{
+		{Name: RuntimeDocker, Detected: true, Reason: "socket found at /var/run/docker.sock"},
+		{Name: RuntimeContainerd, Reason: "docker is detected and owns the containerd socket"},
+		{Name: RuntimeCRIO, Reason: "no socket found at /var/run/crio/crio.sock"},
+	}, detectRuntimes("auto"))
+
+	// a forced containerd is used even if docker runs
+	assert.True(t, detectRuntimes("containerd")[1].Detected)
+}
+
+func TestRuntimeSocket(t *testing.T) {
+	defer fakeSockets("/run/containerd/containerd.sock")()
+
+	assert.Equal(t, "/run/containerd/containerd.sock", RuntimeSocket(RuntimeContainerd))
+	assert.Equal(t, "", RuntimeSocket(RuntimeDocker))
+	assert.Equal(t, "", RuntimeSocket("rkt"))
+}
+
+func TestDetectRuntimesForced(t *testing.T) {
+	defer fakeSockets("/run/containerd/containerd.sock")()
+
+	assert.Equal(t, []RuntimeDetection{
+		{Name: RuntimeDocker, Reason: "container_runtime is set to cri-o"},
+		{Name: RuntimeContainerd, Reason: "container_runtime is set to cri-o"},
+		{Name: RuntimeCRIO, Detected: true, Reason: "container_runtime is set to cri-o"},
+	}, detectRuntimes("CRI-O"))
+
+	defer Datadog.Set("container_runtime", RuntimeAuto)
+	Datadog.Set("container_runtime", RuntimeCRIO)
+	DetectFeatures()
+	assert.True(t, IsFeaturePresent(Cri))
+	assert.False(t, IsFeaturePresent(Docker))
+	assert.Len(t, GetRuntimeDetections(), 3)
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/spf13/viper"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Offsets from `fips.port_range_start` of the ports the local FIPS proxy
+// listens on, one per intake
+const (
+	fipsMetricsPortOffset   = 1
+	fipsTracesPortOffset    = 2
+	fipsProcessesPortOffset = 3
+	fipsLogsPortOffset      = 4
+)
+
+// setupFIPSEndpoints routes the traffic of every intake to its port of the
+// local FIPS proxy, which forwards it to Datadog with FIPS validated
+// cryptography.
+func setupFIPSEndpoints(config *viper.Viper) error {
+	if !config.GetBool("fips.enabled") {
+		return nil
+	}
+
+	host := NormalizeHost(config.GetString("fips.local_address"))
+	portRangeStart := config.GetInt("fips.port_range_start")
+	if portRangeStart <= 0 || portRangeStart+fipsLogsPortOffset > 65535 {
+		return fmt.Errorf("invalid fips.port_range_start: %d", portRangeStart)
+	}
+	scheme := "http"
+	if config.GetBool("fips.https") {
+		scheme = "https"
+	}
+
+	address := func(offset int) string {
+		return net.JoinHostPort(host, strconv.Itoa(portRangeStart+offset))
+	}
+
+	config.Set("dd_url", fmt.Sprintf("%s://%s", scheme, address(fipsMetricsPortOffset)))
+	config.Set("apm_config.apm_dd_url", fmt.Sprintf("%s://%s", scheme, address(fipsTracesPortOffset)))
+	config.Set("process_config.process_dd_url", fmt.Sprintf("%s://%s", scheme, address(fipsProcessesPortOffset)))
+	config.Set("logs_config.logs_dd_url", address(fipsLogsPortOffset))
+	config.Set("logs_config.logs_no_ssl", !config.GetBool("fips.https"))
+
+	if config.IsSet("additional_endpoints") {
+		log.Warnf("fips.enabled is set, the additional_endpoints are still reached directly")
+	}
+	return nil
+}
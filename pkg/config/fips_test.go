@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupFIPSEndpointsDisabled(t *testing.T) {
+	testConfig := setupViperConf(`dd_url: "https://app.datadoghq.com"`)
+
+	require.NoError(t, setupFIPSEndpoints(testConfig))
+	assert.Equal(t, "https://app.datadoghq.com", testConfig.GetString("dd_url"))
+}
+
+func TestSetupFIPSEndpoints(t *testing.T) {
+	testConfig := setupViperConf(`
+fips:
+  enabled: true
+  local_address: 127.0.0.1
+  port_range_start: 5000
+  https: false
+`)
+
+	require.NoError(t, setupFIPSEndpoints(testConfig))
+	assert.Equal(t, "http://127.0.0.1:5001", testConfig.GetString("dd_url"))
+	assert.Equal(t, "http://127.0.0.1:5002", testConfig.GetString("apm_config.apm_dd_url"))
+	assert.Equal(t, "http://127.0.0.1:5003", testConfig.GetString("process_config.process_dd_url"))
+	assert.Equal(t, "127.0.0.1:5004", testConfig.GetString("logs_config.logs_dd_url"))
+	assert.True(t, testConfig.GetBool("logs_config.logs_no_ssl"))
+}
+
+func TestSetupFIPSEndpointsInvalidPort(t *testing.T) {
+	testConfig := setupViperConf(`
+fips:
+  enabled: true
+  port_range_start: 65534
+`)
+
+	assert.Error(t, setupFIPSEndpoints(testConfig))
+}
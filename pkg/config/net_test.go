@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	for host, expected := range map[string]string{
+		"localhost":          "localhost",
+		"127.0.0.1":          "127.0.0.1",
+		"::1":                "::1",
+		"[::1]":              "::1",
+		" [fd00::10:1] ":     "fd00::10:1",
+		"[not-closed":        "[not-closed",
+		"datadoghq.internal": "datadoghq.internal",
+	} {
+		assert.Equal(t, expected, NormalizeHost(host), host)
+	}
+
+	assert.Equal(t, "[::1]:8125", net.JoinHostPort(NormalizeHost("[::1]"), "8125"))
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	defaultSite = "datadoghq.com"
+
+	defaultDDURL          = "https://app." + defaultSite
+	defaultLogsDDURL      = "agent-intake.logs." + defaultSite
+	defaultLogsDDURL443   = "agent-443-intake.logs." + defaultSite
+	defaultTracesDDURL    = "https://trace.agent." + defaultSite
+	defaultProcessesDDURL = "https://process." + defaultSite
+)
+
+// setupSiteEndpoints points every intake to the Datadog site configured with
+// `site`, like `datadoghq.eu`. The endpoints explicitly configured are kept:
+// a key is rewritten only if it still has its default value.
+func setupSiteEndpoints(config *viper.Viper) error {
+	site := strings.Trim(strings.TrimSpace(config.GetString("site")), ".")
+	if site == "" || site == defaultSite {
+		return nil
+	}
+	if _, err := url.Parse("https://app." + site); err != nil || strings.ContainsAny(site, "/:") {
+		return fmt.Errorf("invalid site: %s", site)
+	}
+
+	endpoints := []struct {
+		key          string
+		defaultValue string
+		value        string
+	}{
+		{"dd_url", defaultDDURL, "https://app." + site},
+		{"logs_config.dd_url", defaultLogsDDURL, "agent-intake.logs." + site},
+		{"logs_config.dd_url_443", defaultLogsDDURL443, "agent-443-intake.logs." + site},
+		{"apm_config.apm_dd_url", defaultTracesDDURL, "https://trace.agent." + site},
+		{"process_config.process_dd_url", defaultProcessesDDURL, "https://process." + site},
+	}
+	for _, e := range endpoints {
+		if current := config.GetString(e.key); current != "" && current != e.defaultValue {
+			log.Infof("%s is set, it has precedence over the site %s", e.key, site)
+			continue
+		}
+		config.Set(e.key, e.value)
+	}
+
+	// only the known Datadog domains are prefixed with the agent version
+	if _, found := ddURLs["app."+site]; !found {
+		log.Infof("%s is not a known Datadog site, its domain won't be prefixed with the agent version", site)
+	}
+	return nil
+}
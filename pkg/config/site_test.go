@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupSiteEndpointsDefault(t *testing.T) {
+	testConfig := setupViperConf(`dd_url: "https://app.datadoghq.com"`)
+
+	require.NoError(t, setupSiteEndpoints(testConfig))
+	assert.Equal(t, "https://app.datadoghq.com", testConfig.GetString("dd_url"))
+	assert.Equal(t, "", testConfig.GetString("logs_config.dd_url"))
+}
+
+func TestSetupSiteEndpoints(t *testing.T) {
+	testConfig := setupViperConf(`
+site: datadoghq.eu
+dd_url: "https://app.datadoghq.com"
+process_config:
+  process_dd_url: "https://process.myproxy.com"
+`)
+
+	require.NoError(t, setupSiteEndpoints(testConfig))
+	assert.Equal(t, "https://app.datadoghq.eu", testConfig.GetString("dd_url"))
+	assert.Equal(t, "agent-intake.logs.datadoghq.eu", testConfig.GetString("logs_config.dd_url"))
+	assert.Equal(t, "agent-443-intake.logs.datadoghq.eu", testConfig.GetString("logs_config.dd_url_443"))
+	assert.Equal(t, "https://trace.agent.datadoghq.eu", testConfig.GetString("apm_config.apm_dd_url"))
+	// explicitly configured endpoints are kept
+	assert.Equal(t, "https://process.myproxy.com", testConfig.GetString("process_config.process_dd_url"))
+}
+
+func TestSetupSiteEndpointsUnknownSite(t *testing.T) {
+	testConfig := setupViperConf(`site: datadoghq.example`)
+
+	require.NoError(t, setupSiteEndpoints(testConfig))
+	assert.Equal(t, "https://app.datadoghq.example", testConfig.GetString("dd_url"))
+	// the known domains are left untouched
+	assert.NotContains(t, ddURLs, "app.datadoghq.example")
+	newURL, err := AddAgentVersionToDomain("https://app.datadoghq.example", "app")
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.datadoghq.example", newURL)
+}
+
+func TestSetupSiteEndpointsInvalid(t *testing.T) {
+	testConfig := setupViperConf(`site: "https://datadoghq.eu"`)
+
+	assert.Error(t, setupSiteEndpoints(testConfig))
+}
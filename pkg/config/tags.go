@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxTagFileSize is the maximum size of a file a tag value is read from
+const maxTagFileSize = 1024
+
+// tagFilePrefix prefixes the path of the file a tag value is read from
+const tagFilePrefix = "@file:"
+
+// tagEnvVarRegexp matches the `${ENV_VAR}` templates in tag values
+var tagEnvVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveTagTemplates resolves the templated values of the host tags:
+//   - `env:${DEPLOY_ENV}` is replaced by the value of the DEPLOY_ENV
+//     environment variable
+//   - `rack:@file:/etc/rack-id` is replaced by the trimmed content of the
+//     /etc/rack-id file, other values starting with @ are kept as is
+//
+// Tags that cannot be resolved are dropped with a warning, to avoid
+// sending tags with an empty or partial value.
+func resolveTagTemplates(tags []string) []string {
+	resolved := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		value, err := resolveTagTemplate(tag)
+		if err != nil {
+			log.Warnf("Dropping host tag %q: %s", tag, err)
+			continue
+		}
+		resolved = append(resolved, value)
+	}
+	return resolved
+}
+
+func resolveTagTemplate(tag string) (string, error) {
+	var missing []string
+	tag = tagEnvVarRegexp.ReplaceAllStringFunc(tag, func(match string) string {
+		name := tagEnvVarRegexp.FindStringSubmatch(match)[1]
+		value, found := os.LookupEnv(name)
+		if !found || value == "" {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable(s) not set: %s", strings.Join(missing, ", "))
+	}
+
+	parts := strings.SplitN(tag, ":", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], tagFilePrefix) {
+		return tag, nil
+	}
+
+	path := strings.TrimPrefix(parts[1], tagFilePrefix)
+	value, err := readTagFile(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", parts[0], value), nil
+}
+
+func readTagFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(&io.LimitedReader{R: f, N: maxTagFileSize + 1})
+	if err != nil {
+		return "", err
+	}
+	if len(content) > maxTagFileSize {
+		return "", fmt.Errorf("%s is bigger than %d bytes", path, maxTagFileSize)
+	}
+
+	value := strings.TrimSpace(string(content))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTagTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	rackFile := filepath.Join(dir, "rack-id")
+	require.NoError(t, ioutil.WriteFile(rackFile, []byte("r42\n"), 0644))
+	emptyFile := filepath.Join(dir, "empty")
+	require.NoError(t, ioutil.WriteFile(emptyFile, []byte("  \n"), 0644))
+	bigFile := filepath.Join(dir, "big")
+	require.NoError(t, ioutil.WriteFile(bigFile, []byte(strings.Repeat("a", maxTagFileSize+1)), 0644))
+
+	os.Setenv("TEST_DEPLOY_ENV", "staging")
+	os.Setenv("TEST_REGION", "us-east-1")
+	defer os.Unsetenv("TEST_DEPLOY_ENV")
+	defer os.Unsetenv("TEST_REGION")
+
+	tags := []string{
+		"static:value",
+		"standalone",
+		"env:${TEST_DEPLOY_ENV}",
+		"location:${TEST_REGION}-${TEST_DEPLOY_ENV}",
+		"missing:${TEST_NOT_SET}",
+		"rack:@file:" + rackFile,
+		"empty:@file:" + emptyFile,
+		"big:@file:" + bigFile,
+		"nofile:@file:" + filepath.Join(dir, "not-found"),
+		"email:foo@bar.com",
+		"team:@oncall",
+		"path:@" + rackFile,
+	}
+	expected := []string{
+		"static:value",
+		"standalone",
+		"env:staging",
+		"location:us-east-1-staging",
+		"rack:r42",
+		"email:foo@bar.com",
+		"team:@oncall",
+		"path:@" + rackFile,
+	}
+	assert.Equal(t, expected, resolveTagTemplates(tags))
+}
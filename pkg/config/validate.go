@@ -0,0 +1,249 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// optionType is the type of the value of a configuration option
+type optionType int
+
+const (
+	anyOption optionType = iota
+	boolOption
+	stringOption
+	numberOption
+	listOption
+	mapOption
+)
+
+func (t optionType) String() string {
+	switch t {
+	case boolOption:
+		return "a boolean"
+	case stringOption:
+		return "a string"
+	case numberOption:
+		return "a number"
+	case listOption:
+		return "a list"
+	case mapOption:
+		return "a map"
+	}
+	return "any value"
+}
+
+// optionTypeOf returns the type of an option from its default value
+func optionTypeOf(val interface{}) optionType {
+	if val == nil {
+		return anyOption
+	}
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Bool:
+		return boolOption
+	case reflect.String:
+		return stringOption
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return numberOption
+	case reflect.Slice, reflect.Array:
+		return listOption
+	case reflect.Map:
+		return mapOption
+	}
+	return anyOption
+}
+
+// accepts returns whether a value of the configuration file can be used as
+// an option of this type, the values are cast like viper does
+func (t optionType) accepts(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	var err error
+	switch t {
+	case boolOption:
+		_, err = cast.ToBoolE(value)
+	case stringOption:
+		_, err = cast.ToStringE(value)
+	case numberOption:
+		_, err = cast.ToFloat64E(value)
+	case listOption:
+		// a string is split on the spaces
+		kind := reflect.ValueOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.String
+	case mapOption:
+		return reflect.ValueOf(value).Kind() == reflect.Map
+	}
+	return err == nil
+}
+
+// knownOptions are the options of the configuration file and their type, the
+// options with a default value are registered by BindEnvAndSetDefault
+var knownOptions = map[string]optionType{
+	"api_key":                       stringOption,
+	"proxy":                         mapOption,
+	"additional_endpoints":          mapOption,
+	"secret_backend_command":        stringOption,
+	"secret_backend_arguments":      listOption,
+	"secret_backends":               mapOption,
+	"procfs_path":                   stringOption,
+	"container_proc_root":           stringOption,
+	"container_cgroup_root":         stringOption,
+	"config_providers":              listOption,
+	"listeners":                     listOption,
+	"metadata_providers":            listOption,
+	"autoconf_template_url_timeout": numberOption,
+	"jmx_pipe_path":                 stringOption,
+	"jmx_pipe_name":                 stringOption,
+	"logs_config.dev_mode_no_ssl":   boolOption,
+	"apm_enabled":                   boolOption,
+	"process_agent_enabled":         boolOption,
+	// sections parsed by the trace and process agents
+	"apm_config":     mapOption,
+	"process_config": mapOption,
+}
+
+// deprecatedOptions are the deprecated options and their replacement
+var deprecatedOptions = map[string]string{
+	"log_enabled":           "logs_enabled",
+	"apm_enabled":           "apm_config.enabled",
+	"process_agent_enabled": "process_config.enabled",
+}
+
+// registerOption adds an option to the known options, the type of its
+// default value is expected in the configuration file
+func registerOption(key string, val interface{}) {
+	key = strings.ToLower(key)
+	if _, found := knownOptions[key]; found {
+		return
+	}
+	knownOptions[key] = optionTypeOf(val)
+}
+
+// Validate checks the options of the configuration file loaded by Load. It
+// returns a description of the unknown options, of the options which value
+// has the wrong type and of the deprecated options, viper ignores them.
+func Validate() ([]string, error) {
+	path := Datadog.ConfigFileUsed()
+	if path == "" {
+		return nil, nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return validateSettings("", settings), nil
+}
+
+// validateSettings checks the options of a section of the configuration, the
+// maps of the known options are not checked as their keys are free
+func validateSettings(prefix string, settings map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(settings))
+	values := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		key := prefix + strings.ToLower(fmt.Sprint(k))
+		keys = append(keys, key)
+		values[key] = v
+	}
+	sort.Strings(keys)
+
+	var issues []string
+	for _, key := range keys {
+		value := values[key]
+		if replacement, found := deprecatedOptions[key]; found {
+			issues = append(issues, fmt.Sprintf("option %q is deprecated, use %q instead", key, replacement))
+		}
+
+		if t, found := knownOptions[key]; found {
+			if !t.accepts(value) {
+				issues = append(issues, fmt.Sprintf("option %q should be %s, got %v", key, t, value))
+			}
+			continue
+		}
+
+		if section, ok := value.(map[interface{}]interface{}); ok && isSection(key) {
+			issues = append(issues, validateSettings(key+".", section)...)
+			continue
+		}
+
+		if suggestion := closestOption(key); suggestion != "" {
+			issues = append(issues, fmt.Sprintf("unknown option %q, did you mean %q?", key, suggestion))
+		} else {
+			issues = append(issues, fmt.Sprintf("unknown option %q", key))
+		}
+	}
+	return issues
+}
+
+// isSection returns whether known options are nested under a key
+func isSection(key string) bool {
+	for option := range knownOptions {
+		if strings.HasPrefix(option, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// closestOption returns the known option with the closest name to an unknown
+// one, if it is likely a typo
+func closestOption(key string) string {
+	const maxDistance = 2
+
+	closest, closestDistance := "", maxDistance+1
+	for option := range knownOptions {
+		d := levenshtein(key, option)
+		if d < closestDistance || (d == closestDistance && option < closest) {
+			closest, closestDistance = option, d
+		}
+	}
+	return closest
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
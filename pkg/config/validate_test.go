@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestValidateSettings(t *testing.T) {
+	settings := map[interface{}]interface{}{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+api_key: abcdef
+log_levell: debug
+log_enabled: true
+logs_enabled: enabled
+dogstatsd_port: "8125"
+histogram_percentiles: ["0.95"]
+tags: team:agent env:prod
+logs_config:
+  container_collect_all: true
+  unknown_option_of_logs: 12
+docker_labels_as_tags:
+  com.example.team: team
+apm_config:
+  apm_dd_url: https://trace.example.com
+proxy:
+  https: http://proxy.example.com:3128
+cluster_agent:
+  url: [a, b]
+kubernetes_collect_service_tags: true
+kubernetes_service_tag_update_freq: 30
+`), &settings))
+
+	assert.Equal(t, []string{
+		`option "cluster_agent.url" should be a string, got [a b]`,
+		`option "log_enabled" is deprecated, use "logs_enabled" instead`,
+		`unknown option "log_levell", did you mean "log_level"?`,
+		`unknown option "logs_config.unknown_option_of_logs"`,
+		`option "logs_enabled" should be a boolean, got enabled`,
+	}, validateSettings("", settings))
+}
+
+func TestOptionTypeOf(t *testing.T) {
+	assert.Equal(t, boolOption, optionTypeOf(true))
+	assert.Equal(t, stringOption, optionTypeOf(""))
+	assert.Equal(t, numberOption, optionTypeOf(8125))
+	assert.Equal(t, numberOption, optionTypeOf(0.5))
+	assert.Equal(t, listOption, optionTypeOf([]string{}))
+	assert.Equal(t, mapOption, optionTypeOf(map[string]string{}))
+	assert.Equal(t, anyOption, optionTypeOf(nil))
+}
+
+func TestClosestOption(t *testing.T) {
+	assert.Equal(t, "log_level", closestOption("log_levell"))
+	assert.Equal(t, "dogstatsd_port", closestOption("dogstatsd_prot"))
+	assert.Equal(t, "", closestOption("definitely_not_an_option"))
+}
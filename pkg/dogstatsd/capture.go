@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const maxCaptureDuration = 10 * time.Minute
+
+// trafficCapture holds the state of a running capture
+type trafficCapture struct {
+	file   *os.File
+	writer *replay.Writer
+	timer  *time.Timer
+}
+
+// StartCapture records the incoming dogstatsd packets, with their origin,
+// to a new capture file for the given duration. It returns the path of the
+// capture file.
+func (s *Server) StartCapture(duration time.Duration) (string, error) {
+	if duration <= 0 || duration > maxCaptureDuration {
+		return "", fmt.Errorf("the capture duration must be between 0 and %s", maxCaptureDuration)
+	}
+
+	s.captureMutex.Lock()
+	defer s.captureMutex.Unlock()
+
+	if s.capture != nil {
+		return "", fmt.Errorf("a capture is already running to %s", s.capture.file.Name())
+	}
+
+	location := config.Datadog.GetString("dogstatsd_capture_path")
+	if location == "" {
+		location = os.TempDir()
+	}
+	path := filepath.Join(location, fmt.Sprintf("datadog-capture-%d", time.Now().Unix()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("could not create the capture file: %s", err)
+	}
+	writer, err := replay.NewWriter(file)
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("could not write the capture file: %s", err)
+	}
+
+	s.capture = &trafficCapture{
+		file:   file,
+		writer: writer,
+		timer:  time.AfterFunc(duration, s.StopCapture),
+	}
+	log.Infof("Dogstatsd: capturing the traffic to %s for %s", path, duration)
+	return path, nil
+}
+
+// StopCapture stops the running capture, if any
+func (s *Server) StopCapture() {
+	s.captureMutex.Lock()
+	defer s.captureMutex.Unlock()
+
+	if s.capture == nil {
+		return
+	}
+	s.capture.timer.Stop()
+	if err := s.capture.writer.Flush(); err != nil {
+		log.Errorf("Dogstatsd: could not flush the capture file: %s", err)
+	}
+	s.capture.file.Close()
+	log.Infof("Dogstatsd: capture to %s done", s.capture.file.Name())
+	s.capture = nil
+}
+
+// capturePacket records a packet if a capture is running
+func (s *Server) capturePacket(contents []byte, origin string) {
+	s.captureMutex.RLock()
+	defer s.captureMutex.RUnlock()
+
+	if s.capture == nil {
+		return
+	}
+	if err := s.capture.writer.Write(time.Now(), origin, contents); err != nil {
+		log.Warnf("Dogstatsd: could not capture packet: %s", err)
+	}
+}
+
+// Replay feeds the packets of a capture file back through the pipeline, with
+// their original origin and at the pace they were received. The file header
+// is validated synchronously, the packets are replayed in the background.
+func (s *Server) Replay(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	reader, err := replay.NewReader(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	go func() {
+		defer file.Close()
+		count, err := s.replay(reader)
+		if err != nil {
+			log.Errorf("Dogstatsd: replay of %s stopped after %d packets: %s", path, count, err)
+			return
+		}
+		log.Infof("Dogstatsd: replayed %d packets from %s", count, path)
+	}()
+	return nil
+}
+
+func (s *Server) replay(reader *replay.Reader) (int, error) {
+	var previous time.Time
+	count := 0
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return count, err
+		}
+
+		if !previous.IsZero() {
+			time.Sleep(record.Timestamp.Sub(previous))
+		}
+		previous = record.Timestamp
+
+		packet := s.packetPool.Get()
+		packet.Contents = append(packet.Contents[:0], record.Contents...)
+		packet.Origin = record.Origin
+		select {
+		case s.packetIn <- packet:
+		case <-s.stopChan:
+			return count, nil
+		}
+		count++
+	}
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestCaptureAndReplay(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	dir, err := ioutil.TempDir("", "dsd-capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config.Datadog.Set("dogstatsd_capture_path", dir)
+	defer config.Datadog.Set("dogstatsd_capture_path", "")
+
+	metricOut := make(chan *metrics.MetricSample)
+	eventOut := make(chan metrics.Event)
+	serviceOut := make(chan metrics.ServiceCheck)
+	s, err := NewServer(metricOut, eventOut, serviceOut)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	_, err = s.StartCapture(0)
+	assert.Error(t, err)
+
+	path, err := s.StartCapture(time.Minute)
+	require.NoError(t, err)
+	_, err = s.StartCapture(time.Minute)
+	assert.Error(t, err, "only one capture should run at a time")
+
+	conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	conn.Write([]byte("daemon:666|g"))
+	select {
+	case res := <-metricOut:
+		assert.Equal(t, "daemon", res.Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+	s.StopCapture()
+
+	require.NoError(t, s.Replay(path))
+	select {
+	case res := <-metricOut:
+		assert.Equal(t, "daemon", res.Name)
+		assert.EqualValues(t, 666.0, res.Value)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+
+	assert.Error(t, s.Replay(dir+"/missing"))
+}
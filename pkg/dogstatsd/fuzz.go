@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build gofuzz
+
+package dogstatsd
+
+import (
+	"bytes"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+var fuzzParser = newParser()
+
+// Fuzz is the entry point of go-fuzz, it parses a packet like the server
+// workers do:
+//
+//   go-fuzz-build github.com/DataDog/datadog-agent/pkg/dogstatsd
+//   go-fuzz -bin=dogstatsd-fuzz.zip -workdir=fuzz
+func Fuzz(data []byte) int {
+	parsed := 0
+	for {
+		message := nextMessage(&data)
+		if message == nil {
+			break
+		}
+
+		var err error
+		switch {
+		case bytes.HasPrefix(message, []byte("_sc")):
+			_, err = parseServiceCheckMessage(message)
+		case bytes.HasPrefix(message, []byte("_e")):
+			_, err = parseEventMessage(message)
+		default:
+			var sample *metrics.MetricSample
+			if sample, err = fuzzParser.parseMetricMessage(message, "", "default-hostname"); err == nil {
+				metrics.PutMetricSample(sample)
+			}
+		}
+		if err == nil {
+			parsed++
+		}
+	}
+
+	// favor the inputs containing valid messages
+	if parsed > 0 {
+		return 1
+	}
+	return 0
+}
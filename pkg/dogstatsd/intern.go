@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+// internerMaxSize is the number of strings interned by a parser before it
+// starts over
+const internerMaxSize = 4096
+
+// stringInterner returns the same string for the identical byte slices, to
+// only allocate the names, tags and values of the messages the first time
+// they are seen. It's reset once full, the high cardinality fields would make
+// it grow forever otherwise. It's not thread safe.
+type stringInterner struct {
+	strings map[string]string
+	maxSize int
+}
+
+func newStringInterner(maxSize int) *stringInterner {
+	return &stringInterner{
+		strings: make(map[string]string),
+		maxSize: maxSize,
+	}
+}
+
+// loadOrStore returns the interned string of key, looking it up doesn't
+// allocate
+func (i *stringInterner) loadOrStore(key []byte) string {
+	if s, found := i.strings[string(key)]; found {
+		return s
+	}
+	if len(i.strings) >= i.maxSize {
+		i.strings = make(map[string]string)
+	}
+	s := string(key)
+	i.strings[s] = s
+	return s
+}
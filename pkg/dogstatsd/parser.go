@@ -10,7 +10,10 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
+	"unsafe"
 
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -31,6 +34,12 @@ var metricTypes = map[string]metrics.MetricType{
 var tagSeparator = []byte(",")
 var fieldSeparator = []byte("|")
 var valueSeparator = []byte(":")
+var hostTagPrefix = []byte("host:")
+
+// entityIDTagPrefix is the tag the client libraries set to the UID of their
+// pod, usually injected by the downward API, so that their metrics get the
+// pod tags when the origin of the packets can't be detected, like over UDP.
+const entityIDTagPrefix = "dd.internal.entity_id:"
 
 func nextMessage(packet *[]byte) (message []byte) {
 	if len(*packet) == 0 {
@@ -71,8 +80,8 @@ func parseTags(rawTags []byte, extractHost bool, defaultHostname string) ([]stri
 	var tag []byte
 	for {
 		tag, remainder = nextField(remainder, tagSeparator)
-		if extractHost && bytes.HasPrefix(tag, []byte("host:")) {
-			host = string(tag[5:])
+		if extractHost && bytes.HasPrefix(tag, hostTagPrefix) {
+			host = string(tag[len(hostTagPrefix):])
 		} else {
 			tagsList = append(tagsList, string(tag))
 		}
@@ -84,6 +93,27 @@ func parseTags(rawTags []byte, extractHost bool, defaultHostname string) ([]stri
 	return tagsList, host
 }
 
+// extractEntityID removes the entity ID tag from the tags and returns its
+// entity name, if any
+func extractEntityID(tags []string) ([]string, string) {
+	for i, tag := range tags {
+		if strings.HasPrefix(tag, entityIDTagPrefix) {
+			entityID := tag[len(entityIDTagPrefix):]
+			return append(tags[:i], tags[i+1:]...), entityName(entityID)
+		}
+	}
+	return tags, ""
+}
+
+// entityName returns the tagger entity name of an entity ID sent by a client:
+// a pod UID, or an entity name already prefixed by its kind
+func entityName(entityID string) string {
+	if entityID == "" || strings.Contains(entityID, "://") {
+		return entityID
+	}
+	return kubelet.PodUIDToEntityName(entityID)
+}
+
 func parseServiceCheckMessage(message []byte) (*metrics.ServiceCheck, error) {
 	// _sc|name|status|[metadata|...]
 
@@ -128,7 +158,13 @@ func parseServiceCheckMessage(message []byte) (*metrics.ServiceCheck, error) {
 		} else if bytes.HasPrefix(rawMetadataField, []byte("h:")) {
 			service.Host = string(rawMetadataField[2:])
 		} else if bytes.HasPrefix(rawMetadataField, []byte("#")) {
+			var entity string
 			service.Tags, _ = parseTags(rawMetadataField[1:], false, "")
+			if service.Tags, entity = extractEntityID(service.Tags); entity != "" && service.OriginID == "" {
+				service.OriginID = entity
+			}
+		} else if bytes.HasPrefix(rawMetadataField, []byte("c:")) {
+			service.OriginID = entityName(string(rawMetadataField[2:]))
 		} else if bytes.HasPrefix(rawMetadataField, []byte("m:")) {
 			service.Message = string(rawMetadataField[2:])
 		} else {
@@ -171,7 +207,11 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Invalid message format, could not parse text.length: '%s'", rawLen[0])
 	}
-	if titleLen+textLen+1 > int64(len(message)) {
+	if titleLen < 0 || textLen < 0 {
+		return nil, fmt.Errorf("Invalid message format, title.length and text.length can't be negative")
+	}
+	// compare the lengths separately first, their sum could overflow
+	if titleLen > int64(len(message)) || textLen > int64(len(message)) || titleLen+textLen+1 > int64(len(message)) {
 		return nil, fmt.Errorf("Invalid message format, title.length and text.length exceed total message length")
 	}
 
@@ -223,7 +263,13 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 			} else if bytes.HasPrefix(rawMetadataFields[i], []byte("s:")) {
 				event.SourceTypeName = string(rawMetadataFields[i][2:])
 			} else if bytes.HasPrefix(rawMetadataFields[i], []byte("#")) {
+				var entity string
 				event.Tags, _ = parseTags(rawMetadataFields[i][1:], false, "")
+				if event.Tags, entity = extractEntityID(event.Tags); entity != "" && event.OriginID == "" {
+					event.OriginID = entity
+				}
+			} else if bytes.HasPrefix(rawMetadataFields[i], []byte("c:")) {
+				event.OriginID = entityName(string(rawMetadataFields[i][2:]))
 			} else {
 				log.Warnf("unknown metadata type: '%s'", rawMetadataFields[i])
 			}
@@ -233,12 +279,55 @@ func parseEventMessage(message []byte) (*metrics.Event, error) {
 	return &event, nil
 }
 
-func parseMetricMessage(message []byte, namespace string, defaultHostname string) (*metrics.MetricSample, error) {
+// parser parses the metric messages of a dogstatsd worker without allocating
+// in the steady state: the samples come from the pool of the aggregator, the
+// fields are parsed from the messages bytes and the strings of their names,
+// tags and values are interned. It reuses its buffers and is not thread safe.
+type parser struct {
+	interner *stringInterner
+	nameBuf  []byte
+}
+
+func newParser() *parser {
+	return &parser{
+		interner: newStringInterner(internerMaxSize),
+	}
+}
+
+// parseTags appends the tags of rawTags to tags and returns them, with the
+// hostname set in the `host:` tag, if any
+func (p *parser) parseTags(tags []string, rawTags []byte, defaultHostname string) ([]string, string) {
+	host := defaultHostname
+	remainder := rawTags
+
+	var tag []byte
+	for len(remainder) > 0 {
+		tag, remainder = nextField(remainder, tagSeparator)
+		if bytes.HasPrefix(tag, hostTagPrefix) {
+			host = p.interner.loadOrStore(tag[len(hostTagPrefix):])
+		} else {
+			tags = append(tags, p.interner.loadOrStore(tag))
+		}
+	}
+	return tags, host
+}
+
+// metricName returns the interned name of a metric, prefixed by the namespace
+func (p *parser) metricName(rawName []byte, namespace string) string {
+	if namespace == "" {
+		return p.interner.loadOrStore(rawName)
+	}
+	p.nameBuf = append(append(p.nameBuf[:0], namespace...), rawName...)
+	return p.interner.loadOrStore(p.nameBuf)
+}
+
+func (p *parser) parseMetricMessage(message []byte, namespace string, defaultHostname string) (*metrics.MetricSample, error) {
 	// daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2
 	// daemon:666|g|@0.1|#sometag:somevalue"
+	// daemon:666|g|#sometag:somevalue|c:entity_id
 
 	separatorCount := bytes.Count(message, fieldSeparator)
-	if separatorCount < 1 || separatorCount > 3 {
+	if separatorCount < 1 || separatorCount > 4 {
 		return nil, fmt.Errorf("invalid field number for %q", message)
 	}
 
@@ -254,60 +343,65 @@ func parseMetricMessage(message []byte, namespace string, defaultHostname string
 		return nil, fmt.Errorf("invalid metric message format: empty 'name', 'value' or 'text' field")
 	}
 
+	// the map lookup doesn't allocate a string
+	metricType, ok := metricTypes[string(rawType)]
+	if !ok {
+		return nil, fmt.Errorf("invalid metric type for %q", message)
+	}
+
+	var metricValue float64
+	if metricType != metrics.SetType {
+		var err error
+		if metricValue, err = parseFloat64(rawValue); err != nil {
+			return nil, fmt.Errorf("invalid metric value for %q", message)
+		}
+	}
+
 	// Metadata
-	var metricTags []string
+	sample := metrics.GetMetricSample()
 	host := defaultHostname
 	var rawMetadataField []byte
 	sampleRate := 1.0
+	var entity, tagEntity string
 
-	for {
+	for remainder != nil {
 		rawMetadataField, remainder = nextField(remainder, fieldSeparator)
 
 		if bytes.HasPrefix(rawMetadataField, []byte("#")) {
-			metricTags, host = parseTags(rawMetadataField[1:], true, defaultHostname)
+			sample.Tags, host = p.parseTags(sample.Tags[:0], rawMetadataField[1:], defaultHostname)
+			sample.Tags, tagEntity = extractEntityID(sample.Tags)
+		} else if bytes.HasPrefix(rawMetadataField, []byte("c:")) {
+			entity = entityName(p.interner.loadOrStore(rawMetadataField[2:]))
 		} else if bytes.HasPrefix(rawMetadataField, []byte("@")) {
-			rawSampleRate := rawMetadataField[1:]
 			var err error
-			sampleRate, err = strconv.ParseFloat(string(rawSampleRate), 64)
-			if err != nil {
+			sampleRate, err = parseFloat64(rawMetadataField[1:])
+			// a null rate would extrapolate the sample to infinity
+			if err != nil || sampleRate <= 0 || sampleRate > 1 {
+				metrics.PutMetricSample(sample)
 				return nil, fmt.Errorf("invalid sample value for %q", message)
 			}
 		}
-
-		if remainder == nil {
-			break
-		}
-	}
-
-	metricName := string(rawName)
-	if namespace != "" {
-		metricName = namespace + metricName
-	}
-
-	metricType, ok := metricTypes[string(rawType)]
-	if !ok {
-		return nil, fmt.Errorf("invalid metric type for %q", message)
 	}
 
-	sample := &metrics.MetricSample{
-		Name:       metricName,
-		Mtype:      metricType,
-		Tags:       metricTags,
-		Host:       host,
-		SampleRate: sampleRate,
-		Timestamp:  0,
+	// the entity field takes precedence over the tag
+	if entity == "" {
+		entity = tagEntity
 	}
 
-	if metricType == metrics.SetType {
-		sample.RawValue = string(rawValue)
-	} else {
-		metricValue, err := strconv.ParseFloat(string(rawValue), 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid metric value for %q", message)
-		}
-		sample.RawValue = string(rawValue)
-		sample.Value = metricValue
-	}
+	sample.Name = p.metricName(rawName, namespace)
+	sample.Mtype = metricType
+	sample.Value = metricValue
+	sample.RawValue = p.interner.loadOrStore(rawValue)
+	sample.Host = host
+	sample.SampleRate = sampleRate
+	sample.OriginID = entity
 
 	return sample, nil
 }
+
+// parseFloat64 parses a float from bytes without allocating a string, the
+// string only lives during the call: strconv doesn't retain its input except
+// in its errors, which are dropped.
+func parseFloat64(raw []byte) (float64, error) {
+	return strconv.ParseFloat(*(*string)(unsafe.Pointer(&raw)), 64)
+}
@@ -7,6 +7,7 @@ package dogstatsd
 
 import (
 	// stdlib
+	"bytes"
 	"testing"
 
 	// 3p
@@ -65,7 +66,7 @@ func TestGaugePacketCounter(t *testing.T) {
 }
 
 func TestParseGauge(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -79,7 +80,7 @@ func TestParseGauge(t *testing.T) {
 }
 
 func TestParseCounter(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|c"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:21|c"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -92,7 +93,7 @@ func TestParseCounter(t *testing.T) {
 }
 
 func TestParseCounterWithTags(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("custom_counter:1|c|#protocol:http,bench"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("custom_counter:1|c|#protocol:http,bench"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -107,7 +108,7 @@ func TestParseCounterWithTags(t *testing.T) {
 }
 
 func TestParseHistogram(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|h"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:21|h"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -120,7 +121,7 @@ func TestParseHistogram(t *testing.T) {
 }
 
 func TestParseTimer(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|ms"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:21|ms"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -133,7 +134,7 @@ func TestParseTimer(t *testing.T) {
 }
 
 func TestParseSet(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:abc|s"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:abc|s"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -146,7 +147,7 @@ func TestParseSet(t *testing.T) {
 }
 
 func TestParseDistribution(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:3.5|d"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:3.5|d"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -158,7 +159,7 @@ func TestParseDistribution(t *testing.T) {
 }
 
 func TestParseSetUnicode(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:♬†øU†øU¥ºuT0♪|s"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:♬†øU†øU¥ºuT0♪|s"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -171,7 +172,7 @@ func TestParseSetUnicode(t *testing.T) {
 }
 
 func TestParseGaugeWithTags(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,sometag2:somevalue2"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -186,7 +187,7 @@ func TestParseGaugeWithTags(t *testing.T) {
 }
 
 func TestParseGaugeWithHostTag(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:my-hostname,sometag2:somevalue2"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:my-hostname,sometag2:somevalue2"), "", "default-hostname")
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
@@ -200,7 +201,7 @@ func TestParseGaugeWithHostTag(t *testing.T) {
 }
 
 func TestParseGaugeWithEmptyHostTag(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:,sometag2:somevalue2"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:,sometag2:somevalue2"), "", "default-hostname")
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
@@ -214,7 +215,7 @@ func TestParseGaugeWithEmptyHostTag(t *testing.T) {
 }
 
 func TestParseGaugeWithNoTags(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g"), "", "default-hostname")
 	assert.NoError(t, err)
 
 	assert.Equal(t, "daemon", parsed.Name)
@@ -226,7 +227,7 @@ func TestParseGaugeWithNoTags(t *testing.T) {
 }
 
 func TestParseGaugeWithSampleRate(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|@0.21"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|@0.21"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -238,8 +239,18 @@ func TestParseGaugeWithSampleRate(t *testing.T) {
 	assert.InEpsilon(t, 0.21, parsed.SampleRate, epsilon)
 }
 
+func TestParseSampleRateOnAllTypes(t *testing.T) {
+	for _, rawType := range []string{"g", "c", "s", "h", "ms", "d"} {
+		parsed, err := newParser().parseMetricMessage([]byte("daemon:666|"+rawType+"|@0.25"), "", "default-hostname")
+
+		require.NoError(t, err, rawType)
+		assert.Equal(t, metricTypes[rawType], parsed.Mtype, rawType)
+		assert.InEpsilon(t, 0.25, parsed.SampleRate, epsilon, rawType)
+	}
+}
+
 func TestParseGaugeWithPoundOnly(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:666|g|#"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|#"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -252,7 +263,7 @@ func TestParseGaugeWithPoundOnly(t *testing.T) {
 }
 
 func TestParseGaugeWithUnicode(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("♬†øU†øU¥ºuT0♪:666|g|#intitulé:T0µ"), "", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("♬†øU†øU¥ºuT0♪:666|g|#intitulé:T0µ"), "", "default-hostname")
 
 	assert.NoError(t, err)
 
@@ -265,44 +276,78 @@ func TestParseGaugeWithUnicode(t *testing.T) {
 	assert.InEpsilon(t, 1.0, parsed.SampleRate, epsilon)
 }
 
+func TestParseGaugeWithEntityIDTag(t *testing.T) {
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,dd.internal.entity_id:5e8e05,sometag2:somevalue2"), "", "default-hostname")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"sometag1:somevalue1", "sometag2:somevalue2"}, parsed.Tags)
+	assert.Equal(t, "kubernetes_pod://5e8e05", parsed.OriginID)
+}
+
+func TestParseGaugeWithEntityIDField(t *testing.T) {
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:666|g|@0.5|#sometag1:somevalue1|c:5e8e05"), "", "default-hostname")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"sometag1:somevalue1"}, parsed.Tags)
+	assert.InEpsilon(t, 0.5, parsed.SampleRate, epsilon)
+	assert.Equal(t, "kubernetes_pod://5e8e05", parsed.OriginID)
+
+	// entity names are kept as is, the field takes precedence over the tag
+	parsed, err = newParser().parseMetricMessage([]byte("daemon:666|g|#dd.internal.entity_id:5e8e05|c:docker://abcdef"), "", "default-hostname")
+	assert.NoError(t, err)
+
+	assert.Len(t, parsed.Tags, 0)
+	assert.Equal(t, "docker://abcdef", parsed.OriginID)
+}
+
 func TestParseMetricError(t *testing.T) {
 	// not enough information
-	_, err := parseMetricMessage([]byte("daemon:666"), "", "default-hostname")
+	_, err := newParser().parseMetricMessage([]byte("daemon:666"), "", "default-hostname")
 	assert.Error(t, err)
 
-	_, err = parseMetricMessage([]byte("daemon:666|"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|"), "", "default-hostname")
 	assert.Error(t, err)
 
-	_, err = parseMetricMessage([]byte("daemon:|g"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:|g"), "", "default-hostname")
 	assert.Error(t, err)
 
-	_, err = parseMetricMessage([]byte(":666|g"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte(":666|g"), "", "default-hostname")
 	assert.Error(t, err)
 
 	// too many value
-	_, err = parseMetricMessage([]byte("daemon:666:777|g"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:666:777|g"), "", "default-hostname")
 	assert.Error(t, err)
 
 	// unknown metadata prefix
-	_, err = parseMetricMessage([]byte("daemon:666|g|m:test"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|g|m:test"), "", "default-hostname")
 	assert.NoError(t, err)
 
 	// invalid value
-	_, err = parseMetricMessage([]byte("daemon:abc|g"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:abc|g"), "", "default-hostname")
 	assert.Error(t, err)
 
 	// invalid metric type
-	_, err = parseMetricMessage([]byte("daemon:666|unknown"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|unknown"), "", "default-hostname")
 	assert.Error(t, err)
 
 	// invalid sample rate
-	_, err = parseMetricMessage([]byte("daemon:666|g|@abc"), "", "default-hostname")
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|g|@abc"), "", "default-hostname")
+	assert.Error(t, err)
+
+	// out of range sample rates
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|c|@0"), "", "default-hostname")
+	assert.Error(t, err)
+
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|c|@-0.5"), "", "default-hostname")
+	assert.Error(t, err)
+
+	_, err = newParser().parseMetricMessage([]byte("daemon:666|c|@2"), "", "default-hostname")
 	assert.Error(t, err)
 }
 
 func TestParseMonokeyBatching(t *testing.T) {
 	// TODO: not implemented
-	// parsed, err := parseMetricMessage([]byte("test_gauge:1.5|g|#tag1:one,tag2:two:2.3|g|#tag3:three:3|g"), "default-hostname")
+	// parsed, err := newParser().parseMetricMessage([]byte("test_gauge:1.5|g|#tag1:one,tag2:two:2.3|g|#tag3:three:3|g"), "default-hostname")
 }
 
 func TestEnsureUTF8(t *testing.T) {
@@ -394,6 +439,20 @@ func TestServiceCheckMetadataTags(t *testing.T) {
 	assert.Equal(t, []string{"tag1", "tag2:test", "tag3"}, sc.Tags)
 }
 
+func TestServiceCheckMetadataEntityID(t *testing.T) {
+	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|#tag1,dd.internal.entity_id:5e8e05"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"tag1"}, sc.Tags)
+	assert.Equal(t, "kubernetes_pod://5e8e05", sc.OriginID)
+
+	sc, err = parseServiceCheckMessage([]byte("_sc|agent.up|0|c:5e8e05|#tag1"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"tag1"}, sc.Tags)
+	assert.Equal(t, "kubernetes_pod://5e8e05", sc.OriginID)
+}
+
 func TestServiceCheckMetadataMessage(t *testing.T) {
 	sc, err := parseServiceCheckMessage([]byte("_sc|agent.up|0|m:this is fine"))
 
@@ -653,6 +712,20 @@ func TestEventMetadataTags(t *testing.T) {
 	assert.Equal(t, "", e.EventType)
 }
 
+func TestEventMetadataEntityID(t *testing.T) {
+	e, err := parseEventMessage([]byte("_e{10,9}:test title|test text|#tag1,dd.internal.entity_id:5e8e05"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"tag1"}, e.Tags)
+	assert.Equal(t, "kubernetes_pod://5e8e05", e.OriginID)
+
+	e, err = parseEventMessage([]byte("_e{10,9}:test title|test text|#tag1|c:5e8e05"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"tag1"}, e.Tags)
+	assert.Equal(t, "kubernetes_pod://5e8e05", e.OriginID)
+}
+
 func TestEventMetadataMultiple(t *testing.T) {
 	e, err := parseEventMessage([]byte("_e{10,9}:test title|test text|t:warning|d:12345|p:low|h:some.host|k:aggKey|s:source test|#tag1,tag2:test"))
 
@@ -670,10 +743,98 @@ func TestEventMetadataMultiple(t *testing.T) {
 }
 
 func TestNamespace(t *testing.T) {
-	parsed, err := parseMetricMessage([]byte("daemon:21|ms"), "testNamespace.", "default-hostname")
+	parsed, err := newParser().parseMetricMessage([]byte("daemon:21|ms"), "testNamespace.", "default-hostname")
 
 	assert.NoError(t, err)
 
 	assert.Equal(t, "testNamespace.daemon", parsed.Name)
 	assert.Equal(t, "default-hostname", parsed.Host)
 }
+
+func TestEventNegativeLengths(t *testing.T) {
+	_, err := parseEventMessage([]byte("_e{-5,3}:abc|def"))
+	assert.Error(t, err)
+	_, err = parseEventMessage([]byte("_e{9223372036854775807,9223372036854775807}:abc|def"))
+	assert.Error(t, err)
+}
+
+func TestParserReuse(t *testing.T) {
+	p := newParser()
+
+	parsed, err := p.parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1,host:my-host"), "ns.", "default-hostname")
+	require.NoError(t, err)
+	assert.Equal(t, "ns.daemon", parsed.Name)
+	assert.Equal(t, []string{"sometag1:somevalue1"}, parsed.Tags)
+	assert.Equal(t, "my-host", parsed.Host)
+	metrics.PutMetricSample(parsed)
+
+	// the pooled samples and the name buffer don't leak into the next samples
+	parsed, err = p.parseMetricMessage([]byte("d:21|c"), "ns.", "default-hostname")
+	require.NoError(t, err)
+	assert.Equal(t, "ns.d", parsed.Name)
+	assert.Len(t, parsed.Tags, 0)
+	assert.Equal(t, "default-hostname", parsed.Host)
+	assert.Equal(t, "21", parsed.RawValue)
+
+	other, err := p.parseMetricMessage([]byte("daemon:666|g|#sometag1:somevalue1"), "ns.", "default-hostname")
+	require.NoError(t, err)
+	assert.Equal(t, "ns.d", parsed.Name)
+	assert.Equal(t, "ns.daemon", other.Name)
+}
+
+func TestStringInterner(t *testing.T) {
+	i := newStringInterner(2)
+	foo := i.loadOrStore([]byte("foo"))
+	assert.Equal(t, "foo", foo)
+	assert.Equal(t, "bar", i.loadOrStore([]byte("bar")))
+	assert.Len(t, i.strings, 2)
+	assert.Equal(t, "foo", i.loadOrStore([]byte("foo")))
+
+	// full, it starts over
+	assert.Equal(t, "baz", i.loadOrStore([]byte("baz")))
+	assert.Len(t, i.strings, 1)
+}
+
+// TestParseMalformedMessages makes sure the protocol edge cases found while
+// fuzzing the parser are rejected without panicking
+func TestParseMalformedMessages(t *testing.T) {
+	for _, message := range []string{
+		"", "|", ":", "::", "||||", "a:|g", ":1|g", "a:1|", "a:1||", "a:1|g|@", "a:1|g|@0", "a:1|g|@-1",
+		"a:1|g|#", "a:1|g|#,,,", "a:1|g|c:", "a:1|x", "a:b|g", "a:1|g|||||",
+		"_e", "_e{", "_e{}", "_e{,}:", "_e{1,}:a|", "_e{0,0}:|", "_e{1,1}:a", "_e{2,2}:a|b", "_e{1,1}:a|b|",
+		"_sc", "_sc|", "_sc||", "_sc|a|", "_sc|a|9", "_sc|a|0|d:", "_sc|a|0|#",
+	} {
+		packet := []byte(message)
+		parseMessage(newParser(), packet)
+	}
+}
+
+// parseMessage parses a message like the server workers, it's used by the
+// tests and the fuzzer
+func parseMessage(p *parser, message []byte) {
+	switch {
+	case bytes.HasPrefix(message, []byte("_sc")):
+		parseServiceCheckMessage(message)
+	case bytes.HasPrefix(message, []byte("_e")):
+		parseEventMessage(message)
+	default:
+		if sample, err := p.parseMetricMessage(message, "ns.", "default-hostname"); err == nil {
+			metrics.PutMetricSample(sample)
+		}
+	}
+}
+
+func BenchmarkParseMetricMessage(b *testing.B) {
+	p := newParser()
+	message := []byte("web.page.views:1|c|@0.5|#env:prod,service:web,version:1.2.3,host:web-1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		sample, err := p.parseMetricMessage(message, "", "default-hostname")
+		if err != nil {
+			b.Fatal(err)
+		}
+		metrics.PutMetricSample(sample)
+	}
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+/*
+Package replay implements the capture file format of the dogstatsd traffic.
+
+A capture file starts with a header made of the `DDSTATSD` magic and the
+format version, followed by one record per received packet:
+
+	int64  reception timestamp, in nanoseconds
+	uint32 length of the origin
+	[]byte origin, the container the packet was sent from when detected
+	uint32 length of the packet
+	[]byte raw packet contents
+
+All the integers are little endian.
+*/
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	fileMagic = "DDSTATSD"
+	// FormatVersion is the version of the capture file format
+	FormatVersion uint8 = 1
+
+	// maxRecordSize protects the reader against corrupted files,
+	// dogstatsd packets are much smaller than that
+	maxRecordSize = 1024 * 1024
+)
+
+// Record is a captured dogstatsd packet
+type Record struct {
+	Timestamp time.Time
+	Origin    string
+	Contents  []byte
+}
+
+// Writer writes captured packets to a capture file, it's safe for concurrent use
+type Writer struct {
+	m      sync.Mutex
+	writer *bufio.Writer
+}
+
+// NewWriter returns a Writer and writes the capture file header to w
+func NewWriter(w io.Writer) (*Writer, error) {
+	writer := &Writer{
+		writer: bufio.NewWriter(w),
+	}
+	if _, err := writer.writer.WriteString(fileMagic); err != nil {
+		return nil, err
+	}
+	if err := writer.writer.WriteByte(FormatVersion); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write appends a packet to the capture file
+func (w *Writer) Write(ts time.Time, origin string, contents []byte) error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	var header [8 + 4]byte
+	binary.LittleEndian.PutUint64(header[:8], uint64(ts.UnixNano()))
+	binary.LittleEndian.PutUint32(header[8:], uint32(len(origin)))
+	if _, err := w.writer.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.writer.WriteString(origin); err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(header[:4], uint32(len(contents)))
+	if _, err := w.writer.Write(header[:4]); err != nil {
+		return err
+	}
+	_, err := w.writer.Write(contents)
+	return err
+}
+
+// Flush writes the buffered records to the underlying writer
+func (w *Writer) Flush() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	return w.writer.Flush()
+}
+
+// Reader reads the records of a capture file
+type Reader struct {
+	reader *bufio.Reader
+}
+
+// NewReader returns a Reader after validating the capture file header
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{
+		reader: bufio.NewReader(r),
+	}
+
+	header := make([]byte, len(fileMagic)+1)
+	if _, err := io.ReadFull(reader.reader, header); err != nil {
+		return nil, fmt.Errorf("could not read the capture header: %s", err)
+	}
+	if string(header[:len(fileMagic)]) != fileMagic {
+		return nil, errors.New("not a dogstatsd capture file")
+	}
+	if header[len(fileMagic)] != FormatVersion {
+		return nil, fmt.Errorf("unsupported capture format version %d", header[len(fileMagic)])
+	}
+	return reader, nil
+}
+
+// Next returns the next record of the capture, or io.EOF at the end of the capture
+func (r *Reader) Next() (*Record, error) {
+	var header [8 + 4]byte
+	if _, err := io.ReadFull(r.reader, header[:]); err != nil {
+		// a capture can end after any complete record
+		return nil, err
+	}
+	record := &Record{
+		Timestamp: time.Unix(0, int64(binary.LittleEndian.Uint64(header[:8]))),
+	}
+
+	origin, err := r.readField(binary.LittleEndian.Uint32(header[8:]))
+	if err != nil {
+		return nil, err
+	}
+	record.Origin = string(origin)
+
+	if _, err = io.ReadFull(r.reader, header[:4]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	record.Contents, err = r.readField(binary.LittleEndian.Uint32(header[:4]))
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *Reader) readField(length uint32) ([]byte, error) {
+	if length > maxRecordSize {
+		return nil, fmt.Errorf("invalid record size %d", length)
+	}
+	field := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, field); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return field, nil
+}
+
+// unexpectedEOF makes sure a truncated record is not mistaken for the end of the capture
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
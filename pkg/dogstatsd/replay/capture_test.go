@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package replay
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+
+	ts := time.Unix(1536000000, 42)
+	require.NoError(t, w.Write(ts, "", []byte("daemon:666|g")))
+	require.NoError(t, w.Write(ts.Add(time.Second), "docker://abcdef", []byte("daemon:1|c\ndaemon:2|c")))
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(record.Timestamp))
+	assert.Equal(t, "", record.Origin)
+	assert.Equal(t, []byte("daemon:666|g"), record.Contents)
+
+	record, err = r.Next()
+	require.NoError(t, err)
+	assert.True(t, ts.Add(time.Second).Equal(record.Timestamp))
+	assert.Equal(t, "docker://abcdef", record.Origin)
+	assert.Equal(t, []byte("daemon:1|c\ndaemon:2|c"), record.Contents)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadInvalidHeader(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("daemon:666|g")))
+	assert.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader([]byte(fileMagic + "\x02")))
+	assert.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader([]byte("DD")))
+	assert.Error(t, err)
+}
+
+func TestReadTruncatedRecord(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(time.Now(), "docker://abcdef", []byte("daemon:666|g")))
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-4]))
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// defaultRollupWindow is used when `dogstatsd_rollup.interval` is not positive
+const defaultRollupWindow = 10 * time.Second
+
+var (
+	dogstatsdRolledUpSamples = expvar.Int{}
+	dogstatsdRollupSamples   = expvar.Int{}
+)
+
+func init() {
+	dogstatsdExpvars.Set("RolledUpSamples", &dogstatsdRolledUpSamples)
+	dogstatsdExpvars.Set("RollupFlushedSamples", &dogstatsdRollupSamples)
+}
+
+// rollupKey identifies the samples merged together
+type rollupKey struct {
+	context ckey.ContextKey
+	mtype   metrics.MetricType
+}
+
+// rolledSample is the merge of the samples of a context over the window
+type rolledSample struct {
+	sample *metrics.MetricSample
+	// values are the distinct values of a set
+	values map[string]struct{}
+}
+
+// rollup pre-aggregates the gauges, counters and sets of the configured
+// metric prefixes over a time window, before they reach the aggregator: the
+// high frequency sources then send a single sample per context and window.
+// The other metric types are not rolled up, their distribution is needed.
+type rollup struct {
+	prefixes []string
+	window   time.Duration
+	out      chan<- *metrics.MetricSample
+	stop     chan struct{}
+	stopped  chan struct{}
+
+	m       sync.Mutex
+	samples map[rollupKey]*rolledSample
+}
+
+// newRollup returns a new rollup of the samples of the metric prefixes over
+// the window, which sends the rolled samples on out
+func newRollup(prefixes []string, window time.Duration, out chan<- *metrics.MetricSample) *rollup {
+	if window <= 0 {
+		log.Warnf("Configured dogstatsd_rollup.interval (%v) is not positive; %v will be used", window, defaultRollupWindow)
+		window = defaultRollupWindow
+	}
+	return &rollup{
+		prefixes: prefixes,
+		window:   window,
+		out:      out,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		samples:  make(map[rollupKey]*rolledSample),
+	}
+}
+
+// matches returns whether the samples of the metric are rolled up
+func (r *rollup) matches(sample *metrics.MetricSample) bool {
+	switch sample.Mtype {
+	case metrics.GaugeType, metrics.CounterType, metrics.SetType:
+	default:
+		return false
+	}
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(sample.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// add merges the sample in the current window, returns false if the sample
+// is not rolled up and must be sent as is
+func (r *rollup) add(sample *metrics.MetricSample) bool {
+	if !r.matches(sample) {
+		return false
+	}
+	key := rollupKey{context: ckey.Generate(sample.Name, sample.Host, sample.Tags), mtype: sample.Mtype}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	dogstatsdRolledUpSamples.Add(1)
+
+	rolled, found := r.samples[key]
+	if !found {
+		rolled = &rolledSample{sample: sample}
+		if sample.Mtype == metrics.CounterType {
+			sample.Value = sample.Value * (1 / sample.SampleRate)
+			sample.SampleRate = 1
+		}
+		if sample.Mtype == metrics.SetType {
+			rolled.values = map[string]struct{}{sample.RawValue: {}}
+		}
+		r.samples[key] = rolled
+		return true
+	}
+
+	switch sample.Mtype {
+	case metrics.GaugeType:
+		rolled.sample.Value = sample.Value
+	case metrics.CounterType:
+		rolled.sample.Value += sample.Value * (1 / sample.SampleRate)
+	case metrics.SetType:
+		rolled.values[sample.RawValue] = struct{}{}
+	}
+	return true
+}
+
+// flush returns the samples rolled up in the current window and starts a
+// new one. The samples are timestamped by the aggregator when received, like
+// the others: a window matching the bucket of the aggregator yields a sample
+// per bucket.
+func (r *rollup) flush() []*metrics.MetricSample {
+	r.m.Lock()
+	samples := r.samples
+	r.samples = make(map[rollupKey]*rolledSample)
+	r.m.Unlock()
+
+	flushed := make([]*metrics.MetricSample, 0, len(samples))
+	for _, rolled := range samples {
+		if rolled.sample.Mtype != metrics.SetType {
+			flushed = append(flushed, rolled.sample)
+			continue
+		}
+		for value := range rolled.values {
+			sample := rolled.sample.Copy()
+			sample.RawValue = value
+			flushed = append(flushed, sample)
+		}
+	}
+	dogstatsdRollupSamples.Add(int64(len(flushed)))
+	return flushed
+}
+
+// run sends the rolled samples at the end of every window until stopped,
+// the samples of the open window are sent when stopping
+func (r *rollup) run() {
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+	defer close(r.stopped)
+	for {
+		select {
+		case <-r.stop:
+			r.send()
+			return
+		case <-ticker.C:
+			r.send()
+		}
+	}
+}
+
+func (r *rollup) send() {
+	for _, sample := range r.flush() {
+		r.out <- sample
+	}
+}
+
+// stopAndFlush stops the rollup, once the samples of the open window are sent
+func (r *rollup) stopAndFlush() {
+	close(r.stop)
+	<-r.stopped
+}
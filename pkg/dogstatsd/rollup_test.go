@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newSample(name string, mtype metrics.MetricType, value float64, tags ...string) *metrics.MetricSample {
+	return &metrics.MetricSample{
+		Name:       name,
+		Value:      value,
+		Mtype:      mtype,
+		Tags:       tags,
+		SampleRate: 1,
+	}
+}
+
+func TestRollupMatches(t *testing.T) {
+	r := newRollup([]string{"noisy."}, 10*time.Second, nil)
+	assert.True(t, r.matches(newSample("noisy.gauge", metrics.GaugeType, 1)))
+	assert.False(t, r.matches(newSample("quiet.gauge", metrics.GaugeType, 1)))
+	assert.False(t, r.matches(newSample("noisy.histogram", metrics.HistogramType, 1)))
+}
+
+func TestRollupFlush(t *testing.T) {
+	r := newRollup([]string{"noisy."}, 10*time.Second, nil)
+
+	assert.True(t, r.add(newSample("noisy.gauge", metrics.GaugeType, 1, "a:b")))
+	assert.True(t, r.add(newSample("noisy.gauge", metrics.GaugeType, 3, "a:b")))
+	assert.True(t, r.add(newSample("noisy.gauge", metrics.GaugeType, 5, "a:c")))
+
+	assert.True(t, r.add(newSample("noisy.counter", metrics.CounterType, 1)))
+	sampled := newSample("noisy.counter", metrics.CounterType, 2)
+	sampled.SampleRate = 0.5
+	assert.True(t, r.add(sampled))
+
+	for _, value := range []string{"x", "y", "x"} {
+		sample := newSample("noisy.set", metrics.SetType, 0)
+		sample.RawValue = value
+		assert.True(t, r.add(sample))
+	}
+	assert.False(t, r.add(newSample("quiet.gauge", metrics.GaugeType, 1)))
+
+	flushed := r.flush()
+	require.Len(t, flushed, 5)
+
+	values := map[string]float64{}
+	var setValues []string
+	for _, sample := range flushed {
+		switch sample.Mtype {
+		case metrics.SetType:
+			setValues = append(setValues, sample.RawValue)
+		default:
+			values[strings.Join(append([]string{sample.Name}, sample.Tags...), ",")] = sample.Value
+		}
+	}
+	sort.Strings(setValues)
+	assert.Equal(t, []string{"x", "y"}, setValues)
+	assert.Equal(t, map[string]float64{
+		"noisy.gauge,a:b": 3,
+		"noisy.gauge,a:c": 5,
+		"noisy.counter":   5,
+	}, values)
+
+	assert.Empty(t, r.flush())
+}
+
+func TestRollupInvalidWindow(t *testing.T) {
+	r := newRollup([]string{"noisy."}, 0, nil)
+	assert.Equal(t, defaultRollupWindow, r.window)
+}
+
+func TestRollupStopFlushesOpenWindow(t *testing.T) {
+	out := make(chan *metrics.MetricSample, 10)
+	r := newRollup([]string{"noisy."}, time.Hour, out)
+	go r.run()
+
+	assert.True(t, r.add(newSample("noisy.gauge", metrics.GaugeType, 1)))
+	r.stopAndFlush()
+
+	require.Len(t, out, 1)
+	assert.Equal(t, "noisy.gauge", (<-out).Name)
+}
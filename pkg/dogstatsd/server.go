@@ -11,7 +11,10 @@ import (
 	"fmt"
 	"net"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -52,9 +55,13 @@ type Server struct {
 	stopChan         chan bool
 	health           *health.Handle
 	metricPrefix     string
+	prefixExclusions [][]byte
 	defaultHostname  string
 	histToDist       bool
 	histToDistPrefix string
+	capture          *trafficCapture
+	captureMutex     sync.RWMutex
+	rollup           *rollup
 }
 
 // NewServer returns a running Dogstatsd server
@@ -100,6 +107,12 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 	if metricPrefix != "" && !strings.HasSuffix(metricPrefix, ".") {
 		metricPrefix = metricPrefix + "."
 	}
+	var prefixExclusions [][]byte
+	if metricPrefix != "" {
+		for _, prefix := range config.Datadog.GetStringSlice("statsd_metric_namespace_exclude") {
+			prefixExclusions = append(prefixExclusions, []byte(prefix))
+		}
+	}
 
 	defaultHostname, err := util.GetHostname()
 	if err != nil {
@@ -117,17 +130,25 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 		stopChan:         make(chan bool),
 		health:           health.Register("dogstatsd-main"),
 		metricPrefix:     metricPrefix,
+		prefixExclusions: prefixExclusions,
 		defaultHostname:  defaultHostname,
 		histToDist:       histToDist,
 		histToDistPrefix: histToDistPrefix,
 	}
 
-	forwardHost := config.Datadog.GetString("statsd_forward_host")
+	rollupPrefixes := config.Datadog.GetStringSlice("dogstatsd_rollup.prefixes")
+	if len(rollupPrefixes) > 0 {
+		window := config.Datadog.GetDuration("dogstatsd_rollup.interval") * time.Second
+		s.rollup = newRollup(rollupPrefixes, window, metricOut)
+		go s.rollup.run()
+	}
+
+	forwardHost := config.NormalizeHost(config.Datadog.GetString("statsd_forward_host"))
 	forwardPort := config.Datadog.GetInt("statsd_forward_port")
 
 	if forwardHost != "" && forwardPort != 0 {
 
-		forwardAddress := fmt.Sprintf("%s:%d", forwardHost, forwardPort)
+		forwardAddress := net.JoinHostPort(forwardHost, strconv.Itoa(forwardPort))
 
 		con, err := net.Dial("udp", forwardAddress)
 
@@ -144,6 +165,18 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 	return s, nil
 }
 
+// namespace returns the namespace of the metric of a message, the metrics
+// which name starts with an excluded prefix are not namespaced. The message
+// starts with the metric name.
+func (s *Server) namespace(message []byte) string {
+	for _, prefix := range s.prefixExclusions {
+		if bytes.HasPrefix(message, prefix) {
+			return ""
+		}
+	}
+	return s.metricPrefix
+}
+
 func (s *Server) handleMessages(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.Event, serviceCheckOut chan<- metrics.ServiceCheck) {
 	if s.Statistics != nil {
 		go s.Statistics.Process()
@@ -183,6 +216,7 @@ func (s *Server) forwarder(fcon net.Conn, packetChannel chan *listeners.Packet)
 }
 
 func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.Event, serviceCheckOut chan<- metrics.ServiceCheck) {
+	p := newParser()
 	for {
 		select {
 		case <-s.stopChan:
@@ -191,14 +225,11 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 		case packet := <-s.packetIn:
 			var originTags []string
 
+			s.capturePacket(packet.Contents, packet.Origin)
+
 			if packet.Origin != listeners.NoOrigin {
-				var err error
 				log.Tracef("Dogstatsd receive from %s: %s", packet.Origin, packet.Contents)
-				originTags, err = tagger.Tag(packet.Origin, tagger.IsFullCardinality())
-				if err != nil {
-					log.Errorf(err.Error())
-				}
-				log.Tracef("Tags for %s: %s", packet.Origin, originTags)
+				originTags = getOriginTags(packet.Origin)
 			} else {
 				log.Tracef("Dogstatsd receive: %s", packet.Contents)
 			}
@@ -220,8 +251,10 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 						dogstatsdServiceCheckParseErrors.Add(1)
 						continue
 					}
-					if len(originTags) > 0 {
-						serviceCheck.Tags = append(serviceCheck.Tags, originTags...)
+					// Tags of the origin are added by the aggregator, the
+					// entity sent by the client takes precedence
+					if serviceCheck.OriginID == "" {
+						serviceCheck.OriginID = packet.Origin
 					}
 					dogstatsdServiceCheckPackets.Add(1)
 					serviceCheckOut <- *serviceCheck
@@ -232,27 +265,37 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 						dogstatsdEventParseErrors.Add(1)
 						continue
 					}
-					if len(originTags) > 0 {
-						event.Tags = append(event.Tags, originTags...)
+					if event.OriginID == "" {
+						event.OriginID = packet.Origin
 					}
 					dogstatsdEventPackets.Add(1)
 					eventOut <- *event
 				} else {
-					sample, err := parseMetricMessage(message, s.metricPrefix, s.defaultHostname)
+					sample, err := p.parseMetricMessage(message, s.namespace(message), s.defaultHostname)
 					if err != nil {
 						log.Errorf("Dogstatsd: error parsing metrics: %s", err)
 						dogstatsdMetricParseErrors.Add(1)
 						continue
 					}
-					if len(originTags) > 0 {
+					if sample.OriginID != "" {
+						sample.Tags = append(sample.Tags, getOriginTags(sample.OriginID)...)
+					} else if len(originTags) > 0 {
 						sample.Tags = append(sample.Tags, originTags...)
 					}
 					dogstatsdMetricPackets.Add(1)
-					metricOut <- sample
+					if s.rollup != nil && s.rollup.add(sample) {
+						continue
+					}
+					// copy the sample before sending it, the aggregator
+					// owns it once it is on metricOut
+					var distSample *metrics.MetricSample
 					if s.histToDist && sample.Mtype == metrics.HistogramType {
-						distSample := sample.Copy()
+						distSample = sample.Copy()
 						distSample.Name = s.histToDistPrefix + distSample.Name
 						distSample.Mtype = metrics.DistributionType
+					}
+					metricOut <- sample
+					if distSample != nil {
 						metricOut <- distSample
 					}
 				}
@@ -263,6 +306,16 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 	}
 }
 
+// getOriginTags returns the tags of the entity a packet originates from
+func getOriginTags(origin string) []string {
+	originTags, err := tagger.Tag(origin, tagger.IsFullCardinality())
+	if err != nil {
+		log.Errorf(err.Error())
+	}
+	log.Tracef("Tags for %s: %s", origin, originTags)
+	return originTags
+}
+
 // Stop stops a running Dogstatsd server
 func (s *Server) Stop() {
 	close(s.stopChan)
@@ -272,6 +325,10 @@ func (s *Server) Stop() {
 	if s.Statistics != nil {
 		s.Statistics.Stop()
 	}
+	if s.rollup != nil {
+		s.rollup.stopAndFlush()
+	}
+	s.StopCapture()
 	s.health.Deregister()
 	s.Started = false
 }
@@ -290,3 +290,40 @@ func TestHistToDist(t *testing.T) {
 		assert.FailNow(t, "Timeout on receive channel")
 	}
 }
+
+func TestMetricNamespace(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	defaultPort := config.Datadog.GetInt("dogstatsd_port")
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	defer config.Datadog.SetDefault("dogstatsd_port", defaultPort)
+	config.Datadog.SetDefault("statsd_metric_namespace", "cluster")
+	defer config.Datadog.SetDefault("statsd_metric_namespace", "")
+
+	metricOut := make(chan *metrics.MetricSample)
+	eventOut := make(chan metrics.Event)
+	serviceOut := make(chan metrics.ServiceCheck)
+	s, err := NewServer(metricOut, eventOut, serviceOut)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	for message, name := range map[string]string{
+		"daemon:666|g":                    "cluster.daemon",
+		"datadog.tracer.queue.size:666|g": "datadog.tracer.queue.size",
+		"jvm.heap_memory:666|g|#env:prod": "jvm.heap_memory",
+		"application.jvm.memory:666|g":    "cluster.application.jvm.memory",
+	} {
+		conn.Write([]byte(message))
+		select {
+		case metric := <-metricOut:
+			assert.Equal(t, name, metric.Name)
+		case <-time.After(2 * time.Second):
+			assert.FailNow(t, "Timeout on receive channel")
+		}
+	}
+}
@@ -123,6 +123,11 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		log.Errorf("Could not zip health check: %s", err)
 	}
 
+	err = zipPermissionsInfos(tempDir, hostname, logFilePath)
+	if err != nil {
+		log.Errorf("Could not zip permissions: %s", err)
+	}
+
 	if config.IsContainerized() {
 		err = zipDockerSelfInspect(tempDir, hostname)
 		if err != nil {
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/fatih/color"
 
@@ -28,15 +29,7 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		color.NoColor = true
 	}
 
-	c := util.GetClient(false) // FIX: get certificates right then make this true
-
-	// Set session token
-	err := util.SetAuthToken()
-	if err != nil {
-		return err
-	}
-
-	r, err := util.DoGet(c, ConfigCheckURL)
+	r, err := queryConfigCheck()
 	if err != nil {
 		if r != nil && string(r) != "" {
 			fmt.Fprintln(w, fmt.Sprintf("The agent ran into an error while checking config: %s", string(r)))
@@ -80,11 +73,149 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 				fmt.Fprintln(w, config.String())
 			}
 		}
+		if len(cr.Services) > 0 {
+			fmt.Fprintln(w, fmt.Sprintf("\n=== %s ===", color.YellowString("Services")))
+			for _, svc := range cr.Services {
+				printServiceConfigs(w, svc)
+			}
+		}
+	}
+
+	return nil
+}
+
+// printServiceConfigs prints the configurations resolved for a service, or
+// why none was
+func printServiceConfigs(w io.Writer, svc integration.ServiceConfigs) {
+	fmt.Fprintln(w, fmt.Sprintf("\n%s: %s", color.BlueString("Entity"), color.CyanString(svc.Entity)))
+	if svc.ADIDError != "" {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.RedString("Not monitored"), svc.ADIDError))
+		return
+	}
+	fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Auto-discovery IDs"), strings.Join(svc.ADIdentifiers, ", ")))
+	if svc.WarmingUp {
+		fmt.Fprintln(w, color.YellowString("Warming up: the checks are scheduled once the service is ready"))
+	}
+	if len(svc.Configs) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Configs"), color.GreenString(strings.Join(svc.Configs, ", "))))
+	} else if !svc.WarmingUp {
+		fmt.Fprintln(w, fmt.Sprintf("%s: none, no template matches its Auto-discovery IDs", color.BlueString("Configs")))
+	}
+	for name, err := range svc.ResolveErrors {
+		fmt.Fprintln(w, fmt.Sprintf("%s %s: %s", color.RedString("Could not resolve"), color.YellowString(name), err))
+	}
+}
+
+// GetConfigCheckJSON writes all loaded configurations to the writer as a
+// JSON document, the YAML sections of the configurations are kept as strings
+func GetConfigCheckJSON(w io.Writer, withDebug bool) error {
+	r, err := queryConfigCheck()
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while checking config: %s", string(r))
+		}
+		return fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	cr := response.ConfigCheckResponse{}
+	if err = json.Unmarshal(r, &cr); err != nil {
+		return err
+	}
+
+	out := configCheckJSON{
+		Configs:      make([]configJSON, 0, len(cr.Configs)),
+		ConfigErrors: cr.ConfigErrors,
+	}
+	if out.ConfigErrors == nil {
+		out.ConfigErrors = make(map[string]string)
+	}
+	for _, c := range cr.Configs {
+		out.Configs = append(out.Configs, newConfigJSON(c, true))
+	}
+	out.Services = cr.Services
+	if out.Services == nil {
+		out.Services = []integration.ServiceConfigs{}
+	}
+	if withDebug {
+		out.ResolveWarnings = cr.ResolveWarnings
+		out.Unresolved = make(map[string]configJSON, len(cr.Unresolved))
+		for ids, c := range cr.Unresolved {
+			out.Unresolved[ids] = newConfigJSON(c, false)
+		}
 	}
 
+	j, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(j))
 	return nil
 }
 
+// configCheckJSON is the output of GetConfigCheckJSON, the resolve warnings
+// and the unresolved templates are only set in debug mode
+type configCheckJSON struct {
+	Configs         []configJSON                 `json:"configs"`
+	ConfigErrors    map[string]string            `json:"config_errors"`
+	Services        []integration.ServiceConfigs `json:"services"`
+	ResolveWarnings map[string][]string          `json:"resolve_warnings,omitempty"`
+	Unresolved      map[string]configJSON        `json:"unresolved,omitempty"`
+}
+
+// configJSON is an integration.Config with its YAML sections as strings
+type configJSON struct {
+	Name          string         `json:"check_name"`
+	Provider      string         `json:"provider"`
+	Instances     []instanceJSON `json:"instances"`
+	InitConfig    string         `json:"init_config"`
+	MetricConfig  string         `json:"metric_config,omitempty"`
+	LogsConfig    string         `json:"logs,omitempty"`
+	ADIdentifiers []string       `json:"ad_identifiers"`
+}
+
+type instanceJSON struct {
+	ID     string `json:"id,omitempty"`
+	Config string `json:"config"`
+}
+
+// newConfigJSON converts a configuration, the IDs of the instances are only
+// meaningful for resolved configurations
+func newConfigJSON(c integration.Config, withIDs bool) configJSON {
+	cj := configJSON{
+		Name:          c.Name,
+		Provider:      c.Provider,
+		Instances:     make([]instanceJSON, 0, len(c.Instances)),
+		InitConfig:    string(c.InitConfig),
+		MetricConfig:  string(c.MetricConfig),
+		LogsConfig:    string(c.LogsConfig),
+		ADIdentifiers: c.ADIdentifiers,
+	}
+	if cj.ADIdentifiers == nil {
+		cj.ADIdentifiers = []string{}
+	}
+	for _, inst := range c.Instances {
+		ij := instanceJSON{Config: string(inst)}
+		if withIDs {
+			ij.ID = string(check.BuildID(c.Name, inst, c.InitConfig))
+		}
+		cj.Instances = append(cj.Instances, ij)
+	}
+	return cj
+}
+
+// queryConfigCheck returns the body of the config check endpoint
+func queryConfigCheck() ([]byte, error) {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return util.DoGet(c, ConfigCheckURL)
+}
+
 // PrintConfig prints a human-readable representation of a configuration
 func PrintConfig(w io.Writer, c integration.Config) {
 	fmt.Fprintln(w, fmt.Sprintf("\n=== %s check ===", color.GreenString(c.Name)))
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package flare
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// permissionsInfo holds the owner and mode of a path used by the agent,
+// and the reason why the agent can't use it properly, if any
+type permissionsInfo struct {
+	description string
+	path        string
+	mode        os.FileMode
+	owner       string
+	group       string
+	err         error
+}
+
+// agentPath is a path the agent uses, with the usage the agent requires
+type agentPath struct {
+	description string
+	path        string
+	check       func(path string, fi os.FileInfo) error
+}
+
+// agentPaths returns the paths used by the agent which permissions are audited
+func agentPaths(logFilePath string) []agentPath {
+	paths := []agentPath{
+		{"configuration file", config.Datadog.ConfigFileUsed(), checkNotWorldWritable},
+		{"checks configuration directory", config.Datadog.GetString("confd_path"), checkReadable},
+		{"log file", logFilePath, checkWritable},
+	}
+	if secretsBackendAudited {
+		paths = append(paths, agentPath{"secrets backend command", config.Datadog.GetString("secret_backend_command"), checkNotWorldWritable})
+
+		backends := map[string]secrets.BackendConfig{}
+		if err := config.Datadog.UnmarshalKey("secret_backends", &backends); err != nil {
+			log.Warnf("Could not parse secret_backends: %s", err)
+		}
+		names := make([]string, 0, len(backends))
+		for name := range backends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			description := fmt.Sprintf("secrets backend '%s' command", name)
+			paths = append(paths, agentPath{description, backends[name].Command, checkNotWorldWritable})
+		}
+	}
+	paths = append(paths, agentPath{"dogstatsd socket", config.Datadog.GetString("dogstatsd_socket"), checkSocket})
+	return paths
+}
+
+// getPermissionsInfos collects the permissions of the paths used by the agent,
+// the paths that are not configured are skipped
+func getPermissionsInfos(logFilePath string) []permissionsInfo {
+	var infos []permissionsInfo
+	for _, p := range agentPaths(logFilePath) {
+		if p.path == "" {
+			continue
+		}
+		info := permissionsInfo{
+			description: p.description,
+			path:        p.path,
+		}
+		fi, err := os.Stat(p.path)
+		if err != nil {
+			info.err = fmt.Errorf("can't stat it: %s", err)
+			infos = append(infos, info)
+			continue
+		}
+		info.mode = fi.Mode()
+		info.owner, info.group = fileOwner(fi)
+		info.err = p.check(p.path, fi)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// CheckPermissions logs an error for every path used by the agent
+// with wrong permissions, and returns an error if any was found
+func CheckPermissions(logFilePath string) error {
+	count := 0
+	for _, info := range getPermissionsInfos(logFilePath) {
+		if info.err != nil {
+			log.Errorf("Invalid %s '%s' (mode %s, owner %s, group %s): %s", info.description, info.path, info.mode, info.owner, info.group, info.err)
+			count++
+			continue
+		}
+		log.Infof("Valid %s '%s' (mode %s, owner %s, group %s)", info.description, info.path, info.mode, info.owner, info.group)
+	}
+	if count > 0 {
+		return fmt.Errorf("found %d paths with invalid permissions", count)
+	}
+	return nil
+}
+
+// formatPermissionsInfos returns the permissions of the paths used by the agent as a table
+func formatPermissionsInfos(logFilePath string) []byte {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USAGE\tPATH\tMODE\tOWNER\tGROUP\tERROR")
+	for _, info := range getPermissionsInfos(logFilePath) {
+		errString := ""
+		if info.err != nil {
+			errString = info.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", info.description, info.path, info.mode, info.owner, info.group, errString)
+	}
+	w.Flush()
+	return b.Bytes()
+}
+
+func zipPermissionsInfos(tempDir, hostname, logFilePath string) error {
+	f := filepath.Join(tempDir, hostname, "permissions.log")
+	err := ensureParentDirsExist(f)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewRedactingWriter(f, os.ModePerm, true)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = w.Write(formatPermissionsInfos(logFilePath))
+	return err
+}
+
+// checkReadable fails if the agent can't read the file or list the directory
+func checkReadable(path string, fi os.FileInfo) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("the agent can't read it, give the read permission to the user running the agent: %s", err)
+	}
+	defer f.Close()
+	if fi.IsDir() {
+		if _, err := f.Readdirnames(1); err != nil && err != io.EOF {
+			return fmt.Errorf("the agent can't list it, give the execute permission to the user running the agent: %s", err)
+		}
+	}
+	return nil
+}
+
+// checkWritable fails if the agent can't append to the file
+func checkWritable(path string, fi os.FileInfo) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("the agent can't write it, give the write permission to the user running the agent: %s", err)
+	}
+	return f.Close()
+}
+
+// checkSocket fails if the path is not a socket the agent created
+func checkSocket(path string, fi os.FileInfo) error {
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("it is not a socket, remove it so that the agent can create the socket")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package flare
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// secrets are only supported on unix
+const secretsBackendAudited = true
+
+// fileOwner returns the names of the user and group owning the file,
+// their IDs if the names can't be resolved
+func fileOwner(fi os.FileInfo) (string, string) {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+
+	owner := strconv.FormatUint(uint64(stat.Uid), 10)
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group := strconv.FormatUint(uint64(stat.Gid), 10)
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
+
+// checkNotWorldWritable fails if anyone can modify the file, as it drives
+// what the agent runs or sends
+func checkNotWorldWritable(path string, fi os.FileInfo) error {
+	if fi.Mode().Perm()&0002 != 0 {
+		return fmt.Errorf("it is writable by others, remove the write permission with `chmod o-w %s`", path)
+	}
+	return nil
+}
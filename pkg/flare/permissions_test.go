@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package flare
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestGetPermissionsInfos(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestGetPermissionsInfos")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	command := filepath.Join(dir, "secrets.sh")
+	require.NoError(t, ioutil.WriteFile(command, []byte("#!/bin/sh"), 0700))
+	require.NoError(t, os.Chmod(command, 0777))
+	logFile := filepath.Join(dir, "agent.log")
+	require.NoError(t, ioutil.WriteFile(logFile, []byte{}, 0600))
+	socket := filepath.Join(dir, "dsd.socket")
+	require.NoError(t, ioutil.WriteFile(socket, []byte{}, 0600))
+
+	config.Datadog.Set("confd_path", dir)
+	config.Datadog.Set("secret_backend_command", command)
+	config.Datadog.Set("dogstatsd_socket", socket)
+	defer config.Datadog.Set("confd_path", "")
+	defer config.Datadog.Set("secret_backend_command", "")
+	defer config.Datadog.Set("dogstatsd_socket", "")
+
+	infos := make(map[string]permissionsInfo)
+	for _, info := range getPermissionsInfos(logFile) {
+		infos[info.description] = info
+	}
+
+	assert.NoError(t, infos["checks configuration directory"].err)
+	assert.NoError(t, infos["log file"].err)
+	assert.Equal(t, os.FileMode(0600), infos["log file"].mode)
+	assert.NotEmpty(t, infos["log file"].owner)
+	assert.Error(t, infos["secrets backend command"].err)
+	assert.Error(t, infos["dogstatsd socket"].err)
+
+	assert.Error(t, CheckPermissions(logFile))
+	assert.Contains(t, string(formatPermissionsInfos(logFile)), "it is writable by others")
+}
+
+func TestGetPermissionsInfosMissingFile(t *testing.T) {
+	infos := getPermissionsInfos("/does/not/exist.log")
+	for _, info := range infos {
+		if info.description == "log file" {
+			assert.Error(t, info.err)
+			return
+		}
+	}
+	assert.Fail(t, "the log file permissions were not collected")
+}
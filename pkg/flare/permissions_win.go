@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package flare
+
+import (
+	"os"
+)
+
+// secrets are not available on windows
+const secretsBackendAudited = false
+
+// fileOwner is not implemented on windows, where the permissions are
+// defined by ACLs
+func fileOwner(fi os.FileInfo) (string, string) {
+	return "", ""
+}
+
+// checkNotWorldWritable is not implemented on windows, where the permissions
+// are defined by ACLs
+func checkNotWorldWritable(path string, fi os.FileInfo) error {
+	return nil
+}
@@ -35,19 +35,23 @@ func initDomainForwarderExpvars() {
 // HTTP and retrying them if needed. One domainForwarder is created per HTTP
 // backend.
 type domainForwarder struct {
-	domain              string
-	numberOfWorkers     int
-	highPrio            chan Transaction // use to receive new transactions
-	lowPrio             chan Transaction // use to retry transactions
-	requeuedTransaction chan Transaction
-	stopRetry           chan bool
-	workers             []*Worker
-	retryQueue          []Transaction
-	retryQueueLimit     int
-	internalState       uint32
-	m                   sync.Mutex // To control Start/Stop races
-	isRetrying          int32
-	blockedList         *blockedEndpoints
+	domain                 string
+	numberOfWorkers        int
+	highPrio               chan Transaction // use to receive new transactions
+	lowPrio                chan Transaction // use to retry transactions
+	requeuedTransaction    chan Transaction
+	quarantinedTransaction chan Transaction
+	stopRetry              chan bool
+	workers                []*Worker
+	retryQueue             []Transaction
+	retryQueueLimit        int
+	internalState          uint32
+	m                      sync.Mutex // To control Start/Stop races
+	isRetrying             int32
+	pending                int64 // new transactions not processed yet by the workers, accessed atomically
+	blockedList            *blockedEndpoints
+	quarantine             *quarantine
+	failover               *failover
 }
 
 func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int) *domainForwarder {
@@ -57,6 +61,26 @@ func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int)
 		retryQueueLimit: retryQueueLimit,
 		internalState:   Stopped,
 		blockedList:     newBlockedEndpoints(),
+		quarantine:      newQuarantine(0, 0),
+	}
+}
+
+// activeDomain returns the domain the transactions are sent to, the active
+// domain of the failover if any
+func (f *domainForwarder) activeDomain() string {
+	if f.failover == nil {
+		return f.domain
+	}
+	return f.failover.activeDomain()
+}
+
+// route sets the domain of a transaction to the active domain
+func (f *domainForwarder) route(t Transaction) {
+	if f.failover == nil {
+		return
+	}
+	if httpTransaction, ok := t.(*HTTPTransaction); ok {
+		httpTransaction.Domain = f.failover.activeDomain()
 	}
 }
 
@@ -82,6 +106,7 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	sort.Sort(byCreatedTime(f.retryQueue))
 
 	for _, t := range f.retryQueue {
+		f.route(t)
 		if !f.blockedList.isBlock(t.GetTarget()) {
 			select {
 			case f.lowPrio <- t:
@@ -114,14 +139,57 @@ func (f *domainForwarder) requeueTransaction(t Transaction) {
 	transactionsRetryQueueSize.Set(int64(len(f.retryQueue)))
 }
 
+func (f *domainForwarder) quarantineTransaction(t Transaction) {
+	if !f.quarantine.add(t) {
+		log.Errorf("API Key invalid, dropping transaction for %s", t.GetTarget())
+		transactionsDropped.Add(1)
+	}
+}
+
+// replayQuarantinedTransactions sends back the quarantined transactions which
+// API key was validated, or which were signed with a new valid key, to the
+// workers
+func (f *domainForwarder) replayQuarantinedTransactions(result quarantineCheck) {
+	released := f.quarantine.release(result)
+	for _, t := range released {
+		f.route(t)
+		select {
+		case f.lowPrio <- t:
+			transactionsReplayed.Add(1)
+		default:
+			// the retry queue will send it later
+			f.requeueTransaction(t)
+		}
+	}
+	if len(released) > 0 {
+		log.Infof("Valid API Key found, replaying %d quarantined transactions for %s", len(released), f.domain)
+	}
+}
+
+func (f *domainForwarder) checkQuarantine(now time.Time) {
+	if dropped := f.quarantine.expire(now); dropped > 0 {
+		log.Errorf("Dropped %d transactions quarantined for more than %s because of an invalid API key for %s", dropped, f.quarantine.period, f.domain)
+		transactionsDropped.Add(int64(dropped))
+	}
+	f.quarantine.check(f.activeDomain(), now)
+}
+
 func (f *domainForwarder) handleFailedTransactions() {
 	ticker := time.NewTicker(flushInterval)
 	for {
 		select {
 		case tickTime := <-ticker.C:
+			if f.failover != nil {
+				f.failover.check(tickTime)
+			}
 			f.retryTransactions(tickTime)
+			f.checkQuarantine(tickTime)
 		case t := <-f.requeuedTransaction:
 			f.requeueTransaction(t)
+		case t := <-f.quarantinedTransaction:
+			f.quarantineTransaction(t)
+		case result := <-f.quarantine.checks:
+			f.replayQuarantinedTransactions(result)
 		case <-f.stopRetry:
 			ticker.Stop()
 			return
@@ -133,9 +201,13 @@ func (f *domainForwarder) init() {
 	f.highPrio = make(chan Transaction, chanBufferSize)
 	f.lowPrio = make(chan Transaction, chanBufferSize)
 	f.requeuedTransaction = make(chan Transaction, chanBufferSize)
+	f.quarantinedTransaction = make(chan Transaction, chanBufferSize)
 	f.stopRetry = make(chan bool)
 	f.workers = []*Worker{}
 	f.retryQueue = []Transaction{}
+	f.quarantine.reset()
+	f.quarantine.start()
+	atomic.StoreInt64(&f.pending, 0)
 }
 
 // Start starts a domainForwarder.
@@ -153,6 +225,8 @@ func (f *domainForwarder) Start() error {
 
 	for i := 0; i < f.numberOfWorkers; i++ {
 		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
+		w.QuarantineChan = f.quarantinedTransaction
+		w.processedHighPrio = f.transactionProcessed
 		w.Start()
 		f.workers = append(f.workers, w)
 	}
@@ -174,14 +248,17 @@ func (f *domainForwarder) Stop() {
 	}
 
 	f.stopRetry <- true
+	f.quarantine.stop()
 	for _, w := range f.workers {
 		w.Stop()
 	}
 	f.workers = []*Worker{}
 	f.retryQueue = []Transaction{}
+	f.quarantine.reset()
 	close(f.highPrio)
 	close(f.lowPrio)
 	close(f.requeuedTransaction)
+	close(f.quarantinedTransaction)
 	log.Info("domainForwarder stopped")
 	f.internalState = Stopped
 }
@@ -194,11 +271,26 @@ func (f *domainForwarder) State() uint32 {
 	return f.internalState
 }
 
+// transactionProcessed is called by the workers once a new transaction is
+// processed: sent, requeued to be retried later or dropped
+func (f *domainForwarder) transactionProcessed() {
+	atomic.AddInt64(&f.pending, -1)
+}
+
+// drained returns whether the new transactions were all processed, the failed
+// ones may still be waiting in the retry queue
+func (f *domainForwarder) drained() bool {
+	return atomic.LoadInt64(&f.pending) <= 0
+}
+
 func (f *domainForwarder) sendHTTPTransactions(transaction Transaction) error {
+	f.route(transaction)
 	// We don't want to block the collector if the highPrio queue is full
+	atomic.AddInt64(&f.pending, 1)
 	select {
 	case f.highPrio <- transaction:
 	default:
+		atomic.AddInt64(&f.pending, -1)
 		transactionsDroppedOnInput.Add(1)
 		return fmt.Errorf("the forwarder input queue for %s is full: dropping transaction", f.domain)
 	}
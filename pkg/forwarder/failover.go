@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"expvar"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	// failoverCheckInterval is the interval at which the health of the
+	// domains of a failover is checked
+	failoverCheckInterval = 30 * time.Second
+	failoverCheckTimeout  = 10 * time.Second
+
+	activeDomains = expvar.Map{}
+)
+
+func initFailoverExpvars() {
+	activeDomains.Init()
+	forwarderExpvars.Set("ActiveDomains", &activeDomains)
+}
+
+// failover holds an ordered list of domains receiving the same data, the
+// transactions are sent to the first healthy one. A domain is healthy if
+// it answers HTTP requests.
+type failover struct {
+	domains    []string
+	active     int
+	lastCheck  time.Time
+	isChecking int32
+	m          sync.RWMutex
+	// for testing purpose
+	isHealthy func(domain string) bool
+}
+
+// newFailover returns a new failover over domains, the first one is the
+// primary domain, active until it is reported unhealthy
+func newFailover(domains []string) *failover {
+	f := &failover{
+		domains:   domains,
+		isHealthy: isDomainHealthy,
+	}
+	activeDomains.Set(f.domains[0], stringVar(f.domains[0]))
+	return f
+}
+
+// activeDomain returns the domain the transactions are sent to
+func (f *failover) activeDomain() string {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	return f.domains[f.active]
+}
+
+// check updates the active domain in the background, at most once per
+// failoverCheckInterval
+func (f *failover) check(now time.Time) {
+	if now.Sub(f.lastCheck) < failoverCheckInterval {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&f.isChecking, 0, 1) {
+		return
+	}
+	f.lastCheck = now
+
+	go func() {
+		defer atomic.StoreInt32(&f.isChecking, 0)
+		f.selectDomain()
+	}()
+}
+
+// selectDomain activates the first healthy domain, the active domain is kept
+// if none is healthy
+func (f *failover) selectDomain() {
+	for i, domain := range f.domains {
+		if !f.isHealthy(domain) {
+			continue
+		}
+
+		f.m.Lock()
+		previous := f.domains[f.active]
+		f.active = i
+		f.m.Unlock()
+
+		if previous != domain {
+			log.Warnf("Domain %s is unhealthy or recovered, sending the transactions to %s instead", previous, domain)
+			activeDomains.Set(f.domains[0], stringVar(domain))
+		}
+		return
+	}
+	log.Errorf("No healthy domain among %v, keeping %s", f.domains, f.activeDomain())
+}
+
+// isDomainHealthy returns whether the domain answers HTTP requests, the
+// validation endpoint is used as it doesn't need a valid API key
+func isDomainHealthy(domain string) bool {
+	client := &http.Client{
+		Transport: util.CreateHTTPTransport(),
+		Timeout:   failoverCheckTimeout,
+	}
+
+	resp, err := client.Get(domain + v1ValidateEndpoint)
+	if err != nil {
+		log.Debugf("Domain %s is unhealthy: %s", domain, util.SanitizeURL(err.Error()))
+		return false
+	}
+	resp.Body.Close()
+	// client errors are expected, no API key is sent
+	return resp.StatusCode < 500
+}
+
+func stringVar(s string) *expvar.String {
+	v := &expvar.String{}
+	v.Set(s)
+	return v
+}
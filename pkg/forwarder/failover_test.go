@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverSelectDomain(t *testing.T) {
+	healthy := map[string]bool{"primary": true, "secondary": true}
+	f := newFailover([]string{"primary", "secondary", "tertiary"})
+	f.isHealthy = func(domain string) bool { return healthy[domain] }
+
+	f.selectDomain()
+	assert.Equal(t, "primary", f.activeDomain())
+
+	healthy["primary"] = false
+	f.selectDomain()
+	assert.Equal(t, "secondary", f.activeDomain())
+
+	// the active domain is kept if no domain is healthy
+	healthy["secondary"] = false
+	f.selectDomain()
+	assert.Equal(t, "secondary", f.activeDomain())
+
+	// the primary domain is used again once it recovers
+	healthy["primary"] = true
+	f.selectDomain()
+	assert.Equal(t, "primary", f.activeDomain())
+}
+
+func TestDomainForwarderRoute(t *testing.T) {
+	forwarder := newDomainForwarder("primary", 1, 10)
+	transaction := NewHTTPTransaction()
+	transaction.Domain = "primary"
+
+	forwarder.route(transaction)
+	assert.Equal(t, "primary", transaction.Domain)
+
+	forwarder.failover = newFailover([]string{"primary", "secondary"})
+	forwarder.failover.active = 1
+	forwarder.route(transaction)
+	assert.Equal(t, "secondary", transaction.Domain)
+}
+
+func TestIsDomainHealthy(t *testing.T) {
+	statusCode := http.StatusForbidden
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer ts.Close()
+
+	assert.True(t, isDomainHealthy(ts.URL))
+	statusCode = http.StatusServiceUnavailable
+	assert.False(t, isDomainHealthy(ts.URL))
+	assert.False(t, isDomainHealthy("http://localhost:0"))
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -20,6 +21,10 @@ import (
 )
 
 var (
+	// drainCheckInterval is the interval at which Drain checks whether the
+	// forwarder is drained
+	drainCheckInterval = 100 * time.Millisecond
+
 	forwarderExpvars          = expvar.NewMap("forwarder")
 	transactionsExpvars       = expvar.Map{}
 	transactionsSeries        = expvar.Int{}
@@ -48,6 +53,8 @@ func init() {
 	initDomainForwarderExpvars()
 	initTransactionExpvars()
 	initForwarderHealthExpvars()
+	initQuarantineExpvars()
+	initFailoverExpvars()
 }
 
 const (
@@ -83,6 +90,7 @@ type Payloads []*[]byte
 type Forwarder interface {
 	Start() error
 	Stop()
+	Drain(timeout time.Duration) bool
 	SubmitV1Series(payload Payloads, extra http.Header) error
 	SubmitV1Intake(payload Payloads, extra http.Header) error
 	SubmitV1CheckRuns(payload Payloads, extra http.Header) error
@@ -117,14 +125,39 @@ func NewDefaultForwarder(keysPerDomains map[string][]string) *DefaultForwarder {
 	}
 	numWorkers := config.Datadog.GetInt("forwarder_num_workers")
 	retryQueueMaxSize := config.Datadog.GetInt("forwarder_retry_queue_max_size")
+	quarantinePeriod := config.Datadog.GetDuration("forwarder_apikey_quarantine_period") * time.Second
+	quarantineMaxSize := config.Datadog.GetInt("forwarder_apikey_quarantine_max_size")
+	ddURL := config.Datadog.GetString("dd_url")
+	failoverDomains := config.Datadog.GetStringSlice("failover_domains")
 
 	for domain, keys := range keysPerDomains {
+		isDDURL := domain == ddURL
+		configuredDomain := domain
 		domain, _ := config.AddAgentVersionToDomain(domain, "app")
 		if keys == nil || len(keys) == 0 {
 			log.Errorf("No API keys for domain '%s', dropping domain ", domain)
 		} else {
 			f.keysPerDomains[domain] = keys
-			f.domainForwarders[domain] = newDomainForwarder(domain, numWorkers, retryQueueMaxSize)
+			df := newDomainForwarder(domain, numWorkers, retryQueueMaxSize)
+			df.quarantine = newQuarantine(quarantinePeriod, quarantineMaxSize)
+			df.quarantine.signedKeys = keys
+			df.quarantine.configuredKeys = func() []string {
+				keysPerDomain, err := config.GetMultipleEndpoints()
+				if err != nil {
+					return nil
+				}
+				return keysPerDomain[configuredDomain]
+			}
+			// the failover domains receive the data of the main domain only
+			if isDDURL && len(failoverDomains) > 0 {
+				domains := []string{domain}
+				for _, failoverDomain := range failoverDomains {
+					failoverDomain, _ = config.AddAgentVersionToDomain(failoverDomain, "app")
+					domains = append(domains, failoverDomain)
+				}
+				df.failover = newFailover(domains)
+			}
+			f.domainForwarders[domain] = df
 		}
 	}
 
@@ -181,6 +214,37 @@ func (f *DefaultForwarder) Stop() {
 	f.domainForwarders = map[string]*domainForwarder{}
 }
 
+// Drain blocks until the transactions already submitted are processed by the
+// workers, or until the timeout, and returns whether they were. It's used
+// before stopping the forwarder, which drops the pending transactions, so that
+// the last payloads are sent. The transactions failing are not waited for.
+func (f *DefaultForwarder) Drain(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for !f.drained() {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
+
+func (f *DefaultForwarder) drained() bool {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for _, df := range f.domainForwarders {
+		if !df.drained() {
+			return false
+		}
+	}
+	return true
+}
+
 // State returns the internal state of the forwarder (Started or Stopped)
 func (f *DefaultForwarder) State() uint32 {
 	// Lock so we can't start/stop a Forwarder while getting its state
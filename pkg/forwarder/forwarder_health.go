@@ -119,32 +119,46 @@ func (fh *forwarderHealth) setAPIKeyStatus(apiKey string, domain string, status
 }
 
 func (fh *forwarderHealth) validateAPIKey(apiKey, domain string) (bool, error) {
+	valid, err := validateAPIKey(domain, apiKey, fh.timeout)
+	if err != nil {
+		fh.setAPIKeyStatus(apiKey, domain, &apiKeyStatusUnknown)
+	} else if valid {
+		fh.setAPIKeyStatus(apiKey, domain, &apiKeyValid)
+	} else {
+		fh.setAPIKeyStatus(apiKey, domain, &apiKeyInvalid)
+	}
+	return valid, err
+}
+
+// checkAPIKey validates an API key against the domain within the default timeout
+func checkAPIKey(domain, apiKey string) (bool, error) {
+	return validateAPIKey(domain, apiKey, validateAPIKeyTimeout)
+}
+
+// validateAPIKey queries the validation endpoint of the domain
+func validateAPIKey(domain, apiKey string, timeout time.Duration) (bool, error) {
 	url := fmt.Sprintf("%s%s?api_key=%s", domain, v1ValidateEndpoint, apiKey)
 
 	transport := util.CreateHTTPTransport()
 
 	client := &http.Client{
 		Transport: transport,
-		Timeout:   fh.timeout,
+		Timeout:   timeout,
 	}
 
 	resp, err := client.Get(url)
 	if err != nil {
-		fh.setAPIKeyStatus(apiKey, domain, &apiKeyStatusUnknown)
 		return false, err
 	}
 	defer resp.Body.Close()
 
 	// Server will respond 200 if the key is valid or 403 if invalid
 	if resp.StatusCode == 200 {
-		fh.setAPIKeyStatus(apiKey, domain, &apiKeyValid)
 		return true, nil
 	} else if resp.StatusCode == 403 {
-		fh.setAPIKeyStatus(apiKey, domain, &apiKeyInvalid)
 		return false, nil
 	}
 
-	fh.setAPIKeyStatus(apiKey, domain, &apiKeyStatusUnknown)
 	return false, fmt.Errorf("Unexpected response code from the apikey validation endpoint: %v", resp.StatusCode)
 }
 
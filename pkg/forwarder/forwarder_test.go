@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -140,6 +141,29 @@ func TestSendHTTPTransactions(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDrain(t *testing.T) {
+	defer func(original time.Duration) { drainCheckInterval = original }(drainCheckInterval)
+	drainCheckInterval = time.Millisecond
+
+	forwarder := NewDefaultForwarder(monoKeysDomains)
+	forwarder.Start()
+	defer forwarder.Stop()
+	assert.True(t, forwarder.Drain(time.Second))
+
+	release := make(chan time.Time)
+	tr := newTestTransaction()
+	tr.On("GetTarget").Return("")
+	tr.On("Process", mock.Anything).Return(nil).WaitUntil(release).Times(1)
+	require.Nil(t, forwarder.domainForwarders[testVersionDomain].sendHTTPTransactions(tr))
+
+	// the transaction is still being sent
+	assert.False(t, forwarder.Drain(10*time.Millisecond))
+
+	close(release)
+	assert.True(t, forwarder.Drain(time.Second))
+	tr.AssertExpectations(t)
+}
+
 func TestSubmitV1Intake(t *testing.T) {
 	forwarder := NewDefaultForwarder(monoKeysDomains)
 	forwarder.Start()
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"errors"
+	"expvar"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	// quarantineCheckInterval is the interval at which the API keys of the
+	// quarantined transactions are validated
+	quarantineCheckInterval = 1 * time.Minute
+
+	transactionsQuarantined     = expvar.Int{}
+	transactionsReplayed        = expvar.Int{}
+	transactionsQuarantineSize  = expvar.Int{}
+	transactionsQuarantineDrops = expvar.Int{}
+)
+
+func initQuarantineExpvars() {
+	transactionsExpvars.Set("Quarantined", &transactionsQuarantined)
+	transactionsExpvars.Set("Replayed", &transactionsReplayed)
+	transactionsExpvars.Set("QuarantineSize", &transactionsQuarantineSize)
+	transactionsExpvars.Set("QuarantineDropped", &transactionsQuarantineDrops)
+}
+
+// errAPIKeyInvalid is returned by the transactions rejected by the backend
+// because of their API key
+var errAPIKeyInvalid = errors.New("API key invalid")
+
+// apiKeyOf returns the API key used by a transaction
+func apiKeyOf(t Transaction) string {
+	if httpTransaction, ok := t.(*HTTPTransaction); ok {
+		return httpTransaction.Headers.Get(apiHTTPHeaderKey)
+	}
+	return ""
+}
+
+// resignAPIKey signs a transaction with another API key
+func resignAPIKey(t Transaction, apiKey string) {
+	httpTransaction, ok := t.(*HTTPTransaction)
+	if !ok {
+		return
+	}
+	previous := httpTransaction.Headers.Get(apiHTTPHeaderKey)
+	httpTransaction.Headers.Set(apiHTTPHeaderKey, apiKey)
+	httpTransaction.Endpoint = strings.Replace(httpTransaction.Endpoint, "api_key="+previous, "api_key="+apiKey, 1)
+}
+
+// quarantineCheck is the result of the validation of the API keys
+type quarantineCheck struct {
+	// validKeys are the API keys of the quarantined transactions
+	validKeys map[string]bool
+	// resignKey is a valid API key of the domain the payloads were not sent
+	// with, empty if there is none
+	resignKey string
+}
+
+// quarantine holds the transactions rejected because of their API key until
+// this same key is valid again or they are older than the quarantine period.
+// When the key of a transaction is still invalid, it's signed again with a
+// valid key currently configured for the domain, like a rotated key. The keys
+// the payloads were already sent with are not used, to not send them twice.
+// Except the validation of the keys, the quarantine is only used from the
+// retry goroutine of its domainForwarder.
+type quarantine struct {
+	period       time.Duration
+	limit        int
+	transactions []Transaction
+	lastCheck    time.Time
+	isChecking   int32
+	checks       chan quarantineCheck
+	stopChecks   chan struct{}
+	// signedKeys are the API keys the payloads of the domain are sent with
+	signedKeys []string
+	// configuredKeys returns the API keys currently configured for the domain
+	configuredKeys func() []string
+	// for testing purpose
+	validateAPIKey func(domain, apiKey string) (bool, error)
+}
+
+// newQuarantine returns a new quarantine, it is disabled if the period or the
+// limit is not positive, the transactions are then dropped
+func newQuarantine(period time.Duration, limit int) *quarantine {
+	return &quarantine{
+		period:         period,
+		limit:          limit,
+		checks:         make(chan quarantineCheck, 1),
+		stopChecks:     make(chan struct{}),
+		configuredKeys: func() []string { return nil },
+		validateAPIKey: checkAPIKey,
+	}
+}
+
+// start allows the checks of the API keys to send their result, after stop
+func (q *quarantine) start() {
+	q.stopChecks = make(chan struct{})
+}
+
+// stop drops the result of the running check of the API keys, nothing reads
+// the checks once the domainForwarder is stopped
+func (q *quarantine) stop() {
+	close(q.stopChecks)
+}
+
+func (q *quarantine) enabled() bool {
+	return q.period > 0 && q.limit > 0
+}
+
+// add quarantines a transaction, returns false if it has to be dropped
+func (q *quarantine) add(t Transaction) bool {
+	if !q.enabled() || len(q.transactions) >= q.limit {
+		return false
+	}
+	q.transactions = append(q.transactions, t)
+	transactionsQuarantined.Add(1)
+	transactionsQuarantineSize.Add(1)
+	return true
+}
+
+// expire drops the transactions created before the quarantine period, and
+// returns the number of transactions dropped
+func (q *quarantine) expire(now time.Time) int {
+	kept := q.transactions[:0]
+	for _, t := range q.transactions {
+		if now.Sub(t.GetCreatedAt()) < q.period {
+			kept = append(kept, t)
+		}
+	}
+	dropped := len(q.transactions) - len(kept)
+	q.transactions = kept
+	transactionsQuarantineDrops.Add(int64(dropped))
+	transactionsQuarantineSize.Add(-int64(dropped))
+	return dropped
+}
+
+// check validates the API keys of the quarantined transactions and the new
+// configured keys of the domain in the background, at most once per
+// quarantineCheckInterval. The result is sent on the checks channel.
+func (q *quarantine) check(domain string, now time.Time) {
+	if len(q.transactions) == 0 || now.Sub(q.lastCheck) < quarantineCheckInterval {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&q.isChecking, 0, 1) {
+		return
+	}
+	q.lastCheck = now
+
+	keys := map[string]bool{}
+	for _, t := range q.transactions {
+		keys[apiKeyOf(t)] = false
+	}
+	signed := map[string]bool{}
+	for _, apiKey := range q.signedKeys {
+		signed[apiKey] = true
+	}
+	var newKeys []string
+	for _, apiKey := range q.configuredKeys() {
+		if _, quarantined := keys[apiKey]; apiKey != "" && !signed[apiKey] && !quarantined {
+			newKeys = append(newKeys, apiKey)
+		}
+	}
+	stop := q.stopChecks
+
+	go func() {
+		defer atomic.StoreInt32(&q.isChecking, 0)
+		result := quarantineCheck{validKeys: keys}
+		for apiKey := range keys {
+			valid, err := q.validateAPIKey(domain, apiKey)
+			if err != nil {
+				log.Debugf("Could not validate the API key of quarantined transactions: %s", err)
+				continue
+			}
+			keys[apiKey] = valid
+		}
+		for _, apiKey := range newKeys {
+			valid, err := q.validateAPIKey(domain, apiKey)
+			if err != nil {
+				log.Debugf("Could not validate a configured API key: %s", err)
+				continue
+			}
+			if valid {
+				result.resignKey = apiKey
+				break
+			}
+		}
+		select {
+		case q.checks <- result:
+		case <-stop:
+		}
+	}()
+}
+
+// release removes the transactions which API key is valid from the quarantine
+// and returns them, the transactions which key is still invalid are signed
+// with the new valid key of the domain if any
+func (q *quarantine) release(result quarantineCheck) []Transaction {
+	var released []Transaction
+	kept := q.transactions[:0]
+	for _, t := range q.transactions {
+		switch {
+		case result.validKeys[apiKeyOf(t)]:
+			released = append(released, t)
+		case result.resignKey != "":
+			resignAPIKey(t, result.resignKey)
+			released = append(released, t)
+		default:
+			kept = append(kept, t)
+		}
+	}
+	q.transactions = kept
+	transactionsQuarantineSize.Add(-int64(len(released)))
+	return released
+}
+
+// reset drops all the quarantined transactions
+func (q *quarantine) reset() {
+	transactionsQuarantineSize.Add(-int64(len(q.transactions)))
+	q.transactions = nil
+}
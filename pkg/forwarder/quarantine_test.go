@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuarantinedTransaction(apiKey string, createdAt time.Time) *HTTPTransaction {
+	t := NewHTTPTransaction()
+	t.Headers.Set(apiHTTPHeaderKey, apiKey)
+	t.createdAt = createdAt
+	return t
+}
+
+func TestQuarantineDisabled(t *testing.T) {
+	q := newQuarantine(0, 10)
+	assert.False(t, q.add(newQuarantinedTransaction("key", time.Now())))
+	q = newQuarantine(time.Minute, 0)
+	assert.False(t, q.add(newQuarantinedTransaction("key", time.Now())))
+}
+
+func TestQuarantineLimit(t *testing.T) {
+	q := newQuarantine(time.Minute, 1)
+	assert.True(t, q.add(newQuarantinedTransaction("key", time.Now())))
+	assert.False(t, q.add(newQuarantinedTransaction("key", time.Now())))
+	assert.Len(t, q.transactions, 1)
+}
+
+func TestQuarantineExpire(t *testing.T) {
+	now := time.Now()
+	q := newQuarantine(time.Minute, 10)
+	old := newQuarantinedTransaction("key", now.Add(-2*time.Minute))
+	recent := newQuarantinedTransaction("key", now.Add(-30*time.Second))
+	q.add(old)
+	q.add(recent)
+
+	assert.Equal(t, 1, q.expire(now))
+	assert.Equal(t, []Transaction{recent}, q.transactions)
+}
+
+func TestQuarantineCheckAndRelease(t *testing.T) {
+	now := time.Now()
+	q := newQuarantine(time.Hour, 10)
+	q.validateAPIKey = func(domain, apiKey string) (bool, error) {
+		assert.Equal(t, "https://example.com", domain)
+		return apiKey == "rotated", nil
+	}
+
+	revoked := newQuarantinedTransaction("revoked", now)
+	rotated := newQuarantinedTransaction("rotated", now)
+	q.add(revoked)
+	q.add(rotated)
+
+	q.check("https://example.com", now)
+	var result quarantineCheck
+	select {
+	case result = <-q.checks:
+	case <-time.After(time.Second):
+		require.Fail(t, "the API keys were not validated")
+	}
+	assert.Equal(t, map[string]bool{"revoked": false, "rotated": true}, result.validKeys)
+	assert.Equal(t, "", result.resignKey)
+
+	assert.Equal(t, []Transaction{rotated}, q.release(result))
+	assert.Equal(t, []Transaction{revoked}, q.transactions)
+
+	// the keys are validated once per interval
+	q.check("https://example.com", now.Add(time.Second))
+	assert.Len(t, q.checks, 0)
+}
+
+func TestQuarantineResign(t *testing.T) {
+	now := time.Now()
+	q := newQuarantine(time.Hour, 10)
+	q.signedKeys = []string{"revoked", "other"}
+	q.configuredKeys = func() []string { return []string{"other", "invalid", "new"} }
+	q.validateAPIKey = func(domain, apiKey string) (bool, error) {
+		return apiKey == "other" || apiKey == "new", nil
+	}
+
+	revoked := newQuarantinedTransaction("revoked", now)
+	revoked.Endpoint = "/api/v1/series?api_key=revoked"
+	q.add(revoked)
+
+	q.check("https://example.com", now)
+	var result quarantineCheck
+	select {
+	case result = <-q.checks:
+	case <-time.After(time.Second):
+		require.Fail(t, "the API keys were not validated")
+	}
+	// the payloads were already sent with the other key
+	assert.Equal(t, "new", result.resignKey)
+
+	assert.Equal(t, []Transaction{revoked}, q.release(result))
+	assert.Empty(t, q.transactions)
+	assert.Equal(t, "new", revoked.Headers.Get(apiHTTPHeaderKey))
+	assert.Equal(t, "/api/v1/series?api_key=new", revoked.Endpoint)
+}
+
+func TestQuarantineCheckStopped(t *testing.T) {
+	now := time.Now()
+	q := newQuarantine(time.Hour, 10)
+	q.validateAPIKey = func(domain, apiKey string) (bool, error) { return false, nil }
+
+	waitCheck := func() {
+		for i := 0; i < 100 && atomic.LoadInt32(&q.isChecking) == 1; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// the result of the first check fills the channel, nothing reads it
+	q.add(newQuarantinedTransaction("revoked", now))
+	q.check("https://example.com", now)
+	waitCheck()
+	require.Len(t, q.checks, 1)
+
+	q.lastCheck = time.Time{}
+	q.check("https://example.com", now)
+	q.stop()
+	// the check returns once the quarantine is stopped
+	waitCheck()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&q.isChecking))
+}
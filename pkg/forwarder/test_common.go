@@ -52,6 +52,11 @@ func (tf *MockedForwarder) Stop() {
 	tf.Called()
 }
 
+// Drain updates the internal mock struct
+func (tf *MockedForwarder) Drain(timeout time.Duration) bool {
+	return tf.Called(timeout).Bool(0)
+}
+
 // SubmitV1Series updates the internal mock struct
 func (tf *MockedForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
 	return tf.Called(payload, extra).Error(0)
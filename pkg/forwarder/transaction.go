@@ -10,12 +10,12 @@ import (
 	"context"
 	"expvar"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/bufferpool"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -24,6 +24,10 @@ var (
 	transactionsSuccessful     = expvar.Int{}
 	transactionsDroppedOnInput = expvar.Int{}
 	transactionsErrors         = expvar.Int{}
+
+	// responseBuffers holds the buffers the response bodies are read in,
+	// the bodies are only logged
+	responseBuffers = bufferpool.New(64 * 1024)
 )
 
 func initTransactionExpvars() {
@@ -31,6 +35,7 @@ func initTransactionExpvars() {
 	transactionsExpvars.Set("Success", &transactionsSuccessful)
 	transactionsExpvars.Set("DroppedOnInput", &transactionsDroppedOnInput)
 	transactionsExpvars.Set("Errors", &transactionsErrors)
+	forwarderExpvars.Set("ResponseBuffers", responseBuffers.Stats())
 }
 
 // HTTPTransaction represents one Payload for one Endpoint on one Domain.
@@ -103,20 +108,21 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	b := responseBuffers.Get()
+	defer responseBuffers.Put(b)
+	if _, err := b.ReadFrom(resp.Body); err != nil {
 		log.Errorf("Fail to read the response Body: %s", err)
 		return err
 	}
+	body := b.Bytes()
 
 	if resp.StatusCode == 400 || resp.StatusCode == 404 || resp.StatusCode == 413 {
 		log.Errorf("Error code %q received while sending transaction to %q: %s, dropping it", resp.Status, logURL, string(body))
 		transactionsDropped.Add(1)
 		return nil
 	} else if resp.StatusCode == 403 {
-		log.Errorf("API Key invalid, dropping transaction for %s", logURL)
-		transactionsDropped.Add(1)
-		return nil
+		transactionsErrors.Add(1)
+		return errAPIKeyInvalid
 	} else if resp.StatusCode > 400 {
 		t.ErrorCount++
 		transactionsErrors.Add(1)
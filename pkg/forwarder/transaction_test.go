@@ -107,7 +107,7 @@ func TestProcessHTTPError(t *testing.T) {
 
 	errorCode = http.StatusForbidden
 	err = transaction.Process(context.Background(), client)
-	assert.Nil(t, err)
+	assert.Equal(t, errAPIKeyInvalid, err)
 	assert.Equal(t, transaction.ErrorCount, 1)
 }
 
@@ -29,10 +29,16 @@ type Worker struct {
 	LowPrio <-chan Transaction
 	// RequeueChan is the channel used to send failed transaction back to the Forwarder.
 	RequeueChan chan<- Transaction
-
-	stopChan    chan bool
-	stopped     chan struct{}
-	blockedList *blockedEndpoints
+	// QuarantineChan is the channel used to send transactions rejected because
+	// of their API key back to the Forwarder, they are dropped if it is nil.
+	QuarantineChan chan<- Transaction
+
+	// processedHighPrio is called once a high priority transaction is
+	// processed, if set
+	processedHighPrio func()
+	stopChan          chan bool
+	stopped           chan struct{}
+	blockedList       *blockedEndpoints
 }
 
 // NewWorker returns a new worker to consume Transaction from inputChan
@@ -72,7 +78,7 @@ func (w *Worker) Start() {
 			// handling high priority transactions first
 			select {
 			case t := <-w.HighPrio:
-				if w.callProcess(t) == nil {
+				if w.callProcessHighPrio(t) == nil {
 					continue
 				}
 				return
@@ -83,7 +89,7 @@ func (w *Worker) Start() {
 
 			select {
 			case t := <-w.HighPrio:
-				if w.callProcess(t) != nil {
+				if w.callProcessHighPrio(t) != nil {
 					return
 				}
 			case t := <-w.LowPrio:
@@ -97,6 +103,16 @@ func (w *Worker) Start() {
 	}()
 }
 
+// callProcessHighPrio processes a new transaction and reports it processed,
+// whatever its outcome.
+func (w *Worker) callProcessHighPrio(t Transaction) error {
+	err := w.callProcess(t)
+	if w.processedHighPrio != nil {
+		w.processedHighPrio()
+	}
+	return err
+}
+
 // callProcess will process a transaction and cancel it if we need to stop the
 // worker.
 func (w *Worker) callProcess(t Transaction) error {
@@ -130,12 +146,29 @@ func (w *Worker) process(ctx context.Context, t Transaction) {
 		}
 	}
 
+	quarantine := func(target string) {
+		if w.QuarantineChan != nil {
+			select {
+			case w.QuarantineChan <- t:
+				log.Errorf("API Key invalid, quarantining transaction for %s until its key is valid again", target)
+				return
+			default:
+			}
+		}
+		log.Errorf("API Key invalid, dropping transaction for %s", target)
+		transactionsDropped.Add(1)
+	}
+
 	// Run the endpoint through our blockedEndpoints circuit breaker
 	target := t.GetTarget()
 	if w.blockedList.isBlock(target) {
 		requeue()
 		log.Errorf("Too many errors for endpoint '%s': retrying later", target)
-	} else if err := t.Process(ctx, w.Client); err != nil {
+	} else if err := t.Process(ctx, w.Client); err == errAPIKeyInvalid {
+		// the endpoint is healthy, only this key is rejected
+		w.blockedList.recover(target)
+		quarantine(target)
+	} else if err != nil {
 		w.blockedList.close(target)
 		requeue()
 		log.Errorf("Error while processing transaction: %v", err)
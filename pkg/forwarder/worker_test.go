@@ -108,3 +108,56 @@ func TestWorkerRetryBlockedTransaction(t *testing.T) {
 	assert.Equal(t, mock, retryTransaction)
 	assert.True(t, w.blockedList.isBlock("error_url"))
 }
+
+func TestWorkerQuarantine(t *testing.T) {
+	highPrio := make(chan Transaction)
+	lowPrio := make(chan Transaction)
+	requeue := make(chan Transaction, 1)
+	quarantined := make(chan Transaction, 1)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+	w.QuarantineChan = quarantined
+
+	mock := newTestTransaction()
+	mock.On("Process", w.Client).Return(errAPIKeyInvalid).Times(1)
+	mock.On("GetTarget").Return("forbidden_url").Times(1)
+
+	w.Start()
+	highPrio <- mock
+	quarantinedTransaction := <-quarantined
+	w.Stop()
+	mock.AssertExpectations(t)
+	assert.Equal(t, mock, quarantinedTransaction)
+	assert.Len(t, requeue, 0)
+	assert.False(t, w.blockedList.isBlock("forbidden_url"))
+}
+
+func TestWorkerProcessedHighPrio(t *testing.T) {
+	highPrio := make(chan Transaction)
+	lowPrio := make(chan Transaction)
+	requeue := make(chan Transaction, 1)
+	processed := make(chan bool, 2)
+	w := NewWorker(highPrio, lowPrio, requeue, newBlockedEndpoints())
+	w.processedHighPrio = func() { processed <- true }
+
+	mock := newTestTransaction()
+	mock.On("Process", w.Client).Return(fmt.Errorf("some kind of error")).Times(1)
+	mock.On("GetTarget").Return("error_url").Times(1)
+	mock2 := newTestTransaction()
+	mock2.On("Process", w.Client).Return(nil).Times(1)
+	mock2.On("GetTarget").Return("").Times(1)
+
+	w.Start()
+	// failed transactions are reported processed as well
+	highPrio <- mock
+	<-requeue
+	<-processed
+
+	// the retried transactions aren't reported
+	lowPrio <- mock2
+	<-mock2.processed
+	w.Stop()
+
+	mock.AssertExpectations(t)
+	mock2.AssertExpectations(t)
+	assert.Len(t, processed, 0)
+}
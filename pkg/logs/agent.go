@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
 // Agent represents the data pipeline that collects, decodes,
@@ -33,15 +34,15 @@ type Agent struct {
 	inputs           []restart.Restartable
 }
 
-// NewAgent returns a new Agent
-func NewAgent(sources *config.LogSources, services *service.Services, serverConfig *config.ServerConfig) *Agent {
+// NewAgent returns a new Agent, the metrics extracted from the logs are pushed in metricsOut
+func NewAgent(sources *config.LogSources, services *service.Services, serverConfig *config.ServerConfig, metricsOut chan<- *metrics.MetricSample) *Agent {
 	// setup the auditor
 	messageChan := make(chan message.Message, config.ChanSize)
 	auditor := auditor.New(messageChan, config.LogsAgent.GetString("logs_config.run_path"))
 
 	// setup the pipeline provider that provides pairs of processor and sender
 	connectionManager := sender.NewConnectionManager(serverConfig, config.LogsAgent.GetString("logs_config.socks5_proxy_address"))
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, connectionManager, messageChan)
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, connectionManager, messageChan, metricsOut)
 
 	// setup the inputs
 	inputs := []restart.Restartable{
@@ -8,12 +8,11 @@ package auditor
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/persist"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -26,6 +25,9 @@ const defaultTTL = 23 * time.Hour
 // latest version of the API used by the auditor to retrieve the registry from disk.
 const registryAPIVersion = 2
 
+// name of the registry in the run path
+const registryName = "registry.json"
+
 // Registry holds a list of offsets.
 type Registry interface {
 	GetOffset(identifier string) string
@@ -46,21 +48,21 @@ type JSONRegistry struct {
 
 // An Auditor handles messages successfully submitted to the intake
 type Auditor struct {
-	inputChan    chan message.Message
-	registry     map[string]*RegistryEntry
-	registryPath string
-	mu           sync.Mutex
-	entryTTL     time.Duration
-	done         chan struct{}
+	inputChan chan message.Message
+	registry  map[string]*RegistryEntry
+	store     *persist.Store
+	mu        sync.Mutex
+	entryTTL  time.Duration
+	done      chan struct{}
 }
 
 // New returns an initialized Auditor
 func New(inputChan chan message.Message, runPath string) *Auditor {
 	return &Auditor{
-		inputChan:    inputChan,
-		registryPath: filepath.Join(runPath, "registry.json"),
-		entryTTL:     defaultTTL,
-		done:         make(chan struct{}),
+		inputChan: inputChan,
+		store:     persist.NewStore(runPath),
+		entryTTL:  defaultTTL,
+		done:      make(chan struct{}),
 	}
 }
 
@@ -135,12 +137,12 @@ func (a *Auditor) run() {
 
 // recoverRegistry rebuilds the registry from the state file found at path
 func (a *Auditor) recoverRegistry() map[string]*RegistryEntry {
-	mr, err := ioutil.ReadFile(a.registryPath)
-	if err != nil {
-		log.Error(err)
-		return make(map[string]*RegistryEntry)
-	}
-	r, err := a.unmarshalRegistry(mr)
+	var r map[string]*RegistryEntry
+	err := a.store.Read(registryName, func(mr []byte) error {
+		var err error
+		r, err = a.unmarshalRegistry(mr)
+		return err
+	})
 	if err != nil {
 		log.Error(err)
 		return make(map[string]*RegistryEntry)
@@ -194,7 +196,7 @@ func (a *Auditor) flushRegistry() error {
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(a.registryPath, mr, 0644)
+	return a.store.Write(registryName, mr)
 }
 
 // marshalRegistry marshals a registry
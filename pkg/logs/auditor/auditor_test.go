@@ -31,22 +31,21 @@ type AuditorTestSuite struct {
 }
 
 func (suite *AuditorTestSuite) SetupTest() {
-	suite.testDir = "tests/"
-	os.Remove(suite.testDir)
-	os.MkdirAll(suite.testDir, os.ModeDir)
-	suite.testPath = fmt.Sprintf("%s/auditor.json", suite.testDir)
+	var err error
+	suite.testDir, err = ioutil.TempDir("", "auditor")
+	suite.Nil(err)
+	suite.testPath = fmt.Sprintf("%s/registry.json", suite.testDir)
 
-	_, err := os.Create(suite.testPath)
+	_, err = os.Create(suite.testPath)
 	suite.Nil(err)
 
 	suite.inputChan = make(chan message.Message)
-	suite.a = New(suite.inputChan, "")
-	suite.a.registryPath = suite.testPath
+	suite.a = New(suite.inputChan, suite.testDir)
 	suite.source = config.NewLogSource("", &config.LogsConfig{Path: testpath})
 }
 
 func (suite *AuditorTestSuite) TearDownTest() {
-	os.Remove(suite.testDir)
+	os.RemoveAll(suite.testDir)
 }
 
 func (suite *AuditorTestSuite) TestAuditorUpdatesRegistry() {
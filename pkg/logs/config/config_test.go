@@ -87,6 +87,13 @@ func TestBuildServerConfigShouldSucceedWithDefaultAndValidOverride(t *testing.T)
 	assert.Equal(t, 1234, serverConfig.Port)
 	assert.True(t, serverConfig.UseSSL)
 	assert.Equal(t, ":1234", serverConfig.Address())
+
+	LogsAgent.Set("logs_config.logs_dd_url", "[::1]:1234")
+	serverConfig, err = BuildServerConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "::1", serverConfig.Name)
+	assert.Equal(t, 1234, serverConfig.Port)
+	assert.Equal(t, "[::1]:1234", serverConfig.Address())
 }
 
 func TestBuildServerConfigShouldFailWithInvalidOverride(t *testing.T) {
@@ -26,14 +26,23 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+	// metrics rules, submitting a metric for the matching log lines
+	CountAtMatch     = "count_at_match"
+	HistogramAtMatch = "histogram_at_match"
 )
 
-// ProcessingRule defines an exclusion or a masking rule to
+// MetricValueGroup is the name of the group of the pattern of a
+// histogram_at_match rule which captures the value of the metric
+const MetricValueGroup = "value"
+
+// ProcessingRule defines an exclusion, a masking or a metrics rule to
 // be applied on log lines
 type ProcessingRule struct {
 	Type               string
 	Name               string
-	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
+	ReplacePlaceholder string   `mapstructure:"replace_placeholder" json:"replace_placeholder"`
+	MetricName         string   `mapstructure:"metric_name" json:"metric_name"`
+	TagGroups          []string `mapstructure:"tag_groups" json:"tag_groups"`
 	Pattern            string
 	// TODO: should be moved out
 	Reg                     *regexp.Regexp
@@ -89,6 +98,9 @@ func (c *LogsConfig) Validate() error {
 // - a valid name
 // - a valid type
 // - a valid pattern that compiles
+// The metrics rules must also have a metric name, the pattern of the
+// histogram rules must capture the value of the metric and the pattern
+// must have the groups tagging the metric.
 func (c *LogsConfig) validateProcessingRules() error {
 	for _, rule := range c.ProcessingRules {
 		if rule.Name == "" {
@@ -98,6 +110,10 @@ func (c *LogsConfig) validateProcessingRules() error {
 		switch rule.Type {
 		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
 			break
+		case CountAtMatch, HistogramAtMatch:
+			if rule.MetricName == "" {
+				return fmt.Errorf("metric_name must be set for processing rule `%s`", rule.Name)
+			}
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -107,10 +123,18 @@ func (c *LogsConfig) validateProcessingRules() error {
 		if rule.Pattern == "" {
 			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
 		}
-		_, err := regexp.Compile(rule.Pattern)
+		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
 		}
+		if rule.Type == HistogramAtMatch && !hasGroup(re, MetricValueGroup) {
+			return fmt.Errorf("pattern %s must have a group named %s for processing rule: %s", rule.Pattern, MetricValueGroup, rule.Name)
+		}
+		for _, group := range rule.TagGroups {
+			if !hasGroup(re, group) {
+				return fmt.Errorf("pattern %s must have a group named %s for processing rule: %s", rule.Pattern, group, rule.Name)
+			}
+		}
 	}
 	return nil
 }
@@ -124,7 +148,7 @@ func (c *LogsConfig) Compile() error {
 			return err
 		}
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch:
+		case ExcludeAtMatch, IncludeAtMatch, CountAtMatch, HistogramAtMatch:
 			rules[i].Reg = re
 		case MaskSequences:
 			rules[i].Reg = re
@@ -138,3 +162,13 @@ func (c *LogsConfig) Compile() error {
 	}
 	return nil
 }
+
+// hasGroup returns whether a regular expression has a group with this name
+func hasGroup(re *regexp.Regexp, name string) bool {
+	for _, groupName := range re.SubexpNames() {
+		if groupName == name {
+			return true
+		}
+	}
+	return false
+}
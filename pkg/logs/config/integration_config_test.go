@@ -20,6 +20,9 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: UDPType, Port: 5678},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []ProcessingRule{{Name: "foo", Type: CountAtMatch, MetricName: "foo.errors", Pattern: "ERROR"}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []ProcessingRule{{Name: "foo", Type: CountAtMatch, MetricName: "foo.errors", Pattern: "(?P<level>ERROR|WARN)", TagGroups: []string{"level"}}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []ProcessingRule{{Name: "foo", Type: HistogramAtMatch, MetricName: "foo.duration", Pattern: "took (?P<value>[0-9.]+)s"}}},
 	}
 
 	for _, config := range validConfigs {
@@ -41,6 +44,9 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []ProcessingRule{{Pattern: ".*"}}},
+		{Type: DockerType, ProcessingRules: []ProcessingRule{{Name: "foo", Type: CountAtMatch, Pattern: "ERROR"}}},
+		{Type: DockerType, ProcessingRules: []ProcessingRule{{Name: "foo", Type: HistogramAtMatch, MetricName: "foo.duration", Pattern: "took ([0-9.]+)s"}}},
+		{Type: DockerType, ProcessingRules: []ProcessingRule{{Name: "foo", Type: CountAtMatch, MetricName: "foo.errors", Pattern: "(?P<level>ERROR)", TagGroups: []string{"status"}}}},
 	}
 
 	for _, config := range invalidConfigs {
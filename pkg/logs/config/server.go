@@ -6,7 +6,8 @@
 package config
 
 import (
-	"fmt"
+	"net"
+	"strconv"
 )
 
 // ServerConfig holds the network configuration of the server to send logs to.
@@ -27,5 +28,5 @@ func NewServerConfig(name string, port int, useSSL bool) *ServerConfig {
 
 // Address returns the address of the server to send logs to.
 func (c *ServerConfig) Address() string {
-	return fmt.Sprintf("%s:%d", c.Name, c.Port)
+	return net.JoinHostPort(c.Name, strconv.Itoa(c.Port))
 }
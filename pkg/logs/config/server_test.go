@@ -18,3 +18,8 @@ func TestAddress(t *testing.T) {
 	assert.False(t, config.UseSSL)
 	assert.Equal(t, "foo:12345", config.Address())
 }
+
+func TestAddressIPv6(t *testing.T) {
+	config := NewServerConfig("fd00::1", 10516, true)
+	assert.Equal(t, "[fd00::1]:10516", config.Address())
+}
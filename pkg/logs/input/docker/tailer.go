@@ -42,6 +42,9 @@ type Tailer struct {
 	cli           *client.Client
 	source        *config.LogSource
 	containerTags []string
+	// service of the container from unified service tagging,
+	// the service of the log source config takes precedence
+	containerService string
 
 	sleepDuration      time.Duration
 	shouldStop         bool
@@ -186,6 +189,7 @@ func (t *Tailer) forwardMessages() {
 			origin.Offset = dockerMsg.Timestamp
 			origin.Identifier = t.Identifier()
 			origin.SetTags(t.containerTags)
+			origin.SetService(t.containerService)
 			t.outputChan <- message.New(dockerMsg.Content, origin, dockerMsg.Status)
 		}
 	}
@@ -209,10 +213,21 @@ func (t *Tailer) checkForNewDockerTags() {
 	} else {
 		if !reflect.DeepEqual(tags, t.containerTags) {
 			t.containerTags = tags
+			t.containerService = serviceFromTags(tags)
 		}
 	}
 }
 
+// serviceFromTags returns the value of the service tag if any.
+func serviceFromTags(tags []string) string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "service:") {
+			return strings.TrimPrefix(tag, "service:")
+		}
+	}
+	return ""
+}
+
 // wait lets the reader sleep for a bit
 func (t *Tailer) wait() {
 	time.Sleep(t.sleepDuration)
@@ -17,3 +17,9 @@ func TestTailerIdentifier(t *testing.T) {
 	tailer := &Tailer{ContainerID: "test"}
 	assert.Equal(t, "docker:test", tailer.Identifier())
 }
+
+func TestServiceFromTags(t *testing.T) {
+	assert.Equal(t, "", serviceFromTags(nil))
+	assert.Equal(t, "", serviceFromTags([]string{"env:prod", "image_name:redis"}))
+	assert.Equal(t, "billing", serviceFromTags([]string{"env:prod", "service:billing"}))
+}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -38,6 +40,7 @@ func NewFile(path string, source *config.LogSource) *File {
 type Provider struct {
 	filesLimit      int
 	shouldLogErrors bool
+	limitReached    bool
 }
 
 // NewProvider returns a new Provider
@@ -50,8 +53,9 @@ func NewProvider(filesLimit int) *Provider {
 
 // FilesToTail returns all the Files matching paths in sources,
 // it cannot return more than filesLimit Files.
-// For now, there is no way to prioritize specific Files over others,
-// they are just returned in alphabetical order
+// Sources are processed in order, and the Files matching a wildcard
+// are returned from the most recently modified to the least recently
+// modified one, so that the most active Files win when the limit is reached.
 func (p *Provider) FilesToTail(sources []*config.LogSource) []*File {
 	var filesToTail []*File
 	shouldLogErrors := p.shouldLogErrors
@@ -94,10 +98,14 @@ func (p *Provider) FilesToTail(sources []*config.LogSource) []*File {
 		}
 	}
 
-	if len(filesToTail) == p.filesLimit {
-		log.Warn("Reached the limit on the maximum number of files in use: ", p.filesLimit)
-		return filesToTail
+	// only warn when the limit is first reached to not flood the logs on every scan
+	limitReached := len(filesToTail) >= p.filesLimit
+	if limitReached && !p.limitReached {
+		log.Warnf("Reached the limit on the maximum number of files in use: %d, the least recently modified files won't be tailed", p.filesLimit)
+	} else if !limitReached && p.limitReached {
+		log.Infof("The number of files in use is back under the limit: %d", p.filesLimit)
 	}
+	p.limitReached = limitReached
 
 	return filesToTail
 }
@@ -129,6 +137,17 @@ func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*Fil
 		// no file was found, its parent directories might have wrong permissions or it just does not exist
 		return nil, fmt.Errorf("could not find any file matching pattern %s, check that all its subdirectories are exectutable", pattern)
 	}
+	// sort the paths from the most recently modified to the least recently modified,
+	// the sort is stable to keep the alphabetical order of the paths modified at the same time
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return modTimes[paths[i]].After(modTimes[paths[j]])
+	})
 	var files []*File
 	for _, path := range paths {
 		files = append(files, NewFile(path, source))
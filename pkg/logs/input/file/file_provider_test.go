@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -65,6 +66,14 @@ func (suite *ProviderTestSuite) SetupTest() {
 	path = fmt.Sprintf("%s/2/2.log", suite.testDir)
 	_, err = os.Create(path)
 	suite.Nil(err)
+
+	// Files are modified from the most recent to the least recent in alphabetical order
+	now := time.Now()
+	for i, file := range []string{"1/1.log", "1/2.log", "1/3.log", "2/1.log", "2/2.log"} {
+		modTime := now.Add(-time.Duration(i) * time.Minute)
+		err = os.Chtimes(fmt.Sprintf("%s/%s", suite.testDir, file), modTime, modTime)
+		suite.Nil(err)
+	}
 }
 
 func (suite *ProviderTestSuite) TearDownTest() {
@@ -149,6 +158,28 @@ func (suite *ProviderTestSuite) TestNumberOfFilesToTailDoesNotExceedLimit() {
 	)
 }
 
+func (suite *ProviderTestSuite) TestMostRecentlyModifiedFilesAreTailedFirst() {
+	path := fmt.Sprintf("%s/1/*.log", suite.testDir)
+	fileProvider := NewProvider(2)
+	logSources := suite.newLogSources(path)
+
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(2, len(files))
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/1/2.log", suite.testDir), files[1].Path)
+
+	// 3.log becomes the most active file and replaces the least recently modified one
+	modTime := time.Now().Add(time.Minute)
+	err := os.Chtimes(fmt.Sprintf("%s/1/3.log", suite.testDir), modTime, modTime)
+	suite.Nil(err)
+
+	files = fileProvider.FilesToTail(logSources)
+	suite.Equal(2, len(files))
+	suite.Equal(fmt.Sprintf("%s/1/3.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[1].Path)
+	suite.Equal([]string{"2 files tailed out of 3 files matching"}, logSources[0].Messages.GetMessages())
+}
+
 func (suite *ProviderTestSuite) TestAllWildcardPathsAreUpdated() {
 	filesLimit := 2
 	fileProvider := NewProvider(filesLimit)
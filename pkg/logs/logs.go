@@ -6,6 +6,7 @@
 package logs
 
 import (
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
@@ -24,8 +25,9 @@ var (
 	adScheduler *scheduler.Scheduler
 )
 
-// Start starts logs-agent
-func Start() error {
+// Start starts logs-agent, the metrics extracted from the logs
+// by the processing rules are pushed in metricsOut
+func Start(metricsOut chan<- *metrics.MetricSample) error {
 	// setup the server config
 	serverConfig, err := config.BuildServerConfig()
 	if err != nil {
@@ -43,7 +45,7 @@ func Start() error {
 	status.Initialize(sources)
 
 	// setup and start the agent
-	agent = NewAgent(sources, services, serverConfig)
+	agent = NewAgent(sources, services, serverConfig, metricsOut)
 	log.Info("Starting logs-agent")
 	agent.Start()
 	isRunning = true
@@ -10,6 +10,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
 // Pipeline processes and sends messages to the backend
@@ -20,7 +21,7 @@ type Pipeline struct {
 }
 
 // NewPipeline returns a new Pipeline
-func NewPipeline(connManager *sender.ConnectionManager, outputChan chan message.Message) *Pipeline {
+func NewPipeline(connManager *sender.ConnectionManager, outputChan chan message.Message, metricsOut chan<- *metrics.MetricSample) *Pipeline {
 
 	useProto := config.LogsAgent.GetBool("logs_config.dev_mode_use_proto")
 
@@ -37,7 +38,7 @@ func NewPipeline(connManager *sender.ConnectionManager, outputChan chan message.
 	apikey := config.LogsAgent.GetString("api_key")
 	logset := config.LogsAgent.GetString("logset") // TODO Logset is deprecated and should be removed eventually.
 	prefixer := processor.NewAPIKeyPrefixer(apikey, logset)
-	processor := processor.New(inputChan, senderChan, encoder, prefixer)
+	processor := processor.New(inputChan, senderChan, metricsOut, encoder, prefixer)
 
 	return &Pipeline{
 		InputChan: inputChan,
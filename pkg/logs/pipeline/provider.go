@@ -11,6 +11,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
 // Provider provides message channels
@@ -25,16 +26,18 @@ type provider struct {
 	numberOfPipelines    int
 	connManager          *sender.ConnectionManager
 	outputChan           chan message.Message
+	metricsOut           chan<- *metrics.MetricSample
 	pipelines            []*Pipeline
 	currentPipelineIndex int32
 }
 
 // NewProvider returns a new Provider
-func NewProvider(numberOfPipelines int, connManager *sender.ConnectionManager, outputChan chan message.Message) Provider {
+func NewProvider(numberOfPipelines int, connManager *sender.ConnectionManager, outputChan chan message.Message, metricsOut chan<- *metrics.MetricSample) Provider {
 	return &provider{
 		numberOfPipelines: numberOfPipelines,
 		connManager:       connManager,
 		outputChan:        outputChan,
+		metricsOut:        metricsOut,
 		pipelines:         []*Pipeline{},
 	}
 }
@@ -42,7 +45,7 @@ func NewProvider(numberOfPipelines int, connManager *sender.ConnectionManager, o
 // Start initializes the pipelines
 func (p *provider) Start() {
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.connManager, p.outputChan)
+		pipeline := NewPipeline(p.connManager, p.outputChan, p.metricsOut)
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
@@ -6,8 +6,6 @@
 package processor
 
 import (
-	"time"
-
 	"regexp"
 
 	"unicode/utf8"
@@ -18,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/pb"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 )
 
 // Encoder turns a message into a raw byte array ready to be sent.
@@ -60,7 +59,7 @@ func (r *raw) encode(msg message.Message, redactedMsg []byte) ([]byte, error) {
 		extraContent = append(extraContent, ' ')
 
 		// Timestamp
-		extraContent = time.Now().UTC().AppendFormat(extraContent, config.DateFormat)
+		extraContent = clock.Now().UTC().AppendFormat(extraContent, config.DateFormat)
 		extraContent = append(extraContent, ' ')
 
 		extraContent = append(extraContent, []byte(getHostname())...)
@@ -110,7 +109,7 @@ func (p *proto) encode(msg message.Message, redactedMsg []byte) ([]byte, error)
 	return (&pb.Log{
 		Message:   p.toValidUtf8(redactedMsg),
 		Status:    msg.GetStatus(),
-		Timestamp: time.Now().UTC().UnixNano(),
+		Timestamp: clock.Now().UTC().UnixNano(),
 		Hostname:  getHostname(),
 		Service:   msg.GetOrigin().Service(),
 		Source:    msg.GetOrigin().Source(),
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processor
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// extractMetrics returns the samples of the metrics rules matching a message:
+// a count_at_match rule counts the matching lines and a histogram_at_match rule
+// records the value captured by the group named value. The groups listed in the
+// tag_groups of the rule are added to the tags of the metric, the other groups
+// are ignored to not submit a context per captured value.
+func extractMetrics(msg message.Message, hostname string) []*metrics.MetricSample {
+	var samples []*metrics.MetricSample
+	content := msg.Content()
+	for _, rule := range msg.GetOrigin().LogSource.Config.ProcessingRules {
+		var mtype metrics.MetricType
+		switch rule.Type {
+		case config.CountAtMatch:
+			mtype = metrics.CountType
+		case config.HistogramAtMatch:
+			mtype = metrics.HistogramType
+		default:
+			continue
+		}
+
+		match := rule.Reg.FindSubmatch(content)
+		if match == nil {
+			continue
+		}
+
+		value := 1.0
+		tags := metricTags(msg.GetOrigin())
+		valid := true
+		for i, name := range rule.Reg.SubexpNames() {
+			if name == "" || match[i] == nil {
+				continue
+			}
+			if name == config.MetricValueGroup && mtype == metrics.HistogramType {
+				v, err := strconv.ParseFloat(string(match[i]), 64)
+				if err != nil {
+					log.Debugf("Invalid value %q for the metric %s of the processing rule %s", match[i], rule.MetricName, rule.Name)
+					valid = false
+					break
+				}
+				value = v
+				continue
+			}
+			if isTagGroup(rule, name) {
+				tags = append(tags, name+":"+string(match[i]))
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		samples = append(samples, &metrics.MetricSample{
+			Name:       rule.MetricName,
+			Value:      value,
+			Mtype:      mtype,
+			Tags:       tags,
+			Host:       hostname,
+			SampleRate: 1,
+		})
+	}
+	return samples
+}
+
+func isTagGroup(rule config.ProcessingRule, name string) bool {
+	for _, group := range rule.TagGroups {
+		if group == name {
+			return true
+		}
+	}
+	return false
+}
+
+// metricTags returns the tags of the metrics of a log source, the service and
+// the source of the logs are added like for the logs themselves
+func metricTags(origin *message.Origin) []string {
+	var tags []string
+	if service := origin.Service(); service != "" {
+		tags = append(tags, "service:"+service)
+	}
+	if source := origin.Source(); source != "" {
+		tags = append(tags, "source:"+source)
+	}
+	return append(tags, origin.Tags()...)
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processor
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestMetricsLogSource(rules ...config.ProcessingRule) *config.LogSource {
+	for i := range rules {
+		rules[i].Reg = regexp.MustCompile(rules[i].Pattern)
+	}
+	return &config.LogSource{Config: &config.LogsConfig{
+		Service:         "nginx",
+		Source:          "access",
+		Tags:            []string{"env:prod"},
+		ProcessingRules: rules,
+	}}
+}
+
+func TestExtractCountMetrics(t *testing.T) {
+	source := buildTestMetricsLogSource(config.ProcessingRule{
+		Type:       config.CountAtMatch,
+		Name:       "server_errors",
+		MetricName: "nginx.responses.5xx",
+		Pattern:    `"(?P<method>[A-Z]+) .* (?P<status_code>5[0-9]{2}) `,
+		TagGroups:  []string{"status_code"},
+	})
+
+	samples := extractMetrics(newMessage([]byte(`"GET /index.html HTTP/1.1" 200 612`), source, ""), "myhost")
+	assert.Len(t, samples, 0)
+
+	samples = extractMetrics(newMessage([]byte(`"GET /api HTTP/1.1" 503 12`), source, ""), "myhost")
+	assert.Equal(t, []*metrics.MetricSample{{
+		Name:       "nginx.responses.5xx",
+		Value:      1,
+		Mtype:      metrics.CountType,
+		Tags:       []string{"service:nginx", "source:access", "env:prod", "status_code:503"},
+		Host:       "myhost",
+		SampleRate: 1,
+	}}, samples)
+}
+
+func TestExtractHistogramMetrics(t *testing.T) {
+	source := buildTestMetricsLogSource(
+		config.ProcessingRule{
+			Type:       config.HistogramAtMatch,
+			Name:       "request_time",
+			MetricName: "nginx.request_time",
+			Pattern:    `request_time=(?P<value>\S+)`,
+		},
+		config.ProcessingRule{
+			Type:    config.ExcludeAtMatch,
+			Name:    "health_checks",
+			Pattern: "/health",
+		},
+	)
+
+	samples := extractMetrics(newMessage([]byte("GET /health request_time=0.25"), source, ""), "myhost")
+	assert.Equal(t, []*metrics.MetricSample{{
+		Name:       "nginx.request_time",
+		Value:      0.25,
+		Mtype:      metrics.HistogramType,
+		Tags:       []string{"service:nginx", "source:access", "env:prod"},
+		Host:       "myhost",
+		SampleRate: 1,
+	}}, samples)
+
+	// the value must be a number
+	samples = extractMetrics(newMessage([]byte("GET /health request_time=-"), source, ""), "myhost")
+	assert.Len(t, samples, 0)
+}
+
+func TestSubmitMetricDropsWhenFull(t *testing.T) {
+	metricsOut := make(chan *metrics.MetricSample, 1)
+	p := &Processor{metricsOut: metricsOut}
+	dropped := droppedMetricSamples.Value()
+
+	p.submitMetric(&metrics.MetricSample{Name: "first"})
+	p.submitMetric(&metrics.MetricSample{Name: "second"})
+
+	assert.Equal(t, "first", (<-metricsOut).Name)
+	assert.Equal(t, dropped+1, droppedMetricSamples.Value())
+}
@@ -6,29 +6,47 @@
 package processor
 
 import (
+	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
+var (
+	processorExpvars     = expvar.NewMap("logs-processor")
+	droppedMetricSamples = expvar.Int{}
+)
+
+func init() {
+	processorExpvars.Set("DroppedMetricSamples", &droppedMetricSamples)
+}
+
 // A Processor updates messages from an inputChan and pushes
-// in an outputChan.
+// in an outputChan, the metrics extracted from the messages
+// are pushed in metricsOut.
 type Processor struct {
 	inputChan  chan message.Message
 	outputChan chan message.Message
+	metricsOut chan<- *metrics.MetricSample
 	encoder    Encoder
 	prefixer   Prefixer
+	hostname   string
 	done       chan struct{}
 }
 
-// New returns an initialized Processor.
-func New(inputChan, outputChan chan message.Message, encoder Encoder, prefixer Prefixer) *Processor {
+// New returns an initialized Processor,
+// metricsOut can be nil to not extract metrics from the messages.
+func New(inputChan, outputChan chan message.Message, metricsOut chan<- *metrics.MetricSample, encoder Encoder, prefixer Prefixer) *Processor {
 	return &Processor{
 		inputChan:  inputChan,
 		outputChan: outputChan,
+		metricsOut: metricsOut,
 		encoder:    encoder,
 		prefixer:   prefixer,
+		hostname:   getHostname(),
 		done:       make(chan struct{}),
 	}
 }
@@ -51,6 +69,13 @@ func (p *Processor) run() {
 		p.done <- struct{}{}
 	}()
 	for msg := range p.inputChan {
+		if p.metricsOut != nil {
+			// the metrics are extracted before the other rules are applied,
+			// so the excluded lines are counted as well
+			for _, sample := range extractMetrics(msg, p.hostname) {
+				p.submitMetric(sample)
+			}
+		}
 		if shouldProcess, redactedMsg := applyRedactingRules(msg); shouldProcess {
 			// Encode the message to its final format
 			content, err := p.encoder.encode(msg, redactedMsg)
@@ -66,6 +91,17 @@ func (p *Processor) run() {
 	}
 }
 
+// submitMetric pushes a sample in metricsOut without blocking, the sample is
+// dropped when the aggregator lags behind so the logs are still processed
+func (p *Processor) submitMetric(sample *metrics.MetricSample) {
+	select {
+	case p.metricsOut <- sample:
+	default:
+		droppedMetricSamples.Add(1)
+		log.Debugf("Dropping the sample of the metric %s extracted from the logs, the aggregator is busy", sample.Name)
+	}
+}
+
 // applyRedactingRules returns given a message if we should process it or not,
 // and a copy of the message with some fields redacted, depending on config
 func applyRedactingRules(msg message.Message) (bool, []byte) {
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"golang.org/x/net/proxy"
 
@@ -81,9 +82,15 @@ func (cm *ConnectionManager) NewConnection() net.Conn {
 		}
 
 		if cm.serverConfig.UseSSL {
-			sslConn := tls.Client(conn, &tls.Config{
-				ServerName: cm.serverConfig.Name,
-			})
+			var tlsConfig *tls.Config
+			tlsConfig, err = util.CreateTLSConfig()
+			if err != nil {
+				log.Warn(err)
+				conn.Close()
+				continue
+			}
+			tlsConfig.ServerName = cm.serverConfig.Name
+			sslConn := tls.Client(conn, tlsConfig)
 			err = sslConn.Handshake()
 			if err != nil {
 				log.Warn(err)
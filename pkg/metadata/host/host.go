@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metadata/common"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/alibaba"
@@ -27,19 +28,28 @@ import (
 
 const packageCachePrefix = "host"
 
+// for testing purpose
+var runtimeSocket = config.RuntimeSocket
+
 // GetPayload builds a metadata payload every time is called.
 // Some data is collected only once, some is cached, some is collected at every call.
 func GetPayload(hostname string) *Payload {
 	meta := getMeta()
 	meta.Hostname = hostname
+	containerMeta := getContainerMeta(1 * time.Second)
+
+	// the system stats are cached, the runtimes are set on a copy
+	systemStats := *getSystemStats()
+	systemStats.ContainerRuntimes = getContainerRuntimes(config.GetRuntimeDetections(), containerMeta)
 
 	p := &Payload{
 		Os:            osName,
 		PythonVersion: GetPythonVersion(),
-		SystemStats:   getSystemStats(),
+		SystemStats:   &systemStats,
 		Meta:          meta,
 		HostTags:      getHostTags(),
-		ContainerMeta: getContainerMeta(1 * time.Second),
+		ContainerMeta: containerMeta,
+		Packages:      getInstalledPackages(),
 	}
 
 	// Cache the metadata for use in other payloads
@@ -188,6 +198,29 @@ func getContainerMeta(timeout time.Duration) map[string]string {
 	}
 }
 
+// getContainerRuntimes returns the container runtimes detected at startup and
+// the health of their socket at the time of the call. Only the version of
+// docker is known, from its metadata provider.
+func getContainerRuntimes(detections []config.RuntimeDetection, containerMeta map[string]string) []containerRuntime {
+	var runtimes []containerRuntime
+	for _, detection := range detections {
+		if !detection.Detected {
+			continue
+		}
+		runtime := containerRuntime{
+			Name:   detection.Name,
+			Socket: runtimeSocket(detection.Name),
+		}
+		if detection.Name == config.RuntimeDocker {
+			// the docker API may be reached through DOCKER_HOST
+			runtime.Version = containerMeta["docker_version"]
+		}
+		runtime.Healthy = runtime.Socket != "" || runtime.Version != ""
+		runtimes = append(runtimes, runtime)
+	}
+	return runtimes
+}
+
 func buildKey(key string) string {
 	return path.Join(common.CachePrefix, packageCachePrefix, key)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/ec2"
+	"github.com/DataDog/datadog-agent/pkg/util/ecs"
 	"github.com/DataDog/datadog-agent/pkg/util/gce"
 	k8s "github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -75,6 +76,13 @@ func getHostTags() *tags {
 		hostTags = appendToHostTags(hostTags, dockerTags)
 	}
 
+	ecsTags, err := ecs.GetTags()
+	if err != nil {
+		log.Debugf("No ECS host tags %v", err)
+	} else {
+		hostTags = appendToHostTags(hostTags, ecsTags)
+	}
+
 	gceTags := []string{}
 	if config.Datadog.GetBool("collect_gce_tags") {
 		rawGceTags, err := gce.GetTags()
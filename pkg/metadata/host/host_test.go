@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host/container"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/shirou/gopsutil/cpu"
@@ -96,3 +97,28 @@ func TestGetContainerMetaTimeout(t *testing.T) {
 	meta := getContainerMeta(50 * time.Millisecond)
 	assert.Equal(t, map[string]string{"foo": "bar"}, meta)
 }
+
+func TestGetContainerRuntimes(t *testing.T) {
+	defer func(original func(string) string) { runtimeSocket = original }(runtimeSocket)
+	runtimeSocket = func(runtime string) string {
+		if runtime == config.RuntimeCRIO {
+			return "/var/run/crio/crio.sock"
+		}
+		return ""
+	}
+
+	detections := []config.RuntimeDetection{
+		{Name: config.RuntimeDocker, Detected: true, Reason: "DOCKER_HOST is set"},
+		{Name: config.RuntimeContainerd, Detected: true, Reason: "container_runtime is set to containerd"},
+		{Name: config.RuntimeCRIO, Detected: true, Reason: "socket found at /var/run/crio/crio.sock"},
+	}
+	runtimes := getContainerRuntimes(detections, map[string]string{"docker_version": "18.06.1-ce"})
+	assert.Equal(t, []containerRuntime{
+		{Name: config.RuntimeDocker, Version: "18.06.1-ce", Healthy: true},
+		{Name: config.RuntimeContainerd, Healthy: false},
+		{Name: config.RuntimeCRIO, Socket: "/var/run/crio/crio.sock", Healthy: true},
+	}, runtimes)
+
+	detections = []config.RuntimeDetection{{Name: config.RuntimeDocker, Reason: "no socket found at /var/run/docker.sock"}}
+	assert.Empty(t, getContainerRuntimes(detections, map[string]string{}))
+}
@@ -6,15 +6,16 @@
 package host
 
 type systemStats struct {
-	CPUCores  int32     `json:"cpuCores"`
-	Machine   string    `json:"machine"`
-	Platform  string    `json:"platform"`
-	Pythonv   string    `json:"pythonV"`
-	Processor string    `json:"processor"`
-	Macver    osVersion `json:"macV"`
-	Nixver    osVersion `json:"nixV"`
-	Fbsdver   osVersion `json:"fbsdV"`
-	Winver    osVersion `json:"winV"`
+	CPUCores          int32              `json:"cpuCores"`
+	Machine           string             `json:"machine"`
+	Platform          string             `json:"platform"`
+	Pythonv           string             `json:"pythonV"`
+	Processor         string             `json:"processor"`
+	Macver            osVersion          `json:"macV"`
+	Nixver            osVersion          `json:"nixV"`
+	Fbsdver           osVersion          `json:"fbsdV"`
+	Winver            osVersion          `json:"winV"`
+	ContainerRuntimes []containerRuntime `json:"containerRuntimes,omitempty"`
 }
 
 // Meta is the metadata nested under the meta key
@@ -33,12 +34,29 @@ type tags struct {
 	GoogleCloudPlatform []string `json:"google cloud platform,omitempty"`
 }
 
+// containerRuntime is a container runtime detected on the host, healthy when
+// its socket accepts connections or its API answers
+type containerRuntime struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Socket  string `json:"socket,omitempty"`
+	Healthy bool   `json:"healthy"`
+}
+
+// installedPackages are the python packages of the embedded environment and
+// their versions, the integrations are reported apart from their dependencies
+type installedPackages struct {
+	Integrations   map[string]string `json:"integrations"`
+	PythonPackages map[string]string `json:"python_packages"`
+}
+
 // Payload handles the JSON unmarshalling of the metadata payload
 type Payload struct {
-	Os            string            `json:"os"`
-	PythonVersion string            `json:"python"`
-	SystemStats   *systemStats      `json:"systemStats"`
-	Meta          *Meta             `json:"meta"`
-	HostTags      *tags             `json:"host-tags"`
-	ContainerMeta map[string]string `json:"container-meta,omitempty"`
+	Os            string             `json:"os"`
+	PythonVersion string             `json:"python"`
+	SystemStats   *systemStats       `json:"systemStats"`
+	Meta          *Meta              `json:"meta"`
+	HostTags      *tags              `json:"host-tags"`
+	ContainerMeta map[string]string  `json:"container-meta,omitempty"`
+	Packages      *installedPackages `json:"installed-packages,omitempty"`
 }
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package host
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// integrationPrefix is the prefix of the names of the integration wheels
+const integrationPrefix = "datadog-"
+
+// checksLibraries are the packages named datadog-* which aren't integrations
+// but the libraries and tooling they depend on
+var checksLibraries = map[string]bool{
+	"datadog-checks-base":       true,
+	"datadog-checks-dev":        true,
+	"datadog-checks-downloader": true,
+}
+
+// sitePackagesGlobs match the site-packages of a python home, on unix and on
+// windows
+var sitePackagesGlobs = []string{
+	filepath.Join("lib", "python*", "site-packages"),
+	filepath.Join("lib", "site-packages"),
+	filepath.Join("Lib", "site-packages"),
+}
+
+// getPythonHome returns the home of the embedded python, as set in the cache
+// when the interpreter is initialized
+func getPythonHome() string {
+	if x, found := cache.Cache.Get(cache.BuildAgentKey("pythonHome")); found {
+		return x.(string)
+	}
+	return ""
+}
+
+// getInstalledPackages returns the python packages installed in the embedded
+// environment, the agent integrations are the packages named datadog-*,
+// except the checks libraries.
+// It returns nil when the agent doesn't embed python.
+func getInstalledPackages() *installedPackages {
+	home := getPythonHome()
+	if home == "" {
+		return nil
+	}
+
+	packages := &installedPackages{
+		Integrations:   map[string]string{},
+		PythonPackages: map[string]string{},
+	}
+	for _, dir := range findSitePackages(home) {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			log.Debugf("Unable to list the python packages in %s: %s", dir, err)
+			continue
+		}
+		for _, entry := range entries {
+			name, version, ok := parsePackageInfo(entry.Name())
+			if !ok {
+				continue
+			}
+			if strings.HasPrefix(name, integrationPrefix) && !checksLibraries[name] {
+				packages.Integrations[name] = version
+			} else {
+				packages.PythonPackages[name] = version
+			}
+		}
+	}
+	return packages
+}
+
+// findSitePackages returns the site-packages directories of a python home
+func findSitePackages(home string) []string {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, pattern := range sitePackagesGlobs {
+		matches, _ := filepath.Glob(filepath.Join(home, pattern))
+		for _, dir := range matches {
+			// lib and Lib are the same directory on windows
+			if key := strings.ToLower(dir); !seen[key] {
+				seen[key] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// parsePackageInfo returns the name and version of a package from the name
+// of its metadata directory: name-version.dist-info for the wheels, and
+// name-version[-pyX.Y].egg-info for the eggs. The names are normalized like
+// pip does, so datadog_checks_base is reported as datadog-checks-base.
+func parsePackageInfo(filename string) (string, string, bool) {
+	var base string
+	switch {
+	case strings.HasSuffix(filename, ".dist-info"):
+		base = strings.TrimSuffix(filename, ".dist-info")
+	case strings.HasSuffix(filename, ".egg-info"):
+		base = strings.TrimSuffix(filename, ".egg-info")
+	default:
+		return "", "", false
+	}
+
+	// the dashes of the names and versions are escaped as underscores
+	parts := strings.Split(base, "-")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	name := strings.ToLower(strings.Replace(parts[0], "_", "-", -1))
+	return name, parts[1], true
+}
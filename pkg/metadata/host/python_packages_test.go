@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package host
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+func TestParsePackageInfo(t *testing.T) {
+	for _, tc := range []struct {
+		filename string
+		name     string
+		version  string
+		ok       bool
+	}{
+		{"datadog_postgres-2.1.0.dist-info", "datadog-postgres", "2.1.0", true},
+		{"requests-2.19.1.dist-info", "requests", "2.19.1", true},
+		{"PyYAML-3.13-py2.7.egg-info", "pyyaml", "3.13", true},
+		{"datadog_checks", "", "", false},
+		{"six.py", "", "", false},
+		{"broken.dist-info", "", "", false},
+	} {
+		t.Run(tc.filename, func(t *testing.T) {
+			name, version, ok := parsePackageInfo(tc.filename)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.version, version)
+		})
+	}
+}
+
+func TestGetInstalledPackages(t *testing.T) {
+	key := cache.BuildAgentKey("pythonHome")
+	defer cache.Cache.Delete(key)
+	assert.Nil(t, getInstalledPackages())
+
+	home, err := ioutil.TempDir("", "python-home")
+	require.NoError(t, err)
+	defer os.RemoveAll(home)
+	sitePackages := filepath.Join(home, "lib", "python2.7", "site-packages")
+	for _, dir := range []string{
+		"datadog_checks",
+		"datadog_checks_base-4.1.0.dist-info",
+		"datadog_redisdb-1.6.0.dist-info",
+		"requests-2.19.1.dist-info",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, dir), 0755))
+	}
+
+	cache.Cache.Set(key, home, cache.NoExpiration)
+	packages := getInstalledPackages()
+	require.NotNil(t, packages)
+	assert.Equal(t, map[string]string{"datadog-redisdb": "1.6.0"}, packages.Integrations)
+	assert.Equal(t, map[string]string{
+		"datadog-checks-base": "4.1.0",
+		"requests":            "2.19.1",
+	}, packages.PythonPackages)
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+)
+
+// Reasons for which series are dropped before reaching the intake
+const (
+	DropReasonTooBig        = "too_big"
+	DropReasonMarshalError  = "marshal_error"
+	DropReasonInvalidSample = "invalid_sample"
+)
+
+// maxDroppedSeriesExamples is the number of metric names kept by drop reason
+const maxDroppedSeriesExamples = 5
+
+// DroppedSeriesStats counts the series dropped for a given reason, with
+// the name of a few of them
+type DroppedSeriesStats struct {
+	Count    int64
+	Examples []string
+}
+
+// droppedSeries keeps the drops since the agent start, and since the last
+// call to FlushDroppedSeries
+var (
+	droppedSeriesTotal      = make(map[string]*DroppedSeriesStats)
+	droppedSeriesSinceFlush = make(map[string]*DroppedSeriesStats)
+	droppedSeriesMutex      sync.Mutex
+)
+
+func (s *DroppedSeriesStats) add(name string) {
+	s.Count++
+	if name == "" || len(s.Examples) >= maxDroppedSeriesExamples {
+		return
+	}
+	for _, example := range s.Examples {
+		if example == name {
+			return
+		}
+	}
+	s.Examples = append(s.Examples, name)
+}
+
+func recordDrop(stats map[string]*DroppedSeriesStats, reason, name string) {
+	s, found := stats[reason]
+	if !found {
+		s = &DroppedSeriesStats{}
+		stats[reason] = s
+	}
+	s.add(name)
+}
+
+// RecordDroppedSeries counts a series dropped for the given reason, the name
+// can be empty when unknown
+func RecordDroppedSeries(reason, name string) {
+	droppedSeriesMutex.Lock()
+	defer droppedSeriesMutex.Unlock()
+
+	recordDrop(droppedSeriesTotal, reason, name)
+	recordDrop(droppedSeriesSinceFlush, reason, name)
+}
+
+// RecordDroppedPayload counts every item of a dropped payload, named when
+// the payload is able to describe them
+func RecordDroppedPayload(reason string, m marshaler.Marshaler) {
+	describer, ok := m.(marshaler.ItemDescriber)
+	if !ok {
+		RecordDroppedSeries(reason, "")
+		return
+	}
+	for i := 0; i < describer.Len(); i++ {
+		RecordDroppedSeries(reason, describer.DescribeItem(i))
+	}
+}
+
+// GetDroppedSeries returns the series dropped since the agent start, by reason
+func GetDroppedSeries() map[string]DroppedSeriesStats {
+	droppedSeriesMutex.Lock()
+	defer droppedSeriesMutex.Unlock()
+
+	return copyDroppedSeries(droppedSeriesTotal)
+}
+
+// FlushDroppedSeries returns the series dropped since its last call, by reason
+func FlushDroppedSeries() map[string]DroppedSeriesStats {
+	droppedSeriesMutex.Lock()
+	defer droppedSeriesMutex.Unlock()
+
+	dropped := copyDroppedSeries(droppedSeriesSinceFlush)
+	droppedSeriesSinceFlush = make(map[string]*DroppedSeriesStats)
+	return dropped
+}
+
+func copyDroppedSeries(stats map[string]*DroppedSeriesStats) map[string]DroppedSeriesStats {
+	res := make(map[string]DroppedSeriesStats, len(stats))
+	for reason, s := range stats {
+		res[reason] = DroppedSeriesStats{
+			Count:    s.Count,
+			Examples: append([]string(nil), s.Examples...),
+		}
+	}
+	return res
+}
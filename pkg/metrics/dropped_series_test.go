@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDroppedSeries(t *testing.T) {
+	FlushDroppedSeries()
+
+	for i := 0; i < maxDroppedSeriesExamples+2; i++ {
+		RecordDroppedSeries(DropReasonInvalidSample, fmt.Sprintf("metric.%d", i))
+	}
+	RecordDroppedSeries(DropReasonInvalidSample, "metric.0")
+	RecordDroppedPayload(DropReasonTooBig, Series{{Name: "big.1"}, {Name: "big.2"}})
+
+	dropped := FlushDroppedSeries()
+	assert.Len(t, dropped, 2)
+	assert.Equal(t, int64(maxDroppedSeriesExamples+3), dropped[DropReasonInvalidSample].Count)
+	assert.Equal(t, []string{"metric.0", "metric.1", "metric.2", "metric.3", "metric.4"}, dropped[DropReasonInvalidSample].Examples)
+	assert.Equal(t, DroppedSeriesStats{Count: 2, Examples: []string{"big.1", "big.2"}}, dropped[DropReasonTooBig])
+
+	// the drops since the last flush are reset, not the totals
+	assert.Empty(t, FlushDroppedSeries())
+	assert.True(t, GetDroppedSeries()[DropReasonTooBig].Count >= 2)
+}
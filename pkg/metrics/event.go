@@ -80,6 +80,7 @@ type Event struct {
 	AggregationKey string         `json:"aggregation_key,omitempty"`
 	SourceTypeName string         `json:"source_type_name,omitempty"`
 	EventType      string         `json:"event_type,omitempty"`
+	OriginID       string         `json:"-"` // Entity the event originates from, its tags are added by the aggregator
 }
 
 // Return a JSON string or "" in case of error during the Marshaling
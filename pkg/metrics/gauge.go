@@ -11,6 +11,8 @@ type Gauge struct {
 	sampled bool
 }
 
+// addSample keeps the value of the last sample. The sample rate is ignored,
+// a sampled gauge still reports its last value.
 func (g *Gauge) addSample(sample *MetricSample, timestamp float64) {
 	g.gauge = sample.Value
 	g.sampled = true
@@ -30,3 +30,14 @@ func TestGaugeSampling(t *testing.T) {
 	assert.InEpsilon(t, 2, series[0].Points[0].Value, epsilon)
 	assert.EqualValues(t, 60, series[0].Points[0].Ts)
 }
+
+func TestGaugeSampleRate(t *testing.T) {
+	mGauge := Gauge{}
+
+	// the value of a sampled gauge is not extrapolated
+	mGauge.addSample(&MetricSample{Value: 2, SampleRate: 0.25}, 50)
+
+	series, _ := mGauge.flush(60)
+	assert.Len(t, series, 1)
+	assert.InEpsilon(t, 2, series[0].Points[0].Value, epsilon)
+}
@@ -7,6 +7,7 @@ package metrics
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 
@@ -14,10 +15,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-// weightSample represent a sample with its weight in the histogram (deduce from SampleRate)
+// weightSample represent a sample with its weight in the histogram (deduce from SampleRate).
+// The weight is not rounded, a sample rate of 0.3 weighs 3.33 samples.
 type weightSample struct {
 	value  float64
-	weight int64
+	weight float64
 }
 
 type weightSamples []weightSample
@@ -33,7 +35,7 @@ type Histogram struct {
 	interval    int64    // interval over which the `count` value is normalized (bucket interval for Dogstatsd, 1 otherwise)
 	samples     weightSamples
 	sum         float64
-	count       int64
+	count       float64
 }
 
 const (
@@ -110,9 +112,9 @@ func (h *Histogram) addSample(sample *MetricSample, timestamp float64) {
 		rate = 1
 	}
 
-	h.samples = append(h.samples, weightSample{sample.Value, int64(1 / rate)}) // add value and its weight
+	h.samples = append(h.samples, weightSample{sample.Value, 1 / rate}) // add value and its weight
 	h.sum += sample.Value * (1 / rate)
-	h.count += int64(1 / rate)
+	h.count += 1 / rate
 }
 
 func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
@@ -134,8 +136,8 @@ func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
 		case minAgg:
 			value = h.samples[0].value
 		case medianAgg:
-			weight := int64(0)
-			target := (h.count - 1) / 2
+			weight := 0.0
+			target := math.Floor((h.count - 1) / 2)
 			for _, s := range h.samples {
 				weight += s.weight
 				if weight > target {
@@ -144,11 +146,11 @@ func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
 				}
 			}
 		case avgAgg:
-			value = h.sum / float64(h.count)
+			value = h.sum / h.count
 		case sumAgg:
 			value = h.sum
 		case countAgg:
-			value = float64(h.count) / float64(h.interval)
+			value = h.count / float64(h.interval)
 			mType = APIRateType
 		default:
 			log.Infof("Configured aggregate '%s' is not implemented, skipping", aggregate)
@@ -163,13 +165,13 @@ func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
 	}
 
 	// Compute percentiles
-	var target []int64
+	var target []float64
 	for _, percentile := range h.percentiles {
-		target = append(target, (int64(percentile)*h.count-1)/100)
+		target = append(target, math.Floor((float64(percentile)*h.count-1)/100))
 	}
 
 	if len(target) > 0 {
-		weight := int64(0)
+		weight := 0.0
 		idx := 0
 		for _, s := range h.samples {
 			weight += s.weight
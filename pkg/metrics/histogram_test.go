@@ -233,6 +233,25 @@ func TestHistogramSampleRate(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestHistogramFractionalSampleRate(t *testing.T) {
+	mHistogram := NewHistogram(10)
+	mHistogram.configure([]string{"avg", "sum", "count"}, []int{50})
+
+	// 3 samples at 0.75 stand for 4 values, the weights are not rounded down
+	for i := 0; i < 3; i++ {
+		mHistogram.addSample(&MetricSample{Value: 2, SampleRate: 0.75}, 50)
+	}
+
+	series, err := mHistogram.flush(60)
+	assert.Nil(t, err)
+	require.Len(t, series, 4)
+
+	assert.InEpsilon(t, 2, series[0].Points[0].Value, epsilon)   // avg
+	assert.InEpsilon(t, 8, series[1].Points[0].Value, epsilon)   // sum
+	assert.InEpsilon(t, 0.4, series[2].Points[0].Value, epsilon) // count
+	assert.InEpsilon(t, 2, series[3].Points[0].Value, epsilon)   // 0.50
+}
+
 func TestHistogramReset(t *testing.T) {
 	mHistogram := NewHistogram(10)
 	mHistogram.configure([]string{"max", "min", "median", "avg", "sum", "count"}, []int{20, 95, 80})
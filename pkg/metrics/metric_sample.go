@@ -5,6 +5,10 @@
 
 package metrics
 
+import (
+	"sync"
+)
+
 // MetricType is the representation of an aggregator metric type
 type MetricType int
 
@@ -63,6 +67,7 @@ type MetricSample struct {
 	Host       string
 	SampleRate float64
 	Timestamp  float64
+	OriginID   string // Entity the sample originates from, when sent by the client, its tags are added by dogstatsd
 }
 
 // Copy returns a deep copy of the src MetricSample
@@ -73,3 +78,26 @@ func (src *MetricSample) Copy() *MetricSample {
 	copy(dst.Tags, src.Tags)
 	return dst
 }
+
+// metricSamplePool recycles the samples of the dogstatsd intake, the
+// aggregator gives them back once they are processed
+var metricSamplePool = sync.Pool{
+	New: func() interface{} {
+		return &MetricSample{}
+	},
+}
+
+// GetMetricSample returns an empty sample, reused from the pool if possible.
+// The capacity of its tags is kept to be appended to.
+func GetMetricSample() *MetricSample {
+	sample := metricSamplePool.Get().(*MetricSample)
+	tags := sample.Tags[:0]
+	*sample = MetricSample{Tags: tags}
+	return sample
+}
+
+// PutMetricSample gives a sample back to the pool, neither the sample nor its
+// tags can be used afterwards
+func PutMetricSample(sample *MetricSample) {
+	metricSamplePool.Put(sample)
+}
@@ -17,6 +17,7 @@ import (
 	agentpayload "github.com/DataDog/agent-payload/gogen"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/protobuf"
 )
 
 var seriesExpvar = expvar.NewMap("series")
@@ -84,26 +85,62 @@ func (series Series) Marshal() ([]byte, error) {
 	return proto.Marshal(payload)
 }
 
+// Len returns the number of series, it implements marshaler.StreamProtoMarshaler
+func (series Series) Len() int {
+	return len(series)
+}
+
+// DescribeItem returns the name of the i-th serie
+func (series Series) DescribeItem(i int) string {
+	return series[i].Name
+}
+
+// MarshalProtoItem encodes a serie as a MetricsPayload_Sample, matching the
+// encoding of Marshal
+func (series Series) MarshalProtoItem(i int, b *protobuf.Buffer) {
+	serie := series[i]
+	b.EncodeStringField(1, serie.Name)
+	b.EncodeStringField(2, serie.MType.String())
+	b.EncodeStringField(3, serie.Host)
+	for _, p := range serie.Points {
+		b.EncodeMessageField(4, func(b *protobuf.Buffer) {
+			b.EncodeVarintField(1, int64(p.Ts))
+			b.EncodeDoubleField(2, p.Value)
+		})
+	}
+	b.EncodeRepeatedStringField(5, serie.Tags)
+	b.EncodeStringField(6, serie.SourceTypeName)
+}
+
+// MarshalProtoMetadata encodes the empty CommonMetadata of the MetricsPayload
+func (series Series) MarshalProtoMetadata(b *protobuf.Buffer) {
+	b.EncodeBytesField(2, nil)
+}
+
 // populateDeviceField removes any `device:` tag in the series tags and uses the value to
 // populate the Serie.Device field
 // Mutates the `series` slice in place
 //FIXME(olivier): remove this as soon as the v1 API can handle `device` as a regular tag
 func populateDeviceField(series Series) {
 	for _, serie := range series {
-		// make a copy of the tags array. Otherwise the underlying array won't have
-		// the device tag for the Nth iteration (N>1), and the device field will
-		// be lost
-		var filteredTags []string
-
-		for _, tag := range serie.Tags {
-			if strings.HasPrefix(tag, "device:") {
-				serie.Device = tag[7:]
-			} else {
-				filteredTags = append(filteredTags, tag)
-			}
+		populateSerieDeviceField(serie)
+	}
+}
+
+func populateSerieDeviceField(serie *Serie) {
+	// make a copy of the tags array. Otherwise the underlying array won't have
+	// the device tag for the Nth iteration (N>1), and the device field will
+	// be lost
+	var filteredTags []string
+
+	for _, tag := range serie.Tags {
+		if strings.HasPrefix(tag, "device:") {
+			serie.Device = tag[7:]
+		} else {
+			filteredTags = append(filteredTags, tag)
 		}
-		serie.Tags = filteredTags
 	}
+	serie.Tags = filteredTags
 }
 
 // MarshalJSON serializes timeseries to JSON so it can be sent to V1 endpoints
@@ -121,6 +158,23 @@ func (series Series) MarshalJSON() ([]byte, error) {
 	return reqBody.Bytes(), err
 }
 
+// JSONHeader opens the list of series of the V1 payload, it implements
+// marshaler.StreamJSONMarshaler
+func (series Series) JSONHeader() []byte {
+	return []byte(`{"series":[`)
+}
+
+// JSONItem marshals a serie the same way MarshalJSON does
+func (series Series) JSONItem(i int) ([]byte, error) {
+	populateSerieDeviceField(series[i])
+	return json.Marshal(series[i])
+}
+
+// JSONFooter closes the list of series of the V1 payload
+func (series Series) JSONFooter() []byte {
+	return []byte(`]}`)
+}
+
 // SplitPayload breaks the payload into, at least, "times" number of pieces
 func (series Series) SplitPayload(times int) ([]marshaler.Marshaler, error) {
 	seriesExpvar.Add("TimesSplit", 1)
@@ -14,6 +14,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	agentpayload "github.com/DataDog/agent-payload/gogen"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/protobuf"
 )
 
 func TestMarshalSeries(t *testing.T) {
@@ -273,3 +275,41 @@ func TestUnmarshalSeriesJSON(t *testing.T) {
 	err = json.Unmarshal(badPointJSON, &badPoint)
 	require.NotNil(t, err)
 }
+
+// marshalStream encodes a payload the way the serializer does when building
+// protobuf payloads item by item
+func marshalStream(m marshaler.StreamProtoMarshaler) []byte {
+	var payload, item protobuf.Buffer
+	for i := 0; i < m.Len(); i++ {
+		item.Reset()
+		m.MarshalProtoItem(i, &item)
+		payload.EncodeBytesField(1, item.Bytes())
+	}
+	m.MarshalProtoMetadata(&payload)
+	return payload.Bytes()
+}
+
+func TestMarshalProtoItemSeries(t *testing.T) {
+	series := Series{
+		{
+			Points: []Point{
+				{Ts: 12345.0, Value: float64(21.21)},
+				{Ts: 67890.0, Value: 0},
+			},
+			MType:          APIGaugeType,
+			Name:           "test.metrics",
+			Host:           "localHost",
+			Tags:           []string{"tag1", "tag2:yes"},
+			SourceTypeName: "system",
+		},
+		{
+			Points: []Point{{Ts: 12345.0, Value: float64(-1)}},
+			MType:  APIRateType,
+			Name:   "test.rate",
+		},
+	}
+
+	expected, err := series.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, expected, marshalStream(series))
+}
@@ -70,6 +70,7 @@ type ServiceCheck struct {
 	Status    ServiceCheckStatus `json:"status"`
 	Message   string             `json:"message"`
 	Tags      []string           `json:"tags"`
+	OriginID  string             `json:"-"` // Entity the service check originates from, its tags are added by the aggregator
 }
 
 // ServiceChecks represents a list of service checks ready to be serialize
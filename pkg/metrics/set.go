@@ -16,6 +16,8 @@ func NewSet() *Set {
 	return &Set{values: make(map[string]bool)}
 }
 
+// addSample adds the value of a sample to the set. The sample rate is ignored,
+// the unique values can't be extrapolated.
 func (s *Set) addSample(sample *MetricSample, timestamp float64) {
 	s.values[sample.RawValue] = true
 }
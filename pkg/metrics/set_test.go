@@ -58,3 +58,17 @@ func TestSetAddSample(t *testing.T) {
 	_, err = set.flush(80)
 	assert.NotNil(t, err)
 }
+
+func TestSetSampleRate(t *testing.T) {
+	set := NewSet()
+
+	// the unique values of a sampled set are not extrapolated
+	for _, sampleValue := range []string{"a", "b", "b"} {
+		set.addSample(&MetricSample{RawValue: sampleValue, SampleRate: 0.25}, 55)
+	}
+	series, err := set.flush(60)
+	require.Nil(t, err)
+
+	require.Len(t, series, 1)
+	assert.EqualValues(t, 2, series[0].Points[0].Value)
+}
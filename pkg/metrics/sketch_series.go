@@ -7,6 +7,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/quantile"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/protobuf"
 )
 
 // A SketchSeries is a timeseries of quantile sketches.
@@ -68,6 +69,44 @@ func (sl SketchSeriesList) Marshal() ([]byte, error) {
 	return pb.Marshal()
 }
 
+// Len returns the number of sketch series, it implements marshaler.StreamProtoMarshaler
+func (sl SketchSeriesList) Len() int {
+	return len(sl)
+}
+
+// DescribeItem returns the name of the i-th sketch series
+func (sl SketchSeriesList) DescribeItem(i int) string {
+	return sl[i].Name
+}
+
+// MarshalProtoItem encodes a sketch series as a SketchPayload_Sketch, matching
+// the encoding of Marshal
+func (sl SketchSeriesList) MarshalProtoItem(i int, b *protobuf.Buffer) {
+	ss := sl[i]
+	b.EncodeStringField(1, ss.Name)
+	b.EncodeStringField(2, ss.Host)
+	b.EncodeRepeatedStringField(4, ss.Tags)
+	for _, p := range ss.Points {
+		b.EncodeMessageField(7, func(b *protobuf.Buffer) {
+			basic := p.Sketch.Basic
+			k, n := p.Sketch.Cols()
+			b.EncodeVarintField(1, p.Ts)
+			b.EncodeVarintField(2, basic.Cnt)
+			b.EncodeDoubleField(3, basic.Min)
+			b.EncodeDoubleField(4, basic.Max)
+			b.EncodeDoubleField(5, basic.Avg)
+			b.EncodeDoubleField(6, basic.Sum)
+			b.EncodePackedSint32Field(7, k)
+			b.EncodePackedUint32Field(8, n)
+		})
+	}
+}
+
+// MarshalProtoMetadata encodes the empty CommonMetadata of the SketchPayload
+func (sl SketchSeriesList) MarshalProtoMetadata(b *protobuf.Buffer) {
+	b.EncodeBytesField(2, nil)
+}
+
 // SplitPayload breaks the payload into times number of pieces
 func (sl SketchSeriesList) SplitPayload(times int) ([]marshaler.Marshaler, error) {
 	// Only break it down as much as possible
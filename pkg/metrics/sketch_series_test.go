@@ -102,3 +102,26 @@ func TestSketchSeriesListMarshal(t *testing.T) {
 	}
 
 }
+
+func TestSketchSeriesListMarshalProtoItem(t *testing.T) {
+	sl := make(SketchSeriesList, 2)
+	c := quantile.Default()
+	for i := range sl {
+		sl[i] = SketchSeries{
+			Name: fmt.Sprintf("name.%d", i),
+			Tags: []string{fmt.Sprintf("a:%d", i)},
+			Host: fmt.Sprintf("host.%d", i),
+		}
+		for j := 0; j < 3; j++ {
+			s := &quantile.Sketch{}
+			for v := 0; v < j*10; v++ {
+				s.Insert(c, float64(v-5))
+			}
+			sl[i].Points = append(sl[i].Points, SketchPoint{Ts: 10 * int64(j), Sketch: s})
+		}
+	}
+
+	expected, err := sl.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, expected, marshalStream(sl))
+}
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package persist stores the state of the agent which must survive a restart
+// in the run path. The logs auditor keeps its registry in it. The retry queue
+// of the forwarder and the inventories metadata are kept in memory for now,
+// writing them to the store is left to follow-up changes: the transactions
+// need an on-disk format and a size limit first.
+package persist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	backupSuffix = ".bak"
+	lockSuffix   = ".lock"
+)
+
+// Store reads and writes the small state blobs of the agent in a directory,
+// usually the run path. The blobs are replaced atomically and their previous
+// version is kept as a backup, used if the blob is missing or corrupted. The
+// accesses to a blob are serialized with a lock file, so the directory can be
+// shared by several processes.
+type Store struct {
+	dir string
+	m   sync.Mutex
+}
+
+// NewStore returns a new store of the blobs of dir, the directory is created
+// on the first write
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Write atomically replaces the blob name with data
+func (s *Store) Write(name string, data []byte) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	path := s.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// the blob is written next to its final location, so it can be renamed
+	tmp, err := ioutil.TempFile(filepath.Dir(path), name+".tmp")
+	if err != nil {
+		return err
+	}
+	if err := writeAndSync(tmp, data); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Rename(path, path+backupSuffix); err != nil {
+			log.Debugf("Could not back up %s: %s", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Read reads the blob name and decodes it. If the blob can't be read or
+// decoded its backup is used instead, the error of the blob is returned if
+// both fail: os.IsNotExist(err) is true if the blob was never written.
+func (s *Store) Read(name string, decode func(data []byte) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	path := s.path(name)
+	unlock, err := lockFile(path + lockSuffix)
+	if err != nil {
+		// the directory may not exist yet, or be read-only
+		if !os.IsNotExist(err) {
+			log.Debugf("Could not lock %s, reading it anyway: %s", path, err)
+		}
+		unlock = func() {}
+	}
+	defer unlock()
+
+	err = readAndDecode(path, decode)
+	if err == nil {
+		return nil
+	}
+	if backupErr := readAndDecode(path+backupSuffix, decode); backupErr == nil {
+		log.Warnf("Could not read %s, recovered its previous version: %s", path, err)
+		return nil
+	}
+	return err
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+func readAndDecode(path string, decode func(data []byte) error) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return decode(data)
+}
+
+func writeAndSync(f *os.File, data []byte) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// ioutil.TempFile creates the files with a 0600 mode
+	return os.Chmod(f.Name(), 0644)
+}
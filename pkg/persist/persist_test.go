@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package persist
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type state struct {
+	Offset int
+}
+
+func decodeState(s *state) func([]byte) error {
+	return func(data []byte) error {
+		return json.Unmarshal(data, s)
+	}
+}
+
+func TestStoreReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := NewStore(filepath.Join(dir, "run"))
+	var s state
+	err = store.Read("state.json", decodeState(&s))
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, store.Write("state.json", []byte(`{"Offset":1}`)))
+	require.NoError(t, store.Write("state.json", []byte(`{"Offset":2}`)))
+	require.NoError(t, store.Read("state.json", decodeState(&s)))
+	assert.Equal(t, 2, s.Offset)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "run", "state.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Offset":2}`, string(data))
+
+	// no temporary file is left
+	matches, err := filepath.Glob(filepath.Join(dir, "run", "*.tmp*"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestStoreRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := NewStore(dir)
+	require.NoError(t, store.Write("state.json", []byte(`{"Offset":1}`)))
+	require.NoError(t, store.Write("state.json", []byte(`{"Offset":2}`)))
+
+	// the previous version is used when the blob is corrupted
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "state.json"), []byte(`{"Offs`), 0644))
+	var s state
+	require.NoError(t, store.Read("state.json", decodeState(&s)))
+	assert.Equal(t, 1, s.Offset)
+
+	// or missing, after an interrupted write
+	require.NoError(t, os.Remove(filepath.Join(dir, "state.json")))
+	s = state{}
+	require.NoError(t, store.Read("state.json", decodeState(&s)))
+	assert.Equal(t, 1, s.Offset)
+
+	// the error of the blob is returned when both are corrupted
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "state.json"), []byte(`{"Offs`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "state.json.bak"), []byte(`{"Offs`), 0644))
+	err = store.Read("state.json", decodeState(&s))
+	assert.Error(t, err)
+	assert.False(t, os.IsNotExist(err))
+}
@@ -56,3 +56,13 @@ func (a *Agent) Insert(v float64) {
 
 	a.flush()
 }
+
+// InsertN inserts v n times into the sketch, to account for sampled values.
+// The value is added with a weight of n, its cost doesn't depend on n.
+func (a *Agent) InsertN(v float64, n uint) {
+	if n == 0 {
+		return
+	}
+	a.Sketch.Basic.InsertN(v, n)
+	a.Sketch.insertN(agentConfig, agentConfig.key(v), int(n))
+}
@@ -110,3 +110,24 @@ func TestAgentFinish(t *testing.T) {
 		require.Nil(t, a.Finish())
 	})
 }
+
+func TestAgentInsertN(t *testing.T) {
+	var a, e Agent
+	for i := 0; i < 1000; i++ {
+		e.Insert(3.5)
+	}
+	e.Insert(1)
+	a.InsertN(3.5, 1000)
+	a.Insert(1)
+	a.InsertN(2, 0)
+
+	require.Equal(t, *e.Finish(), *a.Finish())
+
+	// a weight larger than a bin is split across bins of the same key
+	a.Reset()
+	a.InsertN(1, maxBinWidth+1)
+	s := a.Finish()
+	require.EqualValues(t, maxBinWidth+1, s.Basic.Cnt)
+	require.Equal(t, maxBinWidth+1, s.count)
+	require.Equal(t, maxBinWidth+1, binList(s.bins).nSum())
+}
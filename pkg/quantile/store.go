@@ -184,6 +184,14 @@ func (s *sparseStore) insert(c *Config, keys []Key) {
 	putBinList(tmp)
 }
 
+// insertN adds n counts to the bin of the key k.
+func (s *sparseStore) insertN(c *Config, k Key, n int) {
+	s.merge(c, &sparseStore{
+		bins:  appendSafe(nil, k, n),
+		count: n,
+	})
+}
+
 // bufCountLeadingEqual returns the number of consecutive keys in a[i:] that equal a[i].
 // given:
 //   i = 0 1 2 3 4 5 6
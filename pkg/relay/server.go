@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package relay
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	stdLog "log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxPayloadSize bounds the size of the relayed payloads, the serializer of
+// the agents splits them well below
+const maxPayloadSize = 64 * 1024 * 1024
+
+// forwardedHeaders are the headers of the relayed payloads kept when they're
+// submitted to the intake
+var forwardedHeaders = []string{"Content-Type", "Content-Encoding", "DD-Agent-Payload"}
+
+var (
+	relayListener net.Listener
+)
+
+// StartServer starts the HTTP server receiving the series relayed by other
+// agents, set up with `metrics_relay.url`. The series are submitted to the
+// intake with the forwarder of this agent, with its own API key. The relaying
+// agents must use one of the API keys of this agent. The server is served
+// over TLS when `metrics_relay.listener.tls_cert_file` and `tls_key_file` are
+// set.
+func StartServer(f forwarder.Forwarder) error {
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return err
+	}
+
+	bindHost := config.Datadog.GetString("metrics_relay.listener.bind_host")
+	address := net.JoinHostPort(bindHost, config.Datadog.GetString("metrics_relay.listener.port"))
+	certFile := config.Datadog.GetString("metrics_relay.listener.tls_cert_file")
+	keyFile := config.Datadog.GetString("metrics_relay.listener.tls_key_file")
+	relayListener, err = newListener(address, certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("Unable to create the metrics relay server: %v", err)
+	}
+	if certFile == "" && !isLoopback(bindHost) {
+		log.Warnf("The metrics relay server listens on %s over plain HTTP, the API keys of the relaying agents are sent in clear text", address)
+	}
+
+	srv := &http.Server{
+		Handler:      newRouter(f, apiKeysOf(keysPerDomain)),
+		ErrorLog:     stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		WriteTimeout: config.Datadog.GetDuration("server_timeout") * time.Second,
+	}
+
+	log.Infof("Relaying the series received on %s", address)
+	go srv.Serve(relayListener)
+	return nil
+}
+
+// newListener listens on address, over TLS when a certificate is given
+func newListener(address, certFile, keyFile string) (net.Listener, error) {
+	if certFile == "" && keyFile == "" {
+		return net.Listen("tcp", address)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key pair: %v", err)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{cert}}), nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// StopServer closes the connection of the metrics relay server
+func StopServer() {
+	if relayListener != nil {
+		relayListener.Close()
+	}
+}
+
+func newRouter(f forwarder.Forwarder, apiKeys map[string]bool) *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/v1/validate", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"valid":true}`))
+	}).Methods("GET")
+	r.HandleFunc("/api/v1/series", submitHandler(f.SubmitV1Series)).Methods("POST")
+	r.HandleFunc("/api/v2/series", submitHandler(f.SubmitSeries)).Methods("POST")
+	r.Use(validateAPIKey(apiKeys))
+	return r
+}
+
+// submitHandler submits the payloads as they were sent, they're already
+// serialized and compressed by the relaying agent
+func submitHandler(submit func(forwarder.Payloads, http.Header) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		extra := make(http.Header)
+		for _, header := range forwardedHeaders {
+			if value := r.Header.Get(header); value != "" {
+				extra.Set(header, value)
+			}
+		}
+		if err := submit(forwarder.Payloads{&payload}, extra); err != nil {
+			log.Warnf("Unable to relay the series of %s: %s", r.RemoteAddr, err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// validateAPIKey rejects the requests which API key isn't one of the keys of
+// this agent. The key is in the query string for the v1 endpoints and in the
+// DD-Api-Key header otherwise.
+func validateAPIKey(apiKeys map[string]bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.URL.Query().Get("api_key")
+			if apiKey == "" {
+				apiKey = r.Header.Get("DD-Api-Key")
+			}
+			if !apiKeys[apiKey] {
+				http.Error(w, "invalid API key", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func apiKeysOf(keysPerDomain map[string][]string) map[string]bool {
+	apiKeys := make(map[string]bool)
+	for _, keys := range keysPerDomain {
+		for _, key := range keys {
+			apiKeys[key] = true
+		}
+	}
+	return apiKeys
+}
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package relay
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+func TestRelaySeries(t *testing.T) {
+	payload := []byte("compressed series")
+	extra := make(http.Header)
+	extra.Set("Content-Type", "application/json")
+	extra.Set("Content-Encoding", "deflate")
+
+	f := &forwarder.MockedForwarder{}
+	f.On("SubmitV1Series", forwarder.Payloads{&payload}, extra).Return(nil).Times(1)
+	router := newRouter(f, map[string]bool{"abcdef": true})
+
+	req := httptest.NewRequest("POST", "/api/v1/series?api_key=abcdef", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "deflate")
+	req.Header.Set("User-Agent", "datadog-agent/6.5.0")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	f.AssertExpectations(t)
+}
+
+func TestRelayInvalidAPIKey(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	router := newRouter(f, map[string]bool{"abcdef": true})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/api/v1/series?api_key=123456", bytes.NewReader([]byte("series"))),
+		httptest.NewRequest("POST", "/api/v2/series", bytes.NewReader([]byte("series"))),
+		httptest.NewRequest("GET", "/api/v1/validate?api_key=123456", nil),
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+	f.AssertNumberOfCalls(t, "SubmitV1Series", 0)
+	f.AssertNumberOfCalls(t, "SubmitSeries", 0)
+}
+
+func TestRelayValidate(t *testing.T) {
+	router := newRouter(&forwarder.MockedForwarder{}, map[string]bool{"abcdef": true})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/validate?api_key=abcdef", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"valid":true}`, rec.Body.String())
+}
+
+func TestNewListenerTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "relay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, certPEM, key, err := security.GenerateRootCert([]string{"127.0.0.1"}, 2048)
+	require.NoError(t, err)
+	certFile := filepath.Join(dir, "relay.crt")
+	keyFile := filepath.Join(dir, "relay.key")
+	require.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600))
+
+	listener, err := newListener("127.0.0.1:0", certFile, keyFile)
+	require.NoError(t, err)
+	defer listener.Close()
+	go http.Serve(listener, newRouter(&forwarder.MockedForwarder{}, map[string]bool{"abcdef": true}))
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get("https://" + listener.Addr().String() + "/api/v1/validate?api_key=abcdef")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = newListener("127.0.0.1:0", certFile, filepath.Join(dir, "not-found"))
+	assert.Error(t, err)
+}
+
+func TestIsLoopback(t *testing.T) {
+	for host, expected := range map[string]bool{
+		"localhost": true,
+		"127.0.0.1": true,
+		"::1":       true,
+		"0.0.0.0":   false,
+		"10.0.0.1":  false,
+		"gateway":   false,
+	} {
+		assert.Equal(t, expected, isLoopback(host), host)
+	}
+}
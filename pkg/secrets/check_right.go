@@ -19,8 +19,14 @@ func checkRights(path string) error {
 		return fmt.Errorf("invalid executable '%s': can't stat it: %s", path, err)
 	}
 
-	// checking that group and others don't have any rights
-	if stat.Mode&(syscall.S_IRWXG|syscall.S_IRWXO) != 0 {
+	if secretBackendSandbox.AllowGroupExecPerm {
+		// the group can read and execute it, to run it as a dedicated user
+		// of the group, but not write it
+		if stat.Mode&(syscall.S_IWGRP|syscall.S_IRWXO) != 0 {
+			return fmt.Errorf("invalid executable '%s', 'groups' have write rights or 'others' have rights on it", path)
+		}
+	} else if stat.Mode&(syscall.S_IRWXG|syscall.S_IRWXO) != 0 {
+		// checking that group and others don't have any rights
 		return fmt.Errorf("invalid executable '%s', 'groups' or 'others' have rights on it", path)
 	}
 
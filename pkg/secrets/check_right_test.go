@@ -45,3 +45,23 @@ func TestGroupOtherRights(t *testing.T) {
 	require.Nil(t, os.Chmod(tmpfile.Name(), 0701))
 	require.NotNil(t, checkRights(tmpfile.Name()))
 }
+
+func TestGroupExecPerm(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "agent-collector-test")
+	require.Nil(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	secretBackendSandbox.AllowGroupExecPerm = true
+	defer func() { secretBackendSandbox.AllowGroupExecPerm = false }()
+
+	require.Nil(t, os.Chmod(tmpfile.Name(), 0750))
+	require.Nil(t, checkRights(tmpfile.Name()))
+
+	// group should not write it
+	require.Nil(t, os.Chmod(tmpfile.Name(), 0770))
+	require.NotNil(t, checkRights(tmpfile.Name()))
+
+	// other should have no right
+	require.Nil(t, os.Chmod(tmpfile.Name(), 0751))
+	require.NotNil(t, checkRights(tmpfile.Name()))
+}
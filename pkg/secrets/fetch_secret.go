@@ -33,12 +33,12 @@ func (b *limitBuffer) Write(p []byte) (n int, err error) {
 	return b.buf.Write(p)
 }
 
-func execCommand(inputPayload string) ([]byte, error) {
+func execCommand(b backend, inputPayload string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(),
-		time.Duration(secretBackendTimeout)*time.Second)
+		time.Duration(b.timeout)*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, secretBackendCommand, secretBackendArguments...)
+	cmd := exec.CommandContext(ctx, b.command, b.arguments...)
 	if err := checkRights(cmd.Path); err != nil {
 		return nil, err
 	}
@@ -46,26 +46,31 @@ func execCommand(inputPayload string) ([]byte, error) {
 	cmd.Stdin = strings.NewReader(inputPayload)
 	// setting an empty env in case some secrets were set using the ENV (ex: API_KEY)
 	cmd.Env = []string{}
+	// only the standard streams are inherited, the files opened by the agent
+	// are close-on-exec
+	if err := sandboxCommand(cmd, secretBackendSandbox); err != nil {
+		return nil, fmt.Errorf("could not sandbox '%s': %s", b.command, err)
+	}
 
 	stdout := limitBuffer{
 		buf: &bytes.Buffer{},
-		max: secretBackendOutputMaxSize,
+		max: b.outputMaxSize,
 	}
 	stderr := limitBuffer{
 		buf: &bytes.Buffer{},
-		max: secretBackendOutputMaxSize,
+		max: b.outputMaxSize,
 	}
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err := runSandboxed(cmd, secretBackendSandbox)
 	if err != nil {
-		log.Errorf("secret_backend_command stderr: %s", stderr.buf.String())
+		log.Errorf("%s stderr: %s", b, stderr.buf.String())
 
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("error while running '%s': command timeout", secretBackendCommand)
+			return nil, fmt.Errorf("error while running '%s': command timeout", b.command)
 		}
-		return nil, fmt.Errorf("error while running '%s': %s", secretBackendCommand, err)
+		return nil, fmt.Errorf("error while running '%s': %s", b.command, err)
 	}
 	return stdout.buf.Bytes(), nil
 }
@@ -78,9 +83,9 @@ type secret struct {
 // for testing purpose
 var runCommand = execCommand
 
-// fetchSecret receives a list of secrets name to fetch, exec a custom executable
-// to fetch the actual secrets and returns them.
-func fetchSecret(secretsHandle []string) (map[string]string, error) {
+// fetchSecret receives a list of secrets name to fetch, exec the custom executable
+// of the backend to fetch the actual secrets and returns them.
+func fetchSecret(b backend, secretsHandle []string) (map[string]string, error) {
 	payload := map[string]interface{}{
 		"version": payloadVersion,
 		"secrets": secretsHandle,
@@ -89,8 +94,8 @@ func fetchSecret(secretsHandle []string) (map[string]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not serialize secrets IDs to fetch password: %s", err)
 	}
-	log.Debugf("calling secret_backend_command with payload: '%s'", jsonPayload)
-	output, err := runCommand(string(jsonPayload))
+	log.Debugf("calling %s with payload: '%s'", b, jsonPayload)
+	output, err := runCommand(b, string(jsonPayload))
 	if err != nil {
 		return nil, err
 	}
@@ -98,14 +103,14 @@ func fetchSecret(secretsHandle []string) (map[string]string, error) {
 	secrets := map[string]secret{}
 	err = json.Unmarshal(output, &secrets)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal 'secret_backend_command' output: %s", err)
+		return nil, fmt.Errorf("could not unmarshal %s output: %s", b, err)
 	}
 
 	res := map[string]string{}
 	for _, sec := range secretsHandle {
 		v, ok := secrets[sec]
 		if ok == false {
-			return nil, fmt.Errorf("secret handle '%s' was not decrypted by the secret_backend_command", sec)
+			return nil, fmt.Errorf("secret handle '%s' was not decrypted by the %s", sec, b)
 		}
 
 		if v.ErrorMsg != "" {
@@ -115,7 +120,7 @@ func fetchSecret(secretsHandle []string) (map[string]string, error) {
 			return nil, fmt.Errorf("decrypted secret for '%s' is empty", sec)
 		}
 		// add it to the cache
-		secretCache[sec] = v.Value
+		cacheSecret(b.cacheKey(sec), v.Value)
 		res[sec] = v.Value
 	}
 	return res, nil
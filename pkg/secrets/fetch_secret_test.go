@@ -50,45 +50,45 @@ func TestExecCommandError(t *testing.T) {
 
 	// empty secretBackendCommand
 	secretBackendCommand = ""
-	_, err := execCommand(inputPayload)
+	_, err := execCommand(defaultBackend(), inputPayload)
 	require.NotNil(t, err)
 
 	// test timeout
 	os.Chmod("./test/timeout.sh", 0700)
 	secretBackendCommand = "./test/timeout.sh"
 	secretBackendTimeout = 2
-	_, err = execCommand(inputPayload)
+	_, err = execCommand(defaultBackend(), inputPayload)
 	require.NotNil(t, err)
 	require.Equal(t, "error while running './test/timeout.sh': command timeout", err.Error())
 
 	// test simple (no error)
 	os.Chmod("./test/simple.sh", 0700)
 	secretBackendCommand = "./test/simple.sh"
-	resp, err := execCommand(inputPayload)
+	resp, err := execCommand(defaultBackend(), inputPayload)
 	require.Nil(t, err)
 	require.Equal(t, []byte("{\"handle1\":{\"value\":\"simple_password\"}}"), resp)
 
 	// test error
 	secretBackendCommand = "./test/error.sh"
-	_, err = execCommand(inputPayload)
+	_, err = execCommand(defaultBackend(), inputPayload)
 	require.NotNil(t, err)
 
 	// test arguments
 	os.Chmod("./test/argument.sh", 0700)
 	secretBackendCommand = "./test/argument.sh"
 	secretBackendArguments = []string{"arg1"}
-	_, err = execCommand(inputPayload)
+	_, err = execCommand(defaultBackend(), inputPayload)
 	require.NotNil(t, err)
 	secretBackendCommand = "./test/argument.sh"
 	secretBackendArguments = []string{"arg1", "arg2"}
-	resp, err = execCommand(inputPayload)
+	resp, err = execCommand(defaultBackend(), inputPayload)
 	require.Nil(t, err)
 	require.Equal(t, []byte("{\"handle1\":{\"value\":\"arg_password\"}}"), resp)
 
 	// test input
 	os.Chmod("./test/input.sh", 0700)
 	secretBackendCommand = "./test/input.sh"
-	resp, err = execCommand(inputPayload)
+	resp, err = execCommand(defaultBackend(), inputPayload)
 	require.Nil(t, err)
 	require.Equal(t, []byte("{\"handle1\":{\"value\":\"input_password\"}}"), resp)
 
@@ -96,53 +96,53 @@ func TestExecCommandError(t *testing.T) {
 	os.Chmod("./test/response_too_long.sh", 0700)
 	secretBackendCommand = "./test/response_too_long.sh"
 	secretBackendOutputMaxSize = 20
-	_, err = execCommand(inputPayload)
+	_, err = execCommand(defaultBackend(), inputPayload)
 	require.NotNil(t, err)
 	assert.Equal(t, "error while running './test/response_too_long.sh': command output was too long: exceeded 20 bytes", err.Error())
 }
 
 func TestFetchSecretExecError(t *testing.T) {
-	runCommand = func(string) ([]byte, error) { return nil, fmt.Errorf("some error") }
-	_, err := fetchSecret([]string{"handle1", "handle2"})
+	runCommand = func(backend, string) ([]byte, error) { return nil, fmt.Errorf("some error") }
+	_, err := fetchSecret(defaultBackend(), []string{"handle1", "handle2"})
 	assert.NotNil(t, err)
 }
 
 func TestFetchSecretUnmarshalError(t *testing.T) {
-	runCommand = func(string) ([]byte, error) { return []byte("{"), nil }
-	_, err := fetchSecret([]string{"handle1", "handle2"})
+	runCommand = func(backend, string) ([]byte, error) { return []byte("{"), nil }
+	_, err := fetchSecret(defaultBackend(), []string{"handle1", "handle2"})
 	assert.NotNil(t, err)
 }
 
 func TestFetchSecretMissingSecret(t *testing.T) {
 	secrets := []string{"handle1", "handle2"}
 
-	runCommand = func(string) ([]byte, error) { return []byte("{}"), nil }
-	_, err := fetchSecret(secrets)
+	runCommand = func(backend, string) ([]byte, error) { return []byte("{}"), nil }
+	_, err := fetchSecret(defaultBackend(), secrets)
 	assert.NotNil(t, err)
 	assert.Equal(t, "secret handle 'handle1' was not decrypted by the secret_backend_command", err.Error())
 }
 
 func TestFetchSecretErrorForHandle(t *testing.T) {
-	runCommand = func(string) ([]byte, error) {
+	runCommand = func(backend, string) ([]byte, error) {
 		return []byte("{\"handle1\":{\"value\": null, \"error\": \"some error\"}}"), nil
 	}
-	_, err := fetchSecret([]string{"handle1"})
+	_, err := fetchSecret(defaultBackend(), []string{"handle1"})
 	assert.NotNil(t, err)
 	assert.Equal(t, "an error occurred while decrypting 'handle1': some error", err.Error())
 }
 
 func TestFetchSecretEmptyValue(t *testing.T) {
-	runCommand = func(string) ([]byte, error) {
+	runCommand = func(backend, string) ([]byte, error) {
 		return []byte("{\"handle1\":{\"value\": null}}"), nil
 	}
-	_, err := fetchSecret([]string{"handle1"})
+	_, err := fetchSecret(defaultBackend(), []string{"handle1"})
 	assert.NotNil(t, err)
 	assert.Equal(t, "decrypted secret for 'handle1' is empty", err.Error())
 
-	runCommand = func(string) ([]byte, error) {
+	runCommand = func(backend, string) ([]byte, error) {
 		return []byte("{\"handle1\":{\"value\": \"\"}}"), nil
 	}
-	_, err = fetchSecret([]string{"handle1"})
+	_, err = fetchSecret(defaultBackend(), []string{"handle1"})
 	assert.NotNil(t, err)
 	assert.Equal(t, "decrypted secret for 'handle1' is empty", err.Error())
 }
@@ -152,13 +152,13 @@ func TestFetchSecret(t *testing.T) {
 	// some dummy value to check the cache is not purge
 	secretCache["test"] = "yes"
 
-	runCommand = func(string) ([]byte, error) {
+	runCommand = func(backend, string) ([]byte, error) {
 		res := []byte("{\"handle1\":{\"value\":\"p1\"},")
 		res = append(res, []byte("\"handle2\":{\"value\":\"p2\"},")...)
 		res = append(res, []byte("\"handle3\":{\"value\":\"p3\"}}")...)
 		return res, nil
 	}
-	resp, err := fetchSecret(secrets)
+	resp, err := fetchSecret(defaultBackend(), secrets)
 	require.Nil(t, err)
 	assert.Equal(t, map[string]string{
 		"handle1": "p1",
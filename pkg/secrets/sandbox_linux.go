@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strconv"
+	"syscall"
+)
+
+// prSetNoNewPrivs is PR_SET_NO_NEW_PRIVS, from linux/prctl.h
+const prSetNoNewPrivs = 38
+
+// sandboxCommand sets the user and group running the command, and kills it
+// if the agent dies
+func sandboxCommand(cmd *exec.Cmd, sandbox SandboxConfig) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+	if sandbox.User == "" && sandbox.Group == "" {
+		return nil
+	}
+
+	credential := &syscall.Credential{
+		Uid: uint32(os.Getuid()),
+		Gid: uint32(os.Getgid()),
+		// the supplementary groups of the agent are dropped
+		Groups: []uint32{},
+	}
+	if sandbox.User != "" {
+		u, err := user.Lookup(sandbox.User)
+		if err != nil {
+			return err
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid uid %s of user %s", u.Uid, u.Username)
+		}
+		gid, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid gid %s of user %s", u.Gid, u.Username)
+		}
+		credential.Uid, credential.Gid = uint32(uid), uint32(gid)
+	}
+	if sandbox.Group != "" {
+		g, err := user.LookupGroup(sandbox.Group)
+		if err != nil {
+			return err
+		}
+		gid, err := strconv.ParseUint(g.Gid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid gid %s of group %s", g.Gid, g.Name)
+		}
+		credential.Gid = uint32(gid)
+	}
+	cmd.SysProcAttr.Credential = credential
+	return nil
+}
+
+// runSandboxed runs the command, without new privileges if requested
+func runSandboxed(cmd *exec.Cmd, sandbox SandboxConfig) error {
+	if !sandbox.NoNewPrivs {
+		return cmd.Run()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		// no_new_privs is set on the thread forking the command, which
+		// inherits it. The thread is never unlocked: it is destroyed with
+		// the goroutine instead of running the other goroutines of the agent.
+		runtime.LockOSThread()
+		if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+			errCh <- fmt.Errorf("could not set no_new_privs: %s", errno)
+			return
+		}
+		errCh <- cmd.Run()
+	}()
+	return <-errCh
+}
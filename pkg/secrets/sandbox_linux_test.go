@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package secrets
+
+import (
+	"os"
+	"os/user"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecCommandSandbox(t *testing.T) {
+	defer func() {
+		secretBackendCommand = ""
+		secretBackendTimeout = 0
+		secretBackendOutputMaxSize = 0
+		InitSandbox(SandboxConfig{})
+	}()
+
+	os.Setenv("DD_API_KEY", "123456")
+	defer os.Unsetenv("DD_API_KEY")
+
+	os.Chmod("./test/sandbox.sh", 0700)
+	secretBackendCommand = "./test/sandbox.sh"
+	secretBackendTimeout = 5
+	secretBackendOutputMaxSize = 1024
+
+	// the environment of the agent is not inherited
+	resp, err := execCommand(defaultBackend(), "")
+	require.Nil(t, err)
+	assert.Contains(t, string(resp), "env=0\n")
+
+	InitSandbox(SandboxConfig{NoNewPrivs: true})
+	resp, err = execCommand(defaultBackend(), "")
+	require.Nil(t, err)
+	assert.Contains(t, string(resp), "no_new_privs=1\n")
+
+	// an unknown user is an error, not a command running as the agent
+	InitSandbox(SandboxConfig{User: "dd-secret-test-no-such-user"})
+	_, err = execCommand(defaultBackend(), "")
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "could not sandbox './test/sandbox.sh'")
+
+	if os.Getuid() != 0 {
+		t.Skip("switching to a dedicated group requires root")
+	}
+	g, err := user.LookupGroupId("65534")
+	if err != nil {
+		t.Skip("no group with gid 65534")
+	}
+
+	// the supplementary groups of the agent are dropped
+	InitSandbox(SandboxConfig{Group: g.Name})
+	resp, err = execCommand(defaultBackend(), "")
+	require.Nil(t, err)
+	assert.Contains(t, string(resp), "groups=65534\n")
+}
@@ -0,0 +1,26 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !linux,!windows
+
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// sandboxCommand only supports the restrictions of the file permissions
+// outside of Linux
+func sandboxCommand(cmd *exec.Cmd, sandbox SandboxConfig) error {
+	if sandbox.User != "" || sandbox.Group != "" || sandbox.NoNewPrivs {
+		return fmt.Errorf("running the secret backend as a dedicated user or without new privileges is only supported on Linux")
+	}
+	return nil
+}
+
+func runSandboxed(cmd *exec.Cmd, sandbox SandboxConfig) error {
+	return cmd.Run()
+}
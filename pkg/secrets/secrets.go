@@ -9,7 +9,9 @@ package secrets
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -17,16 +19,69 @@ import (
 )
 
 var (
-	secretCache map[string]string
+	// secretCache is read by Mask while the configurations are decrypted
+	secretCache      map[string]string
+	secretCacheMutex sync.RWMutex
 
 	secretBackendCommand       string
 	secretBackendArguments     []string
 	secretBackendTimeout       = 5
 	secretBackendOutputMaxSize = 1024
+
+	namedBackends map[string]backend
+
+	secretBackendSandbox SandboxConfig
 )
 
 func init() {
 	secretCache = make(map[string]string)
+	namedBackends = make(map[string]backend)
+}
+
+// BackendConfig holds the options of a named secret backend, the timeout
+// and output max size default to the ones of the secret_backend_command
+type BackendConfig struct {
+	Command       string   `mapstructure:"command"`
+	Arguments     []string `mapstructure:"arguments"`
+	Timeout       int      `mapstructure:"timeout"`
+	OutputMaxSize int      `mapstructure:"output_max_size"`
+}
+
+// backend is a command fetching secrets, the default backend is the
+// secret_backend_command and has no name
+type backend struct {
+	name          string
+	command       string
+	arguments     []string
+	timeout       int
+	outputMaxSize int
+}
+
+// defaultBackend returns the backend of the secret_backend_command
+func defaultBackend() backend {
+	return backend{
+		command:       secretBackendCommand,
+		arguments:     secretBackendArguments,
+		timeout:       secretBackendTimeout,
+		outputMaxSize: secretBackendOutputMaxSize,
+	}
+}
+
+// String returns the name of the backend used in the logs and errors
+func (b backend) String() string {
+	if b.name == "" {
+		return "secret_backend_command"
+	}
+	return fmt.Sprintf("secret backend '%s'", b.name)
+}
+
+// cacheKey returns the key of a secret of this backend in the cache, it's
+// the handle as written in the configurations
+func (b backend) cacheKey(handle string) string {
+	if b.name == "" {
+		return handle
+	}
+	return b.name + ":" + handle
 }
 
 // Init initializes the command and other options of the secrets package. Since
@@ -39,6 +94,70 @@ func Init(command string, arguments []string, timeout int, maxSize int) {
 	secretBackendOutputMaxSize = maxSize
 }
 
+// SandboxConfig restricts the execution of the secret backend commands
+type SandboxConfig struct {
+	// User and Group run the commands as a dedicated user and group, the
+	// agent must run as root to switch to them
+	User  string
+	Group string
+	// AllowGroupExecPerm allows the group of the commands to read and
+	// execute them, for a dedicated user of this group to run them
+	AllowGroupExecPerm bool
+	// NoNewPrivs prevents the commands from gaining privileges, through
+	// setuid binaries or file capabilities
+	NoNewPrivs bool
+}
+
+// InitSandbox initializes the restrictions of the execution of the secret
+// backend commands, the user, group and no new privileges restrictions
+// are only available on Linux. It must be called before Decrypt.
+func InitSandbox(sandbox SandboxConfig) {
+	secretBackendSandbox = sandbox
+}
+
+// InitBackends initializes the named secret backends, a handle prefixed by
+// the name of a backend and a colon (`ENC[<name>:<handle>]`) is fetched by
+// this backend. It must be called after Init.
+func InitBackends(backends map[string]BackendConfig) {
+	namedBackends = make(map[string]backend, len(backends))
+	for name, conf := range backends {
+		b := backend{
+			name:          name,
+			command:       conf.Command,
+			arguments:     conf.Arguments,
+			timeout:       conf.Timeout,
+			outputMaxSize: conf.OutputMaxSize,
+		}
+		if b.timeout <= 0 {
+			b.timeout = secretBackendTimeout
+		}
+		if b.outputMaxSize <= 0 {
+			b.outputMaxSize = secretBackendOutputMaxSize
+		}
+		namedBackends[name] = b
+	}
+}
+
+// hasBackend returns whether a secret backend is configured
+func hasBackend() bool {
+	return secretBackendCommand != "" || len(namedBackends) != 0
+}
+
+// getBackend returns the backend fetching a handle and the handle to give
+// to it. Handles with no named backend prefix are fetched by the
+// secret_backend_command, as they could contain colons.
+func getBackend(handle string) (backend, string, error) {
+	if idx := strings.Index(handle, ":"); idx > 0 {
+		if b, found := namedBackends[handle[:idx]]; found {
+			return b, handle[idx+1:], nil
+		}
+	}
+	if secretBackendCommand == "" {
+		return backend{}, "", fmt.Errorf("no secret backend configured to fetch secret '%s'", handle)
+	}
+	return defaultBackend(), handle, nil
+}
+
 type walkerCallback func(string) (string, error)
 
 func walkSlice(data []interface{}, callback walkerCallback) error {
@@ -115,10 +234,25 @@ func isEnc(str string) (bool, string) {
 // testing purpose
 var secretFetcher = fetchSecret
 
-// Decrypt replaces all encrypted secrets in data by executing
-// "secret_backend_command" once if all secrets aren't present in the cache.
+// getCachedSecret returns the secret fetched for a handle, if any
+func getCachedSecret(handle string) (string, bool) {
+	secretCacheMutex.RLock()
+	defer secretCacheMutex.RUnlock()
+	secret, ok := secretCache[handle]
+	return secret, ok
+}
+
+// cacheSecret stores a secret fetched by a backend
+func cacheSecret(handle, secret string) {
+	secretCacheMutex.Lock()
+	defer secretCacheMutex.Unlock()
+	secretCache[handle] = secret
+}
+
+// Decrypt replaces all encrypted secrets in data by executing every secret
+// backend once if all its secrets aren't present in the cache.
 func Decrypt(data []byte) ([]byte, error) {
-	if data == nil || secretBackendCommand == "" {
+	if data == nil || !hasBackend() {
 		log.Debugf("No data to decrypt or no secretBackendCommand set: skipping")
 		return data, nil
 	}
@@ -129,18 +263,24 @@ func Decrypt(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("could not Unmarshal config: %s", err)
 	}
 
-	// First we collect all new handles in the config
-	newHandles := []string{}
+	// First we collect all new handles in the config, by backend
+	newHandles := map[string][]string{}
+	backends := map[string]backend{}
 	haveSecret := false
 	err = walk(&config, func(str string) (string, error) {
 		if ok, handle := isEnc(str); ok {
 			haveSecret = true
 			// Check if we already know this secret
-			if secret, ok := secretCache[handle]; ok {
+			if secret, ok := getCachedSecret(handle); ok {
 				log.Debugf("Secret '%s' was retrieved from cache", handle)
 				return secret, nil
 			}
-			newHandles = append(newHandles, handle)
+			b, backendHandle, err := getBackend(handle)
+			if err != nil {
+				return str, err
+			}
+			backends[b.name] = b
+			newHandles[b.name] = append(newHandles[b.name], backendHandle)
 		}
 		return str, nil
 	})
@@ -155,16 +295,30 @@ func Decrypt(data []byte) ([]byte, error) {
 
 	// check if any new secrets need to be fetch
 	if len(newHandles) != 0 {
-		secrets, err := secretFetcher(newHandles)
-		if err != nil {
-			return nil, err
+		// keep a stable order of execution of the backends
+		names := make([]string, 0, len(newHandles))
+		for name := range newHandles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		secrets := map[string]string{}
+		for _, name := range names {
+			b := backends[name]
+			fetched, err := secretFetcher(b, newHandles[name])
+			if err != nil {
+				return nil, err
+			}
+			for handle, secret := range fetched {
+				secrets[b.cacheKey(handle)] = secret
+			}
 		}
 
 		// Replace all new encrypted secrets in the config
 		err = walk(&config, func(str string) (string, error) {
 			if ok, handle := isEnc(str); ok {
 				if secret, ok := secrets[handle]; ok {
-					log.Debugf("Secret '%s' was retrieved from executable", handle)
+					log.Debugf("Secret '%s' was retrieved from its secret backend", handle)
 					return secret, nil
 				}
 				// This should never happen since fetchSecret will return an error
@@ -184,3 +338,51 @@ func Decrypt(data []byte) ([]byte, error) {
 	}
 	return finalConfig, nil
 }
+
+// maskedSecret replaces the decrypted secrets in the configurations shown to
+// the users
+const maskedSecret = "********"
+
+// Mask replaces the decrypted secrets in data, to show the decrypted
+// configurations without leaking their secrets. The strings equal to a
+// secret fetched by a backend are masked.
+func Mask(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	secretCacheMutex.RLock()
+	secrets := make(map[string]bool, len(secretCache))
+	for _, secret := range secretCache {
+		if secret != "" {
+			secrets[secret] = true
+		}
+	}
+	secretCacheMutex.RUnlock()
+	if len(secrets) == 0 {
+		return data, nil
+	}
+
+	var config interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not Unmarshal config: %s", err)
+	}
+
+	masked := false
+	walk(&config, func(str string) (string, error) {
+		if secrets[str] {
+			masked = true
+			return maskedSecret, nil
+		}
+		return str, nil
+	})
+	if !masked {
+		return data, nil
+	}
+
+	finalConfig, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal config after masking secrets: %s", err)
+	}
+	return finalConfig, nil
+}
@@ -10,6 +10,7 @@ package secrets
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -149,7 +150,7 @@ func TestWalkerComplex(t *testing.T) {
 }
 
 func TestDecryptNoCommand(t *testing.T) {
-	secretFetcher = func(secrets []string) (map[string]string, error) {
+	secretFetcher = func(b backend, secrets []string) (map[string]string, error) {
 		return nil, fmt.Errorf("some error")
 	}
 
@@ -162,7 +163,7 @@ func TestDecryptSecretError(t *testing.T) {
 	secretBackendCommand = "some_command"
 	defer func() { secretBackendCommand = "" }()
 
-	secretFetcher = func(secrets []string) (map[string]string, error) {
+	secretFetcher = func(b backend, secrets []string) (map[string]string, error) {
 		return nil, fmt.Errorf("some error")
 	}
 
@@ -174,7 +175,7 @@ func TestDecryptSecretNoCache(t *testing.T) {
 	secretBackendCommand = "some_command"
 	defer func() { secretBackendCommand = "" }()
 
-	secretFetcher = func(secrets []string) (map[string]string, error) {
+	secretFetcher = func(b backend, secrets []string) (map[string]string, error) {
 		sort.Strings(secrets)
 		assert.Equal(t, []string{
 			"pass1",
@@ -199,7 +200,7 @@ func TestDecryptSecretPartialCache(t *testing.T) {
 	secretCache["pass1"] = "password1"
 	defer func() { secretCache = map[string]string{} }()
 
-	secretFetcher = func(secrets []string) (map[string]string, error) {
+	secretFetcher = func(b backend, secrets []string) (map[string]string, error) {
 		sort.Strings(secrets)
 		assert.Equal(t, []string{
 			"pass2",
@@ -223,7 +224,7 @@ func TestDecryptSecretFullCache(t *testing.T) {
 	secretCache["pass2"] = "password2"
 	defer func() { secretCache = map[string]string{} }()
 
-	secretFetcher = func(secrets []string) (map[string]string, error) {
+	secretFetcher = func(b backend, secrets []string) (map[string]string, error) {
 		require.Fail(t, "Secret Cache was not used properly")
 		return nil, nil
 	}
@@ -232,3 +233,109 @@ func TestDecryptSecretFullCache(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, testConfDecrypted, newConf)
 }
+
+func TestGetBackend(t *testing.T) {
+	InitBackends(map[string]BackendConfig{"vault": {Command: "vault_command", Timeout: 10}})
+	defer InitBackends(nil)
+
+	b, handle, err := getBackend("vault:db/password")
+	require.Nil(t, err)
+	assert.Equal(t, "vault", b.name)
+	assert.Equal(t, "vault_command", b.command)
+	assert.Equal(t, 10, b.timeout)
+	assert.Equal(t, secretBackendOutputMaxSize, b.outputMaxSize)
+	assert.Equal(t, "db/password", handle)
+
+	// no default backend for the other handles
+	_, _, err = getBackend("aws:db/password")
+	require.NotNil(t, err)
+
+	secretBackendCommand = "some_command"
+	defer func() { secretBackendCommand = "" }()
+	b, handle, err = getBackend("aws:db/password")
+	require.Nil(t, err)
+	assert.Equal(t, "", b.name)
+	assert.Equal(t, "aws:db/password", handle)
+}
+
+func TestDecryptNamedBackends(t *testing.T) {
+	secretBackendCommand = "some_command"
+	defer func() { secretBackendCommand = "" }()
+	InitBackends(map[string]BackendConfig{"vault": {Command: "vault_command"}})
+	defer InitBackends(nil)
+	defer func() { secretCache = map[string]string{} }()
+
+	conf := []byte(`---
+instances:
+- password: ENC[pass1]
+  user: test
+- password: ENC[vault:pass2]
+  user: test2
+`)
+
+	secretFetcher = func(b backend, secrets []string) (map[string]string, error) {
+		switch b.name {
+		case "":
+			assert.Equal(t, []string{"pass1"}, secrets)
+			return map[string]string{"pass1": "password1"}, nil
+		case "vault":
+			assert.Equal(t, []string{"pass2"}, secrets)
+			return map[string]string{"pass2": "password2"}, nil
+		}
+		return nil, fmt.Errorf("unknown backend %s", b)
+	}
+
+	newConf, err := Decrypt(conf)
+	require.Nil(t, err)
+	assert.Equal(t, string(testConfDecrypted), string(newConf))
+}
+
+func TestMask(t *testing.T) {
+	// nothing to mask before any secret is fetched
+	masked, err := Mask(testConfDecrypted)
+	require.Nil(t, err)
+	assert.Equal(t, string(testConfDecrypted), string(masked))
+
+	secretCache["pass1"] = "password1"
+	secretCache["vault:pass2"] = "password2"
+	defer func() { secretCache = map[string]string{} }()
+
+	masked, err = Mask(testConfDecrypted)
+	require.Nil(t, err)
+	assert.Equal(t, `instances:
+- password: '********'
+  user: test
+- password: '********'
+  user: test2
+`, string(masked))
+
+	conf := []byte("instances:\n- user: test\n")
+	masked, err = Mask(conf)
+	require.Nil(t, err)
+	assert.Equal(t, string(conf), string(masked))
+}
+
+func TestMaskWhileFetching(t *testing.T) {
+	defer func() { secretCache = map[string]string{} }()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			cacheSecret(fmt.Sprintf("pass%d", i), fmt.Sprintf("password%d", i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, err := Mask(testConfDecrypted)
+			assert.Nil(t, err)
+		}
+	}()
+	wg.Wait()
+
+	masked, err := Mask(testConfDecrypted)
+	require.Nil(t, err)
+	assert.NotContains(t, string(masked), "password1")
+}
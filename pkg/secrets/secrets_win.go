@@ -11,7 +11,36 @@ package secrets
 func Init(command string, arguments []string, timeout int, maxSize int) {
 }
 
+// BackendConfig holds the options of a named secret backend
+type BackendConfig struct {
+	Command       string   `mapstructure:"command"`
+	Arguments     []string `mapstructure:"arguments"`
+	Timeout       int      `mapstructure:"timeout"`
+	OutputMaxSize int      `mapstructure:"output_max_size"`
+}
+
+// SandboxConfig restricts the execution of the secret backend commands
+type SandboxConfig struct {
+	User               string
+	Group              string
+	AllowGroupExecPerm bool
+	NoNewPrivs         bool
+}
+
+// InitSandbox encrypted secrets are not available on windows
+func InitSandbox(sandbox SandboxConfig) {
+}
+
+// InitBackends encrypted secrets are not available on windows
+func InitBackends(backends map[string]BackendConfig) {
+}
+
 // Decrypt encrypted secrets are not available on windows
 func Decrypt(data []byte) ([]byte, error) {
 	return data, nil
 }
+
+// Mask encrypted secrets are not available on windows
+func Mask(data []byte) ([]byte, error) {
+	return data, nil
+}
@@ -0,0 +1,5 @@
+#!/bin/bash
+
+echo "env=`env | grep -c '^DD_'`"
+echo "no_new_privs=`grep NoNewPrivs /proc/self/status | cut -f2`"
+echo "groups=`id -G`"
@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	errPayloadFull = errors.New("reached maximum payload size")
+	errItemTooBig  = errors.New("item alone exceeds maximum payload size")
+)
+
+var (
+	jsonBuilderExpvars   = expvar.NewMap("json_builder")
+	jsonBuilderPayloads  = expvar.Int{}
+	jsonBuilderItemDrops = expvar.Int{}
+)
+
+func init() {
+	jsonBuilderExpvars.Set("Payloads", &jsonBuilderPayloads)
+	jsonBuilderExpvars.Set("ItemDrops", &jsonBuilderItemDrops)
+}
+
+// jsonCompressor compresses the JSON items of a payload as they are added,
+// making sure the compressed payload stays under the max payload size
+type jsonCompressor struct {
+	compressed     *bytes.Buffer
+	zipper         compression.StreamWriter
+	header         []byte
+	footer         []byte
+	maxPayloadSize int
+	// bytes written to the zipper since its last flush, their compressed
+	// size is unknown so the worst case is assumed
+	unflushed int
+	items     int
+}
+
+func newJSONCompressor(header, footer []byte, maxPayloadSize int) (*jsonCompressor, error) {
+	c := &jsonCompressor{
+		compressed:     payloadBuffers.Get(),
+		header:         header,
+		footer:         footer,
+		maxPayloadSize: maxPayloadSize,
+	}
+	c.zipper = compression.NewWriter(c.compressed)
+	n, err := c.zipper.Write(header)
+	c.unflushed = n
+	return c, err
+}
+
+// hasRoomForItem checks an item of the given size, with its separator and the
+// footer, would fit in the payload in the worst case
+func (c *jsonCompressor) hasRoomForItem(itemSize int) bool {
+	return c.compressed.Len()+compression.CompressBound(c.unflushed+itemSize+1+len(c.footer)) <= c.maxPayloadSize
+}
+
+// addItem adds a marshaled item to the payload. It returns errPayloadFull when
+// the item doesn't fit anymore, and errItemTooBig when it wouldn't fit in an
+// empty payload either.
+func (c *jsonCompressor) addItem(data []byte) error {
+	if compression.CompressBound(len(c.header)+len(data)+1+len(c.footer)) > c.maxPayloadSize {
+		return errItemTooBig
+	}
+
+	if !c.hasRoomForItem(len(data)) {
+		// flushing gives the actual compressed size of the pending bytes
+		if err := c.zipper.Flush(); err != nil {
+			return err
+		}
+		c.unflushed = 0
+		if !c.hasRoomForItem(len(data)) {
+			return errPayloadFull
+		}
+	}
+
+	if c.items > 0 {
+		n, err := c.zipper.Write([]byte{','})
+		c.unflushed += n
+		if err != nil {
+			return err
+		}
+	}
+	n, err := c.zipper.Write(data)
+	c.unflushed += n
+	if err != nil {
+		return err
+	}
+	c.items++
+	return nil
+}
+
+// close writes the footer and returns the compressed payload, the
+// compressor must not be used afterwards
+func (c *jsonCompressor) close() ([]byte, error) {
+	if _, err := c.zipper.Write(c.footer); err != nil {
+		return nil, err
+	}
+	if err := c.zipper.Close(); err != nil {
+		return nil, err
+	}
+	// the buffer goes back to the pool, the payload gets its own exactly
+	// sized copy, held until the forwarder sends it
+	payload := make([]byte, c.compressed.Len())
+	copy(payload, c.compressed.Bytes())
+	payloadBuffers.Put(c.compressed)
+	c.compressed = nil
+	return payload, nil
+}
+
+// buildJSONPayloads marshals and compresses the items of m one at a time,
+// starting a new payload when the next item doesn't fit in the current one.
+// Contrary to the split package, the uncompressed JSON of the whole payload
+// is never held in memory.
+func buildJSONPayloads(m marshaler.StreamJSONMarshaler, maxPayloadSize int) (forwarder.Payloads, error) {
+	payloads := forwarder.Payloads{}
+	header, footer := m.JSONHeader(), m.JSONFooter()
+
+	compressor, err := newJSONCompressor(header, footer, maxPayloadSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < m.Len(); i++ {
+		item, err := m.JSONItem(i)
+		if err != nil {
+			log.Warnf("Could not marshal an item, dropping it: %s", err)
+			jsonBuilderItemDrops.Add(1)
+			metrics.RecordDroppedSeries(metrics.DropReasonMarshalError, describeItem(m, i))
+			continue
+		}
+
+		err = compressor.addItem(item)
+		switch err {
+		case nil:
+			continue
+		case errItemTooBig:
+			log.Warnf("Dropping an item too big to fit in a payload: %d bytes", len(item))
+			jsonBuilderItemDrops.Add(1)
+			metrics.RecordDroppedSeries(metrics.DropReasonTooBig, describeItem(m, i))
+			continue
+		case errPayloadFull:
+			payload, err := compressor.close()
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, &payload)
+			jsonBuilderPayloads.Add(1)
+
+			compressor, err = newJSONCompressor(header, footer, maxPayloadSize)
+			if err != nil {
+				return nil, err
+			}
+			// the item fits in an empty payload, errItemTooBig was checked first
+			if err = compressor.addItem(item); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+	}
+
+	// an empty payload is still sent when there was no item at all
+	if compressor.items > 0 || len(payloads) == 0 {
+		payload, err := compressor.close()
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, &payload)
+		jsonBuilderPayloads.Add(1)
+	}
+	return payloads, nil
+}
+
+// describeItem returns the name of the i-th item of a payload, if it's able
+// to describe its items
+func describeItem(m interface{}, i int) string {
+	if describer, ok := m.(marshaler.ItemDescriber); ok {
+		return describer.DescribeItem(i)
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+func decodeJSONPayload(t *testing.T, payload []byte) []*metrics.Serie {
+	decompressed, err := compression.Decompress(nil, payload)
+	require.NoError(t, err)
+
+	decoded := struct {
+		Series []*metrics.Serie `json:"series"`
+	}{}
+	require.NoError(t, json.Unmarshal(decompressed, &decoded))
+	return decoded.Series
+}
+
+func TestJSONPayloadBuilderSinglePayload(t *testing.T) {
+	series := makeSeries(10)
+	series[0].Tags = []string{"tag1", "device:/dev/sda1"}
+
+	payloads, err := buildJSONPayloads(series, maxPayloadSize)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decoded := decodeJSONPayload(t, *payloads[0])
+	require.Len(t, decoded, 10)
+	assert.Equal(t, "/dev/sda1", decoded[0].Device)
+	assert.Equal(t, []string{"tag1"}, decoded[0].Tags)
+	for i, serie := range decoded {
+		assert.Equal(t, fmt.Sprintf("test.metric.%d", i), serie.Name)
+	}
+}
+
+func TestJSONPayloadBuilderCutover(t *testing.T) {
+	series := makeSeries(100)
+	maxSize := 500
+
+	payloads, err := buildJSONPayloads(series, maxSize)
+	require.NoError(t, err)
+	require.True(t, len(payloads) > 1)
+
+	total := 0
+	for _, payload := range payloads {
+		assert.True(t, len(*payload) <= maxSize)
+		for _, serie := range decodeJSONPayload(t, *payload) {
+			assert.Equal(t, fmt.Sprintf("test.metric.%d", total), serie.Name)
+			total++
+		}
+	}
+	assert.Equal(t, len(series), total)
+}
+
+func TestJSONPayloadBuilderReusedBuffers(t *testing.T) {
+	first, err := buildJSONPayloads(makeSeries(10), maxPayloadSize)
+	require.NoError(t, err)
+	firstCopy := append([]byte{}, *first[0]...)
+
+	// the buffers of the first flush are reused, its payload must not change
+	second, err := buildJSONPayloads(makeSeries(20), maxPayloadSize)
+	require.NoError(t, err)
+	assert.Equal(t, firstCopy, *first[0])
+	assert.Len(t, decodeJSONPayload(t, *first[0]), 10)
+	assert.Len(t, decodeJSONPayload(t, *second[0]), 20)
+}
+
+func TestJSONPayloadBuilderItemTooBig(t *testing.T) {
+	series := makeSeries(3)
+	series[1].Tags = append(series[1].Tags, string(make([]byte, 1000)))
+
+	payloads, err := buildJSONPayloads(series, 500)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decoded := decodeJSONPayload(t, *payloads[0])
+	require.Len(t, decoded, 2)
+	assert.Equal(t, "test.metric.0", decoded[0].Name)
+	assert.Equal(t, "test.metric.2", decoded[1].Name)
+}
+
+func TestJSONPayloadBuilderEmpty(t *testing.T) {
+	payloads, err := buildJSONPayloads(metrics.Series{}, maxPayloadSize)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Empty(t, decodeJSONPayload(t, *payloads[0]))
+}
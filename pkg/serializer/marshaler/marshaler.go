@@ -5,9 +5,48 @@
 
 package marshaler
 
+import "github.com/DataDog/datadog-agent/pkg/serializer/protobuf"
+
 // Marshaler is an interface for metrics that are able to serialize themselves to JSON and protobuf
 type Marshaler interface {
 	MarshalJSON() ([]byte, error)
 	Marshal() ([]byte, error)
 	SplitPayload(int) ([]Marshaler, error)
 }
+
+// StreamProtoMarshaler is an interface for payloads able to encode their protobuf
+// message item by item, so that the serializer can build size bounded payloads
+// without materializing the whole message in memory
+type StreamProtoMarshaler interface {
+	Marshaler
+	// Len returns the number of items of the payload
+	Len() int
+	// MarshalProtoItem encodes the i-th item of the repeated first field of the message
+	MarshalProtoItem(i int, b *protobuf.Buffer)
+	// MarshalProtoMetadata encodes the fields following the items in the message
+	MarshalProtoMetadata(b *protobuf.Buffer)
+}
+
+// StreamJSONMarshaler is an interface for payloads able to marshal their JSON
+// item by item, so that the serializer can compress them as they are marshaled
+// instead of marshaling the whole payload in memory first
+type StreamJSONMarshaler interface {
+	Marshaler
+	// Len returns the number of items of the payload
+	Len() int
+	// JSONHeader returns the JSON preceding the items, the opening of the list
+	JSONHeader() []byte
+	// JSONItem marshals the i-th item of the payload
+	JSONItem(i int) ([]byte, error)
+	// JSONFooter returns the JSON following the items, the closing of the list
+	JSONFooter() []byte
+}
+
+// ItemDescriber is an interface for payloads able to name their items, so
+// that the ones dropped by the serializer can be reported
+type ItemDescriber interface {
+	// Len returns the number of items of the payload
+	Len() int
+	// DescribeItem returns the name of the i-th item of the payload
+	DescribeItem(i int) string
+}
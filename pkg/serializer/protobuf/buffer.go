@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package protobuf
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// Buffer is a minimal protobuf encoder. It follows the encoding of the
+// generated marshalers: fields must be encoded in the order of their number
+// and zero values of scalar fields are skipped, as in proto3.
+type Buffer struct {
+	buf []byte
+}
+
+// Reset empties the buffer, keeping its allocated memory
+func (b *Buffer) Reset() {
+	b.buf = b.buf[:0]
+}
+
+// Bytes returns the encoded bytes, only valid until the next modification
+func (b *Buffer) Bytes() []byte {
+	return b.buf
+}
+
+// Len returns the length of the encoded bytes
+func (b *Buffer) Len() int {
+	return len(b.buf)
+}
+
+func (b *Buffer) encodeVarint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *Buffer) encodeTag(field int, wireType int) {
+	b.encodeVarint(uint64(field)<<3 | uint64(wireType))
+}
+
+// EncodeVarintField encodes an int64/uint64/int32 field, skipped if zero
+func (b *Buffer) EncodeVarintField(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	b.encodeTag(field, wireVarint)
+	b.encodeVarint(uint64(v))
+}
+
+// EncodeDoubleField encodes a double field, skipped if zero
+func (b *Buffer) EncodeDoubleField(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	b.encodeTag(field, wireFixed64)
+	var fixed [8]byte
+	binary.LittleEndian.PutUint64(fixed[:], math.Float64bits(v))
+	b.buf = append(b.buf, fixed[:]...)
+}
+
+// EncodeStringField encodes a string field, skipped if empty
+func (b *Buffer) EncodeStringField(field int, s string) {
+	if s == "" {
+		return
+	}
+	b.encodeString(field, s)
+}
+
+// EncodeRepeatedStringField encodes every element of a repeated string field,
+// including the empty ones
+func (b *Buffer) EncodeRepeatedStringField(field int, values []string) {
+	for _, s := range values {
+		b.encodeString(field, s)
+	}
+}
+
+func (b *Buffer) encodeString(field int, s string) {
+	b.encodeTag(field, wireBytes)
+	b.encodeVarint(uint64(len(s)))
+	b.buf = append(b.buf, s...)
+}
+
+// EncodeBytesField encodes an already encoded embedded message
+func (b *Buffer) EncodeBytesField(field int, data []byte) {
+	b.encodeTag(field, wireBytes)
+	b.encodeVarint(uint64(len(data)))
+	b.buf = append(b.buf, data...)
+}
+
+// EncodeMessageField encodes an embedded message, written by the encode function
+func (b *Buffer) EncodeMessageField(field int, encode func(b *Buffer)) {
+	b.encodeTag(field, wireBytes)
+	start := len(b.buf)
+	encode(b)
+	b.prefixLength(start)
+}
+
+// EncodePackedSint32Field encodes a packed repeated sint32 field, skipped if empty
+func (b *Buffer) EncodePackedSint32Field(field int, values []int32) {
+	if len(values) == 0 {
+		return
+	}
+	b.encodeTag(field, wireBytes)
+	start := len(b.buf)
+	for _, v := range values {
+		// zigzag encoding
+		b.encodeVarint(uint64(uint32((v << 1) ^ (v >> 31))))
+	}
+	b.prefixLength(start)
+}
+
+// EncodePackedUint32Field encodes a packed repeated uint32 field, skipped if empty
+func (b *Buffer) EncodePackedUint32Field(field int, values []uint32) {
+	if len(values) == 0 {
+		return
+	}
+	b.encodeTag(field, wireBytes)
+	start := len(b.buf)
+	for _, v := range values {
+		b.encodeVarint(uint64(v))
+	}
+	b.prefixLength(start)
+}
+
+// prefixLength inserts the varint encoded length of the bytes written since
+// start before them, as the size of length delimited fields isn't known
+// until they are encoded.
+func (b *Buffer) prefixLength(start int) {
+	size := len(b.buf) - start
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(size))
+	b.buf = append(b.buf, prefix[:n]...)
+	copy(b.buf[start+n:], b.buf[start:start+size])
+	copy(b.buf[start:], prefix[:n])
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package protobuf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeScalarFields(t *testing.T) {
+	var b Buffer
+	b.EncodeVarintField(1, 150)
+	b.EncodeVarintField(2, 0)
+	b.EncodeStringField(3, "testing")
+	b.EncodeStringField(4, "")
+	b.EncodeDoubleField(5, 1)
+	b.EncodeDoubleField(6, 0)
+
+	assert.Equal(t, []byte{
+		0x08, 0x96, 0x01,
+		0x1a, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g',
+		0x29, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+	}, b.Bytes())
+
+	b.Reset()
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestEncodeNegativeVarint(t *testing.T) {
+	var b Buffer
+	b.EncodeVarintField(1, -1)
+	assert.Equal(t, []byte{0x08, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, b.Bytes())
+}
+
+func TestEncodeRepeatedFields(t *testing.T) {
+	var b Buffer
+	b.EncodeRepeatedStringField(1, []string{"a", ""})
+	b.EncodePackedSint32Field(2, []int32{-1, 1})
+	b.EncodePackedUint32Field(3, []uint32{3, 300})
+	b.EncodePackedUint32Field(4, nil)
+
+	assert.Equal(t, []byte{
+		0x0a, 0x01, 'a',
+		0x0a, 0x00,
+		0x12, 0x02, 0x01, 0x02,
+		0x1a, 0x03, 0x03, 0xac, 0x02,
+	}, b.Bytes())
+}
+
+func TestEncodeMessageField(t *testing.T) {
+	var b Buffer
+	b.EncodeMessageField(1, func(b *Buffer) {
+		b.EncodeVarintField(1, 150)
+	})
+	b.EncodeBytesField(2, nil)
+	assert.Equal(t, []byte{0x0a, 0x03, 0x08, 0x96, 0x01, 0x12, 0x00}, b.Bytes())
+
+	// the length prefix of big messages takes more than one byte
+	long := string(bytes.Repeat([]byte("a"), 200))
+	b.Reset()
+	b.EncodeMessageField(1, func(b *Buffer) {
+		b.EncodeStringField(1, long)
+	})
+	assert.Equal(t, append([]byte{0x0a, 0xcb, 0x01, 0x0a, 0xc8, 0x01}, long...), b.Bytes())
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package serializer
+
+import (
+	"encoding/binary"
+	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/protobuf"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// the backend accepts payloads up to 3MB, but being conservative is okay.
+// Shared by the protobuf and JSON builders.
+const maxPayloadSize = 2 * 1024 * 1024
+
+// maximum size of the tag and length prefix of an item
+const maxItemPrefixSize = 1 + binary.MaxVarintLen64
+
+var (
+	protobufBuilderExpvars   = expvar.NewMap("protobuf_builder")
+	protobufBuilderPayloads  = expvar.Int{}
+	protobufBuilderItemDrops = expvar.Int{}
+)
+
+func init() {
+	protobufBuilderExpvars.Set("Payloads", &protobufBuilderPayloads)
+	protobufBuilderExpvars.Set("ItemDrops", &protobufBuilderItemDrops)
+}
+
+// protobufPayloadBuilder encodes StreamProtoMarshaler payloads item by item,
+// starting a new payload when the next item would make the current one exceed
+// the max payload size. Contrary to the split package, the whole message is
+// never materialized so the memory used is bounded by the payload size.
+type protobufPayloadBuilder struct {
+	maxPayloadSize int
+	item           protobuf.Buffer
+	payload        protobuf.Buffer
+	metadata       protobuf.Buffer
+}
+
+func newProtobufPayloadBuilder(maxPayloadSize int) *protobufPayloadBuilder {
+	return &protobufPayloadBuilder{
+		maxPayloadSize: maxPayloadSize,
+	}
+}
+
+// build returns the payloads, compressed if needed, holding all the items of m
+func (pb *protobufPayloadBuilder) build(m marshaler.StreamProtoMarshaler, compress bool) (forwarder.Payloads, error) {
+	payloads := forwarder.Payloads{}
+
+	pb.metadata.Reset()
+	m.MarshalProtoMetadata(&pb.metadata)
+	pb.payload.Reset()
+	items := 0
+
+	for i := 0; i < m.Len(); i++ {
+		pb.item.Reset()
+		m.MarshalProtoItem(i, &pb.item)
+		itemSize := pb.item.Len() + maxItemPrefixSize
+
+		if itemSize+pb.metadata.Len() > pb.maxPayloadSize {
+			log.Warnf("Dropping an item too big to fit in a payload: %d bytes", pb.item.Len())
+			protobufBuilderItemDrops.Add(1)
+			metrics.RecordDroppedSeries(metrics.DropReasonTooBig, describeItem(m, i))
+			continue
+		}
+		if pb.payload.Len()+itemSize+pb.metadata.Len() > pb.maxPayloadSize {
+			payload, err := pb.finishPayload(compress)
+			if err != nil {
+				return payloads, err
+			}
+			payloads = append(payloads, payload)
+			items = 0
+		}
+
+		// items are the repeated first field of the message
+		pb.payload.EncodeBytesField(1, pb.item.Bytes())
+		items++
+	}
+
+	if items > 0 {
+		payload, err := pb.finishPayload(compress)
+		if err != nil {
+			return payloads, err
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+// finishPayload appends the metadata to the current payload and resets it
+func (pb *protobufPayloadBuilder) finishPayload(compress bool) (*[]byte, error) {
+	var payload []byte
+	if compress {
+		// the uncompressed payload is only needed until it's compressed
+		b := payloadBuffers.Get()
+		b.Write(pb.payload.Bytes())
+		b.Write(pb.metadata.Bytes())
+		pb.payload.Reset()
+		compressed, err := compression.Compress(nil, b.Bytes())
+		payloadBuffers.Put(b)
+		if err != nil {
+			return nil, err
+		}
+		payload = compressed
+	} else {
+		payload = make([]byte, 0, pb.payload.Len()+pb.metadata.Len())
+		payload = append(payload, pb.payload.Bytes()...)
+		payload = append(payload, pb.metadata.Bytes()...)
+		pb.payload.Reset()
+	}
+	protobufBuilderPayloads.Add(1)
+	return &payload, nil
+}
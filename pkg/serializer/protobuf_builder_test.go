@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package serializer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentpayload "github.com/DataDog/agent-payload/gogen"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+func makeSeries(n int) metrics.Series {
+	series := metrics.Series{}
+	for i := 0; i < n; i++ {
+		series = append(series, &metrics.Serie{
+			Name:   fmt.Sprintf("test.metric.%d", i),
+			Host:   "localHost",
+			MType:  metrics.APIGaugeType,
+			Tags:   []string{"tag1", "tag2:yes"},
+			Points: []metrics.Point{{Ts: 12345.0, Value: float64(i)}},
+		})
+	}
+	return series
+}
+
+func TestProtobufPayloadBuilderSinglePayload(t *testing.T) {
+	series := makeSeries(10)
+
+	payloads, err := newProtobufPayloadBuilder(maxPayloadSize).build(series, false)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	expected, err := series.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, expected, *payloads[0])
+}
+
+func TestProtobufPayloadBuilderCutover(t *testing.T) {
+	series := makeSeries(100)
+	maxSize := 500
+
+	payloads, err := newProtobufPayloadBuilder(maxSize).build(series, false)
+	require.NoError(t, err)
+	require.True(t, len(payloads) > 1)
+
+	total := 0
+	for _, payload := range payloads {
+		assert.True(t, len(*payload) <= maxSize)
+		decoded := &agentpayload.MetricsPayload{}
+		require.NoError(t, proto.Unmarshal(*payload, decoded))
+		for _, sample := range decoded.Samples {
+			assert.Equal(t, fmt.Sprintf("test.metric.%d", total), sample.Metric)
+			total++
+		}
+	}
+	assert.Equal(t, len(series), total)
+}
+
+func TestProtobufPayloadBuilderCompress(t *testing.T) {
+	series := makeSeries(10)
+
+	payloads, err := newProtobufPayloadBuilder(maxPayloadSize).build(series, true)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decompressed, err := compression.Decompress(nil, *payloads[0])
+	require.NoError(t, err)
+	expected, err := series.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, expected, decompressed)
+}
+
+func TestProtobufPayloadBuilderItemTooBig(t *testing.T) {
+	series := makeSeries(3)
+	series[1].Tags = append(series[1].Tags, string(make([]byte, 1000)))
+
+	payloads, err := newProtobufPayloadBuilder(500).build(series, false)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decoded := &agentpayload.MetricsPayload{}
+	require.NoError(t, proto.Unmarshal(*payloads[0], decoded))
+	require.Len(t, decoded.Samples, 2)
+	assert.Equal(t, "test.metric.0", decoded.Samples[0].Metric)
+	assert.Equal(t, "test.metric.2", decoded.Samples[1].Metric)
+}
@@ -7,6 +7,7 @@ package serializer
 
 import (
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -15,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
+	"github.com/DataDog/datadog-agent/pkg/util/bufferpool"
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -25,6 +27,8 @@ const (
 	jsonContentType          = "application/json"
 	payloadVersionHTTPHeader = "DD-Agent-Payload"
 	apiKeyReplacement        = "\"apiKey\":\"*************************$1"
+	// maxPooledBufferSize bounds the buffers kept in payloadBuffers
+	maxPooledBufferSize = 16 * 1024 * 1024
 )
 
 var (
@@ -36,12 +40,18 @@ var (
 	protobufExtraHeaders                http.Header
 	jsonExtraHeadersWithCompression     http.Header
 	protobufExtraHeadersWithCompression http.Header
+
+	serializerExpvars = expvar.NewMap("serializer")
+	// payloadBuffers holds the buffers the payloads are built in, they are
+	// reused from one flush to the next
+	payloadBuffers = bufferpool.New(maxPooledBufferSize)
 )
 
 var apiKeyRegExp = regexp.MustCompile("\"apiKey\":\"*\\w+(\\w{5})")
 
 func init() {
 	initExtraHeaders()
+	serializerExpvars.Set("PayloadBuffers", payloadBuffers.Stats())
 }
 
 // initExtraHeaders initializes the global extraHeaders variables.
@@ -137,6 +147,22 @@ func (s Serializer) serializePayload(payload marshaler.Marshaler, compress bool,
 		}
 	}
 
+	// Payloads supporting it are encoded item by item to bound the memory usage
+	if streamPayload, ok := payload.(marshaler.StreamJSONMarshaler); ok && marshalType == split.MarshalJSON && compress {
+		payloads, err := buildJSONPayloads(streamPayload, maxPayloadSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not build JSON payloads: %s", err)
+		}
+		return payloads, extraHeaders, nil
+	}
+	if streamPayload, ok := payload.(marshaler.StreamProtoMarshaler); ok && marshalType == split.Marshal {
+		payloads, err := newProtobufPayloadBuilder(maxPayloadSize).build(streamPayload, compress)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not build protobuf payloads: %s", err)
+		}
+		return payloads, extraHeaders, nil
+	}
+
 	payloads, err := split.Payloads(payload, compress, marshalType)
 
 	if err != nil {
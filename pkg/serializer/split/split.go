@@ -9,6 +9,7 @@ import (
 	"expvar"
 
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 
@@ -98,6 +99,7 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 			if err != nil {
 				log.Warnf("Some payloads could not be split, dropping them")
 				splitterPayloadDrops.Add(1)
+				metrics.RecordDroppedPayload(metrics.DropReasonTooBig, toSplit)
 				return smallEnoughPayloads, err
 			}
 			// after the payload has been split, loop through the chunks
@@ -130,6 +132,9 @@ func Payloads(m marshaler.Marshaler, compress bool, mType MarshalType) (forwarde
 	if len(marshallers) != 0 {
 		log.Warnf("Some payloads could not be split, dropping them")
 		splitterPayloadDrops.Add(1)
+		for _, m := range marshallers {
+			metrics.RecordDroppedPayload(metrics.DropReasonTooBig, m)
+		}
 	}
 
 	return smallEnoughPayloads, nil
@@ -32,6 +32,7 @@ func Fmap() template.FuncMap {
 		"formatUnixTime":     formatUnixTime,
 		"humanize":           mkHuman,
 		"humanizeDuration":   mkHumanDuration,
+		"humanizeBytes":      mkHumanBytes,
 		"toUnsortedList":     toUnsortedList,
 		"formatTitle":        formatTitle,
 		"add":                add,
@@ -128,6 +129,11 @@ func mkHumanDuration(f float64, unit string) string {
 	return duration.String()
 }
 
+// mkHumanBytes makes memory sizes more readable
+func mkHumanBytes(f float64) string {
+	return humanize.Bytes(uint64(f))
+}
+
 func stringLength(s string) int {
 	/*
 		len(string) is wrong if the string has unicode characters in it,
@@ -46,6 +46,8 @@ func GetStatus() (map[string]interface{}, error) {
 
 	stats["config"] = getPartialConfig()
 	stats["conf_file"] = config.Datadog.ConfigFileUsed()
+	stats["features"] = config.GetDetectedFeatures().String()
+	stats["containerRuntimes"] = config.GetRuntimeDetections()
 
 	platformPayload, err := getPlatformPayload()
 	if err != nil {
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/cloudfoundry"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/garden"
+)
+
+// parseAppInstances returns the TagInfo of the new app instances, or of all
+// the app instances if parseAll is set. It also updates the lastSeen cache
+// of the expire process.
+func (c *CloudFoundryCollector) parseAppInstances(instances []cloudfoudry.AppInstance, parseAll bool) []*TagInfo {
+	var output []*TagInfo
+	now := time.Now()
+
+	for _, instance := range instances {
+		if !c.expire.Update(instance.InstanceGUID, now) && !parseAll {
+			continue
+		}
+		low, high := extractAppInstanceTags(instance)
+		output = append(output, &TagInfo{
+			Source:       cloudFoundryCollectorName,
+			Entity:       garden.ContainerIDToEntityName(instance.InstanceGUID),
+			HighCardTags: high,
+			LowCardTags:  low,
+		})
+	}
+	return output
+}
+
+// extractAppInstanceTags returns the low and high cardinality tags of an app
+// instance, its garden container is named after its instance GUID
+func extractAppInstanceTags(instance cloudfoudry.AppInstance) ([]string, []string) {
+	tags := utils.NewTagList()
+
+	tags.AddLow("app_name", instance.App.AppName)
+	tags.AddLow("app_guid", instance.App.AppGUID)
+	tags.AddLow("app_space_name", instance.App.SpaceName)
+	tags.AddLow("app_space_guid", instance.App.SpaceGUID)
+	tags.AddLow("app_org_name", instance.App.OrgName)
+	tags.AddLow("app_org_guid", instance.App.OrgGUID)
+	tags.AddHigh("app_instance_index", strconv.Itoa(int(instance.Index)))
+	tags.AddHigh("app_instance_guid", instance.InstanceGUID)
+	tags.AddHigh("container_name", instance.InstanceGUID)
+
+	return tags.Compute()
+}
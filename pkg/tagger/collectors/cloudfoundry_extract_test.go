@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/cloudfoundry"
+)
+
+func TestCloudFoundryParseAppInstances(t *testing.T) {
+	collector := &CloudFoundryCollector{}
+	var err error
+	collector.expire, err = taggerutil.NewExpire(cloudFoundryExpireFreq)
+	require.NoError(t, err)
+
+	instances := []cloudfoudry.AppInstance{
+		{
+			InstanceGUID: "5c8a3a6e-9b1d-4c2e-6f7a-0b1c",
+			ProcessGUID:  "0d4f6b48-4d1e-4f5b-9a3c-5e7f8a9b0c1d-7b2c3d4e",
+			Index:        2,
+			App: cloudfoudry.AppInfo{
+				AppName:   "dora",
+				AppGUID:   "0d4f6b48-4d1e-4f5b-9a3c-5e7f8a9b0c1d",
+				SpaceName: "dev",
+				SpaceGUID: "a1b2c3d4-space",
+				OrgName:   "acme",
+				OrgGUID:   "e5f6a7b8-org",
+			},
+		},
+	}
+
+	infos := collector.parseAppInstances(instances, false)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "garden://5c8a3a6e-9b1d-4c2e-6f7a-0b1c", infos[0].Entity)
+	assert.Equal(t, cloudFoundryCollectorName, infos[0].Source)
+	assert.ElementsMatch(t, []string{
+		"app_name:dora",
+		"app_guid:0d4f6b48-4d1e-4f5b-9a3c-5e7f8a9b0c1d",
+		"app_space_name:dev",
+		"app_space_guid:a1b2c3d4-space",
+		"app_org_name:acme",
+		"app_org_guid:e5f6a7b8-org",
+	}, infos[0].LowCardTags)
+	assert.ElementsMatch(t, []string{
+		"app_instance_index:2",
+		"app_instance_guid:5c8a3a6e-9b1d-4c2e-6f7a-0b1c",
+		"container_name:5c8a3a6e-9b1d-4c2e-6f7a-0b1c",
+	}, infos[0].HighCardTags)
+
+	// known app instances are only parsed again when asked to
+	assert.Len(t, collector.parseAppInstances(instances, false), 0)
+	assert.Len(t, collector.parseAppInstances(instances, true), 1)
+}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/cloudfoundry"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/garden"
+)
+
+const (
+	cloudFoundryCollectorName = "cloudfoundry"
+	cloudFoundryExpireFreq    = 5 * time.Minute
+)
+
+// CloudFoundryCollector polls the Diego BBS for the app instances of the
+// cell, and tags their garden containers with their app, space and org
+type CloudFoundryCollector struct {
+	bbsUtil    *cloudfoudry.BBSUtil
+	infoOut    chan<- []*TagInfo
+	expire     *taggerutil.Expire
+	lastExpire time.Time
+	expireFreq time.Duration
+}
+
+// Detect tries to connect to the BBS
+func (c *CloudFoundryCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
+	bu, err := cloudfoudry.GetBBSUtil()
+	if err != nil {
+		return NoCollection, err
+	}
+
+	c.bbsUtil = bu
+	c.infoOut = out
+	c.lastExpire = time.Now()
+	c.expireFreq = cloudFoundryExpireFreq
+	c.expire, err = taggerutil.NewExpire(cloudFoundryExpireFreq)
+	if err != nil {
+		return NoCollection, fmt.Errorf("Failed to instantiate the container expiring process")
+	}
+
+	return PullCollection, nil
+}
+
+// Pull looks for new app instances and computes deletions
+func (c *CloudFoundryCollector) Pull() error {
+	instances, err := c.bbsUtil.GetAppInstances()
+	if err != nil {
+		return err
+	}
+	// Only parse new app instances
+	c.infoOut <- c.parseAppInstances(instances, false)
+
+	// Throttle deletions
+	if time.Now().Before(c.lastExpire.Add(c.expireFreq)) {
+		return nil
+	}
+
+	expireList, err := c.expire.ComputeExpires()
+	if err != nil {
+		return err
+	}
+	c.infoOut <- c.parseExpires(expireList)
+	c.lastExpire = time.Now()
+	return nil
+}
+
+// Fetch parses tags for a container on cache miss. We avoid races with Pull,
+// we re-parse the whole list, but don't send updates on other containers.
+func (c *CloudFoundryCollector) Fetch(entity string) ([]string, []string, error) {
+	instances, err := c.bbsUtil.GetAppInstances()
+	if err != nil {
+		return []string{}, []string{}, err
+	}
+
+	for _, info := range c.parseAppInstances(instances, true) {
+		if info.Entity == entity {
+			return info.LowCardTags, info.HighCardTags, nil
+		}
+	}
+	// container not found in updates
+	return []string{}, []string{}, errors.NewNotFound(entity)
+}
+
+// parseExpires transforms the expired instance GUIDs to deletion TagInfo
+func (c *CloudFoundryCollector) parseExpires(idList []string) []*TagInfo {
+	var output []*TagInfo
+	for _, id := range idList {
+		output = append(output, &TagInfo{
+			Source:       cloudFoundryCollectorName,
+			Entity:       garden.ContainerIDToEntityName(id),
+			DeleteEntity: true,
+		})
+	}
+	return output
+}
+
+func cloudFoundryFactory() Collector {
+	return &CloudFoundryCollector{}
+}
+
+func init() {
+	registerCollector(cloudFoundryCollectorName, cloudFoundryFactory, NodeOrchestrator)
+}
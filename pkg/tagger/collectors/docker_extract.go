@@ -17,6 +17,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// kubernetesPodUIDLabel is set by the kubelet on the containers of the pods
+const kubernetesPodUIDLabel = "io.kubernetes.pod.uid"
+
 // extractFromInspect extract tags for a container inspect JSON
 func (c *DockerCollector) extractFromInspect(co types.ContainerJSON) ([]string, []string, error) {
 	tags := utils.NewTagList()
@@ -30,6 +33,7 @@ func (c *DockerCollector) extractFromInspect(co types.ContainerJSON) ([]string,
 	}
 	dockerExtractLabels(tags, co.Config.Labels, c.labelsAsTags)
 	dockerExtractEnvironmentVariables(tags, co.Config.Env, c.envAsTags)
+	dockerExtractClusterName(tags, co.Config.Labels, c.clusterName)
 
 	tags.AddHigh("container_name", strings.TrimPrefix(co.Name, "/"))
 	tags.AddHigh("container_id", co.ID)
@@ -51,11 +55,20 @@ func dockerExtractImage(tags *utils.TagList, dockerImage string) {
 }
 
 // dockerExtractLabels contain hard-coded labels from:
+// - Datadog unified service tagging
 // - Docker swarm
 func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string, labelsAsTags map[string]string) {
 
 	for labelName, labelValue := range containerLabels {
 		switch labelName {
+		// Unified service tagging
+		case dockerLabelEnv:
+			tags.AddLow(tagKeyEnv, labelValue)
+		case dockerLabelService:
+			tags.AddLow(tagKeyService, labelValue)
+		case dockerLabelVersion:
+			tags.AddLow(tagKeyVersion, labelValue)
+
 		// Docker swarm
 		case "com.docker.swarm.service.name":
 			tags.AddLow("swarm_service", labelValue)
@@ -78,7 +91,17 @@ func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string,
 	}
 }
 
+// dockerExtractClusterName tags the containers of the kubernetes pods, set by
+// the kubelet, with the cluster they run in
+func dockerExtractClusterName(tags *utils.TagList, containerLabels map[string]string, clusterName string) {
+	if clusterName == "" || containerLabels[kubernetesPodUIDLabel] == "" {
+		return
+	}
+	tags.AddLow("kube_cluster_name", clusterName)
+}
+
 // dockerExtractEnvironmentVariables contain hard-coded environment variables from:
+// - Datadog unified service tagging
 // - Mesos/DCOS tags (mesos, marathon, chronos)
 func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariables []string, envAsTags map[string]string) {
 	var envSplit []string
@@ -92,6 +115,14 @@ func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariable
 		envName = envSplit[0]
 		envValue = envSplit[1]
 		switch envName {
+		// Unified service tagging
+		case envVarEnv:
+			tags.AddLow(tagKeyEnv, envValue)
+		case envVarService:
+			tags.AddLow(tagKeyService, envValue)
+		case envVarVersion:
+			tags.AddLow(tagKeyVersion, envValue)
+
 		// Mesos/DCOS tags (mesos, marathon, chronos)
 		case "MARATHON_APP_ID":
 			tags.AddLow("marathon_app", envValue)
@@ -215,6 +215,50 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 			},
 			expectedHigh: []string{},
 		},
+		{
+			testName: "extractUnifiedServiceTagsFromLabels",
+			co: &types.ContainerJSON{
+				Config: &container.Config{
+					Env: []string{"PATH=/bin"},
+					Labels: map[string]string{
+						"com.datadoghq.tags.env":     "prod",
+						"com.datadoghq.tags.service": "billing",
+						"com.datadoghq.tags.version": "1.2.3",
+					},
+				},
+			},
+			toRecordEnvAsTags:    map[string]string{},
+			toRecordLabelsAsTags: map[string]string{},
+			expectedLow: []string{
+				"env:prod",
+				"service:billing",
+				"version:1.2.3",
+			},
+			expectedHigh: []string{},
+		},
+		{
+			testName: "extractUnifiedServiceTagsFromEnv",
+			co: &types.ContainerJSON{
+				Config: &container.Config{
+					Env: []string{
+						"DD_ENV=staging",
+						"DD_SERVICE=billing",
+						"DD_VERSION=",
+					},
+					Labels: map[string]string{
+						"com.datadoghq.tags.version": "1.2.3",
+					},
+				},
+			},
+			toRecordEnvAsTags:    map[string]string{},
+			toRecordLabelsAsTags: map[string]string{},
+			expectedLow: []string{
+				"env:staging",
+				"service:billing",
+				"version:1.2.3",
+			},
+			expectedHigh: []string{},
+		},
 	}
 
 	dc := &DockerCollector{}
@@ -241,3 +285,19 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerExtractClusterName(t *testing.T) {
+	podLabels := map[string]string{"io.kubernetes.pod.uid": "e42e5adc-0749-11e8-a2b8-000c29dea4f6"}
+
+	tags := utils.NewTagList()
+	dockerExtractClusterName(tags, podLabels, "prod-cluster")
+	low, _ := tags.Compute()
+	assert.Equal(t, []string{"kube_cluster_name:prod-cluster"}, low)
+
+	// the containers outside of the pods and the unknown clusters are not tagged
+	tags = utils.NewTagList()
+	dockerExtractClusterName(tags, map[string]string{}, "prod-cluster")
+	dockerExtractClusterName(tags, podLabels, "")
+	low, _ = tags.Compute()
+	assert.Empty(t, low)
+}
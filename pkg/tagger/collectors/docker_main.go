@@ -13,8 +13,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
 )
 
 const (
@@ -30,6 +32,7 @@ type DockerCollector struct {
 	infoOut      chan<- []*TagInfo
 	labelsAsTags map[string]string
 	envAsTags    map[string]string
+	clusterName  string
 }
 
 // Detect tries to connect to the docker socket and returns success
@@ -46,6 +49,9 @@ func (c *DockerCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error)
 	// We lower-case the values collected by viper as well as the ones from inspecting the labels of containers.
 	c.labelsAsTags = retrieveMappingFromConfig("docker_labels_as_tags")
 	c.envAsTags = retrieveMappingFromConfig("docker_env_as_tags")
+	if config.IsKubernetes() {
+		c.clusterName = clustername.GetClusterNameOrUID()
+	}
 
 	// TODO: list and inspect existing containers once docker utils are merged
 
@@ -18,6 +18,7 @@ import (
 func (c *ECSCollector) parseTasks(tasks_list ecsutil.TasksV1Response, targetDockerID string) ([]*TagInfo, error) {
 	var output []*TagInfo
 	now := time.Now()
+	clusterName := c.getClusterName()
 	for _, task := range tasks_list.Tasks {
 		// We only want to collect tasks without a STOPPED status.
 		if task.KnownStatus == "STOPPED" {
@@ -29,6 +30,8 @@ func (c *ECSCollector) parseTasks(tasks_list ecsutil.TasksV1Response, targetDock
 				tags := utils.NewTagList()
 				tags.AddLow("task_version", task.Version)
 				tags.AddLow("task_name", task.Family)
+				tags.AddLow("ecs_cluster_name", clusterName)
+				tags.AddHigh("task_arn", task.Arn)
 
 				low, high := tags.Compute()
 
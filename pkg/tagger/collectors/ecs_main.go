@@ -8,12 +8,14 @@
 package collectors
 
 import (
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/ecs"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 const (
@@ -29,6 +31,9 @@ type ECSCollector struct {
 	lastExpire time.Time
 	expireFreq time.Duration
 	ecsUtil    *ecs.Util
+	// cluster of the container instance, set on the first successful fetch
+	clusterName   string
+	clusterNameMu sync.Mutex
 }
 
 // Detect tries to connect to the ECS agent
@@ -57,6 +62,8 @@ func (c *ECSCollector) Fetch(container string) ([]string, []string, error) {
 		return nil, nil, nil
 	}
 
+	c.fetchClusterName()
+
 	tasks_list, err := c.ecsUtil.GetTasks()
 	if err != nil {
 		return []string{}, []string{}, err
@@ -84,6 +91,29 @@ func (c *ECSCollector) Fetch(container string) ([]string, []string, error) {
 	return []string{}, []string{}, errors.NewNotFound(container)
 }
 
+// fetchClusterName queries the cluster name of the container instance until
+// it is known
+func (c *ECSCollector) fetchClusterName() {
+	c.clusterNameMu.Lock()
+	defer c.clusterNameMu.Unlock()
+
+	if c.clusterName != "" {
+		return
+	}
+	if meta, err := c.ecsUtil.GetInstanceMetadata(); err == nil {
+		c.clusterName = meta.Cluster
+	} else {
+		log.Debugf("Could not get the ECS cluster name: %s", err)
+	}
+}
+
+func (c *ECSCollector) getClusterName() string {
+	c.clusterNameMu.Lock()
+	defer c.clusterNameMu.Unlock()
+
+	return c.clusterName
+}
+
 func ecsFactory() Collector {
 	return &ECSCollector{}
 }
@@ -35,9 +35,16 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 		// Pod name
 		tags.AddHigh("pod_name", pod.Metadata.Name)
 		tags.AddLow("kube_namespace", pod.Metadata.Namespace)
+		tags.AddLow("kube_cluster_name", c.clusterName)
 
 		// Pod labels
 		for name, value := range pod.Metadata.Labels {
+			// Unified service tagging, service and version
+			// can be overridden per container below
+			if name == podLabelEnv {
+				tags.AddLow(tagKeyEnv, value)
+			}
+
 			if tagName, found := c.labelsAsTags[strings.ToLower(name)]; found {
 				tags.AddAuto(tagName, value)
 			}
@@ -101,6 +108,11 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 		for _, container := range pod.Status.Containers {
 			cTags := tags.Copy()
 			cTags.AddLow("kube_container_name", container.Name)
+
+			// Unified service tagging
+			cTags.AddLow(tagKeyService, standardContainerLabel(pod.Metadata.Labels, container.Name, tagKeyService))
+			cTags.AddLow(tagKeyVersion, standardContainerLabel(pod.Metadata.Labels, container.Name, tagKeyVersion))
+
 			cTags.AddHigh("container_id", kubelet.TrimRuntimeFromCID(container.ID))
 			if container.Name != "" && pod.Metadata.Name != "" {
 				cTags.AddHigh("display_container_name", fmt.Sprintf("%s_%s", container.Name, pod.Metadata.Name))
@@ -88,6 +88,7 @@ func TestParsePods(t *testing.T) {
 		pod               *kubelet.Pod
 		labelsAsTags      map[string]string
 		annotationsAsTags map[string]string
+		clusterName       string
 		expectedInfo      []*TagInfo
 	}{
 		{
@@ -132,6 +133,36 @@ func TestParsePods(t *testing.T) {
 				},
 			}},
 		},
+		{
+			desc: "cluster name",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Name:      "dd-agent-rc-qd876",
+					Namespace: "default",
+				},
+				Status: dockerContainerStatus,
+				Spec:   dockerContainerSpec,
+			},
+			labelsAsTags: map[string]string{},
+			clusterName:  "prod-cluster",
+			expectedInfo: []*TagInfo{{
+				Source: "kubelet",
+				Entity: dockerEntityID,
+				LowCardTags: []string{
+					"kube_namespace:default",
+					"kube_cluster_name:prod-cluster",
+					"kube_container_name:dd-agent",
+					"image_tag:latest5",
+					"image_name:datadog/docker-dd-agent",
+					"short_image:docker-dd-agent",
+				},
+				HighCardTags: []string{
+					"container_id:d0242fc32d53137526dc365e7c86ef43b5f50b6f72dfd53dcb948eff4560376f",
+					"pod_name:dd-agent-rc-qd876",
+					"display_container_name:dd-agent_dd-agent-rc-qd876",
+				},
+			}},
+		},
 		{
 			desc: "two containers + pod",
 			pod: &kubelet.Pod{
@@ -399,6 +430,38 @@ func TestParsePods(t *testing.T) {
 				},
 			}},
 		},
+		{
+			desc: "unified service tagging",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Labels: map[string]string{
+						"tags.datadoghq.com/env":              "prod",
+						"tags.datadoghq.com/service":          "billing",
+						"tags.datadoghq.com/version":          "1.2.3",
+						"tags.datadoghq.com/dd-agent.service": "agent",
+					},
+				},
+				Status: dockerContainerStatus,
+				Spec:   dockerContainerSpec,
+			},
+			labelsAsTags: map[string]string{},
+			expectedInfo: []*TagInfo{{
+				Source: "kubelet",
+				Entity: dockerEntityID,
+				LowCardTags: []string{
+					"kube_container_name:dd-agent",
+					"env:prod",
+					"service:agent",
+					"version:1.2.3",
+					"image_tag:latest5",
+					"image_name:datadog/docker-dd-agent",
+					"short_image:docker-dd-agent",
+				},
+				HighCardTags: []string{
+					"container_id:d0242fc32d53137526dc365e7c86ef43b5f50b6f72dfd53dcb948eff4560376f",
+				},
+			}},
+		},
 		{
 			desc: "CRI pod",
 			pod: &kubelet.Pod{
@@ -438,6 +501,7 @@ func TestParsePods(t *testing.T) {
 			collector := &KubeletCollector{
 				labelsAsTags:      tc.labelsAsTags,
 				annotationsAsTags: tc.annotationsAsTags,
+				clusterName:       tc.clusterName,
 			}
 			infos, err := collector.parsePods([]*kubelet.Pod{tc.pod})
 			assert.Nil(t, err)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
@@ -31,6 +32,7 @@ type KubeletCollector struct {
 	expireFreq        time.Duration
 	labelsAsTags      map[string]string
 	annotationsAsTags map[string]string
+	clusterName       string
 }
 
 // Detect tries to connect to the kubelet
@@ -58,6 +60,7 @@ func (c *KubeletCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error)
 		annotationsList[strings.ToLower(annotation)] = value
 	}
 	c.annotationsAsTags = annotationsList
+	c.clusterName = clustername.GetClusterNameOrUID()
 	return PullCollection, nil
 }
 
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/podman"
+)
+
+// parseContainers returns the TagInfo of the new containers, or of all the
+// containers if parseAll is set. It also updates the lastSeen cache of the
+// expire process.
+func (c *PodmanCollector) parseContainers(cList []podman.Container, parseAll bool) []*TagInfo {
+	var output []*TagInfo
+	now := time.Now()
+
+	for _, co := range cList {
+		if !c.expire.Update(co.ID, now) && !parseAll {
+			continue
+		}
+		low, high := c.extractTags(co)
+		output = append(output, &TagInfo{
+			Source:       podmanCollectorName,
+			Entity:       podman.ContainerIDToEntityName(co.ID),
+			HighCardTags: high,
+			LowCardTags:  low,
+		})
+	}
+	return output
+}
+
+// extractTags returns the low and high cardinality tags of a container
+func (c *PodmanCollector) extractTags(co podman.Container) ([]string, []string) {
+	tags := utils.NewTagList()
+
+	tags.AddLow("docker_image", co.Image)
+	imageName, shortImage, imageTag, err := containers.SplitImageName(co.Image)
+	if err != nil {
+		log.Debugf("Cannot split %s: %s", co.Image, err)
+	} else {
+		tags.AddLow("image_name", imageName)
+		tags.AddLow("short_image", shortImage)
+		tags.AddLow("image_tag", imageTag)
+	}
+
+	for labelName, labelValue := range co.Labels {
+		switch labelName {
+		// Unified service tagging
+		case dockerLabelEnv:
+			tags.AddLow(tagKeyEnv, labelValue)
+		case dockerLabelService:
+			tags.AddLow(tagKeyService, labelValue)
+		case dockerLabelVersion:
+			tags.AddLow(tagKeyVersion, labelValue)
+		}
+		if tagName, found := c.labelsAsTags[strings.ToLower(labelName)]; found {
+			tags.AddAuto(tagName, labelValue)
+		}
+	}
+
+	if co.PodName != "" {
+		tags.AddLow("podman_pod", co.PodName)
+	}
+	if len(co.Names) > 0 {
+		tags.AddHigh("container_name", strings.TrimPrefix(co.Names[0], "/"))
+	}
+	tags.AddHigh("container_id", co.ID)
+
+	return tags.Compute()
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/podman"
+)
+
+func TestPodmanParseContainers(t *testing.T) {
+	collector := &PodmanCollector{
+		labelsAsTags: map[string]string{
+			"team": "team",
+			"rev":  "+git_rev",
+		},
+	}
+	var err error
+	collector.expire, err = taggerutil.NewExpire(podmanExpireFreq)
+	require.NoError(t, err)
+
+	cList := []podman.Container{
+		{
+			ID:      "e1f0f2a3b4c5",
+			Names:   []string{"web"},
+			Image:   "docker.io/library/nginx:1.15",
+			PodName: "frontend",
+			Labels: map[string]string{
+				"com.datadoghq.tags.env": "prod",
+				"team":                   "infra",
+				"rev":                    "4f2a1b",
+				"ignored":                "value",
+			},
+		},
+	}
+
+	infos := collector.parseContainers(cList, false)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "podman://e1f0f2a3b4c5", infos[0].Entity)
+	assert.Equal(t, podmanCollectorName, infos[0].Source)
+	assert.ElementsMatch(t, []string{
+		"docker_image:docker.io/library/nginx:1.15",
+		"image_name:docker.io/library/nginx",
+		"short_image:nginx",
+		"image_tag:1.15",
+		"env:prod",
+		"team:infra",
+		"podman_pod:frontend",
+	}, infos[0].LowCardTags)
+	assert.ElementsMatch(t, []string{
+		"git_rev:4f2a1b",
+		"container_name:web",
+		"container_id:e1f0f2a3b4c5",
+	}, infos[0].HighCardTags)
+
+	// known containers are only parsed again when asked to
+	assert.Len(t, collector.parseContainers(cList, false), 0)
+	assert.Len(t, collector.parseContainers(cList, true), 1)
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/podman"
+)
+
+const (
+	podmanCollectorName = "podman"
+	podmanExpireFreq    = 5 * time.Minute
+)
+
+// PodmanCollector polls the podman REST API, podman has no event stream
+// the collector could subscribe to without a long-running podman process
+type PodmanCollector struct {
+	podmanUtil   *podman.PodmanUtil
+	infoOut      chan<- []*TagInfo
+	expire       *taggerutil.Expire
+	lastExpire   time.Time
+	expireFreq   time.Duration
+	labelsAsTags map[string]string
+}
+
+// Detect tries to connect to the podman socket
+func (c *PodmanCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
+	pu, err := podman.GetPodmanUtil()
+	if err != nil {
+		return NoCollection, err
+	}
+
+	c.podmanUtil = pu
+	c.infoOut = out
+	c.lastExpire = time.Now()
+	c.expireFreq = podmanExpireFreq
+	c.expire, err = taggerutil.NewExpire(podmanExpireFreq)
+	if err != nil {
+		return NoCollection, fmt.Errorf("Failed to instantiate the container expiring process")
+	}
+	// podman containers carry the same OCI labels as docker containers
+	c.labelsAsTags = retrieveMappingFromConfig("docker_labels_as_tags")
+
+	return PullCollection, nil
+}
+
+// Pull looks for new containers and computes deletions
+func (c *PodmanCollector) Pull() error {
+	cList, err := c.podmanUtil.GetContainers()
+	if err != nil {
+		return err
+	}
+	// Only parse new containers
+	c.infoOut <- c.parseContainers(cList, false)
+
+	// Throttle deletions
+	if time.Now().Before(c.lastExpire.Add(c.expireFreq)) {
+		return nil
+	}
+
+	expireList, err := c.expire.ComputeExpires()
+	if err != nil {
+		return err
+	}
+	c.infoOut <- c.parseExpires(expireList)
+	c.lastExpire = time.Now()
+	return nil
+}
+
+// Fetch parses tags for a container on cache miss. We avoid races with Pull,
+// we re-parse the whole list, but don't send updates on other containers.
+func (c *PodmanCollector) Fetch(entity string) ([]string, []string, error) {
+	cList, err := c.podmanUtil.GetContainers()
+	if err != nil {
+		return []string{}, []string{}, err
+	}
+
+	for _, info := range c.parseContainers(cList, true) {
+		if info.Entity == entity {
+			return info.LowCardTags, info.HighCardTags, nil
+		}
+	}
+	// container not found in updates
+	return []string{}, []string{}, errors.NewNotFound(entity)
+}
+
+// parseExpires transforms the expired container IDs to deletion TagInfo
+func (c *PodmanCollector) parseExpires(idList []string) []*TagInfo {
+	var output []*TagInfo
+	for _, id := range idList {
+		output = append(output, &TagInfo{
+			Source:       podmanCollectorName,
+			Entity:       podman.ContainerIDToEntityName(id),
+			DeleteEntity: true,
+		})
+	}
+	return output
+}
+
+func podmanFactory() Collector {
+	return &PodmanCollector{}
+}
+
+func init() {
+	registerCollector(podmanCollectorName, podmanFactory, NodeRuntime)
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package collectors
+
+// Unified service tagging: the env, service and version tags are extracted
+// from standardized labels and environment variables by every collector,
+// so they are attached consistently to metrics, logs and AD check configs.
+const (
+	tagKeyEnv     = "env"
+	tagKeyService = "service"
+	tagKeyVersion = "version"
+
+	// Docker container labels
+	dockerLabelEnv     = "com.datadoghq.tags.env"
+	dockerLabelService = "com.datadoghq.tags.service"
+	dockerLabelVersion = "com.datadoghq.tags.version"
+
+	// Container environment variables, also used by the tracing libraries
+	envVarEnv     = "DD_ENV"
+	envVarService = "DD_SERVICE"
+	envVarVersion = "DD_VERSION"
+
+	// Kubernetes pod labels, `tags.datadoghq.com/<container>.service` and
+	// `tags.datadoghq.com/<container>.version` override them per container
+	podLabelPrefix = "tags.datadoghq.com/"
+	podLabelEnv    = podLabelPrefix + tagKeyEnv
+)
+
+// standardContainerLabel returns the value of the `tags.datadoghq.com/<container>.<key>`
+// pod label, falling back to the pod level `tags.datadoghq.com/<key>` label.
+func standardContainerLabel(podLabels map[string]string, containerName, key string) string {
+	if value, found := podLabels[podLabelPrefix+containerName+"."+key]; found {
+		return value
+	}
+	return podLabels[podLabelPrefix+key]
+}
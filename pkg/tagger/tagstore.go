@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
@@ -20,8 +21,10 @@ type entityTags struct {
 	highCardTags map[string][]string
 	cacheValid   bool
 	cachedSource []string
-	cachedAll    []string // Low + high
-	cachedLow    []string // Sub-slice of cachedAll
+	cachedAll    []string // Low + high, interned
+	cachedLow    []string // Low, interned
+	cachedAllKey tagset.Key
+	cachedLowKey tagset.Key
 	tagsHash     string
 }
 
@@ -107,6 +110,11 @@ func (s *tagStore) prune() error {
 	s.storeMutex.Lock()
 	defer s.storeMutex.Unlock()
 	for entity := range s.toDelete {
+		if storedTags, found := s.store[entity]; found {
+			storedTags.Lock()
+			storedTags.releaseCache()
+			storedTags.Unlock()
+		}
 		delete(s.store, entity)
 	}
 
@@ -187,19 +195,31 @@ func (e *entityTags) get(highCard bool) ([]string, []string, string) {
 		}
 	}
 
+	// sort the tags to share them with the entities having the same tags
+	sort.Strings(lowCardTags)
+	sort.Strings(highCardTags)
 	tags := append(lowCardTags, highCardTags...)
 
 	// Write cache
+	e.releaseCache()
 	e.cacheValid = true
 	e.cachedSource = sources
-	e.cachedAll = tags
-	e.cachedLow = e.cachedAll[:len(lowCardTags)]
+	e.cachedAll, e.cachedAllKey = tagset.Intern(tags)
+	e.cachedLow, e.cachedLowKey = tagset.Intern(tags[:len(lowCardTags)])
 	e.tagsHash = computeTagsHash(e.cachedAll)
 
 	if highCard {
-		return tags, sources, e.tagsHash
+		return e.cachedAll, sources, e.tagsHash
 	}
-	return lowCardTags, sources, e.tagsHash
+	return e.cachedLow, sources, e.tagsHash
+}
+
+// releaseCache releases the interned tags of the cache, the lock must be held
+func (e *entityTags) releaseCache() {
+	tagset.Release(e.cachedAllKey)
+	tagset.Release(e.cachedLowKey)
+	e.cachedAllKey = tagset.Key{}
+	e.cachedLowKey = tagset.Key{}
 }
 
 func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, isHighCard bool) {
@@ -157,6 +157,25 @@ func (s *StoreTestSuite) TestPrune() {
 
 }
 
+func (s *StoreTestSuite) TestLookupSharesTags() {
+	for _, entity := range []string{"replica1", "replica2"} {
+		s.store.processTagInfo(&collectors.TagInfo{
+			Source:       "source",
+			Entity:       entity,
+			LowCardTags:  []string{"service:web", "env:prod"},
+			HighCardTags: []string{"pod_name:" + entity},
+		})
+	}
+
+	low1, _, _ := s.store.lookup("replica1", false)
+	low2, _, _ := s.store.lookup("replica2", false)
+	assert.Equal(s.T(), []string{"env:prod", "service:web"}, low1)
+	assert.True(s.T(), &low1[0] == &low2[0])
+
+	all1, _, _ := s.store.lookup("replica1", true)
+	assert.Equal(s.T(), []string{"env:prod", "service:web", "pod_name:replica1"}, all1)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package tagset interns the tag sets shared by the tagger entities and the
+// aggregator contexts. On hosts running many replicas of the same containers,
+// or sending many metrics with the same tags, most contexts have identical
+// tag sets: they are stored once and referenced by the sets they replace.
+package tagset
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/DataDog/mmh3"
+)
+
+const keySize = 16
+
+// Key identifies a tag set, it's the 128bit murmur3 hash of its tags. Like
+// the aggregator context keys, there is no collision mitigation.
+type Key [keySize]byte
+
+var (
+	tagsetExpvars      = expvar.NewMap("tagset")
+	internedSets       = expvar.Int{}
+	internedReferences = expvar.Int{}
+	internedTags       = expvar.Int{}
+
+	// defaultStore is the store backing the global Intern and Release functions
+	defaultStore = NewStore()
+)
+
+func init() {
+	tagsetExpvars.Set("Sets", &internedSets)
+	tagsetExpvars.Set("References", &internedReferences)
+	tagsetExpvars.Set("Tags", &internedTags)
+}
+
+// NewKey returns the key of a tag set. The order of the tags matters, they
+// should be sorted to share the sets listing the same tags.
+func NewKey(tags []string) Key {
+	mmh := &mmh3.HashWriter128{}
+	mmh.Reset()
+	for _, t := range tags {
+		mmh.WriteString(t)
+		mmh.WriteString(",")
+	}
+
+	var key [keySize]byte
+	mmh.Sum(key[0:0])
+	return key
+}
+
+// IsZero returns whether the key is the zero value, the key of the sets
+// which are not interned
+func (k Key) IsZero() bool {
+	return k == Key{}
+}
+
+// entry is an interned tag set and the number of its references
+type entry struct {
+	tags []string
+	refs int
+}
+
+// Store holds the interned tag sets, until their last reference is released
+type Store struct {
+	sets map[Key]*entry
+	m    sync.Mutex
+}
+
+// NewStore returns a new empty Store
+func NewStore() *Store {
+	return &Store{
+		sets: make(map[Key]*entry),
+	}
+}
+
+// Intern returns the interned copy of tags and its key, and takes a
+// reference on it. The interned sets are shared: they must not be modified,
+// and as their capacity is their length, appending to them copies them.
+// Empty sets are not interned and have a zero key.
+func (s *Store) Intern(tags []string) ([]string, Key) {
+	if len(tags) == 0 {
+		return nil, Key{}
+	}
+	key := NewKey(tags)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	e, found := s.sets[key]
+	if !found {
+		interned := make([]string, len(tags))
+		copy(interned, tags)
+		e = &entry{tags: interned}
+		s.sets[key] = e
+		internedSets.Add(1)
+		internedTags.Add(int64(len(interned)))
+	}
+	e.refs++
+	internedReferences.Add(1)
+	return e.tags[:len(e.tags):len(e.tags)], key
+}
+
+// Release drops a reference taken by Intern, the set is removed from the
+// store with its last reference
+func (s *Store) Release(key Key) {
+	if key.IsZero() {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	e, found := s.sets[key]
+	if !found {
+		return
+	}
+	e.refs--
+	internedReferences.Add(-1)
+	if e.refs <= 0 {
+		delete(s.sets, key)
+		internedSets.Add(-1)
+		internedTags.Add(-int64(len(e.tags)))
+	}
+}
+
+// Len returns the number of interned sets
+func (s *Store) Len() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return len(s.sets)
+}
+
+// Intern interns a tag set in the default store, see Store.Intern
+func Intern(tags []string) ([]string, Key) {
+	return defaultStore.Intern(tags)
+}
+
+// Release releases a tag set of the default store, see Store.Release
+func Release(key Key) {
+	defaultStore.Release(key)
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package tagset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntern(t *testing.T) {
+	s := NewStore()
+	original := []string{"env:prod", "service:web"}
+
+	tags1, key1 := s.Intern(original)
+	tags2, key2 := s.Intern([]string{"env:prod", "service:web"})
+	require.Equal(t, original, tags1)
+	assert.Equal(t, key1, key2)
+	assert.Equal(t, NewKey(original), key1)
+	assert.True(t, &tags1[0] == &tags2[0])
+	assert.Equal(t, 1, s.Len())
+
+	// the interned set is a copy
+	original[0] = "env:dev"
+	assert.Equal(t, "env:prod", tags1[0])
+
+	// appending copies the set
+	appended := append(tags1, "version:1")
+	assert.False(t, &appended[0] == &tags2[0])
+
+	// the order matters
+	_, key3 := s.Intern([]string{"service:web", "env:prod"})
+	assert.NotEqual(t, key1, key3)
+	assert.Equal(t, 2, s.Len())
+}
+
+func TestInternEmpty(t *testing.T) {
+	s := NewStore()
+	tags, key := s.Intern(nil)
+	assert.Nil(t, tags)
+	assert.True(t, key.IsZero())
+	assert.Equal(t, 0, s.Len())
+	s.Release(key)
+}
+
+func TestRelease(t *testing.T) {
+	s := NewStore()
+	_, key := s.Intern([]string{"env:prod"})
+	s.Intern([]string{"env:prod"})
+
+	s.Release(key)
+	assert.Equal(t, 1, s.Len())
+	s.Release(key)
+	assert.Equal(t, 0, s.Len())
+
+	// releasing an unknown set is a noop
+	s.Release(key)
+	assert.Equal(t, 0, s.Len())
+}
+
+func BenchmarkIntern(b *testing.B) {
+	s := NewStore()
+	tagSets := make([][]string, 100)
+	for i := range tagSets {
+		for j := 0; j < 10; j++ {
+			tagSets[i] = append(tagSets[i], fmt.Sprintf("tag%d:value%d", j, i))
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		s.Intern(tagSets[n%len(tagSets)])
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -34,6 +35,29 @@ func GetHostAlias() (string, error) {
 	return string(all), nil
 }
 
+// GetClusterName returns the name of the AKS cluster of the VM, AKS creates the
+// nodes in a resource group named MC_<resource group>_<cluster>_<location>
+func GetClusterName() (string, error) {
+	res, err := getResponse(metadataURL + "/metadata/instance/compute/resourceGroupName?api-version=2017-08-01&format=text")
+	if err != nil {
+		return "", fmt.Errorf("Azure cluster name: unable to query metadata endpoint: %s", err)
+	}
+
+	defer res.Body.Close()
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error while reading response from azure metadata endpoint: %s", err)
+	}
+
+	// the resource group of the cluster may contain underscores, the cluster
+	// and the location may not
+	parts := strings.Split(string(all), "_")
+	if len(parts) < 4 || strings.ToLower(parts[0]) != "mc" {
+		return "", fmt.Errorf("cannot parse the cluster name from the resource group %q", string(all))
+	}
+	return parts[len(parts)-2], nil
+}
+
 func getResponse(url string) (*http.Response, error) {
 	client := http.Client{
 		Timeout: timeout,
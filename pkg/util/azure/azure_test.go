@@ -31,3 +31,32 @@ func TestGetHostname(t *testing.T) {
 	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/vmId")
 	assert.Equal(t, lastRequest.URL.RawQuery, "api-version=2017-04-02&format=text")
 }
+
+func TestGetClusterName(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "MC_my_rg_prod-aks_westeurope")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetClusterName()
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-aks", val)
+	assert.Equal(t, "/metadata/instance/compute/resourceGroupName", lastRequest.URL.Path)
+	assert.Equal(t, "api-version=2017-08-01&format=text", lastRequest.URL.RawQuery)
+}
+
+func TestGetClusterNameNotAKS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "my-resource-group")
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	_, err := GetClusterName()
+	assert.Error(t, err)
+}
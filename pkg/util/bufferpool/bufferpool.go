@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package bufferpool provides pools of bytes.Buffer, reused from one flush
+// to the next instead of being grown again and garbage collected. The stats
+// of a pool give its hit rate, the ratio of Hits to Gets.
+package bufferpool
+
+import (
+	"bytes"
+	"expvar"
+	"sync"
+)
+
+// Pool is a pool of bytes.Buffer. The buffers grown beyond the max size of
+// the pool are not put back, to not hold their memory after a spike.
+type Pool struct {
+	pool    sync.Pool
+	maxSize int
+
+	stats    expvar.Map
+	gets     expvar.Int
+	hits     expvar.Int
+	discards expvar.Int
+}
+
+// New returns a new pool keeping the buffers up to maxSize bytes
+func New(maxSize int) *Pool {
+	p := &Pool{maxSize: maxSize}
+	p.stats.Init()
+	p.stats.Set("Gets", &p.gets)
+	p.stats.Set("Hits", &p.hits)
+	p.stats.Set("Discards", &p.discards)
+	return p
+}
+
+// Get returns an empty buffer, reused from the pool if one is available
+func (p *Pool) Get() *bytes.Buffer {
+	p.gets.Add(1)
+	if b, ok := p.pool.Get().(*bytes.Buffer); ok {
+		p.hits.Add(1)
+		return b
+	}
+	return &bytes.Buffer{}
+}
+
+// Put resets a buffer and puts it back in the pool. The buffer must not be
+// used afterwards, nor the slices returned by its Bytes method.
+func (p *Pool) Put(b *bytes.Buffer) {
+	if b.Cap() > p.maxSize {
+		p.discards.Add(1)
+		return
+	}
+	b.Reset()
+	p.pool.Put(b)
+}
+
+// Stats returns the Gets, Hits and Discards counts of the pool, to be
+// published in the expvars of its user
+func (p *Pool) Stats() *expvar.Map {
+	return &p.stats
+}
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package bufferpool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	p := New(1024)
+
+	b := p.Get()
+	assert.Equal(t, 0, b.Len())
+	assert.Equal(t, "0", p.Stats().Get("Hits").String())
+	b.WriteString("payload")
+	p.Put(b)
+
+	// sync.Pool may drop its items at any GC, a hit isn't guaranteed
+	reused := p.Get()
+	assert.Equal(t, 0, reused.Len())
+	assert.Equal(t, "2", p.Stats().Get("Gets").String())
+}
+
+func TestPoolDiscardsBigBuffers(t *testing.T) {
+	p := New(16)
+
+	b := p.Get()
+	b.Write(make([]byte, 64))
+	p.Put(b)
+	assert.Equal(t, "1", p.Stats().Get("Discards").String())
+}
+
+func BenchmarkPool(b *testing.B) {
+	p := New(1024 * 1024)
+	data := make([]byte, 64*1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := p.Get()
+		buf.Write(data)
+		p.Put(buf)
+	}
+}
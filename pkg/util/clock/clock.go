@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clock
+
+import (
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/beevik/ntp"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ntpVersion is the version of the protocol used to query the NTP servers
+const ntpVersion = 3
+
+var (
+	clockExpvars   = expvar.NewMap("clock")
+	measuredOffset = expvar.Float{}
+	appliedOffset  = expvar.Float{}
+	syncs          = expvar.Int{}
+	syncErrors     = expvar.Int{}
+	correctedSyncs = expvar.Int{}
+
+	// offset is the correction applied to the time, in nanoseconds,
+	// accessed atomically
+	offset int64
+
+	stop   chan struct{}
+	stopMu sync.Mutex
+
+	// for testing purpose
+	ntpQuery = ntp.Query
+	timeNow  = time.Now
+)
+
+func init() {
+	clockExpvars.Set("MeasuredOffset", &measuredOffset)
+	clockExpvars.Set("AppliedOffset", &appliedOffset)
+	clockExpvars.Set("Syncs", &syncs)
+	clockExpvars.Set("SyncErrors", &syncErrors)
+	clockExpvars.Set("CorrectedSyncs", &correctedSyncs)
+}
+
+// Now returns the current time. When the correction is enabled and the offset
+// of the system clock measured with NTP exceeds `clock_correction.threshold`,
+// the offset is applied so that the timestamps of the metrics, events and
+// logs aren't rejected by the intake.
+func Now() time.Time {
+	return timeNow().Add(Offset())
+}
+
+// Offset returns the correction applied to the system clock by Now
+func Offset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&offset))
+}
+
+// Start measures the offset of the system clock with the NTP servers of
+// `clock_correction.ntp_hosts`, every `clock_correction.interval` seconds
+func Start() {
+	stopMu.Lock()
+	defer stopMu.Unlock()
+	if stop != nil {
+		return
+	}
+
+	hosts := config.Datadog.GetStringSlice("clock_correction.ntp_hosts")
+	threshold := time.Duration(config.Datadog.GetInt("clock_correction.threshold")) * time.Second
+	interval := time.Duration(config.Datadog.GetInt("clock_correction.interval")) * time.Second
+	if len(hosts) == 0 || interval <= 0 {
+		log.Warnf("Invalid clock_correction settings, not correcting the clock")
+		return
+	}
+
+	stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := syncOffset(hosts, threshold); err != nil {
+				log.Infof("Unable to measure the offset of the system clock: %s", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(stop)
+}
+
+// Stop stops measuring the offset of the system clock, the last correction
+// keeps being applied
+func Stop() {
+	stopMu.Lock()
+	defer stopMu.Unlock()
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// syncOffset measures the offset of the system clock and updates the
+// correction, the offsets within the threshold aren't corrected. The
+// correction is kept when the servers can't be reached.
+func syncOffset(hosts []string, threshold time.Duration) error {
+	measured, err := measureOffset(hosts)
+	if err != nil {
+		syncErrors.Add(1)
+		return err
+	}
+	syncs.Add(1)
+	measuredOffset.Set(measured.Seconds())
+
+	var applied time.Duration
+	if measured > threshold || measured < -threshold {
+		applied = measured
+		correctedSyncs.Add(1)
+	}
+	appliedOffset.Set(applied.Seconds())
+
+	previous := time.Duration(atomic.SwapInt64(&offset, int64(applied)))
+	switch {
+	case previous == 0 && applied != 0:
+		log.Warnf("The system clock is off by %s, correcting the timestamps of the metrics, events and logs", measured)
+	case previous != 0 && applied == 0:
+		log.Infof("The system clock is off by %s only, the timestamps aren't corrected anymore", measured)
+	}
+	return nil
+}
+
+// measureOffset returns the median of the offsets of the system clock
+// measured with the NTP servers
+func measureOffset(hosts []string) (time.Duration, error) {
+	var offsets []time.Duration
+	for _, host := range hosts {
+		response, err := ntpQuery(host, ntpVersion)
+		if err != nil {
+			log.Debugf("Unable to query the NTP server %s: %s", host, err)
+			continue
+		}
+		offsets = append(offsets, response.ClockOffset)
+	}
+	if len(offsets) == 0 {
+		return 0, fmt.Errorf("no NTP server could be reached out of %v", hosts)
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	length := len(offsets)
+	if length%2 == 0 {
+		return (offsets[length/2-1] + offsets[length/2]) / 2, nil
+	}
+	return offsets[length/2], nil
+}
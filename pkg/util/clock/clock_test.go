@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clock
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/beevik/ntp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockNTP(offsets map[string]time.Duration) func() {
+	ntpQuery = func(host string, version int) (*ntp.Response, error) {
+		if o, found := offsets[host]; found {
+			return &ntp.Response{ClockOffset: o}, nil
+		}
+		return nil, fmt.Errorf("no response from %s", host)
+	}
+	return func() {
+		ntpQuery = ntp.Query
+		atomic.StoreInt64(&offset, 0)
+	}
+}
+
+func TestMeasureOffset(t *testing.T) {
+	defer mockNTP(map[string]time.Duration{
+		"a": 10 * time.Second,
+		"b": 12 * time.Second,
+		"c": 30 * time.Second,
+		"d": 14 * time.Second,
+	})()
+
+	measured, err := measureOffset([]string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Second, measured)
+
+	measured, err = measureOffset([]string{"a", "b", "c", "d", "unreachable"})
+	require.NoError(t, err)
+	assert.Equal(t, 13*time.Second, measured)
+
+	_, err = measureOffset([]string{"unreachable"})
+	assert.Error(t, err)
+}
+
+func TestSyncOffset(t *testing.T) {
+	defer func(original func() time.Time) { timeNow = original }(timeNow)
+	now := time.Unix(1500000000, 0)
+	timeNow = func() time.Time { return now }
+
+	offsets := map[string]time.Duration{"pool": -5 * time.Minute}
+	defer mockNTP(offsets)()
+
+	// the offset exceeds the threshold, it's corrected
+	require.NoError(t, syncOffset([]string{"pool"}, time.Minute))
+	assert.Equal(t, -5*time.Minute, Offset())
+	assert.Equal(t, now.Add(-5*time.Minute), Now())
+
+	// the correction is kept when the servers can't be reached
+	assert.Error(t, syncOffset([]string{"unreachable"}, time.Minute))
+	assert.Equal(t, -5*time.Minute, Offset())
+
+	// the offset is within the threshold, it's not corrected anymore
+	offsets["pool"] = 2 * time.Second
+	require.NoError(t, syncOffset([]string{"pool"}, time.Minute))
+	assert.Equal(t, time.Duration(0), Offset())
+	assert.Equal(t, now, Now())
+}
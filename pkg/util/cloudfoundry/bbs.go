@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cloudfoudry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/retry"
+)
+
+// vcapApplicationEnvVar describes the app of a desired LRP, the Cloud
+// Controller sets it in the environment of the app processes
+const vcapApplicationEnvVar = "VCAP_APPLICATION"
+
+var globalBBSUtil *BBSUtil
+
+// AppInstance is an instance of a Cloud Foundry app, run by the cell in the
+// garden container named after its instance GUID
+type AppInstance struct {
+	InstanceGUID string
+	ProcessGUID  string
+	Index        int32
+	App          AppInfo
+}
+
+// AppInfo is the part of the VCAP_APPLICATION of an app used for tagging
+type AppInfo struct {
+	AppName   string `json:"application_name"`
+	AppGUID   string `json:"application_id"`
+	SpaceName string `json:"space_name"`
+	SpaceGUID string `json:"space_id"`
+	OrgName   string `json:"organization_name"`
+	OrgGUID   string `json:"organization_id"`
+}
+
+// BBSUtil queries the Diego BBS for the app instances of the cell. The apps
+// of the desired LRPs are cached, a new version of an app gets a new process
+// GUID.
+type BBSUtil struct {
+	initRetry retry.Retrier
+
+	client bbs.Client
+	logger lager.Logger
+	cellID string
+
+	m    sync.Mutex
+	apps map[string]AppInfo
+}
+
+// GetBBSUtil returns the global BBSUtil, it's initialized on the first call
+// and retried if the BBS is not reachable
+func GetBBSUtil() (*BBSUtil, error) {
+	if globalBBSUtil == nil {
+		globalBBSUtil = &BBSUtil{}
+		globalBBSUtil.initRetry.SetupRetrier(&retry.Config{
+			Name:          "bbsutil",
+			AttemptMethod: globalBBSUtil.init,
+			Strategy:      retry.RetryCount,
+			RetryCount:    10,
+			RetryDelay:    30 * time.Second,
+		})
+	}
+	if err := globalBBSUtil.initRetry.TriggerRetry(); err != nil {
+		log.Debugf("BBS init error: %s", err)
+		return nil, err
+	}
+	return globalBBSUtil, nil
+}
+
+func (b *BBSUtil) init() error {
+	if !config.Datadog.GetBool("cloud_foundry") {
+		return fmt.Errorf("cloud_foundry is not enabled")
+	}
+	// the diego cells are identified by their bosh instance ID, without it
+	// the actual LRPs of every cell would be listed
+	cellID := config.Datadog.GetString("bosh_id")
+	if cellID == "" {
+		return fmt.Errorf("bosh_id is not set, the app instances of the cell can't be listed")
+	}
+
+	// the lager logs of the client are dropped, its errors are returned
+	b.logger = lager.NewLogger("datadog-agent")
+	client, err := bbs.NewSecureClient(
+		config.Datadog.GetString("cloud_foundry_bbs.url"),
+		config.Datadog.GetString("cloud_foundry_bbs.ca_file"),
+		config.Datadog.GetString("cloud_foundry_bbs.cert_file"),
+		config.Datadog.GetString("cloud_foundry_bbs.key_file"),
+		0, 0,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create the BBS client: %s", err)
+	}
+	if !client.Ping(b.logger) {
+		return fmt.Errorf("the BBS is not reachable")
+	}
+
+	b.client = client
+	b.cellID = cellID
+	b.apps = make(map[string]AppInfo)
+	return nil
+}
+
+// GetAppInstances returns the app instances running on the cell
+func (b *BBSUtil) GetAppInstances() ([]AppInstance, error) {
+	if b.cellID == "" {
+		return nil, fmt.Errorf("bosh_id is not set, the app instances of the cell can't be listed")
+	}
+	groups, err := b.client.ActualLRPGroups(b.logger, models.ActualLRPFilter{CellID: b.cellID})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the actual LRPs: %s", err)
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	live := make(map[string]bool)
+	var instances []AppInstance
+	for _, group := range groups {
+		lrp := group.Instance
+		if lrp == nil || lrp.ActualLRPInstanceKey.InstanceGuid == "" {
+			continue
+		}
+		processGUID := lrp.ActualLRPKey.ProcessGuid
+		live[processGUID] = true
+
+		app, found := b.apps[processGUID]
+		if !found {
+			app, err = b.getApp(processGUID)
+			if err != nil {
+				log.Debugf("Unable to get the app of %s: %s", processGUID, err)
+				continue
+			}
+			b.apps[processGUID] = app
+		}
+
+		instances = append(instances, AppInstance{
+			InstanceGUID: lrp.ActualLRPInstanceKey.InstanceGuid,
+			ProcessGUID:  processGUID,
+			Index:        lrp.ActualLRPKey.Index,
+			App:          app,
+		})
+	}
+
+	for processGUID := range b.apps {
+		if !live[processGUID] {
+			delete(b.apps, processGUID)
+		}
+	}
+	return instances, nil
+}
+
+// getApp returns the app of a desired LRP from its VCAP_APPLICATION
+func (b *BBSUtil) getApp(processGUID string) (AppInfo, error) {
+	lrp, err := b.client.DesiredLRPByProcessGuid(b.logger, processGUID)
+	if err != nil {
+		return AppInfo{}, err
+	}
+
+	var app AppInfo
+	for _, env := range actionEnvironment(lrp.Action) {
+		if env.Name == vcapApplicationEnvVar {
+			err := json.Unmarshal([]byte(env.Value), &app)
+			return app, err
+		}
+	}
+	return app, fmt.Errorf("no %s in the environment", vcapApplicationEnvVar)
+}
+
+// actionEnvironment returns the environment variables of the run actions of
+// an action, the actions are nested in each other
+func actionEnvironment(action *models.Action) []*models.EnvironmentVariable {
+	if action == nil {
+		return nil
+	}
+
+	var env []*models.EnvironmentVariable
+	var nested []*models.Action
+	switch {
+	case action.RunAction != nil:
+		env = append(env, action.RunAction.Env...)
+	case action.SerialAction != nil:
+		nested = action.SerialAction.Actions
+	case action.ParallelAction != nil:
+		nested = action.ParallelAction.Actions
+	case action.CodependentAction != nil:
+		nested = action.CodependentAction.Actions
+	case action.TimeoutAction != nil:
+		nested = []*models.Action{action.TimeoutAction.Action}
+	case action.TryAction != nil:
+		nested = []*models.Action{action.TryAction.Action}
+	case action.EmitProgressAction != nil:
+		nested = []*models.Action{action.EmitProgressAction.Action}
+	}
+	for _, a := range nested {
+		env = append(env, actionEnvironment(a)...)
+	}
+	return env
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cloudfoudry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code.cloudfoundry.org/bbs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionEnvironment(t *testing.T) {
+	vcap := `{"application_id":"0d4f6b48","application_name":"dora","space_name":"dev","space_id":"a1b2c3d4","organization_name":"acme","organization_id":"e5f6a7b8"}`
+	// the app process is wrapped in the actions of the cloud controller
+	action := &models.Action{
+		TimeoutAction: &models.TimeoutAction{
+			Action: &models.Action{
+				CodependentAction: &models.CodependentAction{
+					Actions: []*models.Action{
+						{RunAction: &models.RunAction{
+							Path: "/tmp/lifecycle/launcher",
+							Env: []*models.EnvironmentVariable{
+								{Name: "PORT", Value: "8080"},
+								{Name: vcapApplicationEnvVar, Value: vcap},
+							},
+						}},
+						{RunAction: &models.RunAction{
+							Path: "/tmp/lifecycle/diego-sshd",
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	env := actionEnvironment(action)
+	require.Len(t, env, 2)
+	assert.Equal(t, vcapApplicationEnvVar, env[1].Name)
+
+	var app AppInfo
+	require.NoError(t, json.Unmarshal([]byte(env[1].Value), &app))
+	assert.Equal(t, AppInfo{
+		AppName:   "dora",
+		AppGUID:   "0d4f6b48",
+		SpaceName: "dev",
+		SpaceGUID: "a1b2c3d4",
+		OrgName:   "acme",
+		OrgGUID:   "e5f6a7b8",
+	}, app)
+
+	assert.Nil(t, actionEnvironment(nil))
+}
@@ -190,12 +190,14 @@ func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error
 
 // CreateHTTPTransport creates an *http.Transport for use in the agent
 func CreateHTTPTransport() *http.Transport {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
-	}
-
-	if config.Datadog.GetBool("force_tls_12") {
-		tlsConfig.MinVersion = tls.VersionTLS12
+	tlsConfig, err := CreateTLSConfig()
+	if err != nil {
+		// Not trusting the custom CA would make every request fail, the
+		// connections are still attempted to surface the error in the logs
+		log.Errorf("Invalid TLS configuration, using the default one: %s", err)
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
+		}
 	}
 
 	transport := &http.Transport{
@@ -7,6 +7,8 @@
 
 package compression
 
+import "io"
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // empty here since there's no compression
 // var instead of const to ease testing
@@ -23,3 +25,26 @@ func Decompress(dst []byte, src []byte) ([]byte, error) {
 	dst = src
 	return dst, nil
 }
+
+// writer passes the data through without compressing it
+type writer struct {
+	io.Writer
+}
+
+func (w writer) Flush() error {
+	return nil
+}
+
+func (w writer) Close() error {
+	return nil
+}
+
+// NewWriter returns a StreamWriter writing to w without compression
+func NewWriter(w io.Writer) StreamWriter {
+	return writer{w}
+}
+
+// CompressBound returns the size of the data as it isn't compressed
+func CompressBound(sourceLen int) int {
+	return sourceLen
+}
@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package compression
+
+import (
+	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/util/bufferpool"
+)
+
+// maxPooledBufferSize bounds the compressed payloads kept by the buffer pool,
+// the serializer payloads are smaller
+const maxPooledBufferSize = 16 * 1024 * 1024
+
+var (
+	compressionExpvars = expvar.NewMap("compression")
+	writersExpvars     = expvar.Map{}
+	writerGets         = expvar.Int{}
+	writerHits         = expvar.Int{}
+
+	// buffers holds the scratch buffers of Compress
+	buffers = bufferpool.New(maxPooledBufferSize)
+)
+
+func init() {
+	writersExpvars.Init()
+	compressionExpvars.Set("Writers", &writersExpvars)
+	writersExpvars.Set("Gets", &writerGets)
+	writersExpvars.Set("Hits", &writerHits)
+	compressionExpvars.Set("Buffers", buffers.Stats())
+}
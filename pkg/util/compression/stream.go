@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package compression
+
+import "io"
+
+// StreamWriter compresses the data written to it into an underlying writer.
+// The compressed output is only complete once Flush or Close are called.
+type StreamWriter interface {
+	io.WriteCloser
+	Flush() error
+}
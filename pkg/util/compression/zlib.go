@@ -10,26 +10,70 @@ package compression
 import (
 	"bytes"
 	"compress/zlib"
+	"io"
 	"io/ioutil"
+	"sync"
 )
 
 // ContentEncoding describes the HTTP header value associated with the compression method
 // var instead of const to ease testing
 var ContentEncoding = "deflate"
 
-// Compress will compress the data with zlib
+// writerPool holds the closed zlib writers, their compression state weighs
+// hundreds of KB and is reset instead of being allocated for every payload
+var writerPool sync.Pool
+
+// pooledWriter is a zlib writer put back in writerPool when it's closed
+type pooledWriter struct {
+	*zlib.Writer
+	closed bool
+}
+
+func getWriter(w io.Writer) *pooledWriter {
+	writerGets.Add(1)
+	if zw, ok := writerPool.Get().(*zlib.Writer); ok {
+		writerHits.Add(1)
+		zw.Reset(w)
+		return &pooledWriter{Writer: zw}
+	}
+	return &pooledWriter{Writer: zlib.NewWriter(w)}
+}
+
+// Close flushes the compressed data and puts the writer back in the pool,
+// it must not be used afterwards
+func (w *pooledWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.Writer.Close()
+	// drop the reference to the destination until the writer is reused
+	w.Writer.Reset(nil)
+	writerPool.Put(w.Writer)
+	return err
+}
+
+// Compress will compress the data with zlib, dst is reused if it's large
+// enough
 func Compress(dst []byte, src []byte) ([]byte, error) {
-	var b bytes.Buffer
-	w := zlib.NewWriter(&b)
+	b := buffers.Get()
+	defer buffers.Put(b)
+
+	w := getWriter(b)
 	_, err := w.Write(src)
 	if err != nil {
+		w.Close()
 		return nil, err
 	}
 	err = w.Close()
 	if err != nil {
 		return nil, err
 	}
-	dst = b.Bytes()
+	// b goes back to the pool, the payload gets its own exactly sized copy
+	if cap(dst) < b.Len() {
+		dst = make([]byte, 0, b.Len())
+	}
+	dst = append(dst[:0], b.Bytes()...)
 	return dst, nil
 }
 
@@ -47,3 +91,15 @@ func Decompress(dst []byte, src []byte) ([]byte, error) {
 	}
 	return dst, nil
 }
+
+// NewWriter returns a StreamWriter compressing to w, its resources are
+// reused once it's closed
+func NewWriter(w io.Writer) StreamWriter {
+	return getWriter(w)
+}
+
+// CompressBound returns the worst case size of the compressed data of a given size
+func CompressBound(sourceLen int) int {
+	// from zlib's compressBound
+	return sourceLen + (sourceLen >> 12) + (sourceLen >> 14) + (sourceLen >> 25) + 13
+}
@@ -7,7 +7,11 @@
 
 package compression
 
-import "github.com/DataDog/zstd"
+import (
+	"io"
+
+	"github.com/DataDog/zstd"
+)
 
 // TODO: the intake still uses a pre-v1 (unstable) version of the zstd compression format.
 // The agent shouldn't use zstd compression until the intake supports a stable v1 format.
@@ -25,3 +29,23 @@ func Compress(dst []byte, src []byte) ([]byte, error) {
 func Decompress(dst []byte, src []byte) ([]byte, error) {
 	return zstd.Decompress(dst, src)
 }
+
+// zstdWriter adds a no-op Flush to the zstd writer, which doesn't need it:
+// every Write is compressed and written to the underlying writer right away
+type zstdWriter struct {
+	*zstd.Writer
+}
+
+func (w zstdWriter) Flush() error {
+	return nil
+}
+
+// NewWriter returns a StreamWriter compressing to w
+func NewWriter(w io.Writer) StreamWriter {
+	return zstdWriter{zstd.NewWriter(w)}
+}
+
+// CompressBound returns the worst case size of the compressed data of a given size
+func CompressBound(sourceLen int) int {
+	return zstd.CompressBound(sourceLen)
+}
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/garden"
+)
+
+const (
+	gardenCollectorName = "garden"
+)
+
+// GardenCollector lists the containers of a Cloud Foundry cell and their
+// metrics from the Garden API
+type GardenCollector struct {
+	gardenUtil *garden.GardenUtil
+}
+
+// Detect tries to connect to the Garden API and returns success
+func (c *GardenCollector) Detect() error {
+	gu, err := garden.GetGardenUtil()
+	if err != nil {
+		return err
+	}
+	c.gardenUtil = gu
+	return nil
+}
+
+// List gets all running containers
+func (c *GardenCollector) List() ([]*containers.Container, error) {
+	return c.gardenUtil.ListContainers()
+}
+
+// UpdateMetrics updates metrics on an existing list of containers
+func (c *GardenCollector) UpdateMetrics(cList []*containers.Container) error {
+	return c.gardenUtil.UpdateContainerMetrics(cList)
+}
+
+func gardenFactory() Collector {
+	return &GardenCollector{}
+}
+
+func init() {
+	registerCollector(gardenCollectorName, gardenFactory, NodeRuntime)
+}
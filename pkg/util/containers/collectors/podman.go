@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/podman"
+)
+
+const (
+	podmanCollectorName = "podman"
+)
+
+// PodmanCollector lists containers from the podman REST API and populates
+// performance metric from the linux cgroups
+type PodmanCollector struct {
+	podmanUtil *podman.PodmanUtil
+}
+
+// Detect tries to connect to the podman socket and returns success
+func (c *PodmanCollector) Detect() error {
+	pu, err := podman.GetPodmanUtil()
+	if err != nil {
+		return err
+	}
+	c.podmanUtil = pu
+	return nil
+}
+
+// List gets all running containers
+func (c *PodmanCollector) List() ([]*containers.Container, error) {
+	return c.podmanUtil.ListContainers()
+}
+
+// UpdateMetrics updates metrics on an existing list of containers
+func (c *PodmanCollector) UpdateMetrics(cList []*containers.Container) error {
+	return c.podmanUtil.UpdateContainerMetrics(cList)
+}
+
+func podmanFactory() Collector {
+	return &PodmanCollector{}
+}
+
+func init() {
+	registerCollector(podmanCollectorName, podmanFactory, NodeRuntime)
+}
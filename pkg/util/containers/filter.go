@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 const (
@@ -20,15 +22,35 @@ const (
 	// - gcr.io/google_containers/pause-amd64:3.0
 	pauseContainerGCR        = `image:(.*)gcr\.io(/google_containers/|/)pause(.*)`
 	pauseContainerOpenshift  = "image:openshift/origin-pod"
+	pauseContainerOpenshift3 = "image:openshift3/ose-pod"
 	pauseContainerKubernetes = "image:kubernetes/pause"
 	pauseContainerECS        = "image:amazon/amazon-ecs-pause"
+	// pauseContainerEKS regex matches:
+	// - 602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause-amd64:3.1
+	pauseContainerEKS = `image:(.*)amazonaws\.com/eks/pause(.*)`
 	// pauseContainerAzure regex matches:
 	// - k8s-gcrio.azureedge.net/pause-amd64
 	// - gcrio.azureedge.net/google_containers/pause-amd64
-	pauseContainerAzure   = `image:(.*)azureedge\.net(/google_containers/|/)pause(.*)`
-	pauseContainerRancher = `image:rancher/pause(.*)`
+	pauseContainerAzure    = `image:(.*)azureedge\.net(/google_containers/|/)pause(.*)`
+	pauseContainerAzureMCR = `image:mcr\.microsoft\.com/k8s/core/pause(.*)`
+	pauseContainerRancher  = `image:rancher/pause(.*)`
 )
 
+// pauseContainerImages are the images of the infra containers of the
+// orchestrators, excluded if exclude_pause_container is set. Their list is
+// extended with the pause_container_images option.
+var pauseContainerImages = []string{
+	pauseContainerGCR,
+	pauseContainerOpenshift,
+	pauseContainerOpenshift3,
+	pauseContainerKubernetes,
+	pauseContainerECS,
+	pauseContainerEKS,
+	pauseContainerAzure,
+	pauseContainerAzureMCR,
+	pauseContainerRancher,
+}
+
 // Filter holds the state for the container filtering logic
 type Filter struct {
 	Enabled        bool
@@ -38,7 +60,14 @@ type Filter struct {
 	NameBlacklist  []*regexp.Regexp
 }
 
-var sharedFilter *Filter
+var (
+	sharedFilter *Filter
+
+	// pauseFilter is built once, an invalid pattern leaves it nil
+	pauseFilter      *Filter
+	pauseFilterBuilt bool
+	pauseFilterMutex sync.Mutex
+)
 
 func parseFilters(filters []string) (imageFilters, nameFilters []*regexp.Regexp, err error) {
 	for _, filter := range filters {
@@ -80,6 +109,11 @@ func GetSharedFilter() (*Filter, error) {
 // filter instance to force re-parsing of the configuration.
 func ResetSharedFilter() {
 	sharedFilter = nil
+
+	pauseFilterMutex.Lock()
+	defer pauseFilterMutex.Unlock()
+	pauseFilter = nil
+	pauseFilterBuilt = false
 }
 
 // NewFilter creates a new container filter from a two slices of
@@ -112,18 +146,51 @@ func NewFilterFromConfig() (*Filter, error) {
 	blacklist := config.Datadog.GetStringSlice("ac_exclude")
 
 	if config.Datadog.GetBool("exclude_pause_container") {
-		blacklist = append(blacklist,
-			pauseContainerGCR,
-			pauseContainerOpenshift,
-			pauseContainerKubernetes,
-			pauseContainerAzure,
-			pauseContainerECS,
-			pauseContainerRancher,
-		)
+		blacklist = append(blacklist, getPauseContainerFilters()...)
 	}
 	return NewFilter(whitelist, blacklist)
 }
 
+// getPauseContainerFilters returns the image filters of the pause containers
+func getPauseContainerFilters() []string {
+	filters := append([]string{}, pauseContainerImages...)
+	for _, image := range config.Datadog.GetStringSlice("pause_container_images") {
+		filters = append(filters, "image:"+image)
+	}
+	return filters
+}
+
+// IsPauseContainer returns whether a container is the infra container of a
+// pod and is excluded as such. The pause containers are not counted in the
+// running containers.
+func IsPauseContainer(containerImage string) bool {
+	if !config.Datadog.GetBool("exclude_pause_container") {
+		return false
+	}
+	f := getPauseFilter()
+	if f == nil {
+		return false
+	}
+	return f.IsExcluded("", containerImage)
+}
+
+// getPauseFilter returns the filter of the pause containers, built on the
+// first call. Its error is only logged once.
+func getPauseFilter() *Filter {
+	pauseFilterMutex.Lock()
+	defer pauseFilterMutex.Unlock()
+
+	if !pauseFilterBuilt {
+		f, err := NewFilter(nil, getPauseContainerFilters())
+		if err != nil {
+			log.Errorf("Invalid pause_container_images, the pause containers are not detected: %s", err)
+		}
+		pauseFilter = f
+		pauseFilterBuilt = true
+	}
+	return pauseFilter
+}
+
 // NewFilterFromConfigIncludePause creates a new container filter, sourcing patterns
 // from the pkg/config options, but ignoring the exclude_pause_container option, for
 // use in autodiscovery
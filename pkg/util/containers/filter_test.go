@@ -178,3 +178,61 @@ func TestNewFilterFromConfigIncludePause(t *testing.T) {
 	config.Datadog.SetDefault("ac_include", []string{})
 	config.Datadog.SetDefault("ac_exclude", []string{})
 }
+
+func TestPauseContainerImages(t *testing.T) {
+	f, err := NewFilter(nil, pauseContainerImages)
+	require.NoError(t, err)
+
+	for _, image := range []string{
+		"k8s.gcr.io/pause-amd64:3.1",
+		"gcr.io/google_containers/pause-amd64:3.0",
+		"openshift/origin-pod:v3.9.0",
+		"registry.access.redhat.com/openshift3/ose-pod:v3.9",
+		"kubernetes/pause:latest",
+		"amazon/amazon-ecs-pause:0.1.0",
+		"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause-amd64:3.1",
+		"k8s-gcrio.azureedge.net/pause-amd64:3.0",
+		"mcr.microsoft.com/k8s/core/pause:1.0",
+		"rancher/pause-amd64:3.0",
+	} {
+		assert.True(t, f.IsExcluded("", image), image)
+	}
+	for _, image := range []string{
+		"gcr.io/random-project/superpause:1.0",
+		"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/kube-proxy:v1.10.3",
+		"mcr.microsoft.com/k8s/core/redis:1.0",
+		"datadog/agent:latest",
+	} {
+		assert.False(t, f.IsExcluded("", image), image)
+	}
+}
+
+func TestIsPauseContainer(t *testing.T) {
+	defer ResetSharedFilter()
+	config.Datadog.SetDefault("pause_container_images", []string{"my-registry.local/pause.*"})
+	defer config.Datadog.SetDefault("pause_container_images", []string{})
+
+	ResetSharedFilter()
+	assert.True(t, IsPauseContainer("k8s.gcr.io/pause-amd64:3.1"))
+	assert.True(t, IsPauseContainer("my-registry.local/pause:3.1"))
+	assert.False(t, IsPauseContainer("my-registry.local/redis:4.0"))
+
+	config.Datadog.SetDefault("exclude_pause_container", false)
+	defer config.Datadog.SetDefault("exclude_pause_container", true)
+	f, err := NewFilterFromConfig()
+	require.NoError(t, err)
+	assert.False(t, f.IsExcluded("dummy", "my-registry.local/pause:3.1"))
+	assert.False(t, IsPauseContainer("k8s.gcr.io/pause-amd64:3.1"))
+}
+
+func TestIsPauseContainerInvalidPattern(t *testing.T) {
+	defer ResetSharedFilter()
+	config.Datadog.SetDefault("pause_container_images", []string{"my-registry.local/pause("})
+	defer config.Datadog.SetDefault("pause_container_images", []string{})
+
+	ResetSharedFilter()
+	assert.False(t, IsPauseContainer("k8s.gcr.io/pause-amd64:3.1"))
+	assert.True(t, pauseFilterBuilt)
+	assert.Nil(t, pauseFilter)
+	assert.False(t, IsPauseContainer("k8s.gcr.io/pause-amd64:3.1"))
+}
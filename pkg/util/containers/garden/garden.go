@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+// Package garden lists the containers of a Cloud Foundry Diego cell from the
+// Garden API, and reads their metrics from it. The handles of the containers
+// are the GUIDs of the app instances they run.
+package garden
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/garden/client"
+	"code.cloudfoundry.org/garden/client/connection"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/retry"
+)
+
+// gardenStateActive is the state of the running containers
+const gardenStateActive = "active"
+
+var globalGardenUtil *GardenUtil
+
+// GardenUtil queries the Garden API of the cell
+type GardenUtil struct {
+	initRetry retry.Retrier
+	cli       garden.Client
+}
+
+// GetGardenUtil returns the global GardenUtil, it's initialized on the first
+// call and retried if the Garden API is not reachable
+func GetGardenUtil() (*GardenUtil, error) {
+	if globalGardenUtil == nil {
+		globalGardenUtil = &GardenUtil{}
+		globalGardenUtil.initRetry.SetupRetrier(&retry.Config{
+			Name:          "gardenutil",
+			AttemptMethod: globalGardenUtil.init,
+			Strategy:      retry.RetryCount,
+			RetryCount:    10,
+			RetryDelay:    30 * time.Second,
+		})
+	}
+	if err := globalGardenUtil.initRetry.TriggerRetry(); err != nil {
+		log.Debugf("Garden init error: %s", err)
+		return nil, err
+	}
+	return globalGardenUtil, nil
+}
+
+func (gu *GardenUtil) init() error {
+	if !config.Datadog.GetBool("cloud_foundry") {
+		return fmt.Errorf("cloud_foundry is not enabled")
+	}
+	network := config.Datadog.GetString("cloud_foundry_garden.listen_network")
+	address := config.Datadog.GetString("cloud_foundry_garden.listen_address")
+	gu.cli = client.New(connection.New(network, address))
+	return gu.cli.Ping()
+}
+
+// ContainerIDToEntityName returns the entity name of a garden container
+func ContainerIDToEntityName(handle string) string {
+	return containers.BuildEntityName(containers.RuntimeNameGarden, handle)
+}
+
+// ListContainers returns the containers of the cell, with their limits and
+// metrics
+func (gu *GardenUtil) ListContainers() ([]*containers.Container, error) {
+	gardenContainers, err := gu.cli.Containers(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing garden containers: %s", err)
+	}
+	handles := make([]string, 0, len(gardenContainers))
+	for _, gc := range gardenContainers {
+		handles = append(handles, gc.Handle())
+	}
+	infos, err := gu.cli.BulkInfo(handles)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the info of the garden containers: %s", err)
+	}
+
+	cList := make([]*containers.Container, 0, len(gardenContainers))
+	for _, gc := range gardenContainers {
+		handle := gc.Handle()
+		entry, ok := infos[handle]
+		if !ok || entry.Err != nil {
+			log.Debugf("No info for garden container %s, skipping: %v", handle, entry.Err)
+			continue
+		}
+
+		container := &containers.Container{
+			Type:     "Garden",
+			ID:       handle,
+			EntityID: ContainerIDToEntityName(handle),
+			Name:     handle,
+			State:    containers.ContainerUnknownState,
+		}
+		if entry.Info.State == gardenStateActive {
+			container.State = containers.ContainerRunningState
+		}
+		if limits, err := gc.CurrentMemoryLimits(); err == nil {
+			container.MemLimit = limits.LimitInBytes
+		} else {
+			log.Debugf("Cannot get the memory limit of garden container %s: %s", handle, err)
+		}
+		cList = append(cList, container)
+	}
+
+	err = gu.UpdateContainerMetrics(cList)
+	return cList, err
+}
+
+// UpdateContainerMetrics updates the metrics of a list of containers from
+// the Garden API, the cgroups of garden are not named after the containers
+func (gu *GardenUtil) UpdateContainerMetrics(cList []*containers.Container) error {
+	handles := make([]string, 0, len(cList))
+	for _, container := range cList {
+		if container.State == containers.ContainerRunningState {
+			handles = append(handles, container.ID)
+		}
+	}
+	if len(handles) == 0 {
+		return nil
+	}
+
+	gardenMetrics, err := gu.cli.BulkMetrics(handles)
+	if err != nil {
+		return fmt.Errorf("error getting the metrics of the garden containers: %s", err)
+	}
+
+	now := time.Now()
+	for _, container := range cList {
+		entry, ok := gardenMetrics[container.ID]
+		if !ok || entry.Err != nil {
+			continue
+		}
+		fillMetrics(container, entry.Metrics, now)
+	}
+	return nil
+}
+
+// fillMetrics sets the metrics of a container from its garden metrics, the
+// CPU times are converted to USER_HZ like the cgroup ones
+func fillMetrics(container *containers.Container, m garden.Metrics, now time.Time) {
+	container.CPU = &metrics.CgroupTimesStat{
+		ContainerID: container.ID,
+		User:        m.CPUStat.User / uint64(metrics.NanoToUserHZDivisor),
+		System:      m.CPUStat.System / uint64(metrics.NanoToUserHZDivisor),
+		UsageTotal:  float64(m.CPUStat.Usage) / metrics.NanoToUserHZDivisor,
+	}
+	container.Memory = &metrics.CgroupMemStat{
+		ContainerID: container.ID,
+		Cache:       m.MemoryStat.Cache,
+		RSS:         m.MemoryStat.Rss,
+		TotalCache:  m.MemoryStat.TotalCache,
+		TotalRSS:    m.MemoryStat.TotalRss,
+	}
+
+	if m.Age > 0 {
+		container.StartedAt = now.Add(-m.Age).Unix()
+		container.Created = container.StartedAt
+	}
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package garden
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func TestFillMetrics(t *testing.T) {
+	now := time.Unix(1540000000, 0)
+	container := &containers.Container{ID: "5c8a3a6e-9b1d-4c2e-6f7a-0b1c"}
+	fillMetrics(container, garden.Metrics{
+		Age: 10 * time.Minute,
+		CPUStat: garden.ContainerCPUStat{
+			Usage:  3000000000,
+			User:   2000000000,
+			System: 1000000000,
+		},
+		MemoryStat: garden.ContainerMemoryStat{
+			Cache:      1024,
+			Rss:        2048,
+			TotalCache: 4096,
+			TotalRss:   8192,
+		},
+	}, now)
+
+	require.NotNil(t, container.CPU)
+	assert.Equal(t, uint64(200), container.CPU.User)
+	assert.Equal(t, uint64(100), container.CPU.System)
+	assert.Equal(t, float64(300), container.CPU.UsageTotal)
+
+	require.NotNil(t, container.Memory)
+	assert.Equal(t, uint64(2048), container.Memory.RSS)
+	assert.Equal(t, uint64(8192), container.Memory.TotalRSS)
+
+	assert.Equal(t, int64(1539999400), container.StartedAt)
+	assert.Equal(t, container.StartedAt, container.Created)
+}
@@ -183,14 +183,34 @@ func ScrapeAllCgroups() (map[string]*ContainerCgroup, error) {
 // the PID is not in a container.
 //
 // Matching is tested for docker on known cgroup variations, and
-// containerd / cri-o default Kubernetes cgroups
+// containerd / cri-o / podman / systemd-nspawn cgroups, see cgroupLayouts
 func ContainerIDForPID(pid int) (string, error) {
-	cgPath := hostProc(strconv.Itoa(pid), "cgroup")
-	containerID, _, err := ReadCgroupsForPath(cgPath)
-
+	_, containerID, err := ContainerForPID(pid)
 	return containerID, err
 }
 
+// ContainerForPID returns the runtime and the ID of the container of a PID,
+// empty if the PID is not in a container. The runtime is empty if the cgroup
+// layout is shared by several runtimes, like the cgroupfs layout of the
+// kubelet.
+func ContainerForPID(pid int) (string, string, error) {
+	f, err := os.Open(hostProc(strconv.Itoa(pid), "cgroup"))
+	if os.IsNotExist(err) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if runtime, containerID, ok := containerFromCgroup(scanner.Text()); ok {
+			return runtime, containerID, nil
+		}
+	}
+	return "", "", scanner.Err()
+}
+
 // ReadCgroupsForPath reads the cgroups from a /proc/$pid/cgroup path.
 func ReadCgroupsForPath(pidCgroupPath string) (string, map[string]string, error) {
 	f, err := os.Open(pidCgroupPath)
@@ -255,14 +275,94 @@ func parseCgroupPaths(r io.Reader) (string, map[string]string, error) {
 	return containerID, paths, nil
 }
 
+// Container runtimes recognized from their cgroup layouts, they are the
+// runtime names of the containers package
+const (
+	cgroupRuntimeDocker     = "docker"
+	cgroupRuntimeContainerd = "containerd"
+	cgroupRuntimeCRIO       = "cri-o"
+	cgroupRuntimePodman     = "podman"
+	cgroupRuntimeNspawn     = "nspawn"
+)
+
+// cgroupLayouts are the cgroup paths of the containers of each runtime, the
+// first capturing group is the container ID. When a path matches several
+// times, like with docker in docker, the last match is the container.
+var cgroupLayouts = []struct {
+	runtime string
+	re      *regexp.Regexp
+}{
+	// systemd driver: /system.slice/docker-$id.scope, cgroupfs: /docker/$id
+	{cgroupRuntimeDocker, regexp.MustCompile(`(?:docker-|/docker/)([0-9a-f]{64})`)},
+	// kubelet systemd driver: .../cri-containerd-$id.scope, or
+	// /system.slice/containerd.service/kubepods-$qos-pod$uid.slice:cri-containerd:$id
+	{cgroupRuntimeContainerd, regexp.MustCompile(`cri-containerd[-:]([0-9a-f]{64})`)},
+	// kubelet systemd driver: .../crio-$id.scope
+	{cgroupRuntimeCRIO, regexp.MustCompile(`crio-([0-9a-f]{64})`)},
+	// systemd driver: /machine.slice/libpod-$id.scope, also under the
+	// user.slice when rootless, cgroupfs: /libpod_parent/libpod-$id
+	{cgroupRuntimePodman, regexp.MustCompile(`libpod-([0-9a-f]{64})`)},
+	// /machine.slice/systemd-nspawn@$name.service, the ID is the machine name
+	{cgroupRuntimeNspawn, regexp.MustCompile(`systemd-nspawn@([^/]+)\.service`)},
+}
+
+// conmonCgroupRe matches the cgroups of the conmon monitors of CRI-O and
+// podman, they run next to their container but not in it
+var conmonCgroupRe = regexp.MustCompile(`(?:crio|libpod)-conmon-[0-9a-f]{64}`)
+
 func containerIDFromCgroup(cgroup string) (string, bool) {
+	_, containerID, ok := containerFromCgroup(cgroup)
+	return containerID, ok
+}
+
+// containerFromCgroup returns the runtime and the ID of the container of a
+// line of a /proc/$pid/cgroup file. The runtime is empty when the path is a
+// bare container ID, as in the kubelet cgroupfs layout.
+func containerFromCgroup(cgroup string) (string, string, bool) {
 	sp := strings.SplitN(cgroup, ":", 3)
 	if len(sp) < 3 {
-		return "", false
+		return "", "", false
+	}
+	cgroupPath := sp[2]
+	if conmonCgroupRe.MatchString(cgroupPath) {
+		return "", "", false
+	}
+
+	for _, layout := range cgroupLayouts {
+		matches := layout.re.FindAllStringSubmatch(cgroupPath, -1)
+		if matches == nil {
+			continue
+		}
+		containerID := matches[len(matches)-1][1]
+		if layout.runtime == cgroupRuntimeNspawn {
+			containerID = unescapeSystemdUnit(containerID)
+		}
+		return layout.runtime, containerID, true
 	}
-	matches := containerRe.FindAllString(sp[2], -1)
+
+	matches := containerRe.FindAllString(cgroupPath, -1)
 	if matches == nil {
-		return "", false
+		return "", "", false
+	}
+	return "", matches[len(matches)-1], true
+}
+
+// unescapeSystemdUnit decodes the \xNN escapes of a systemd unit name, the
+// dashes of the machine names are escaped as \x2d
+func unescapeSystemdUnit(name string) string {
+	if !strings.Contains(name, `\x`) {
+		return name
+	}
+	var unescaped strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) && name[i+1] == 'x' {
+			if b, err := strconv.ParseUint(name[i+2:i+4], 16, 8); err == nil {
+				unescaped.WriteByte(byte(b))
+				i += 3
+				continue
+			}
+		}
+		unescaped.WriteByte(name[i])
 	}
-	return matches[len(matches)-1], true
+	return unescaped.String()
 }
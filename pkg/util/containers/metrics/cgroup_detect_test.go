@@ -285,6 +285,46 @@ func TestContainerIDFromCgroup(t *testing.T) {
 	}
 }
 
+func TestContainerFromCgroup(t *testing.T) {
+	id := "a27f1331f6ddf72629811aac65207949fc858ea90100c438768b531a4c540419"
+	for _, tc := range []struct {
+		cgroup  string
+		runtime string
+		id      string
+		ok      bool
+	}{
+		// docker, systemd and cgroupfs drivers
+		{"5:memory:/system.slice/docker-" + id + ".scope", "docker", id, true},
+		{"5:memory:/docker/" + id, "docker", id, true},
+		// kubelet cgroupfs driver, shared by the runtimes
+		{"4:memory:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/" + id, "", id, true},
+		// containerd, kubelet systemd driver
+		{"4:memory:/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod2baa3444_4d37_11e7_bd2f_080027d2bf10.slice/cri-containerd-" + id + ".scope", "containerd", id, true},
+		{"4:memory:/system.slice/containerd.service/kubepods-besteffort-pod2baa3444_4d37_11e7_bd2f_080027d2bf10.slice:cri-containerd:" + id, "containerd", id, true},
+		// CRI-O and its conmon monitor
+		{"4:memory:/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod2baa3444_4d37_11e7_bd2f_080027d2bf10.slice/crio-" + id + ".scope", "cri-o", id, true},
+		{"4:memory:/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod2baa3444_4d37_11e7_bd2f_080027d2bf10.slice/crio-conmon-" + id + ".scope", "", "", false},
+		// podman, rootful, rootless and cgroupfs, and its conmon monitor
+		{"4:memory:/machine.slice/libpod-" + id + ".scope", "podman", id, true},
+		{"1:name=systemd:/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + id + ".scope", "podman", id, true},
+		{"4:memory:/libpod_parent/libpod-" + id, "podman", id, true},
+		{"4:memory:/machine.slice/libpod-conmon-" + id + ".scope", "", "", false},
+		// systemd-nspawn, the ID is the machine name
+		{"4:memory:/machine.slice/systemd-nspawn@debian\\x2dstretch.service", "nspawn", "debian-stretch", true},
+		{"4:memory:/machine.slice/systemd-nspawn@web.service/payload", "nspawn", "web", true},
+		// not containers
+		{"4:memory:/user.slice/user-1000.slice/session-2.scope", "", "", false},
+		{"4:memory:/", "", "", false},
+	} {
+		t.Run(tc.cgroup, func(t *testing.T) {
+			runtime, containerID, ok := containerFromCgroup(tc.cgroup)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.runtime, runtime)
+			assert.Equal(t, tc.id, containerID)
+		})
+	}
+}
+
 // TestDindContainer is to test if our agent can handle dind container correctly
 func TestDindContainer(t *testing.T) {
 	containerID := "6ab998413f7ae63bb26403dfe9e7ec02aa92b5cfc019de79da925594786c985f"
@@ -38,9 +38,10 @@ var ErrNoContainerMatch = errors.New("cannot match a container ID")
 
 // EntityForPID returns the entity ID for a given PID. It can return
 // either ErrNoRuntimeMatch or ErrNoContainerMatch if no match its
-// found for the PID.
+// found for the PID. The runtime is given by the cgroup of the PID if its
+// layout is specific to a runtime, by the parent processes otherwise.
 func EntityForPID(pid int32) (string, error) {
-	cID, err := metrics.ContainerIDForPID(int(pid))
+	runtime, cID, err := metrics.ContainerForPID(int(pid))
 	if err != nil {
 		return "", err
 	}
@@ -48,9 +49,11 @@ func EntityForPID(pid int32) (string, error) {
 		return "", ErrNoContainerMatch
 	}
 
-	runtime, err := GetRuntimeForPID(pid)
-	if err != nil {
-		return "", err
+	if runtime == "" {
+		runtime, err = GetRuntimeForPID(pid)
+		if err != nil {
+			return "", err
+		}
 	}
 	return BuildEntityName(runtime, cID), nil
 }
@@ -14,6 +14,9 @@ const (
 	RuntimeNameDocker     string = "docker"
 	RuntimeNameContainerd string = "containerd"
 	RuntimeNameCRIO       string = "cri-o"
+	RuntimeNamePodman     string = "podman"
+	RuntimeNameNspawn     string = "nspawn"
+	RuntimeNameGarden     string = "garden"
 )
 
 // Supported container states
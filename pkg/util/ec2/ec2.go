@@ -16,6 +16,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// clusterTagPrefix prefixes the kubernetes.io/cluster/<name> tag EKS and the
+// kubernetes provisioners set on the instances of a cluster
+const clusterTagPrefix = "kubernetes.io/cluster/"
+
 // declare these as vars not const to ease testing
 var (
 	metadataURL         = "http://169.254.169.254/latest/meta-data"
@@ -71,6 +75,29 @@ func getResponse(url string) (*http.Response, error) {
 	return res, nil
 }
 
+// GetClusterName returns the name of the kubernetes cluster of the instance
+// from its tags, it requires the agent to be built with the ec2 tag
+func GetClusterName() (string, error) {
+	tags, err := GetTags()
+	if err != nil {
+		return "", err
+	}
+	return parseClusterName(tags)
+}
+
+func parseClusterName(tags []string) (string, error) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, clusterTagPrefix) {
+			// the tags are formatted as key:value, the key is the name
+			name := strings.SplitN(strings.TrimPrefix(tag, clusterTagPrefix), ":", 2)[0]
+			if name != "" {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no %s<name> tag on the instance", clusterTagPrefix)
+}
+
 // IsDefaultHostname returns whether the given hostname is a default one for EC2
 func IsDefaultHostname(hostname string) bool {
 	hostname = strings.ToLower(hostname)
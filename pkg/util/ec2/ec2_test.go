@@ -20,6 +20,15 @@ func TestIsDefaultHostname(t *testing.T) {
 	assert.False(t, IsDefaultHostname(""))
 }
 
+func TestParseClusterName(t *testing.T) {
+	name, err := parseClusterName([]string{"Name:node", "kubernetes.io/cluster/prod-eks:owned"})
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-eks", name)
+
+	_, err = parseClusterName([]string{"Name:node", "kubernetes.io/cluster/:owned"})
+	assert.Error(t, err)
+}
+
 func TestGetInstanceID(t *testing.T) {
 	expected := "i-0123456789abcdef0"
 	var lastRequest *http.Request
@@ -10,7 +10,9 @@ package ecs
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -54,6 +56,14 @@ type (
 		Containers    []ContainerV1 `json:"containers"`
 	}
 
+	// InstanceMetadataV1Response is the format of a response from the ECS
+	// metadata API, describing the container instance.
+	InstanceMetadataV1Response struct {
+		Cluster              string `json:"Cluster"`
+		ContainerInstanceArn string `json:"ContainerInstanceArn"`
+		Version              string `json:"Version"`
+	}
+
 	// ContainerV1 is the format of a Container in the ECS tasks API.
 	ContainerV1 struct {
 		DockerID   string `json:"DockerId"`
@@ -162,6 +172,22 @@ func (u *Util) GetTasks() (TasksV1Response, error) {
 	return resp, nil
 }
 
+// GetInstanceMetadata returns the cluster name and the ARN of the container
+// instance the local ECS agent runs on.
+func (u *Util) GetInstanceMetadata() (InstanceMetadataV1Response, error) {
+	var resp InstanceMetadataV1Response
+	r, err := http.Get(fmt.Sprintf("%sv1/metadata", u.agentURL))
+	if err != nil {
+		return resp, err
+	}
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
 // detectAgentURL finds a hostname for the ECS-agent either via Docker, if
 // running inside of a container, or just defaulting to localhost.
 func detectAgentURL() (string, error) {
@@ -186,7 +212,7 @@ func detectAgentURL() (string, error) {
 		for _, network := range ecsConfig.NetworkSettings.Networks {
 			ip := network.IPAddress
 			if ip != "" {
-				urls = append(urls, fmt.Sprintf("http://%s:%d/", ip, DefaultAgentPort))
+				urls = append(urls, fmt.Sprintf("http://%s/", net.JoinHostPort(ip, strconv.Itoa(DefaultAgentPort))))
 			}
 		}
 
@@ -198,7 +224,7 @@ func detectAgentURL() (string, error) {
 			return "", err
 		}
 		if gw != nil {
-			urls = append(urls, fmt.Sprintf("http://%s:%d/", gw.String(), DefaultAgentPort))
+			urls = append(urls, fmt.Sprintf("http://%s/", net.JoinHostPort(gw.String(), strconv.Itoa(DefaultAgentPort))))
 		}
 	}
 
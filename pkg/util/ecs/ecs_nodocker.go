@@ -25,3 +25,8 @@ func GetTaskMetadata() (TaskMetadata, error) {
 	var meta TaskMetadata
 	return meta, nil
 }
+
+// GetTags returns the ECS host tags
+func GetTags() ([]string, error) {
+	return nil, docker.ErrDockerNotCompiled
+}
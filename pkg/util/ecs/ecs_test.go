@@ -27,6 +27,7 @@ import (
 type dummyECS struct {
 	Requests     chan *http.Request
 	TaskListJSON string
+	MetadataJSON string
 }
 
 func newDummyECS() (*dummyECS, error) {
@@ -41,6 +42,8 @@ func (d *dummyECS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{"AvailableCommands":["/v1/metadata","/v1/tasks","/license"]}`))
 	case "/v1/tasks":
 		w.Write([]byte(d.TaskListJSON))
+	case "/v1/metadata":
+		w.Write([]byte(d.MetadataJSON))
 	default:
 		w.WriteHeader(http.StatusNotFound)
 	}
@@ -155,3 +158,27 @@ func TestLocateECSHTTP(t *testing.T) {
 		assert.FailNow("Timeout on receive channel")
 	}
 }
+
+func TestGetInstanceMetadata(t *testing.T) {
+	ecsinterface, err := newDummyECS()
+	require.Nil(t, err)
+	ecsinterface.MetadataJSON = `{
+		"Cluster": "default",
+		"ContainerInstanceArn": "arn:aws:ecs:us-east-1:<aws_account_id>:container-instance/example7-0c1d-4d4f-b6e9-2f1dexample",
+		"Version": "Amazon ECS Agent - v1.20.3 (a6ec7a9f)"
+	}`
+	ts, _, err := ecsinterface.Start()
+	defer ts.Close()
+	require.Nil(t, err)
+
+	util := &Util{agentURL: ts.URL + "/"}
+	meta, err := util.GetInstanceMetadata()
+	require.Nil(t, err)
+	assert.Equal(t, "default", meta.Cluster)
+	assert.Equal(t, []string{
+		"ecs_cluster_name:default",
+		"ecs_container_instance_arn:arn:aws:ecs:us-east-1:<aws_account_id>:container-instance/example7-0c1d-4d4f-b6e9-2f1dexample",
+	}, metadataToTags(meta))
+
+	assert.Empty(t, metadataToTags(InstanceMetadataV1Response{}))
+}
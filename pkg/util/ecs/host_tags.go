@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package ecs
+
+import (
+	"fmt"
+)
+
+// GetTags returns the ECS cluster name and container instance ARN of the
+// host as tags, on the EC2 launch type. Fargate tasks have no host.
+func GetTags() ([]string, error) {
+	if IsFargateInstance() {
+		return nil, nil
+	}
+
+	u, err := GetUtil()
+	if err != nil {
+		return nil, err
+	}
+	meta, err := u.GetInstanceMetadata()
+	if err != nil {
+		return nil, err
+	}
+	return metadataToTags(meta), nil
+}
+
+func metadataToTags(meta InstanceMetadataV1Response) []string {
+	var tags []string
+	if meta.Cluster != "" {
+		tags = append(tags, fmt.Sprintf("ecs_cluster_name:%s", meta.Cluster))
+	}
+	if meta.ContainerInstanceArn != "" {
+		tags = append(tags, fmt.Sprintf("ecs_container_instance_arn:%s", meta.ContainerInstanceArn))
+	}
+	return tags
+}
@@ -62,6 +62,16 @@ func GetHostAlias() (string, error) {
 	return fmt.Sprintf("%s.%s", instanceName, projectID), nil
 }
 
+// GetClusterName returns the name of the GKE cluster of the instance, GKE sets
+// it in the cluster-name attribute of its nodes
+func GetClusterName() (string, error) {
+	clusterName, err := getResponse(metadataURL + "/instance/attributes/cluster-name")
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve the cluster name from GCE: %s", err)
+	}
+	return clusterName, nil
+}
+
 func getResponse(url string) (string, error) {
 	client := http.Client{
 		Timeout: timeout,
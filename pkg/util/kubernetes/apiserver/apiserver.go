@@ -347,6 +347,16 @@ func (c *APIClient) NodeLabels(nodeName string) (map[string]string, error) {
 	return node.Labels, nil
 }
 
+// GetKubeSystemUID returns the UID of the kube-system namespace, it's unique
+// per cluster and never changes
+func (c *APIClient) GetKubeSystemUID() (string, error) {
+	namespace, err := c.Cl.CoreV1().Namespaces().Get("kube-system", metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(namespace.UID), nil
+}
+
 // GetMetadataMapBundleOnAllNodes is used for the CLI svcmap command to run fetch the metadata map of all nodes.
 func GetMetadataMapBundleOnAllNodes(cl *APIClient) (map[string]interface{}, error) {
 	nodePodMetadataMap := make(map[string]*MetadataMapperBundle)
@@ -419,3 +429,15 @@ func (c *APIClient) GetRESTObject(path string, output runtime.Object) error {
 
 	return result.Into(output)
 }
+
+// GetRawEndpoint queries a non-resource path of the APIserver (eg. `/healthz`
+// or `/metrics`) and returns the raw response body. For health endpoints, an
+// unhealthy status is returned as an error, verbose lists the individual checks
+// in the body.
+func (c *APIClient) GetRawEndpoint(path string, verbose bool) ([]byte, error) {
+	req := c.Cl.CoreV1().RESTClient().Get().AbsPath(path)
+	if verbose {
+		req = req.Param("verbose", "true")
+	}
+	return req.DoRaw()
+}
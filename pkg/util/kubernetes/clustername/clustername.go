@@ -6,15 +6,48 @@
 package clustername
 
 import (
+	"regexp"
+	"strings"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/azure"
+	"github.com/DataDog/datadog-agent/pkg/util/ec2"
+	"github.com/DataDog/datadog-agent/pkg/util/gce"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// the discovered cluster names must be valid lowercase DNS names of at most
+// 40 characters, like the configured ones
+const maxClusterNameLength = 40
+
+var validClusterName = regexp.MustCompile(`^[a-z0-9]([a-z0-9\-.]*[a-z0-9])?$`)
+
+type provider struct {
+	name           string
+	getClusterName func() (string, error)
+}
+
+// providers discover the cluster name through the k8s providers' API, in
+// order. They are only queried when the agent runs on kubernetes.
+// for testing purpose
+var providers = []provider{
+	{"GKE", gce.GetClusterName},
+	{"EKS", ec2.GetClusterName},
+	{"AKS", azure.GetClusterName},
+}
+
+// for testing purpose
+var getKubeSystemUID = kubeSystemUID
+
 type clusterNameData struct {
-	clusterName string
-	initDone    bool
-	mutex       sync.Mutex
+	clusterName    string
+	initDone       bool
+	discoveredName string
+	discoveryDone  bool
+	uid            string
+	uidDone        bool
+	mutex          sync.Mutex
 }
 
 func newClusterNameData() *clusterNameData {
@@ -33,21 +66,98 @@ func getClusterName(data *clusterNameData) string {
 
 	if !data.initDone {
 		data.clusterName = config.Datadog.GetString("cluster_name")
-		// TODO: autodiscover clustername through k8s providers' API
 		data.initDone = true
 	}
 	return data.clusterName
 }
 
-// GetClusterName returns a k8s cluster name if it exists, either directly specified or autodiscovered
+func getClusterNameOrDiscovered(data *clusterNameData) string {
+	if name := getClusterName(data); name != "" {
+		return name
+	}
+
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+	if !data.discoveryDone {
+		data.discoveredName = discoverClusterName()
+		data.discoveryDone = true
+	}
+	return data.discoveredName
+}
+
+// discoverClusterName returns the name returned by the first k8s provider
+// which knows it
+func discoverClusterName() string {
+	if !config.IsKubernetes() {
+		return ""
+	}
+
+	for _, p := range providers {
+		name, err := p.getClusterName()
+		if err != nil {
+			log.Debugf("Cannot get the cluster name from %s: %s", p.name, err)
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isValidClusterName(name) {
+			log.Infof("Ignoring the cluster name %q discovered from %s, it isn't a valid DNS name", name, p.name)
+			continue
+		}
+		log.Infof("Cluster name %s discovered from %s", name, p.name)
+		return name
+	}
+	return ""
+}
+
+func isValidClusterName(name string) bool {
+	return len(name) <= maxClusterNameLength && validClusterName.MatchString(name)
+}
+
+// GetClusterName returns the configured k8s cluster name, used in the
+// hostnames. The discovered names are not used, they would change the
+// hostname of the existing nodes.
 func GetClusterName() string {
 	return getClusterName(defaultClusterNameData)
 }
 
+// GetClusterNameOrDiscovered returns the configured k8s cluster name, or the
+// one discovered through the k8s providers' API. It's only used in the tags.
+func GetClusterNameOrDiscovered() string {
+	return getClusterNameOrDiscovered(defaultClusterNameData)
+}
+
+func getClusterNameOrUID(data *clusterNameData) string {
+	if name := getClusterNameOrDiscovered(data); name != "" {
+		return name
+	}
+
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+	if !data.uidDone {
+		uid, err := getKubeSystemUID()
+		if err != nil {
+			log.Debugf("Cannot get the UID of the kube-system namespace: %s", err)
+		}
+		data.uid = uid
+		data.uidDone = true
+	}
+	return data.uid
+}
+
+// GetClusterNameOrUID returns the configured or discovered cluster name, or
+// the UID of the kube-system namespace if no cluster name was set or
+// discovered. It identifies the cluster in the tags, it isn't used in the
+// hostnames.
+func GetClusterNameOrUID() string {
+	return getClusterNameOrUID(defaultClusterNameData)
+}
+
 func resetClusterName(data *clusterNameData) {
 	data.mutex.Lock()
 	defer data.mutex.Unlock()
 	data.initDone = false
+	data.discoveryDone = false
+	data.uidDone = false
 }
 
 // ResetClusterName resets the clustername, which allows it to be detected again. Used for tests
@@ -6,6 +6,8 @@
 package clustername
 
 import (
+	"errors"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,42 @@ func TestGetClusterName(t *testing.T) {
 	freshData := newClusterNameData()
 	assert.Equal(t, newClusterName, getClusterName(freshData))
 }
+
+func TestDiscoverClusterName(t *testing.T) {
+	defer func(p []provider) { providers = p }(providers)
+	os.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	defer os.Unsetenv("KUBERNETES_SERVICE_PORT")
+
+	providers = []provider{
+		{"failing", func() (string, error) { return "", errors.New("not on this provider") }},
+		{"invalid", func() (string, error) { return "not_a_dns_name", nil }},
+		{"valid", func() (string, error) { return "Prod-Cluster\n", nil }},
+		{"ignored", func() (string, error) { return "other", nil }},
+	}
+	data := newClusterNameData()
+	assert.Equal(t, "prod-cluster", getClusterNameOrDiscovered(data))
+	// the discovered name is not used in the hostnames
+	assert.Equal(t, "", getClusterName(data))
+
+	// the configured name takes precedence
+	config.Datadog.Set("cluster_name", "Laika")
+	defer config.Datadog.Set("cluster_name", nil)
+	assert.Equal(t, "Laika", getClusterNameOrDiscovered(newClusterNameData()))
+}
+
+func TestGetClusterNameOrUID(t *testing.T) {
+	defer func(p []provider) { providers = p }(providers)
+	defer func(f func() (string, error)) { getKubeSystemUID = f }(getKubeSystemUID)
+	os.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	defer os.Unsetenv("KUBERNETES_SERVICE_PORT")
+
+	providers = nil
+	getKubeSystemUID = func() (string, error) { return "4b0c5ab1-6ba9-11e8-9d5b-42010a840012", nil }
+	data := newClusterNameData()
+	assert.Equal(t, "", getClusterNameOrDiscovered(data))
+	assert.Equal(t, "4b0c5ab1-6ba9-11e8-9d5b-42010a840012", getClusterNameOrUID(data))
+
+	config.Datadog.Set("cluster_name", "laika")
+	defer config.Datadog.Set("cluster_name", nil)
+	assert.Equal(t, "laika", getClusterNameOrUID(newClusterNameData()))
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package clustername
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+// kubeSystemUID returns the UID of the kube-system namespace from the API
+// server, it's only queried if the agent collects the metadata of the pods
+// from the API server
+func kubeSystemUID() (string, error) {
+	if !config.IsKubernetes() || !config.Datadog.GetBool("kubernetes_collect_metadata_tags") {
+		return "", nil
+	}
+	cl, err := apiserver.GetAPIClient()
+	if err != nil {
+		return "", err
+	}
+	return cl.GetKubeSystemUID()
+}
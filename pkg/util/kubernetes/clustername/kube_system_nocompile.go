@@ -0,0 +1,13 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package clustername
+
+// kubeSystemUID returns an empty UID, the API server support is not compiled in
+func kubeSystemUID() (string, error) {
+	return "", nil
+}
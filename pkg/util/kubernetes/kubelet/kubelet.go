@@ -12,8 +12,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -129,7 +131,7 @@ func (ku *KubeUtil) GetHostname() (string, error) {
 
 	clusterName := clustername.GetClusterName()
 	if clusterName == "" {
-		log.Debugf("Now using plain kubernetes nodename as an alias: no cluster name was set and none could be autodiscovered")
+		log.Debugf("Now using plain kubernetes nodename as an alias: no cluster_name was set")
 		return nodeName, nil
 	} else {
 		return (nodeName + "-" + clusterName), nil
@@ -423,7 +425,7 @@ func (ku *KubeUtil) GetRawMetrics() ([]byte, error) {
 
 func (ku *KubeUtil) setupKubeletApiEndpoint() error {
 	// HTTPS
-	ku.kubeletApiEndpoint = fmt.Sprintf("https://%s:%d", ku.kubeletHost, config.Datadog.GetInt("kubernetes_https_kubelet_port"))
+	ku.kubeletApiEndpoint = fmt.Sprintf("https://%s", net.JoinHostPort(ku.kubeletHost, strconv.Itoa(config.Datadog.GetInt("kubernetes_https_kubelet_port"))))
 	_, code, httpsUrlErr := ku.QueryKubelet(kubeletPodPath)
 	if httpsUrlErr == nil {
 		if code == http.StatusOK {
@@ -441,7 +443,7 @@ func (ku *KubeUtil) setupKubeletApiEndpoint() error {
 	ku.resetCredentials()
 
 	// HTTP
-	ku.kubeletApiEndpoint = fmt.Sprintf("http://%s:%d", ku.kubeletHost, config.Datadog.GetInt("kubernetes_http_kubelet_port"))
+	ku.kubeletApiEndpoint = fmt.Sprintf("http://%s", net.JoinHostPort(ku.kubeletHost, strconv.Itoa(config.Datadog.GetInt("kubernetes_http_kubelet_port"))))
 	_, code, httpUrlErr := ku.QueryKubelet(kubeletPodPath)
 	if httpUrlErr == nil {
 		if code == http.StatusOK {
@@ -459,7 +461,7 @@ func (ku *KubeUtil) init() error {
 	var err error
 
 	// setting the kubeletHost
-	ku.kubeletHost = config.Datadog.GetString("kubernetes_kubelet_host")
+	ku.kubeletHost = config.NormalizeHost(config.Datadog.GetString("kubernetes_kubelet_host"))
 	if ku.kubeletHost == "" {
 		ku.kubeletHost, err = docker.HostnameProvider("")
 		if err != nil {
@@ -472,11 +474,11 @@ func (ku *KubeUtil) init() error {
 	c.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 
 	// HTTPS first
-	if _, errHTTPS := c.Get(fmt.Sprintf("https://%s:%d/", ku.kubeletHost, config.Datadog.GetInt("kubernetes_https_kubelet_port"))); errHTTPS != nil {
+	if _, errHTTPS := c.Get(fmt.Sprintf("https://%s/", net.JoinHostPort(ku.kubeletHost, strconv.Itoa(config.Datadog.GetInt("kubernetes_https_kubelet_port"))))); errHTTPS != nil {
 		log.Debugf("Cannot connect through HTTPS: %s, trying through http", errHTTPS)
 
 		// Only try the HTTP if HTTPS failed
-		if _, errHTTP := c.Get(fmt.Sprintf("http://%s:%d/", ku.kubeletHost, config.Datadog.GetInt("kubernetes_http_kubelet_port"))); errHTTP != nil {
+		if _, errHTTP := c.Get(fmt.Sprintf("http://%s/", net.JoinHostPort(ku.kubeletHost, strconv.Itoa(config.Datadog.GetInt("kubernetes_http_kubelet_port"))))); errHTTP != nil {
 			log.Debugf("Cannot connect through HTTP: %s", errHTTP)
 			return fmt.Errorf("cannot connect: https: %q, http: %q", errHTTPS, errHTTP)
 		}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+// throttleInterval is the minimum interval between two logs of the errors
+// sharing a throttling key
+const throttleInterval = 5 * time.Minute
+
+type throttledError struct {
+	lastLog    time.Time
+	suppressed int
+}
+
+var (
+	throttledErrors = map[string]*throttledError{}
+	throttleMutex   sync.Mutex
+
+	// for testing purpose
+	throttleNow = time.Now
+)
+
+// ErrorfThrottled logs with format at the error level like Errorf, but the
+// repeated errors sharing a key are collapsed: they are logged at most once
+// every 5 minutes, with the number of errors suppressed since the last log.
+// It's meant for the errors of sustained failures retried in a loop, for
+// instance an unreachable container runtime. It returns an error containing
+// the formated log message.
+func ErrorfThrottled(key string, format string, params ...interface{}) error {
+	suppressed, since, ok := allowThrottled(key)
+	if !ok {
+		return formatErrorf(format, params...)
+	}
+	if suppressed > 0 {
+		format += " (%d similar errors suppressed in the last %s)"
+		params = append(params, suppressed, since)
+	}
+
+	if logger != nil && logger.inner != nil && logger.shouldLog(seelog.ErrorLvl) {
+		return logger.errorf(format, params...)
+	} else if bufferLogsBeforeInit && (logger == nil || logger.inner == nil) {
+		addLogToBuffer(func() { Errorf(format, params...) })
+	}
+	// We print the error to Stderr in case the agent exit before initializing the log module
+	err := formatErrorf(format, params...)
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+	return err
+}
+
+// allowThrottled returns whether an error of key can be logged, and the
+// number of errors suppressed since the last one and for how long
+func allowThrottled(key string) (int, time.Duration, bool) {
+	throttleMutex.Lock()
+	defer throttleMutex.Unlock()
+
+	now := throttleNow()
+	e, found := throttledErrors[key]
+	if !found {
+		throttledErrors[key] = &throttledError{lastLog: now}
+		return 0, 0, true
+	}
+	since := now.Sub(e.lastLog)
+	if since < throttleInterval {
+		e.suppressed++
+		return 0, 0, false
+	}
+	suppressed := e.suppressed
+	e.lastLog, e.suppressed = now, 0
+	return suppressed, since.Round(time.Second), true
+}
+
+// ResetThrottled forgets the errors logged with a key, the next one is logged
+// right away. It's meant to be called once the failure is resolved.
+func ResetThrottled(key string) {
+	throttleMutex.Lock()
+	defer throttleMutex.Unlock()
+
+	delete(throttledErrors, key)
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorfThrottled(t *testing.T) {
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+
+	l, err := seelog.LoggerFromWriterWithMinLevelAndFormat(w, seelog.DebugLvl, "[%LEVEL] %FuncShort: %Msg\n")
+	assert.Nil(t, err)
+	SetupDatadogLogger(l, "debug")
+
+	now := time.Now()
+	throttleNow = func() time.Time { return now }
+	defer func() { throttleNow = time.Now }()
+	defer ResetThrottled("runtime")
+
+	for i := 0; i < 10; i++ {
+		err := ErrorfThrottled("runtime", "connection refused: %s", "/var/run/crio.sock")
+		assert.EqualError(t, err, "connection refused: /var/run/crio.sock")
+		now = now.Add(10 * time.Second)
+	}
+	ErrorfThrottled("other", "other error")
+	w.Flush()
+	assert.Equal(t, 1, strings.Count(b.String(), "connection refused"))
+	assert.Equal(t, 1, strings.Count(b.String(), "other error"))
+
+	// the suppressed errors are counted in the next log
+	now = now.Add(5 * time.Minute)
+	ErrorfThrottled("runtime", "connection refused: %s", "/var/run/crio.sock")
+	w.Flush()
+	assert.Contains(t, b.String(), "connection refused: /var/run/crio.sock (9 similar errors suppressed in the last 6m40s)")
+
+	// the errors are logged right away once reset
+	b.Reset()
+	ResetThrottled("runtime")
+	ErrorfThrottled("runtime", "connection refused: %s", "/var/run/crio.sock")
+	w.Flush()
+	assert.Equal(t, "[ERROR] TestErrorfThrottled: connection refused: /var/run/crio.sock\n", b.String())
+}
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package podman
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ContainerIDToEntityName returns the entity name of a podman container
+func ContainerIDToEntityName(id string) string {
+	return containers.BuildEntityName(containers.RuntimeNamePodman, id)
+}
+
+// GetContainers returns the running containers listed by the podman API
+func (p *PodmanUtil) GetContainers() ([]Container, error) {
+	var cList []Container
+	if err := p.get("/libpod/containers/json", &cList); err != nil {
+		return nil, fmt.Errorf("error listing containers: %s", err)
+	}
+	return cList, nil
+}
+
+// ListContainers returns the podman containers not excluded by the container
+// filters, with the limits and metrics of their cgroups
+func (p *PodmanUtil) ListContainers() ([]*containers.Container, error) {
+	cgByContainer, err := metrics.ScrapeAllCgroups()
+	if err != nil {
+		return nil, fmt.Errorf("could not get cgroups: %s", err)
+	}
+
+	podmanContainers, err := p.GetContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	cList := make([]*containers.Container, 0, len(podmanContainers))
+	for _, c := range podmanContainers {
+		container := p.newContainer(c)
+		if container.Excluded {
+			continue
+		}
+		cList = append(cList, container)
+		if container.State != containers.ContainerRunningState {
+			continue
+		}
+
+		cgroup, ok := cgByContainer[container.ID]
+		if !ok {
+			log.Debugf("No matching cgroups for container %s, skipping", shortID(container.ID))
+			continue
+		}
+		container.SetCgroups(cgroup)
+		if err := container.FillCgroupLimits(); err != nil {
+			log.Debugf("Cannot get limits for container %s: %s, skipping", shortID(container.ID), err)
+			continue
+		}
+	}
+	err = p.UpdateContainerMetrics(cList)
+	return cList, err
+}
+
+// UpdateContainerMetrics updates the cgroup and network metrics of a list
+// of containers
+func (p *PodmanUtil) UpdateContainerMetrics(cList []*containers.Container) error {
+	for _, container := range cList {
+		if container.State != containers.ContainerRunningState || container.Excluded {
+			continue
+		}
+
+		if err := container.FillCgroupMetrics(); err != nil {
+			log.Debugf("Cannot get metrics for container %s: %s", shortID(container.ID), err)
+			continue
+		}
+		// podman doesn't name the networks of the containers
+		if err := container.FillNetworkMetrics(nil); err != nil {
+			log.Debugf("Cannot get network stats for container %s: %s", shortID(container.ID), err)
+			continue
+		}
+	}
+	return nil
+}
+
+// newContainer converts a container of the podman API
+func (p *PodmanUtil) newContainer(c Container) *containers.Container {
+	name := ""
+	if len(c.Names) > 0 {
+		name = strings.TrimPrefix(c.Names[0], "/")
+	}
+
+	return &containers.Container{
+		Type:      "Podman",
+		ID:        c.ID,
+		EntityID:  ContainerIDToEntityName(c.ID),
+		Name:      name,
+		Image:     c.Image,
+		ImageID:   c.ImageID,
+		Created:   int64(c.Created),
+		StartedAt: c.StartedAt,
+		State:     c.State,
+		Excluded:  p.filter.IsExcluded(name, c.Image),
+	}
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/retry"
+)
+
+const (
+	apiVersion   = "v1.0.0"
+	queryTimeout = 5 * time.Second
+)
+
+var globalPodmanUtil *PodmanUtil
+
+// PodmanUtil queries the podman REST API, served on a unix socket by
+// `podman system service`. Podman has no daemon: the API is only used to
+// list the containers, their metrics are read from their cgroups.
+type PodmanUtil struct {
+	initRetry retry.Retrier
+
+	socketPath string
+	client     *http.Client
+	filter     *containers.Filter
+}
+
+// GetPodmanUtil returns the global PodmanUtil, it's initialized on the first
+// call and retried if the socket is not reachable
+func GetPodmanUtil() (*PodmanUtil, error) {
+	if globalPodmanUtil == nil {
+		globalPodmanUtil = &PodmanUtil{}
+		globalPodmanUtil.initRetry.SetupRetrier(&retry.Config{
+			Name:          "podmanutil",
+			AttemptMethod: globalPodmanUtil.init,
+			Strategy:      retry.RetryCount,
+			RetryCount:    10,
+			RetryDelay:    30 * time.Second,
+		})
+	}
+	if err := globalPodmanUtil.initRetry.TriggerRetry(); err != nil {
+		log.Debugf("Podman init error: %s", err)
+		return nil, err
+	}
+	return globalPodmanUtil, nil
+}
+
+// init connects to the podman socket and checks the API answers
+func (p *PodmanUtil) init() error {
+	p.socketPath = config.Datadog.GetString("podman_socket")
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", p.socketPath)
+			},
+		},
+		Timeout: queryTimeout,
+	}
+
+	var err error
+	p.filter, err = containers.GetSharedFilter()
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Get(p.url("/libpod/_ping"))
+	if err != nil {
+		return fmt.Errorf("podman socket %s is not reachable: %s", p.socketPath, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %q from the podman API", resp.Status)
+	}
+	return nil
+}
+
+// url returns the URL of an endpoint of the API, the host is ignored, the
+// requests are sent on the socket
+func (p *PodmanUtil) url(path string) string {
+	return "http://podman/" + apiVersion + path
+}
+
+// get queries an endpoint of the API and decodes its JSON response in v
+func (p *PodmanUtil) get(path string, v interface{}) error {
+	resp, err := p.client.Get(p.url(path))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %q from %s: %s", resp.Status, path, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
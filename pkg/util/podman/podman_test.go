@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package podman
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+const testContainers = `[
+  {
+    "Id": "e1f0f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f",
+    "Names": ["web"],
+    "Image": "docker.io/library/nginx:1.15",
+    "ImageID": "sha256:62f816a209e6",
+    "Labels": {"app": "web"},
+    "State": "running",
+    "Created": 1539532800,
+    "StartedAt": 1539532805
+  },
+  {
+    "Id": "0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9",
+    "Names": ["db"],
+    "Image": "docker.io/library/redis:4",
+    "State": "exited",
+    "Created": "2018-10-14T16:00:00Z"
+  }
+]`
+
+// startTestServer serves the podman API on a unix socket
+func startTestServer(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "podman")
+	require.NoError(t, err)
+	socketPath := filepath.Join(dir, "podman.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1.0.0/libpod/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("/v1.0.0/libpod/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testContainers))
+	})
+	go http.Serve(listener, mux)
+
+	return socketPath, func() {
+		listener.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestTimestampUnmarshal(t *testing.T) {
+	var ts Timestamp
+	require.NoError(t, json.Unmarshal([]byte(`1539532800`), &ts))
+	assert.Equal(t, Timestamp(1539532800), ts)
+	require.NoError(t, json.Unmarshal([]byte(`"2018-10-14T16:00:00Z"`), &ts))
+	assert.Equal(t, Timestamp(1539532800), ts)
+	assert.Error(t, json.Unmarshal([]byte(`"yesterday"`), &ts))
+}
+
+func TestGetContainers(t *testing.T) {
+	socketPath, stop := startTestServer(t)
+	defer stop()
+	defer config.Datadog.Set("podman_socket", config.Datadog.GetString("podman_socket"))
+	config.Datadog.Set("podman_socket", socketPath)
+
+	p := &PodmanUtil{}
+	require.NoError(t, p.init())
+
+	cList, err := p.GetContainers()
+	require.NoError(t, err)
+	require.Len(t, cList, 2)
+	assert.Equal(t, map[string]string{"app": "web"}, cList[0].Labels)
+
+	web := p.newContainer(cList[0])
+	assert.Equal(t, "podman://e1f0f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f", web.EntityID)
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, containers.ContainerRunningState, web.State)
+	assert.Equal(t, int64(1539532805), web.StartedAt)
+
+	db := p.newContainer(cList[1])
+	assert.Equal(t, int64(1539532800), db.Created)
+	assert.Equal(t, "exited", db.State)
+}
+
+func TestInitUnreachableSocket(t *testing.T) {
+	defer config.Datadog.Set("podman_socket", config.Datadog.GetString("podman_socket"))
+	config.Datadog.Set("podman_socket", "/nonexistent/podman.sock")
+
+	p := &PodmanUtil{}
+	assert.Error(t, p.init())
+}
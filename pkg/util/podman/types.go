@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package podman
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Container is a container listed by the podman API
+type Container struct {
+	ID        string            `json:"Id"`
+	Names     []string          `json:"Names"`
+	Image     string            `json:"Image"`
+	ImageID   string            `json:"ImageID"`
+	Labels    map[string]string `json:"Labels"`
+	State     string            `json:"State"`
+	Pid       int               `json:"Pid"`
+	Pod       string            `json:"Pod"`
+	PodName   string            `json:"PodName"`
+	Created   Timestamp         `json:"Created"`
+	StartedAt int64             `json:"StartedAt"`
+}
+
+// Timestamp is a unix timestamp, the podman API encodes it either as a number
+// of seconds or as an RFC 3339 date depending on its version
+type Timestamp int64
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*t = Timestamp(seconds)
+		return nil
+	}
+
+	var date time.Time
+	if err := json.Unmarshal(data, &date); err != nil {
+		return err
+	}
+	*t = Timestamp(date.Unix())
+	return nil
+}
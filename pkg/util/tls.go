@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// TLS cipher suites and curves approved by FIPS 140-2
+var (
+	fipsCipherSuites = []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}
+	fipsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+)
+
+// CreateTLSConfig returns the TLS configuration used by the agent to connect
+// to Datadog, or to the proxy intercepting its traffic. The CA bundle set in
+// `tls_ca_file` is trusted on top of the system roots, and the client
+// certificate set in `tls_client_cert_file` and `tls_client_key_file` is sent
+// to the servers requesting one. In FIPS mode, only the FIPS approved TLS
+// versions and cipher suites are allowed.
+func CreateTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
+	}
+
+	if config.Datadog.GetBool("force_tls_12") {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+
+	if config.Datadog.GetBool("fips.enabled") {
+		tlsConfig.MinVersion = tls.VersionTLS12
+		tlsConfig.CipherSuites = fipsCipherSuites
+		tlsConfig.CurvePreferences = fipsCurves
+		tlsConfig.PreferServerCipherSuites = true
+	}
+
+	if caFile := config.Datadog.GetString("tls_ca_file"); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certFile := config.Datadog.GetString("tls_client_cert_file")
+	keyFile := config.Datadog.GetString("tls_client_key_file")
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("tls_client_cert_file and tls_client_key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load the client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCertPool returns the system cert pool with the certificates of the PEM
+// bundle at path added to it
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the CA bundle: %s", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		// not available on Windows, only the bundle is trusted
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid certificate found in the CA bundle %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// writeSelfSignedCert writes a self signed certificate and its key in dir
+func writeSelfSignedCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certPath, keyPath
+}
+
+func resetTLSConfig() {
+	config.Datadog.Set("tls_ca_file", "")
+	config.Datadog.Set("tls_client_cert_file", "")
+	config.Datadog.Set("tls_client_key_file", "")
+}
+
+func TestCreateTLSConfigCustomCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer resetTLSConfig()
+
+	certPath, keyPath := writeSelfSignedCert(t, dir)
+	config.Datadog.Set("tls_ca_file", certPath)
+	config.Datadog.Set("tls_client_cert_file", certPath)
+	config.Datadog.Set("tls_client_key_file", keyPath)
+
+	tlsConfig, err := CreateTLSConfig()
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+}
+
+func TestCreateTLSConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer resetTLSConfig()
+
+	config.Datadog.Set("tls_ca_file", filepath.Join(dir, "missing.pem"))
+	_, err = CreateTLSConfig()
+	assert.Error(t, err)
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("not a certificate"), 0600))
+	config.Datadog.Set("tls_ca_file", invalid)
+	_, err = CreateTLSConfig()
+	assert.Error(t, err)
+
+	// the key is required with the certificate
+	certPath, _ := writeSelfSignedCert(t, dir)
+	config.Datadog.Set("tls_ca_file", "")
+	config.Datadog.Set("tls_client_cert_file", certPath)
+	_, err = CreateTLSConfig()
+	assert.Error(t, err)
+}
+
+func TestCreateTLSConfigFIPS(t *testing.T) {
+	config.Datadog.Set("fips.enabled", true)
+	defer config.Datadog.Set("fips.enabled", false)
+
+	tlsConfig, err := CreateTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, fipsCipherSuites, tlsConfig.CipherSuites)
+}
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package winutil
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// These helpers call the native APIs directly instead of querying WMI or
+// the performance counters, which both rely on the WMI service and make it
+// spike on busy hosts.
+
+var (
+	modntdll = syscall.NewLazyDLL("ntdll.dll")
+
+	procNtQuerySystemInformation = modntdll.NewProc("NtQuerySystemInformation")
+)
+
+// SYSTEM_INFORMATION_CLASS values
+const (
+	systemProcessInformation  = 5
+	systemPageFileInformation = 18
+)
+
+const (
+	statusInfoLengthMismatch = 0xC0000004
+	// threadStateReady is the KTHREAD_STATE of the threads waiting for a processor
+	threadStateReady = 1
+)
+
+// PerformanceInfoStat contains the system wide counters of GetPerformanceInfo
+type PerformanceInfoStat struct {
+	CommitTotal  uint64 // in bytes
+	CommitLimit  uint64 // in bytes
+	CommitPeak   uint64 // in bytes
+	PageSize     uint64
+	HandleCount  uint32
+	ProcessCount uint32
+	ThreadCount  uint32
+}
+
+// PageFileStat contains the usage of a paging file
+type PageFileStat struct {
+	Name  string
+	Total uint64 // in bytes
+	Used  uint64 // in bytes
+	Peak  uint64 // in bytes
+}
+
+// unicodeString is the UNICODE_STRING structure
+type unicodeString struct {
+	Length        uint16
+	MaximumLength uint16
+	Buffer        *uint16
+}
+
+// systemPageFileInformationEntry is the SYSTEM_PAGEFILE_INFORMATION structure
+type systemPageFileInformationEntry struct {
+	NextEntryOffset uint32
+	TotalSize       uint32 // in pages
+	TotalInUse      uint32 // in pages
+	PeakUsage       uint32 // in pages
+	PageFileName    unicodeString
+}
+
+// systemProcessInformationEntry is the SYSTEM_PROCESS_INFORMATION structure,
+// followed in memory by NumberOfThreads systemThreadInformationEntry
+type systemProcessInformationEntry struct {
+	NextEntryOffset              uint32
+	NumberOfThreads              uint32
+	WorkingSetPrivateSize        int64
+	HardFaultCount               uint32
+	NumberOfThreadsHighWatermark uint32
+	CycleTime                    uint64
+	CreateTime                   int64
+	UserTime                     int64
+	KernelTime                   int64
+	ImageName                    unicodeString
+	BasePriority                 int32
+	UniqueProcessID              uintptr
+	InheritedFromUniqueProcessID uintptr
+	HandleCount                  uint32
+	SessionID                    uint32
+	UniqueProcessKey             uintptr
+	PeakVirtualSize              uintptr
+	VirtualSize                  uintptr
+	PageFaultCount               uint32
+	PeakWorkingSetSize           uintptr
+	WorkingSetSize               uintptr
+	QuotaPeakPagedPoolUsage      uintptr
+	QuotaPagedPoolUsage          uintptr
+	QuotaPeakNonPagedPoolUsage   uintptr
+	QuotaNonPagedPoolUsage       uintptr
+	PagefileUsage                uintptr
+	PeakPagefileUsage            uintptr
+	PrivatePageCount             uintptr
+	ReadOperationCount           int64
+	WriteOperationCount          int64
+	OtherOperationCount          int64
+	ReadTransferCount            int64
+	WriteTransferCount           int64
+	OtherTransferCount           int64
+}
+
+// systemThreadInformationEntry is the SYSTEM_THREAD_INFORMATION structure
+type systemThreadInformationEntry struct {
+	KernelTime      int64
+	UserTime        int64
+	CreateTime      int64
+	WaitTime        uint32
+	StartAddress    uintptr
+	UniqueProcess   uintptr
+	UniqueThread    uintptr
+	Priority        int32
+	BasePriority    int32
+	ContextSwitches uint32
+	ThreadState     uint32
+	WaitReason      uint32
+}
+
+// PerformanceInfo returns the system wide counters of GetPerformanceInfo
+func PerformanceInfo() (*PerformanceInfoStat, error) {
+	var perfInfo performanceInformation
+	perfInfo.cb = uint32(unsafe.Sizeof(perfInfo))
+	ret, _, _ := procGetPerformanceInfo.Call(uintptr(unsafe.Pointer(&perfInfo)), uintptr(perfInfo.cb))
+	if ret == 0 {
+		return nil, windows.GetLastError()
+	}
+	return &PerformanceInfoStat{
+		CommitTotal:  perfInfo.commitTotal * perfInfo.pageSize,
+		CommitLimit:  perfInfo.commitLimit * perfInfo.pageSize,
+		CommitPeak:   perfInfo.commitPeak * perfInfo.pageSize,
+		PageSize:     perfInfo.pageSize,
+		HandleCount:  perfInfo.handleCount,
+		ProcessCount: perfInfo.processCount,
+		ThreadCount:  perfInfo.threadCount,
+	}, nil
+}
+
+// querySystemInformation calls NtQuerySystemInformation, growing the buffer
+// until the information fits in it
+func querySystemInformation(class uintptr) ([]byte, error) {
+	size := uint32(64 * 1024)
+	for i := 0; i < 10; i++ {
+		buf := make([]byte, size)
+		var needed uint32
+		status, _, _ := procNtQuerySystemInformation.Call(class, uintptr(unsafe.Pointer(&buf[0])), uintptr(size), uintptr(unsafe.Pointer(&needed)))
+		switch {
+		case status == 0:
+			return buf[:needed], nil
+		case status == statusInfoLengthMismatch:
+			// The information can grow between two calls
+			if needed > size {
+				size = needed
+			}
+			size += size / 2
+		default:
+			return nil, fmt.Errorf("NtQuerySystemInformation failed with status 0x%x", status)
+		}
+	}
+	return nil, fmt.Errorf("NtQuerySystemInformation: could not allocate a large enough buffer")
+}
+
+// ProcessorQueueLength returns the number of threads ready to run and
+// waiting for a processor, like the `Processor Queue Length` counter
+func ProcessorQueueLength() (uint64, error) {
+	buf, err := querySystemInformation(systemProcessInformation)
+	if err != nil {
+		return 0, err
+	}
+
+	var ready uint64
+	threadSize := unsafe.Sizeof(systemThreadInformationEntry{})
+	for offset := uintptr(0); offset+unsafe.Sizeof(systemProcessInformationEntry{}) <= uintptr(len(buf)); {
+		process := (*systemProcessInformationEntry)(unsafe.Pointer(&buf[offset]))
+		threads := offset + unsafe.Sizeof(*process)
+		for i := uintptr(0); i < uintptr(process.NumberOfThreads); i++ {
+			if threads+(i+1)*threadSize > uintptr(len(buf)) {
+				break
+			}
+			thread := (*systemThreadInformationEntry)(unsafe.Pointer(&buf[threads+i*threadSize]))
+			if thread.ThreadState == threadStateReady {
+				ready++
+			}
+		}
+		if process.NextEntryOffset == 0 {
+			break
+		}
+		offset += uintptr(process.NextEntryOffset)
+	}
+	return ready, nil
+}
+
+// PageFiles returns the usage of every paging file
+func PageFiles() ([]PageFileStat, error) {
+	perfInfo, err := PerformanceInfo()
+	if err != nil {
+		return nil, err
+	}
+	buf, err := querySystemInformation(systemPageFileInformation)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []PageFileStat
+	for offset := uintptr(0); offset+unsafe.Sizeof(systemPageFileInformationEntry{}) <= uintptr(len(buf)); {
+		entry := (*systemPageFileInformationEntry)(unsafe.Pointer(&buf[offset]))
+		name := ""
+		if entry.PageFileName.Buffer != nil {
+			name = windows.UTF16ToString((*[1 << 15]uint16)(unsafe.Pointer(entry.PageFileName.Buffer))[:entry.PageFileName.Length/2])
+			// The names are NT paths, like \??\C:\pagefile.sys
+			name = strings.TrimPrefix(name, `\??\`)
+		}
+		stats = append(stats, PageFileStat{
+			Name:  name,
+			Total: uint64(entry.TotalSize) * perfInfo.PageSize,
+			Used:  uint64(entry.TotalInUse) * perfInfo.PageSize,
+			Peak:  uint64(entry.PeakUsage) * perfInfo.PageSize,
+		})
+		if entry.NextEntryOffset == 0 {
+			break
+		}
+		offset += uintptr(entry.NextEntryOffset)
+	}
+	return stats, nil
+}
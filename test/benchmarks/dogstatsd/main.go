@@ -75,6 +75,9 @@ func (f *forwarderBenchStub) Start() error {
 func (f *forwarderBenchStub) Stop() {
 	return
 }
+func (f *forwarderBenchStub) Drain(timeout time.Duration) bool {
+	return true
+}
 
 func (f *forwarderBenchStub) SubmitV1Series(payloads forwarder.Payloads, extraHeaders http.Header) error {
 	f.computeStats(payloads)